@@ -0,0 +1,81 @@
+// Command reencrypt 重新加密用户敏感字段
+//
+// 在以下场景运行:
+//   - 主密钥轮换: 向 security.field_keys 追加新版本并重启服务后运行，完成后即可移除旧版本
+//   - 加密上线或更换盲索引密钥: 加密存量明文并回填盲索引
+//
+// 可与服务同时运行；被并发修改的行会跳过并计入 conflicts，重新运行即可。
+//
+// 用法: go run ./cmd/reencrypt -config config/config.yaml [-batch 500] [-dry-run]
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"arch3/internal/ioc"
+	userrepo "arch3/internal/repository/user"
+	"arch3/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	configPath := flag.String("config", "config/config.dev.yaml", "配置文件路径")
+	batchSize := flag.Int("batch", 500, "每批处理的行数")
+	dryRun := flag.Bool("dry-run", false, "只统计需要改写的行数，不写入")
+	flag.Parse()
+
+	if err := run(*configPath, *batchSize, *dryRun); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "reencrypt failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, batchSize int, dryRun bool) error {
+	if batchSize <= 0 {
+		return fmt.Errorf("batch must be positive")
+	}
+
+	cfg, err := ioc.InitConfig(configPath)
+	if err != nil {
+		return err
+	}
+	if err := ioc.InitLogger(cfg); err != nil {
+		return err
+	}
+	defer func() { _ = logger.Sync() }()
+
+	keys, err := ioc.InitKeyring(cfg)
+	if err != nil {
+		return err
+	}
+	db, err := ioc.InitDB(cfg)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer func() { _ = sqlDB.Close() }()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("reencrypt started",
+		zap.Int("active_key_version", keys.ActiveVersion()),
+		zap.Int("batch", batchSize),
+		zap.Bool("dry_run", dryRun),
+	)
+	stats, err := userrepo.NewReencryptor(userrepo.NewDAO(db), keys).Run(ctx, batchSize, dryRun)
+	logger.Info("reencrypt finished",
+		zap.Int("scanned", stats.Scanned),
+		zap.Int("updated", stats.Updated),
+		zap.Int("conflicts", stats.Conflicts),
+		zap.Error(err),
+	)
+	return err
+}
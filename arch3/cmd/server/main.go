@@ -36,6 +36,11 @@ func main() {
 func run() error {
 	flag.Parse()
 
+	// migrate 子命令: 只加载配置与数据库，不启动服务
+	if flag.Arg(0) == "migrate" {
+		return runMigrate(flag.Args()[1:])
+	}
+
 	// 创建可取消的 context，用于协调关闭
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
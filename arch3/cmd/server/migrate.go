@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"arch3/internal/ioc"
+	"arch3/internal/migration"
+	"arch3/pkg/migrate"
+)
+
+const migrateUsage = `用法: server [-config 配置文件] migrate <命令>
+
+命令:
+  up            执行全部未执行的迁移
+  down [n]      回滚最近执行的 n 个迁移（默认 1）
+  status        列出迁移及执行状态
+  create <name> 在 ` + migration.Dir + ` 下为每个驱动创建新的迁移文件（name 只含小写字母、数字与下划线）`
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n%s", migrateUsage)
+	}
+
+	// create 只生成文件，不需要连接数据库
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return fmt.Errorf("migrate create requires a name\n%s", migrateUsage)
+		}
+		dirs := make([]string, 0, len(migration.Drivers))
+		for _, driver := range migration.Drivers {
+			dirs = append(dirs, filepath.Join(migration.Dir, driver))
+		}
+		created, err := migrate.Create(args[1], dirs...)
+		if err != nil {
+			return err
+		}
+		for _, path := range created {
+			fmt.Printf("created %s\n", path)
+		}
+		return nil
+	}
+
+	steps := 1
+	switch args[0] {
+	case "up", "status":
+		if len(args) != 1 {
+			return fmt.Errorf("unexpected arguments %v\n%s", args[1:], migrateUsage)
+		}
+	case "down":
+		if len(args) > 2 {
+			return fmt.Errorf("unexpected arguments %v\n%s", args[2:], migrateUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+
+	cfg, err := ioc.InitConfig(configPath)
+	if err != nil {
+		return err
+	}
+	db, err := ioc.InitDB(cfg)
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer func() { _ = sqlDB.Close() }()
+	}
+	m, err := ioc.InitMigrator(cfg, db)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, mig := range applied {
+			fmt.Printf("applied  %s\n", mig)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+		return err
+	case "down":
+		reverted, err := m.Down(ctx, steps)
+		for _, mig := range reverted {
+			fmt.Printf("reverted %s\n", mig)
+		}
+		return err
+	default:
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, st := range statuses {
+			_, _ = fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", st.Version, st.Name, statusLabel(st), appliedAt(st))
+		}
+		return w.Flush()
+	}
+}
+
+// statusLabel 迁移状态的展示文本
+func statusLabel(st migrate.Status) string {
+	switch {
+	case st.Dirty:
+		return "dirty"
+	case st.Missing:
+		return "applied (file missing)"
+	case st.Applied:
+		return "applied"
+	default:
+		return "pending"
+	}
+}
+
+// appliedAt 执行时间的展示文本
+func appliedAt(st migrate.Status) string {
+	if !st.Applied {
+		return "-"
+	}
+	return st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+}
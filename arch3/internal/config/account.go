@@ -0,0 +1,12 @@
+package config
+
+// AccountConfig 账号注销配置
+type AccountConfig struct {
+	// DeletionCoolingOff 注销冷静期（天），期间可撤销
+	// 默认值: 15
+	DeletionCoolingOff int `mapstructure:"deletion_cooling_off"`
+
+	// PurgeInterval 冷静期结束账号的匿名化任务执行间隔（分钟）
+	// 默认值: 10
+	PurgeInterval int `mapstructure:"purge_interval"`
+}
@@ -0,0 +1,28 @@
+package config
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	// User 用户数据缓存
+	User UserCacheConfig `mapstructure:"user"`
+}
+
+// UserCacheConfig 用户数据缓存配置
+// 缓存按用户 ID 与手机号查询的结果，减少刷新令牌、鉴权等高频查询对数据库的压力
+type UserCacheConfig struct {
+	// Enabled 是否启用
+	// 默认值: true
+	Enabled bool `mapstructure:"enabled"`
+
+	// TTL 用户数据缓存时间（秒）
+	// 默认值: 600
+	TTL int `mapstructure:"ttl"`
+
+	// NegativeTTL 用户不存在的缓存时间（秒），应远小于 TTL
+	// 默认值: 30
+	NegativeTTL int `mapstructure:"negative_ttl"`
+
+	// Jitter TTL 随机延长的最大百分比，避免大量缓存同时过期
+	// 范围: 0-100
+	// 默认值: 10
+	Jitter int `mapstructure:"jitter"`
+}
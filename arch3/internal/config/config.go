@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
@@ -25,12 +26,48 @@ type Config struct {
 	// Redis Redis缓存配置
 	Redis RedisConfig `mapstructure:"redis"`
 
+	// Cache 数据缓存配置
+	Cache CacheConfig `mapstructure:"cache"`
+
 	// JWT JWT认证配置
 	JWT JWTConfig `mapstructure:"jwt"`
 
 	// SMS 短信服务配置
 	SMS SMSConfig `mapstructure:"sms"`
 
+	// Email 邮件服务配置
+	Email EmailConfig `mapstructure:"email"`
+
+	// Voice 语音验证码配置
+	Voice VoiceConfig `mapstructure:"voice"`
+
+	// OTP 一次性验证码配置
+	OTP OTPConfig `mapstructure:"otp"`
+
+	// Notification 通知服务配置
+	Notification NotificationConfig `mapstructure:"notification"`
+
+	// OSS 对象存储配置
+	OSS OSSConfig `mapstructure:"oss"`
+
+	// Security 数据安全配置
+	Security SecurityConfig `mapstructure:"security"`
+
+	// Identity 实名身份核验配置
+	Identity IdentityConfig `mapstructure:"identity"`
+
+	// Admin 管理员配置
+	Admin AdminConfig `mapstructure:"admin"`
+
+	// Account 账号注销配置
+	Account AccountConfig `mapstructure:"account"`
+
+	// Outbox 领域事件 outbox 配置
+	Outbox OutboxConfig `mapstructure:"outbox"`
+
+	// Retention 数据保留配置
+	Retention RetentionConfig `mapstructure:"retention"`
+
 	// Middleware 中间件配置
 	Middleware MiddlewareConfig `mapstructure:"middleware"`
 }
@@ -128,6 +165,33 @@ func validateProductionConfig(cfg *Config) error {
 		return fmt.Errorf("production config error: jwt.secret must be at least 32 characters")
 	}
 
+	// OTP Secret 必须配置（至少 32 字符）
+	if len(cfg.OTP.Secret) < 32 {
+		return fmt.Errorf("production config error: otp.secret must be at least 32 characters (use ECHO_OTP_SECRET env var)")
+	}
+
+	// 字段加密密钥与盲索引密钥必须配置（base64 编码的 32 字节）
+	keys, err := cfg.Security.ParseFieldKeys()
+	if err != nil {
+		return fmt.Errorf("production config error: %w", err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("production config error: security.field_keys must be configured (use ECHO_SECURITY_FIELD_KEYS env var)")
+	}
+	if key, err := base64.StdEncoding.DecodeString(cfg.Security.BlindIndexKey); err != nil || len(key) != 32 {
+		return fmt.Errorf("production config error: security.blind_index_key must be 32 bytes base64-encoded (use ECHO_SECURITY_BLIND_INDEX_KEY env var)")
+	}
+
+	// 模拟实名核验不得用于生产（未接入服务商时使用 manual）
+	if cfg.Identity.Provider == "fake" {
+		return fmt.Errorf("production config error: identity.provider must not be fake in release mode")
+	}
+
+	// 测试号码在 release 模式下必须显式允许
+	if cfg.OTP.TestPhones.Enabled && !cfg.OTP.TestPhones.AllowInRelease {
+		return fmt.Errorf("production config error: otp.test_phones is enabled in release mode, set otp.test_phones.allow_in_release to confirm")
+	}
+
 	return nil
 }
 
@@ -145,6 +209,9 @@ func setDefaults(v *viper.Viper) {
 	// Redis 默认值
 	setRedisDefaults(v)
 
+	// Cache 默认值
+	setCacheDefaults(v)
+
 	// JWT 默认值
 	setJWTDefaults(v)
 
@@ -153,6 +220,30 @@ func setDefaults(v *viper.Viper) {
 
 	// SMS 默认值
 	setSMSDefaults(v)
+
+	// Email 默认值
+	setEmailDefaults(v)
+
+	// Voice 默认值
+	setVoiceDefaults(v)
+
+	// OTP 默认值
+	setOTPDefaults(v)
+
+	// OSS 默认值
+	setOSSDefaults(v)
+
+	// Security/Identity 默认值
+	setSecurityDefaults(v)
+
+	// Account 默认值
+	setAccountDefaults(v)
+
+	// Outbox 默认值
+	setOutboxDefaults(v)
+
+	// Retention 默认值
+	setRetentionDefaults(v)
 }
 
 // setServerDefaults 设置服务器配置默认值
@@ -164,6 +255,7 @@ func setServerDefaults(v *viper.Viper) {
 	v.SetDefault("server.write_timeout", 30)
 	v.SetDefault("server.max_request_body", 67108864) // 64MB
 	v.SetDefault("server.shutdown_timeout", 10)
+	v.SetDefault("server.trusted_proxies", []string{})
 }
 
 // setLogDefaults 设置日志配置默认值
@@ -189,9 +281,15 @@ func setDBDefaults(v *viper.Viper) {
 	v.SetDefault("db.username", "root")
 	v.SetDefault("db.password", "")
 	v.SetDefault("db.database", "arch3")
+	v.SetDefault("db.ssl_mode", "disable")
 	v.SetDefault("db.max_idle_conns", 10)
 	v.SetDefault("db.max_open_conns", 100)
 	v.SetDefault("db.conn_max_lifetime", 3600)
+	v.SetDefault("db.auto_migrate", false)
+	v.SetDefault("db.replica_health_check_interval", 5)
+	v.SetDefault("db.tx_max_retries", 2)
+	v.SetDefault("db.slow_threshold", 200)
+	v.SetDefault("db.log_params", false)
 }
 
 // setRedisDefaults 设置Redis配置默认值
@@ -202,6 +300,14 @@ func setRedisDefaults(v *viper.Viper) {
 	v.SetDefault("redis.pool_size", 100)
 }
 
+// setCacheDefaults 设置缓存配置默认值
+func setCacheDefaults(v *viper.Viper) {
+	v.SetDefault("cache.user.enabled", true)
+	v.SetDefault("cache.user.ttl", 600)
+	v.SetDefault("cache.user.negative_ttl", 30)
+	v.SetDefault("cache.user.jitter", 10)
+}
+
 // setJWTDefaults 设置JWT配置默认值
 func setJWTDefaults(v *viper.Viper) {
 	v.SetDefault("jwt.secret", "")              // 必须通过 ECHO_JWT_SECRET 环境变量设置
@@ -219,7 +325,10 @@ func setMiddlewareDefaults(v *viper.Viper) {
 	v.SetDefault("middleware.cors.enabled", true)
 	v.SetDefault("middleware.cors.allow_origins", []string{"*"})
 	v.SetDefault("middleware.cors.allow_methods", []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"})
-	v.SetDefault("middleware.cors.allow_headers", []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Trace-ID"})
+	v.SetDefault("middleware.cors.allow_headers", []string{
+		"Origin", "Content-Type", "Accept", "Authorization", "X-Trace-ID",
+		"X-App-Version", "X-Platform", "X-Channel", "X-Device-ID", "X-UTM-Source", "X-UTM-Medium", "X-UTM-Campaign",
+	})
 	v.SetDefault("middleware.cors.expose_headers", []string{"Content-Length", "X-Trace-ID"})
 	v.SetDefault("middleware.cors.allow_credentials", true)
 	v.SetDefault("middleware.cors.max_age", 43200) // 12小时
@@ -267,4 +376,88 @@ func setSMSDefaults(v *viper.Viper) {
 	v.SetDefault("sms.templates.login", "")
 	v.SetDefault("sms.templates.register", "")
 	v.SetDefault("sms.templates.forget", "")
+	v.SetDefault("sms.templates.change_phone_old", "")
+	v.SetDefault("sms.templates.change_phone_new", "")
+	v.SetDefault("sms.templates.delete_account", "")
+}
+
+// setEmailDefaults 设置邮件服务配置默认值
+func setEmailDefaults(v *viper.Viper) {
+	v.SetDefault("email.enabled", false)
+	v.SetDefault("email.host", "")
+	v.SetDefault("email.port", 587)
+	v.SetDefault("email.username", "")
+	v.SetDefault("email.password", "")
+	v.SetDefault("email.from", "")
+	v.SetDefault("email.from_name", "")
+	v.SetDefault("email.tls_mode", "starttls")
+	v.SetDefault("email.timeout", 10)
+	v.SetDefault("email.verify.link_url", "http://localhost:3000/verify-email")
+	v.SetDefault("email.verify.ttl", 1440)
+	v.SetDefault("email.verify.resend_interval", 60)
+}
+
+// setVoiceDefaults 设置语音验证码配置默认值
+func setVoiceDefaults(v *viper.Viper) {
+	v.SetDefault("voice.enabled", false)
+	v.SetDefault("voice.provider", "mock")
+}
+
+// setOTPDefaults 设置验证码配置默认值
+func setOTPDefaults(v *viper.Viper) {
+	v.SetDefault("otp.secret", "") // 生产环境必须通过 ECHO_OTP_SECRET 环境变量设置
+	v.SetDefault("otp.test_phones.enabled", false)
+	v.SetDefault("otp.test_phones.allow_in_release", false)
+}
+
+// setOSSDefaults 设置对象存储配置默认值
+func setOSSDefaults(v *viper.Viper) {
+	v.SetDefault("oss.provider", "local")
+	v.SetDefault("oss.public_base_url", "")
+	v.SetDefault("oss.local.dir", "./data/uploads")
+	v.SetDefault("oss.local.serve_path", "/uploads")
+	v.SetDefault("oss.s3.region", "us-east-1")
+	v.SetDefault("oss.s3.path_style", false)
+	v.SetDefault("oss.s3.timeout", 10)
+}
+
+// setAccountDefaults 设置账号注销配置默认值
+func setAccountDefaults(v *viper.Viper) {
+	v.SetDefault("account.deletion_cooling_off", 15)
+	v.SetDefault("account.purge_interval", 10)
+}
+
+// setOutboxDefaults 设置领域事件 outbox 配置默认值
+func setOutboxDefaults(v *viper.Viper) {
+	v.SetDefault("outbox.stream", "arch3:events")
+	v.SetDefault("outbox.stream_max_len", 100000)
+	v.SetDefault("outbox.relay_interval", 1000)
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.max_attempts", 15)
+	v.SetDefault("outbox.retention", 7)
+	v.SetDefault("outbox.dead_retention", 30)
+	v.SetDefault("outbox.cleanup_interval", 60)
+	v.SetDefault("outbox.consumer_group", "arch3")
+	v.SetDefault("outbox.consume_interval", 1000)
+	v.SetDefault("outbox.consume_max_deliveries", 5)
+}
+
+// setRetentionDefaults 设置数据保留配置默认值
+func setRetentionDefaults(v *viper.Viper) {
+	v.SetDefault("retention.interval", 60)
+	v.SetDefault("retention.batch_size", 500)
+	v.SetDefault("retention.max_batches", 20)
+	v.SetDefault("retention.dry_run", false)
+	v.SetDefault("retention.login_history", 180)
+	v.SetDefault("retention.deleted_users", 365)
+	v.SetDefault("retention.unverified_users", 0)
+}
+
+// setSecurityDefaults 设置数据安全与实名核验配置默认值
+func setSecurityDefaults(v *viper.Viper) {
+	v.SetDefault("security.field_keys", []string{}) // 生产环境必须通过 ECHO_SECURITY_FIELD_KEYS 环境变量设置
+	v.SetDefault("security.field_key", "")
+	v.SetDefault("security.blind_index_key", "") // 生产环境必须通过 ECHO_SECURITY_BLIND_INDEX_KEY 环境变量设置
+	v.SetDefault("identity.provider", "fake")
+	v.SetDefault("admin.user_ids", []string{})
 }
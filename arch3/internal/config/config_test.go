@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// loadWithEnv 以空配置文件加载配置，env 中的环境变量覆盖默认值
+func loadWithEnv(t *testing.T, env map[string]string) *Config {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("server:\n  mode: debug\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	for k, v := range env {
+		t.Setenv(k, v)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg
+}
+
+func TestLoad_TrustedProxiesEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"空格分隔", "10.0.0.0/8 127.0.0.1", []string{"10.0.0.0/8", "127.0.0.1/32"}},
+		{"逗号分隔", "10.0.0.0/8,127.0.0.1", []string{"10.0.0.0/8", "127.0.0.1/32"}},
+		{"逗号与空格混合", "10.0.0.0/8, 127.0.0.1  ::1", []string{"10.0.0.0/8", "127.0.0.1/32", "::1/128"}},
+		{"单个地址", "172.16.0.0/12", []string{"172.16.0.0/12"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadWithEnv(t, map[string]string{"ECHO_SERVER_TRUSTED_PROXIES": tt.value})
+			cidrs, err := cfg.Server.ParseTrustedProxies()
+			if err != nil {
+				t.Fatalf("ParseTrustedProxies() error = %v", err)
+			}
+			got := make([]string, 0, len(cidrs))
+			for _, c := range cidrs {
+				got = append(got, c.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoad_FieldKeysEnv(t *testing.T) {
+	key1 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	key2 := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+
+	tests := []struct {
+		name         string
+		value        string
+		wantVersions []int
+	}{
+		{"空格分隔", "1:" + key1 + " 2:" + key2, []int{1, 2}},
+		{"逗号分隔", "1:" + key1 + ",2:" + key2, []int{1, 2}},
+		{"单个密钥", "2:" + key2, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := loadWithEnv(t, map[string]string{"ECHO_SECURITY_FIELD_KEYS": tt.value})
+			keys, err := cfg.Security.ParseFieldKeys()
+			if err != nil {
+				t.Fatalf("ParseFieldKeys() error = %v", err)
+			}
+			if len(keys) != len(tt.wantVersions) {
+				t.Fatalf("Expected %d keys, got %d", len(tt.wantVersions), len(keys))
+			}
+			for _, v := range tt.wantVersions {
+				if want := bytes.Repeat([]byte{byte(v)}, 32); !bytes.Equal(keys[v], want) {
+					t.Errorf("Expected key version %d decoded, got %x", v, keys[v])
+				}
+			}
+		})
+	}
+}
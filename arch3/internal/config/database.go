@@ -1,9 +1,12 @@
 package config
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // DBConfig 数据库配置
-// 支持MySQL等关系型数据库的连接池配置
+// 支持 MySQL、PostgreSQL、SQLite 及其连接池配置
 type DBConfig struct {
 	// Driver 数据库驱动类型
 	// 可选值: mysql, postgres, sqlite
@@ -26,8 +29,14 @@ type DBConfig struct {
 	Password string `mapstructure:"password"`
 
 	// Database 数据库名称
+	// sqlite 驱动下为数据库文件路径，":memory:" 表示内存库
 	Database string `mapstructure:"database"`
 
+	// SSLMode PostgreSQL 的 sslmode 参数
+	// 可选值: disable, require, verify-ca, verify-full
+	// 默认值: "disable"
+	SSLMode string `mapstructure:"ssl_mode"`
+
 	// MaxIdleConns 连接池最大空闲连接数
 	// 默认值: 10
 	MaxIdleConns int `mapstructure:"max_idle_conns"`
@@ -39,10 +48,89 @@ type DBConfig struct {
 	// ConnMaxLifetime 连接最大生命周期(秒)
 	// 默认值: 3600 (1小时)
 	ConnMaxLifetime int `mapstructure:"conn_max_lifetime"`
+
+	// AutoMigrate 启动时自动执行未执行的迁移
+	// 仅建议在开发环境开启，生产环境通过 migrate up 子命令在发布前执行
+	// 默认值: false
+	AutoMigrate bool `mapstructure:"auto_migrate"`
+
+	// Replicas 从库列表，配置后读请求路由到从库，写请求与事务使用主库
+	// 为空时不启用读写分离；sqlite 驱动不支持从库
+	Replicas []DBReplicaConfig `mapstructure:"replicas"`
+
+	// ReplicaHealthCheckInterval 从库健康检查间隔(秒)
+	// 不健康的从库不接收读请求，全部不健康时读请求回退到主库
+	// 默认值: 5
+	ReplicaHealthCheckInterval int `mapstructure:"replica_health_check_interval"`
+
+	// TxMaxRetries 事务因死锁或序列化冲突失败时的最大重试次数，0 表示不重试
+	// 默认值: 2
+	TxMaxRetries int `mapstructure:"tx_max_retries"`
+
+	// SlowThreshold 慢查询阈值(毫秒)，超过该值的 SQL 以 Warn 级别记录，0 表示不记录慢查询
+	// 默认值: 200
+	SlowThreshold int `mapstructure:"slow_threshold"`
+
+	// LogParams SQL 日志是否输出参数值
+	// 默认只输出带占位符的 SQL，避免手机号、密文等敏感数据写入日志
+	// 默认值: false
+	LogParams bool `mapstructure:"log_params"`
+}
+
+// DBReplicaConfig 从库配置
+// 库名、驱动与连接池参数与主库相同；用户名、密码为空时沿用主库
+type DBReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// Replica 返回从库的完整连接配置
+func (c *DBConfig) Replica(r DBReplicaConfig) DBConfig {
+	replica := *c
+	replica.Replicas = nil
+	replica.Host = r.Host
+	if r.Port != 0 {
+		replica.Port = r.Port
+	}
+	if r.Username != "" {
+		replica.Username = r.Username
+	}
+	if r.Password != "" {
+		replica.Password = r.Password
+	}
+	return replica
+}
+
+// Addr 返回 host:port，用于日志与指标
+func (c *DBConfig) Addr() string {
+	return c.Host + ":" + strconv.Itoa(c.Port)
 }
 
 // DSN 返回数据库连接字符串
-// MySQL格式: user:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local
+//
+// 格式:
+//   - mysql: user:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local
+//   - postgres: host=... port=... user=... password=... dbname=... sslmode=...
+//   - sqlite: 文件路径（或 :memory:），附带外键与忙等待参数
 func (c *DBConfig) DSN() string {
-	return c.Username + ":" + c.Password + "@tcp(" + c.Host + ":" + strconv.Itoa(c.Port) + ")/" + c.Database + "?charset=utf8mb4&parseTime=True&loc=Local"
+	switch c.Driver {
+	case "postgres":
+		return "host=" + pgQuote(c.Host) +
+			" port=" + strconv.Itoa(c.Port) +
+			" user=" + pgQuote(c.Username) +
+			" password=" + pgQuote(c.Password) +
+			" dbname=" + pgQuote(c.Database) +
+			" sslmode=" + pgQuote(c.SSLMode)
+	case "sqlite":
+		return c.Database + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+	default:
+		return c.Username + ":" + c.Password + "@tcp(" + c.Host + ":" + strconv.Itoa(c.Port) + ")/" + c.Database + "?charset=utf8mb4&parseTime=True&loc=Local"
+	}
+}
+
+// pgQuote 按 libpq 关键字/值格式引用参数值，值可包含空格与引号
+func pgQuote(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
 }
@@ -0,0 +1,57 @@
+package config
+
+// EmailConfig 邮件服务配置
+// 用于发送验证码等邮件，基于 SMTP 协议
+type EmailConfig struct {
+	// Enabled 是否启用邮件渠道
+	// 默认值: false
+	Enabled bool `mapstructure:"enabled"`
+
+	// Host SMTP 服务器地址
+	Host string `mapstructure:"host"`
+
+	// Port SMTP 端口
+	// 587(STARTTLS) / 465(隐式 TLS) / 25
+	// 默认值: 587
+	Port int `mapstructure:"port"`
+
+	// Username SMTP 认证用户名，为空时不认证
+	Username string `mapstructure:"username"`
+
+	// Password SMTP 认证密码
+	Password string `mapstructure:"password"`
+
+	// From 发件人地址
+	From string `mapstructure:"from"`
+
+	// FromName 发件人名称
+	FromName string `mapstructure:"from_name"`
+
+	// TLSMode TLS 模式
+	// 可选值: starttls, tls, none
+	// 默认值: "starttls"
+	TLSMode string `mapstructure:"tls_mode"`
+
+	// Timeout 连接与收发超时(秒)
+	// 默认值: 10
+	Timeout int `mapstructure:"timeout"`
+
+	// Verify 邮箱验证链接配置
+	Verify EmailVerifyConfig `mapstructure:"verify"`
+}
+
+// EmailVerifyConfig 邮箱验证链接配置
+// 链接中的 token 使用 otp.secret 派生的密钥签名，无需额外存储
+type EmailVerifyConfig struct {
+	// LinkURL 验证页面地址，邮件中的链接为 {link_url}?token=xxx
+	// 页面取出 token 后调用 POST /api/v1/user/email/verify 完成验证
+	LinkURL string `mapstructure:"link_url"`
+
+	// TTL 链接有效期(分钟)
+	// 默认值: 1440
+	TTL int `mapstructure:"ttl"`
+
+	// ResendInterval 同一用户两次发送验证邮件的最小间隔(秒)
+	// 默认值: 60
+	ResendInterval int `mapstructure:"resend_interval"`
+}
@@ -0,0 +1,8 @@
+package config
+
+// NotificationConfig 通知服务配置
+type NotificationConfig struct {
+	// SMSTemplates 通知模板对应的短信服务商模板 ID
+	// key 为通知模板名称（如 login_new_device），未配置的模板不发送短信
+	SMSTemplates map[string]string `mapstructure:"sms_templates"`
+}
@@ -0,0 +1,63 @@
+package config
+
+// OSSConfig 对象存储配置
+// 用于头像等用户上传文件
+type OSSConfig struct {
+	// Provider 存储后端
+	// 可选值: local（本地文件系统，由本服务提供静态访问）, s3（S3 兼容服务，如 AWS S3、MinIO、火山引擎 TOS）
+	// 默认值: "local"
+	Provider string `mapstructure:"provider"`
+
+	// PublicBaseURL 对象公开访问地址前缀，对象 URL 为 PublicBaseURL + "/" + key
+	// local 默认为 Local.ServePath；s3 为空时使用桶地址，配置 CDN 时填写 CDN 域名
+	PublicBaseURL string `mapstructure:"public_base_url"`
+
+	// Local 本地存储配置
+	Local OSSLocalConfig `mapstructure:"local"`
+
+	// S3 S3 兼容存储配置
+	S3 OSSS3Config `mapstructure:"s3"`
+}
+
+// OSSLocalConfig 本地文件系统存储配置
+type OSSLocalConfig struct {
+	// Dir 文件存储目录
+	// 默认值: "./data/uploads"
+	Dir string `mapstructure:"dir"`
+
+	// ServePath 静态文件访问路径，为空时不提供访问（由 Nginx 等直接托管 Dir）
+	// 默认值: "/uploads"
+	ServePath string `mapstructure:"serve_path"`
+}
+
+// OSSS3Config S3 兼容存储配置
+type OSSS3Config struct {
+	// Endpoint 服务地址，如 https://s3.amazonaws.com、http://127.0.0.1:9000
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Region 区域
+	// 默认值: "us-east-1"
+	Region string `mapstructure:"region"`
+
+	// Bucket 桶名称
+	Bucket string `mapstructure:"bucket"`
+
+	// AccessKeyID 访问密钥 ID
+	AccessKeyID string `mapstructure:"access_key_id"`
+
+	// SecretAccessKey 访问密钥
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+
+	// PathStyle 使用路径风格访问（endpoint/bucket/key），MinIO 等自建服务通常需要开启
+	// 默认值: false
+	PathStyle bool `mapstructure:"path_style"`
+
+	// Timeout 请求超时(秒)
+	// 默认值: 10
+	Timeout int `mapstructure:"timeout"`
+}
+
+// IsLocal 是否使用本地存储
+func (c *OSSConfig) IsLocal() bool {
+	return c.Provider == "" || c.Provider == "local"
+}
@@ -0,0 +1,46 @@
+package config
+
+// OTPConfig 一次性验证码配置
+type OTPConfig struct {
+	// Secret 验证码摘要密钥
+	// 存储层只保存验证码的 HMAC 摘要，投递队列中的验证码使用该密钥派生的子密钥加密
+	// 生产环境必须配置，至少 32 字符；多实例部署须保持一致
+	// 开发环境为空时使用进程内随机密钥（重启后已发送的验证码失效）
+	// 默认值: ""
+	Secret string `mapstructure:"secret"`
+
+	// TestPhones 测试号码白名单（应用商店审核、自动化测试）
+	TestPhones OTPTestPhonesConfig `mapstructure:"test_phones"`
+}
+
+// OTPTestPhonesConfig 测试号码白名单配置
+// 白名单内的号码使用固定验证码，不调用真实服务商
+type OTPTestPhonesConfig struct {
+	// Enabled 是否启用测试号码
+	// 默认值: false
+	Enabled bool `mapstructure:"enabled"`
+
+	// AllowInRelease 是否允许在 release 模式下启用
+	// release 模式下启用测试号码必须显式设置为 true，否则拒绝启动
+	// 默认值: false
+	AllowInRelease bool `mapstructure:"allow_in_release"`
+
+	// Phones 测试号码列表
+	Phones []OTPTestPhoneConfig `mapstructure:"phones"`
+}
+
+// OTPTestPhoneConfig 单个测试号码
+type OTPTestPhoneConfig struct {
+	// Phone 手机号
+	Phone string `mapstructure:"phone"`
+
+	// Code 固定验证码，6 位数字
+	Code string `mapstructure:"code"`
+
+	// ExpiresAt 失效时间 (RFC3339)，为空表示不过期
+	// 审核账号建议设置，审核结束后自动失效
+	ExpiresAt string `mapstructure:"expires_at"`
+
+	// AllowedIPs 允许使用的客户端 IP 或 CIDR，为空表示不限制
+	AllowedIPs []string `mapstructure:"allowed_ips"`
+}
@@ -0,0 +1,49 @@
+package config
+
+// OutboxConfig 领域事件 outbox 配置
+// 事件随业务事务写入 outbox 表，由后台任务发布到 Redis Stream，再由消费者组异步处理
+type OutboxConfig struct {
+	// Stream 事件写入的 Redis Stream 名称
+	// 默认值: arch3:events
+	Stream string `mapstructure:"stream"`
+
+	// StreamMaxLen Stream 近似最大长度，超出后裁剪最早的消息
+	// 默认值: 100000
+	StreamMaxLen int64 `mapstructure:"stream_max_len"`
+
+	// RelayInterval 发布任务轮询间隔（毫秒）
+	// 默认值: 1000
+	RelayInterval int `mapstructure:"relay_interval"`
+
+	// BatchSize 每轮最多发布的事件数
+	// 默认值: 100
+	BatchSize int `mapstructure:"batch_size"`
+
+	// MaxAttempts 最大发布次数，超过后不再重试，等待人工处理
+	// 默认值: 15
+	MaxAttempts int `mapstructure:"max_attempts"`
+
+	// Retention 已发布事件的保留天数
+	// 默认值: 7
+	Retention int `mapstructure:"retention"`
+
+	// DeadRetention 发布失败事件的保留天数
+	// 默认值: 30
+	DeadRetention int `mapstructure:"dead_retention"`
+
+	// CleanupInterval 过期事件清理任务执行间隔（分钟）
+	// 默认值: 60
+	CleanupInterval int `mapstructure:"cleanup_interval"`
+
+	// ConsumerGroup 本服务消费事件使用的消费者组，多实例共用同一组分摊消息
+	// 默认值: arch3
+	ConsumerGroup string `mapstructure:"consumer_group"`
+
+	// ConsumeInterval 消费任务轮询间隔（毫秒）
+	// 默认值: 1000
+	ConsumeInterval int `mapstructure:"consume_interval"`
+
+	// ConsumeMaxDeliveries 单条事件最大处理次数，超过后丢弃并记录日志
+	// 默认值: 5
+	ConsumeMaxDeliveries int64 `mapstructure:"consume_max_deliveries"`
+}
@@ -0,0 +1,35 @@
+package config
+
+// RetentionConfig 数据保留配置
+// 后台任务按各模块注册的策略分批删除或匿名化过期数据
+type RetentionConfig struct {
+	// Interval 保留任务执行间隔（分钟）
+	// 默认值: 60
+	Interval int `mapstructure:"interval"`
+
+	// BatchSize 每批处理的记录数
+	// 默认值: 500
+	BatchSize int `mapstructure:"batch_size"`
+
+	// MaxBatches 每个策略每轮最多处理的批数，剩余数据在下一轮处理
+	// 默认值: 20
+	MaxBatches int `mapstructure:"max_batches"`
+
+	// DryRun 只统计过期数据（日志与指标），不删除或修改
+	// 默认值: false
+	DryRun bool `mapstructure:"dry_run"`
+
+	// LoginHistory 登录记录保留天数，0 表示不清理
+	// 默认值: 180
+	LoginHistory int `mapstructure:"login_history"`
+
+	// DeletedUsers 已注销（匿名化）用户记录保留天数，到期后物理删除，0 表示不清理
+	// 默认值: 365
+	DeletedUsers int `mapstructure:"deleted_users"`
+
+	// UnverifiedUsers 未完成引导的账号不活跃天数上限，0 表示不清理
+	// 从未修改资料、验证邮箱或提交实名认证，且超过该天数没有登录或刷新令牌的账号
+	// 转入注销冷静期（account.deletion_cooling_off）并通知用户，冷静期内登录撤销即可保留
+	// 默认值: 0
+	UnverifiedUsers int `mapstructure:"unverified_users"`
+}
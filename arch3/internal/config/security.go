@@ -0,0 +1,81 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SecurityConfig 数据安全配置
+//
+// 手机号、邮箱、姓名、身份证号使用信封加密存储，并以盲索引支持查询与唯一约束。
+// 生产环境必须配置；密钥丢失将无法解密已有数据，须妥善备份。
+// 开发环境未配置时使用固定的开发密钥。
+// 生成密钥: openssl rand -base64 32
+type SecurityConfig struct {
+	// FieldKeys 字段加密主密钥列表，格式 "版本:base64 密钥"，如 "2:xxxx"
+	// 新数据使用版本号最大的密钥，旧版本仅用于解密
+	// 轮换: 追加新版本 → 重启服务 → 运行 cmd/reencrypt → 移除旧版本
+	// 环境变量 ECHO_SECURITY_FIELD_KEYS 以空格或逗号分隔多个密钥
+	// 默认值: []
+	FieldKeys []string `mapstructure:"field_keys"`
+
+	// FieldKey 早期的单一字段加密密钥，等价于 FieldKeys 中的版本 1
+	// 默认值: ""
+	FieldKey string `mapstructure:"field_key"`
+
+	// BlindIndexKey 盲索引密钥，base64 编码的 32 字节
+	// 不参与轮换；更换后必须运行 cmd/reencrypt 重建全部索引，期间按手机号查询不可用
+	// 默认值: ""
+	BlindIndexKey string `mapstructure:"blind_index_key"`
+}
+
+// ParseFieldKeys 解析字段加密主密钥，返回版本号到密钥的映射
+// 以空格分隔的环境变量会作为一项到达，与 ParseTrustedProxies 相同再按空白拆分
+func (c *SecurityConfig) ParseFieldKeys() (map[int][]byte, error) {
+	keys := make(map[int][]byte, len(c.FieldKeys)+1)
+	add := func(version int, encoded string) error {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return fmt.Errorf("security field key version %d must be 32 bytes base64-encoded", version)
+		}
+		if _, dup := keys[version]; dup {
+			return fmt.Errorf("security field key version %d configured twice", version)
+		}
+		keys[version] = key
+		return nil
+	}
+
+	if c.FieldKey != "" {
+		if err := add(1, c.FieldKey); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range splitFields(c.FieldKeys) {
+		v, encoded, ok := strings.Cut(entry, ":")
+		version, err := strconv.Atoi(v)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("security.field_keys entry must be \"version:base64key\"")
+		}
+		if err := add(version, encoded); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// IdentityConfig 实名身份核验配置
+type IdentityConfig struct {
+	// Provider 核验服务商
+	// 可选值: manual（全部转人工审核）, fake（本地模拟，仅开发测试）
+	// 默认值: "fake"
+	Provider string `mapstructure:"provider"`
+}
+
+// AdminConfig 管理员配置
+type AdminConfig struct {
+	// UserIDs 管理员用户 ID 白名单，可访问 /api/v1/admin 下的接口
+	// 默认值: []
+	UserIDs []string `mapstructure:"user_ids"`
+}
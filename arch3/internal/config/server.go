@@ -1,6 +1,10 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"net"
+	"strings"
+)
 
 // ServerConfig 服务器配置
 // 包含HTTP服务器的基本配置参数
@@ -33,6 +37,39 @@ type ServerConfig struct {
 	// ShutdownTimeout 优雅关闭超时时间(秒)
 	// 默认值: 10
 	ShutdownTimeout int `mapstructure:"shutdown_timeout"`
+
+	// TrustedProxies 可信反向代理的 IP 或 CIDR，如 "10.0.0.0/8"、"127.0.0.1"
+	// 仅当请求的对端地址属于可信代理时，才从 X-Forwarded-For / X-Real-IP 读取客户端 IP，
+	// 否则使用对端地址，避免客户端伪造请求头绕过按 IP 的限流与风控
+	// 部署在负载均衡或网关之后时必须配置，环境变量 ECHO_SERVER_TRUSTED_PROXIES 以空格或逗号分隔
+	// 默认值: [] (不信任任何代理请求头)
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// ParseTrustedProxies 解析可信代理地址，单个 IP 视为 /32（IPv6 为 /128）
+// viper 只按逗号拆分环境变量，以空格分隔的多个地址会作为一项到达，这里再按空白拆分
+func (s *ServerConfig) ParseTrustedProxies() ([]*net.IPNet, error) {
+	cidrs := make([]*net.IPNet, 0, len(s.TrustedProxies))
+	for _, entry := range splitFields(s.TrustedProxies) {
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("server.trusted_proxies: invalid IP %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			cidrs = append(cidrs, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, cidr, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("server.trusted_proxies: invalid CIDR %q", entry)
+		}
+		cidrs = append(cidrs, cidr)
+	}
+	return cidrs, nil
 }
 
 // Address 获取服务器完整监听地址
@@ -50,3 +87,12 @@ func (s *ServerConfig) IsProd() bool {
 func (s *ServerConfig) IsDebug() bool {
 	return s.Mode == "debug"
 }
+
+// splitFields 按空白拆分列表中的每一项并去除空项
+func splitFields(entries []string) []string {
+	var fields []string
+	for _, entry := range entries {
+		fields = append(fields, strings.Fields(entry)...)
+	}
+	return fields
+}
@@ -39,4 +39,13 @@ type SMSTemplatesConfig struct {
 
 	// Forget 忘记密码验证码模板ID
 	Forget string `mapstructure:"forget"`
+
+	// ChangePhoneOld 更换手机号时验证原手机号的模板ID
+	ChangePhoneOld string `mapstructure:"change_phone_old"`
+
+	// ChangePhoneNew 更换手机号时验证新手机号的模板ID
+	ChangePhoneNew string `mapstructure:"change_phone_new"`
+
+	// DeleteAccount 注销账号验证码模板ID
+	DeleteAccount string `mapstructure:"delete_account"`
 }
@@ -0,0 +1,14 @@
+package config
+
+// VoiceConfig 语音验证码配置
+// 用于无法稳定接收短信的用户通过语音电话获取验证码
+type VoiceConfig struct {
+	// Enabled 是否启用语音渠道
+	// 默认值: false
+	Enabled bool `mapstructure:"enabled"`
+
+	// Provider 语音服务提供商
+	// 可选值: mock(仅记录，不发起真实呼叫)
+	// 默认值: "mock"
+	Provider string `mapstructure:"provider"`
+}
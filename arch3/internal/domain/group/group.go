@@ -0,0 +1,80 @@
+package group
+
+import (
+	"errors"
+	"time"
+)
+
+// 组织相关错误
+var (
+	ErrGroupNotFound      = errors.New("group not found")
+	ErrMemberNotFound     = errors.New("group member not found")
+	ErrAlreadyMember      = errors.New("user already in a group")
+	ErrInvitationNotFound = errors.New("group invitation not found")
+	// ErrInvitationClosed 邀请已被处理或已过期
+	ErrInvitationClosed = errors.New("group invitation closed")
+	// ErrGroupConflict 条件更新未命中（并发修改）
+	ErrGroupConflict = errors.New("group conflict")
+)
+
+// 成员角色
+const (
+	RoleOwner  = "owner"  // 所有者，每个组织有且仅有一个
+	RoleAdmin  = "admin"  // 管理员，可邀请和移除普通成员
+	RoleMember = "member" // 普通成员
+)
+
+// roleRanks 角色等级，数值越大权限越高
+var roleRanks = map[string]int{RoleMember: 1, RoleAdmin: 2, RoleOwner: 3}
+
+// RoleAtLeast role 的权限是否不低于 min
+func RoleAtLeast(role, min string) bool {
+	return roleRanks[role] >= roleRanks[min] && roleRanks[role] > 0
+}
+
+// 邀请状态
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+// Group 组织
+// 一个用户同时只能属于一个组织，users.group_id 指向其所在组织
+type Group struct {
+	ID        uint
+	GroupID   string
+	Name      string
+	OwnerID   string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Member 组织成员
+type Member struct {
+	GroupID  string
+	UserID   string
+	Role     string
+	JoinedAt time.Time
+}
+
+// Invitation 加入组织的邀请
+// 按手机号邀请，手机号未注册时 InviteeID 为空，注册后绑定到新用户
+type Invitation struct {
+	ID           uint
+	InvitationID string
+	GroupID      string
+	InviteeID    string
+	InviteePhone string // 被邀请手机号，仅创建时写入（存储为盲索引），查询结果中为空
+	InviterID    string
+	Role         string // 接受后的角色
+	Status       string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	RespondedAt  *time.Time
+}
+
+// Open 邀请是否仍可接受或拒绝
+func (i *Invitation) Open(now time.Time) bool {
+	return i.Status == InvitationPending && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrMessageNotFound 站内信不存在
+var ErrMessageNotFound = errors.New("notification message not found")
+
+// Channel 通知渠道
+type Channel string
+
+const (
+	ChannelSMS   Channel = "sms"   // 短信
+	ChannelEmail Channel = "email" // 邮件
+	ChannelInApp Channel = "inapp" // 站内信
+)
+
+// Message 站内信领域模型
+type Message struct {
+	ID        uint
+	MessageID string
+	UserID    string
+	Template  string // 来源模板名称
+	Title     string
+	Body      string
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// Preference 用户通知偏好
+// 仅保存用户显式设置过的项，未设置时默认接收
+type Preference struct {
+	UserID    string
+	Template  string
+	Channel   Channel
+	Enabled   bool
+	UpdatedAt time.Time
+}
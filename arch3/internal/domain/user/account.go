@@ -0,0 +1,16 @@
+package user
+
+import "time"
+
+// DeletedUserName 注销匿名化后的用户名
+const DeletedUserName = "已注销用户"
+
+// AccountExport 账号数据导出
+type AccountExport struct {
+	ExportedAt time.Time
+	User       *User
+	// LoginHistory 最近的登录记录，按时间倒序
+	LoginHistory []*LoginRecord
+	// Modules 其他模块通过导出钩子提供的数据，键为模块名
+	Modules map[string]any
+}
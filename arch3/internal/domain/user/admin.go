@@ -0,0 +1,34 @@
+package user
+
+import "time"
+
+// UserFilter 管理后台用户查询条件，零值字段不参与过滤
+type UserFilter struct {
+	Phone       string     // 完整手机号精确匹配，或 4 位数字匹配手机号后 4 位
+	UserName    string     // 用户名前缀
+	Status      string     // 用户状态
+	Source      string     // 注册来源
+	GroupID     string     // 所属组织
+	CreatedFrom *time.Time // 注册时间下限（含）
+	CreatedTo   *time.Time // 注册时间上限（不含）
+
+	// Cursor 上一页最后一条的 UserID，结果按 UserID 倒序（即注册时间倒序）
+	Cursor string
+	Limit  int
+}
+
+// StatusChange 用户状态变更记录
+type StatusChange struct {
+	UserID     string
+	FromStatus string
+	ToStatus   string
+	Reason     string
+	OperatorID string // 操作人用户 ID
+	CreatedAt  time.Time
+}
+
+// UserDetail 管理后台用户详情
+type UserDetail struct {
+	User          *User
+	StatusChanges []*StatusChange // 最近的状态变更，按时间倒序
+}
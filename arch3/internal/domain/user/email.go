@@ -0,0 +1,6 @@
+package user
+
+import "errors"
+
+// ErrEmailTaken 邮箱已被其他账号绑定
+var ErrEmailTaken = errors.New("email already registered")
@@ -0,0 +1,39 @@
+package user
+
+import "time"
+
+// 用户领域事件类型，AggregateID 为用户 ID
+//
+// 事件内容只包含 ID 与状态等非敏感字段，不包含手机号、姓名等个人信息，
+// 消费方需要时通过接口查询。
+const (
+	EventUserRegistered = "user.registered"
+	EventUserBanned     = "user.banned"
+	EventUserUnbanned   = "user.unbanned"
+
+	EventUserLoginNewDevice = "user.login_new_device"
+)
+
+// RegisteredEvent 用户注册事件内容
+type RegisteredEvent struct {
+	UserID       string    `json:"user_id"`
+	Source       string    `json:"source,omitempty"` // 注册来源
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// StatusChangedEvent 封禁、解封事件内容
+type StatusChangedEvent struct {
+	UserID     string    `json:"user_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason,omitempty"`
+	OperatorID string    `json:"operator_id"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// LoginNewDeviceEvent 新设备登录事件内容，由事件消费者发送新设备登录提醒
+type LoginNewDeviceEvent struct {
+	UserID     string    `json:"user_id"`
+	DeviceID   string    `json:"device_id"`
+	LoggedInAt time.Time `json:"logged_in_at"`
+}
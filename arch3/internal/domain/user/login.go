@@ -0,0 +1,26 @@
+package user
+
+import "time"
+
+// 登录记录事件类型
+const (
+	LoginEventRegister = "register" // 首次登录即注册
+	LoginEventLogin    = "login"
+)
+
+// LoginRecord 登录记录，保存每次登录时的客户端信息，用于渠道归因与安全审计
+type LoginRecord struct {
+	ID          uint
+	UserID      string
+	Event       string
+	DeviceID    string
+	Platform    string
+	AppVersion  string
+	Channel     string
+	UTMSource   string
+	UTMMedium   string
+	UTMCampaign string
+	IP          string
+	UserAgent   string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,17 @@
+package user
+
+import "errors"
+
+var (
+	// ErrPhoneTaken 手机号已被其他账号绑定
+	ErrPhoneTaken = errors.New("phone number already registered")
+	// ErrTicketInvalid 验证凭证不存在、已过期或已使用
+	ErrTicketInvalid = errors.New("verification ticket invalid")
+)
+
+// PhoneChangeTicket 更换手机号凭证
+// 原手机号（或备用邮箱）验证通过后签发，凭证在有效期内用于发送新手机号验证码与提交变更
+type PhoneChangeTicket struct {
+	UserID   string `json:"user_id"`
+	OldPhone string `json:"old_phone"` // 签发时绑定的手机号，提交时作为条件更新的前置条件
+}
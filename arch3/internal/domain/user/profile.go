@@ -0,0 +1,39 @@
+package user
+
+import "errors"
+
+// ErrUserConflict 用户资料已被并发修改
+var ErrUserConflict = errors.New("user modified concurrently")
+
+// 性别取值
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+	GenderOther  = "other"
+)
+
+// ProfileUpdate 用户资料部分更新
+//
+// 字段为 nil 表示不修改；Email、AvatarURL 为空字符串表示清空。
+// Version 为前置条件: 仅当用户当前版本号与之相等时才写入。
+type ProfileUpdate struct {
+	UserName  *string
+	Gender    *string
+	Email     *string
+	AvatarURL *string
+	Version   int64
+
+	// EmailVerified 邮箱验证状态，由 Service 层在邮箱变更时设置，不接受客户端输入
+	EmailVerified *bool
+}
+
+// Empty 是否没有任何待修改字段
+func (p *ProfileUpdate) Empty() bool {
+	return p.UserName == nil && p.Gender == nil && p.Email == nil && p.AvatarURL == nil
+}
+
+// Avatar 头像上传结果
+type Avatar struct {
+	URL        string         // 头像地址，已写入 User.AvatarURL
+	Thumbnails map[int]string // 各尺寸缩略图地址，key 为边长（像素）
+}
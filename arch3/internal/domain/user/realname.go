@@ -0,0 +1,31 @@
+package user
+
+import "errors"
+
+// ErrIDNumberTaken 身份证号已被其他账号认证
+var ErrIDNumberTaken = errors.New("id number already verified by another user")
+
+// 用户状态
+const (
+	StatusRealNameUnverified = "real_name_unverified" // 未实名（注册默认）
+	StatusUnderReview        = "under_review"         // 实名信息待人工审核
+	StatusRealNameVerified   = "real_name_verified"   // 已实名
+	StatusBanned             = "banned"               // 已封禁
+)
+
+// IdentityResult 身份核验结果
+type IdentityResult string
+
+const (
+	IdentityMatch     IdentityResult = "match"     // 姓名与身份证号一致
+	IdentityMismatch  IdentityResult = "mismatch"  // 姓名与身份证号不一致
+	IdentityUncertain IdentityResult = "uncertain" // 无法自动判定，需人工审核
+)
+
+// RealNameUpdate 实名信息变更
+// RealName、IDNumber 为空表示清除（审核驳回时）
+type RealNameUpdate struct {
+	RealName string
+	IDNumber string
+	Status   string
+}
@@ -17,13 +17,51 @@ type User struct {
 	RealName     *string
 	PasswordHash string
 	Email        *string
-	PhoneNumber  string
-	AvatarURL    *string
-	Gender       string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
-	Status       string
-	IDNumber     *string
-	Source       *string
-	DeviceID     *string
+	// EmailVerified 邮箱是否已验证，更换邮箱后重置；未验证的邮箱不用于登录和通知
+	EmailVerified bool
+	PhoneNumber   string
+	AvatarURL     *string
+	Gender        string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Status        string
+	IDNumber      *string
+	Source        *string
+	DeviceID      *string
+	// DeletionScheduledAt 申请注销后冷静期的截止时间，到期后账号被匿名化；nil 表示未申请注销
+	DeletionScheduledAt *time.Time
+	// OnboardedAt 完成引导的时间，nil 表示未完成引导，见 Repository.MarkOnboarded
+	// 完成引导指注册后首次修改资料、验证邮箱或提交实名认证，未完成引导且长期不活跃的账号会被转入注销流程
+	OnboardedAt *time.Time
+	// LastActiveAt 最近活跃（登录或刷新令牌）的时间，按小时粒度更新，缓存中的值可能滞后
+	LastActiveAt *time.Time
+	// Version 乐观锁版本号，每次写入递增；更新时与数据库不一致返回 ErrUserConflict
+	Version int64
+}
+
+// Field 可通过 Repository.UpdateFields 单独更新的用户字段
+type Field string
+
+// 可更新字段；UserID、GroupID（由组织模块维护）与时间戳不可直接更新
+const (
+	FieldUserName            Field = "user_name"
+	FieldRealName            Field = "real_name"
+	FieldPasswordHash        Field = "password_hash"
+	FieldEmail               Field = "email"
+	FieldEmailVerified       Field = "email_verified"
+	FieldPhoneNumber         Field = "phone_number"
+	FieldAvatarURL           Field = "avatar_url"
+	FieldGender              Field = "gender"
+	FieldStatus              Field = "status"
+	FieldIDNumber            Field = "id_number"
+	FieldSource              Field = "source"
+	FieldDeviceID            Field = "device_id"
+	FieldDeletionScheduledAt Field = "deletion_scheduled_at"
+)
+
+// UpdatableFields 全部可更新字段，Repository.Update 写入这些字段
+var UpdatableFields = []Field{
+	FieldUserName, FieldRealName, FieldPasswordHash, FieldEmail, FieldEmailVerified,
+	FieldPhoneNumber, FieldAvatarURL, FieldGender, FieldStatus, FieldIDNumber,
+	FieldSource, FieldDeviceID, FieldDeletionScheduledAt,
 }
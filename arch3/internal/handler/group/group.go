@@ -0,0 +1,158 @@
+package group
+
+import (
+	"context"
+
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// CreateGroup 创建组织
+// @Summary 创建组织
+// @Description 创建者成为所有者；已加入其他组织时需先退出
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param request body CreateGroupRequest true "组织信息"
+// @Success 200 {object} response.Result{data=GroupResponse}
+// @Router /api/v1/groups [post]
+func (h *Handler) CreateGroup(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.CreateGroup")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req CreateGroupRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	g, err := h.groupService.CreateGroup(ctx, userID, req.Name)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewGroupResponse(g))
+}
+
+// GetMyGroup 查询当前用户所在组织
+// @Summary 我的组织
+// @Description 未加入组织时 data 为 null
+// @Tags groups
+// @Produce json
+// @Success 200 {object} response.Result{data=MyGroupResponse}
+// @Router /api/v1/groups [get]
+func (h *Handler) GetMyGroup(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.GetMyGroup")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	m, err := h.groupService.GetMyMembership(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if m == nil {
+		return response.Success(c, nil)
+	}
+
+	g, err := h.groupService.GetGroup(ctx, m.GroupID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, &MyGroupResponse{Group: NewGroupResponse(g), Role: m.Role})
+}
+
+// GetGroup 查询组织信息
+// @Summary 组织信息
+// @Description 仅组织成员可查看
+// @Tags groups
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Success 200 {object} response.Result{data=GroupResponse}
+// @Router /api/v1/groups/{group_id} [get]
+func (h *Handler) GetGroup(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.GetGroup")
+	defer span.End()
+
+	var req GroupPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	g, err := h.groupService.GetGroup(ctx, req.GroupID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewGroupResponse(g))
+}
+
+// ListMembers 查询组织成员
+// @Summary 组织成员列表
+// @Description 按加入时间升序返回全部成员，仅组织成员可查看
+// @Tags groups
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Success 200 {object} response.Result{data=[]MemberResponse}
+// @Router /api/v1/groups/{group_id}/members [get]
+func (h *Handler) ListMembers(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ListMembers")
+	defer span.End()
+
+	var req GroupPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	members, err := h.groupService.ListMembers(ctx, req.GroupID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewMembersResponse(members))
+}
+
+// LeaveGroup 退出组织
+// @Summary 退出组织
+// @Description 所有者需先转让组织；所有者是唯一成员时退出即解散组织
+// @Tags groups
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Success 200 {object} response.Result
+// @Router /api/v1/groups/{group_id}/leave [post]
+func (h *Handler) LeaveGroup(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.LeaveGroup")
+	defer span.End()
+
+	var req GroupPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	if err := h.groupService.LeaveGroup(ctx, middleware.GetUserID(c), req.GroupID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
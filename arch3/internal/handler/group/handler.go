@@ -0,0 +1,24 @@
+package group
+
+import (
+	"context"
+
+	"arch3/internal/service/group"
+)
+
+// Handler 组织 HTTP 处理器
+type Handler struct {
+	groupService group.Service
+}
+
+// NewHandler 创建组织处理器实例
+func NewHandler(groupService group.Service) *Handler {
+	return &Handler{
+		groupService: groupService,
+	}
+}
+
+// RoleResolver 返回组织权限中间件使用的角色查询函数
+func (h *Handler) RoleResolver() func(ctx context.Context, groupID, userID string) (string, error) {
+	return h.groupService.MemberRole
+}
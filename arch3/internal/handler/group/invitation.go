@@ -0,0 +1,138 @@
+package group
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/group"
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// InviteMember 邀请成员
+// @Summary 邀请成员
+// @Description 按手机号邀请，7 天内有效；手机号未注册时在注册后收到邀请，响应不返回被邀请人 ID。管理员只能邀请普通成员，邀请管理员需所有者权限
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Param request body InviteMemberRequest true "邀请信息"
+// @Success 200 {object} response.Result{data=InvitationResponse}
+// @Router /api/v1/groups/{group_id}/invitations [post]
+func (h *Handler) InviteMember(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.InviteMember")
+	defer span.End()
+
+	var req InviteMemberRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+	if req.Role == "" {
+		req.Role = domain.RoleMember
+	}
+
+	inv, err := h.groupService.InviteMember(ctx, middleware.GetUserID(c), req.GroupID, req.PhoneNumber, req.Role)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	// 不返回被邀请人 ID，避免据此判断手机号是否注册
+	resp := NewInvitationResponse(inv)
+	resp.InviteeID = ""
+	return response.Success(c, resp)
+}
+
+// ListMyInvitations 查询收到的邀请
+// @Summary 我收到的组织邀请
+// @Description 返回未处理且未过期的邀请，按时间倒序
+// @Tags groups
+// @Produce json
+// @Success 200 {object} response.Result{data=[]InvitationResponse}
+// @Router /api/v1/group-invitations [get]
+func (h *Handler) ListMyInvitations(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ListMyInvitations")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	invitations, err := h.groupService.ListMyInvitations(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	items := make([]*InvitationResponse, 0, len(invitations))
+	for _, inv := range invitations {
+		items = append(items, NewInvitationResponse(inv))
+	}
+	return response.Success(c, items)
+}
+
+// AcceptInvitation 接受邀请
+// @Summary 接受组织邀请
+// @Description 已加入其他组织时需先退出
+// @Tags groups
+// @Produce json
+// @Param invitation_id path string true "邀请 ID"
+// @Success 200 {object} response.Result{data=MemberResponse}
+// @Router /api/v1/group-invitations/{invitation_id}/accept [post]
+func (h *Handler) AcceptInvitation(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.AcceptInvitation")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req InvitationPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	m, err := h.groupService.AcceptInvitation(ctx, userID, req.InvitationID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewMemberResponse(m))
+}
+
+// DeclineInvitation 拒绝邀请
+// @Summary 拒绝组织邀请
+// @Tags groups
+// @Produce json
+// @Param invitation_id path string true "邀请 ID"
+// @Success 200 {object} response.Result
+// @Router /api/v1/group-invitations/{invitation_id}/decline [post]
+func (h *Handler) DeclineInvitation(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.DeclineInvitation")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req InvitationPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	if err := h.groupService.DeclineInvitation(ctx, userID, req.InvitationID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
@@ -0,0 +1,96 @@
+package group
+
+import (
+	"context"
+
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SetMemberRole 设置成员角色
+// @Summary 设置成员角色
+// @Description 仅所有者可操作，角色为 admin 或 member；转让所有权请使用转让接口
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Param user_id path string true "成员用户 ID"
+// @Param request body SetMemberRoleRequest true "角色"
+// @Success 200 {object} response.Result{data=MemberResponse}
+// @Router /api/v1/groups/{group_id}/members/{user_id}/role [put]
+func (h *Handler) SetMemberRole(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SetMemberRole")
+	defer span.End()
+
+	var req SetMemberRoleRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	m, err := h.groupService.SetMemberRole(ctx, middleware.GetUserID(c), req.GroupID, req.UserID, req.Role)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewMemberResponse(m))
+}
+
+// RemoveMember 移除成员
+// @Summary 移除成员
+// @Description 所有者可移除管理员与普通成员，管理员只能移除普通成员
+// @Tags groups
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Param user_id path string true "成员用户 ID"
+// @Success 200 {object} response.Result
+// @Router /api/v1/groups/{group_id}/members/{user_id} [delete]
+func (h *Handler) RemoveMember(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.RemoveMember")
+	defer span.End()
+
+	var req MemberPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	if err := h.groupService.RemoveMember(ctx, middleware.GetUserID(c), req.GroupID, req.UserID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
+
+// TransferOwnership 转让所有权
+// @Summary 转让组织
+// @Description 仅所有者可操作，新所有者需为组织成员，原所有者降为管理员
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group_id path string true "组织 ID"
+// @Param request body TransferOwnershipRequest true "新所有者"
+// @Success 200 {object} response.Result
+// @Router /api/v1/groups/{group_id}/transfer [post]
+func (h *Handler) TransferOwnership(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.TransferOwnership")
+	defer span.End()
+
+	var req TransferOwnershipRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	if err := h.groupService.TransferOwnership(ctx, middleware.GetUserID(c), req.GroupID, req.UserID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
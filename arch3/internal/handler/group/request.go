@@ -0,0 +1,55 @@
+package group
+
+// CreateGroupRequest 创建组织请求
+type CreateGroupRequest struct {
+	// 组织名称：必填，1-64 字
+	Name string `json:"name" vd:"len($)>0 && len($)<=64; msg:'组织名称不能为空且不超过 64 字'"`
+}
+
+// GroupPathRequest 路径中仅包含组织 ID 的请求
+type GroupPathRequest struct {
+	// 组织 ID：路径参数
+	GroupID string `path:"group_id" vd:"len($)==26; msg:'组织 ID 格式无效'"`
+}
+
+// InviteMemberRequest 邀请成员请求
+type InviteMemberRequest struct {
+	// 组织 ID：路径参数
+	GroupID string `path:"group_id" vd:"len($)==26; msg:'组织 ID 格式无效'"`
+	// 被邀请人手机号：必填，11位中国大陆手机号
+	PhoneNumber string `json:"phone_number" vd:"len($)==11 && regexp('^1[3-9]\\d{9}$'); msg:'手机号格式无效，需要11位中国大陆手机号'"`
+	// 加入后的角色：可选，admin/member，默认 member；邀请管理员需所有者权限
+	Role string `json:"role" vd:"in($,'','admin','member'); msg:'角色必须是 admin 或 member'"`
+}
+
+// InvitationPathRequest 路径中仅包含邀请 ID 的请求
+type InvitationPathRequest struct {
+	// 邀请 ID：路径参数
+	InvitationID string `path:"invitation_id" vd:"len($)==26; msg:'邀请 ID 格式无效'"`
+}
+
+// MemberPathRequest 路径中包含组织 ID 与成员用户 ID 的请求
+type MemberPathRequest struct {
+	// 组织 ID：路径参数
+	GroupID string `path:"group_id" vd:"len($)==26; msg:'组织 ID 格式无效'"`
+	// 成员用户 ID：路径参数
+	UserID string `path:"user_id" vd:"len($)>0; msg:'用户 ID 不能为空'"`
+}
+
+// SetMemberRoleRequest 设置成员角色请求
+type SetMemberRoleRequest struct {
+	// 组织 ID：路径参数
+	GroupID string `path:"group_id" vd:"len($)==26; msg:'组织 ID 格式无效'"`
+	// 成员用户 ID：路径参数
+	UserID string `path:"user_id" vd:"len($)>0; msg:'用户 ID 不能为空'"`
+	// 角色：必填，admin/member
+	Role string `json:"role" vd:"in($,'admin','member'); msg:'角色必须是 admin 或 member'"`
+}
+
+// TransferOwnershipRequest 转让所有权请求
+type TransferOwnershipRequest struct {
+	// 组织 ID：路径参数
+	GroupID string `path:"group_id" vd:"len($)==26; msg:'组织 ID 格式无效'"`
+	// 新所有者用户 ID：必填，需为组织成员
+	UserID string `json:"user_id" vd:"len($)>0; msg:'用户 ID 不能为空'"`
+}
@@ -0,0 +1,84 @@
+package group
+
+import (
+	"time"
+
+	domain "arch3/internal/domain/group"
+)
+
+// GroupResponse 组织信息
+type GroupResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewGroupResponse 从 domain.Group 创建组织信息
+func NewGroupResponse(g *domain.Group) *GroupResponse {
+	return &GroupResponse{
+		ID:        g.GroupID,
+		Name:      g.Name,
+		OwnerID:   g.OwnerID,
+		CreatedAt: g.CreatedAt,
+	}
+}
+
+// MemberResponse 组织成员
+type MemberResponse struct {
+	GroupID  string    `json:"group_id"`
+	UserID   string    `json:"user_id"`
+	Role     string    `json:"role"` // owner/admin/member
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// NewMemberResponse 从 domain.Member 创建组织成员
+func NewMemberResponse(m *domain.Member) *MemberResponse {
+	return &MemberResponse{
+		GroupID:  m.GroupID,
+		UserID:   m.UserID,
+		Role:     m.Role,
+		JoinedAt: m.JoinedAt,
+	}
+}
+
+// NewMembersResponse 批量创建组织成员
+func NewMembersResponse(members []*domain.Member) []*MemberResponse {
+	items := make([]*MemberResponse, 0, len(members))
+	for _, m := range members {
+		items = append(items, NewMemberResponse(m))
+	}
+	return items
+}
+
+// InvitationResponse 组织邀请
+type InvitationResponse struct {
+	ID        string    `json:"id"`
+	GroupID   string    `json:"group_id"`
+	InviteeID string    `json:"invitee_id,omitempty"` // 邀请人创建邀请时不返回
+	InviterID string    `json:"inviter_id"`
+	Role      string    `json:"role"`
+	Status    string    `json:"status"` // pending/accepted/declined
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewInvitationResponse 从 domain.Invitation 创建组织邀请
+func NewInvitationResponse(i *domain.Invitation) *InvitationResponse {
+	return &InvitationResponse{
+		ID:        i.InvitationID,
+		GroupID:   i.GroupID,
+		InviteeID: i.InviteeID,
+		InviterID: i.InviterID,
+		Role:      i.Role,
+		Status:    i.Status,
+		CreatedAt: i.CreatedAt,
+		ExpiresAt: i.ExpiresAt,
+	}
+}
+
+// MyGroupResponse 当前用户所在组织
+type MyGroupResponse struct {
+	Group *GroupResponse `json:"group"`
+	Role  string         `json:"role"`
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+
+	"arch3/pkg/logger"
+	"arch3/pkg/response"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"go.uber.org/zap"
+)
+
+// RequireAdmin 管理员权限中间件
+// 仅允许 userIDs 白名单中的已登录用户访问，需注册在认证中间件之后
+func RequireAdmin(userIDs []string) app.HandlerFunc {
+	admins := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		admins[id] = true
+	}
+
+	return func(ctx context.Context, c *app.RequestContext) {
+		userID := GetUserID(c)
+		if userID == "" {
+			response.Error(c, response.Err(response.CodeUnauthorized, "未登录"))
+			c.Abort()
+			return
+		}
+		if !admins[userID] {
+			logger.Ctx(ctx).Warn("admin access denied",
+				zap.String("user_id", userID),
+				zap.String("path", string(c.Path())),
+			)
+			response.Error(c, response.Err(response.CodeForbidden, "无权限"))
+			c.Abort()
+			return
+		}
+		c.Next(ctx)
+	}
+}
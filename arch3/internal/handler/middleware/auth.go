@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"strings"
 
 	"arch3/internal/config"
 	"arch3/pkg/jwt"
@@ -17,21 +18,28 @@ import (
 var publicPaths = map[string]bool{
 	// 认证相关接口
 	"POST:" + config.APIPrefix + "/user/sms":       true, // 发送短信验证码
+	"GET:" + config.APIPrefix + "/user/sms/status": true, // 查询验证码投递状态
 	"POST:" + config.APIPrefix + "/user/sms-login": true, // 验证码登录
 	"POST:" + config.APIPrefix + "/user/refresh":   true, // 刷新 token
+
+	// 邮箱验证链接可能在未登录的浏览器中打开
+	"POST:" + config.APIPrefix + "/user/email/verify": true,
 }
 
 // AuthMiddleware 认证中间件
 type AuthMiddleware struct {
-	jwtManager *jwt.Manager
-	enabled    bool
+	jwtManager     *jwt.Manager
+	enabled        bool
+	publicPrefixes []string // 公开路径前缀（如本地存储的静态文件路径）
 }
 
 // NewAuthMiddleware 创建认证中间件
-func NewAuthMiddleware(jwtManager *jwt.Manager, enabled bool) *AuthMiddleware {
+// publicPrefixes 为额外的公开路径前缀，匹配的 GET/HEAD 请求不需要认证
+func NewAuthMiddleware(jwtManager *jwt.Manager, enabled bool, publicPrefixes ...string) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtManager: jwtManager,
-		enabled:    enabled,
+		jwtManager:     jwtManager,
+		enabled:        enabled,
+		publicPrefixes: publicPrefixes,
 	}
 }
 
@@ -46,7 +54,7 @@ func (m *AuthMiddleware) Handle() app.HandlerFunc {
 
 		// 检查是否是公开路径
 		pathKey := string(c.Method()) + ":" + string(c.Path())
-		if publicPaths[pathKey] {
+		if publicPaths[pathKey] || m.isPublicPrefix(c) {
 			c.Next(ctx)
 			return
 		}
@@ -68,8 +76,8 @@ func (m *AuthMiddleware) Handle() app.HandlerFunc {
 			return
 		}
 
-		// 检查 token 是否在黑名单中
-		blacklisted, err := m.jwtManager.IsTokenBlacklisted(ctx, claims.ID)
+		// 检查 token 是否已撤销（单个 token 黑名单或用户级撤销）
+		blacklisted, err := m.jwtManager.IsTokenRevoked(ctx, claims)
 		if err != nil {
 			logger.Ctx(ctx).Error("check token blacklist failed", zap.Error(err))
 			response.Error(c, response.Err(response.CodeCacheError, "系统错误"))
@@ -88,6 +96,20 @@ func (m *AuthMiddleware) Handle() app.HandlerFunc {
 	}
 }
 
+// isPublicPrefix 是否为公开前缀下的只读请求
+func (m *AuthMiddleware) isPublicPrefix(c *app.RequestContext) bool {
+	if !c.IsGet() && !c.IsHead() {
+		return false
+	}
+	path := string(c.Path())
+	for _, prefix := range m.publicPrefixes {
+		if strings.HasPrefix(path, strings.TrimRight(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
 // GetUserID 从上下文获取用户 ID
 func GetUserID(c *app.RequestContext) string {
 	if v, exists := c.Get("userID"); exists {
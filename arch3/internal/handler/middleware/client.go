@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"arch3/internal/service/common"
+	"arch3/pkg/logger"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"go.uber.org/zap"
+)
+
+// 客户端标准请求头
+// UTM 参数优先取请求头，未设置时取同名查询参数（utm_source 等），便于 H5 落地页直接透传
+const (
+	HeaderAppVersion  = "X-App-Version"
+	HeaderPlatform    = "X-Platform"
+	HeaderChannel     = "X-Channel"
+	HeaderDeviceID    = "X-Device-ID"
+	HeaderUTMSource   = "X-UTM-Source"
+	HeaderUTMMedium   = "X-UTM-Medium"
+	HeaderUTMCampaign = "X-UTM-Campaign"
+)
+
+// Platforms 支持的平台取值
+var Platforms = []string{"ios", "android", "harmony", "web", "h5", "miniprogram", "windows", "macos", "linux"}
+
+var (
+	appVersionPattern = regexp.MustCompile(`^\d{1,4}(\.\d{1,4}){0,3}([-+][0-9A-Za-z.\-]{1,32})?$`)
+	channelPattern    = regexp.MustCompile(`^[0-9A-Za-z_.\-]{1,64}$`)
+	deviceIDPattern   = regexp.MustCompile(`^[0-9A-Za-z_.:\-]{1,128}$`)
+)
+
+// maxUserAgentLen User-Agent 最大保留长度
+const maxUserAgentLen = 255
+
+// ClientInfo 客户端信息中间件
+// 解析标准请求头，写入 context（common.ClientInfoFrom 读取）
+// 客户端信息只用于统计与风控，不影响业务处理：格式无效的字段丢弃并记录警告，不拒绝请求
+func ClientInfo() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		info := common.ClientInfo{
+			AppVersion:  strings.TrimSpace(string(c.GetHeader(HeaderAppVersion))),
+			Platform:    strings.ToLower(strings.TrimSpace(string(c.GetHeader(HeaderPlatform)))),
+			Channel:     strings.TrimSpace(string(c.GetHeader(HeaderChannel))),
+			UTMSource:   strings.TrimSpace(headerOrQuery(c, HeaderUTMSource, "utm_source")),
+			UTMMedium:   strings.TrimSpace(headerOrQuery(c, HeaderUTMMedium, "utm_medium")),
+			UTMCampaign: strings.TrimSpace(headerOrQuery(c, HeaderUTMCampaign, "utm_campaign")),
+			DeviceID:    strings.TrimSpace(string(c.GetHeader(HeaderDeviceID))),
+			IP:          c.ClientIP(),
+			UserAgent:   truncate(string(c.UserAgent()), maxUserAgentLen),
+		}
+
+		if dropped := sanitizeClientInfo(&info); len(dropped) > 0 {
+			logger.Ctx(ctx).Warn("invalid client info dropped",
+				zap.Strings("fields", dropped),
+				zap.String("path", string(c.Request.URI().Path())),
+			)
+		}
+
+		c.Next(common.WithClientInfo(ctx, info))
+	}
+}
+
+// sanitizeClientInfo 清空格式无效的字段，返回被清空的字段名
+func sanitizeClientInfo(info *common.ClientInfo) []string {
+	var dropped []string
+	drop := func(field *string, name string, valid func(string) bool) {
+		if *field != "" && !valid(*field) {
+			*field = ""
+			dropped = append(dropped, name)
+		}
+	}
+	drop(&info.AppVersion, HeaderAppVersion, appVersionPattern.MatchString)
+	drop(&info.Platform, HeaderPlatform, func(v string) bool { return slices.Contains(Platforms, v) })
+	drop(&info.Channel, HeaderChannel, channelPattern.MatchString)
+	drop(&info.UTMSource, "utm_source", channelPattern.MatchString)
+	drop(&info.UTMMedium, "utm_medium", channelPattern.MatchString)
+	drop(&info.UTMCampaign, "utm_campaign", channelPattern.MatchString)
+	drop(&info.DeviceID, HeaderDeviceID, deviceIDPattern.MatchString)
+	return dropped
+}
+
+// headerOrQuery 优先读取请求头，未设置时读取查询参数
+func headerOrQuery(c *app.RequestContext, header, query string) string {
+	if v := c.GetHeader(header); len(v) > 0 {
+		return string(v)
+	}
+	return c.Query(query)
+}
+
+// truncate 按字节截断字符串，不截断多字节字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
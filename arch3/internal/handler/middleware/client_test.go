@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"slices"
+	"testing"
+
+	"arch3/internal/service/common"
+)
+
+func TestSanitizeClientInfo(t *testing.T) {
+	tests := []struct {
+		name        string
+		info        common.ClientInfo
+		want        common.ClientInfo
+		wantDropped []string
+	}{
+		{
+			name: "全部有效",
+			info: common.ClientInfo{AppVersion: "3.2.1", Platform: "ios", Channel: "appstore", UTMSource: "wechat", DeviceID: "dev-1"},
+			want: common.ClientInfo{AppVersion: "3.2.1", Platform: "ios", Channel: "appstore", UTMSource: "wechat", DeviceID: "dev-1"},
+		},
+		{
+			name: "未设置的字段不校验",
+			info: common.ClientInfo{IP: "203.0.113.7"},
+			want: common.ClientInfo{IP: "203.0.113.7"},
+		},
+		{
+			name:        "无效字段被清空，其余保留",
+			info:        common.ClientInfo{AppVersion: "latest", Platform: "symbian", Channel: "appstore", UTMCampaign: "双十一", DeviceID: "dev 1"},
+			want:        common.ClientInfo{Channel: "appstore"},
+			wantDropped: []string{HeaderAppVersion, HeaderPlatform, "utm_campaign", HeaderDeviceID},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dropped := sanitizeClientInfo(&tt.info)
+			if tt.info != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, tt.info)
+			}
+			if !slices.Equal(dropped, tt.wantDropped) {
+				t.Errorf("Expected dropped %v, got %v", tt.wantDropped, dropped)
+			}
+		})
+	}
+}
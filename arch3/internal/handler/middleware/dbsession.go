@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"context"
+
+	"arch3/pkg/dbreplica"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// DBSession 为每个请求创建数据库读写会话
+//
+// 启用从库时，请求内发生写入后，同一请求的后续读取改走主库，
+// 保证请求能读到自己刚写入的数据（如更新资料后返回最新资料）。
+// 未启用从库时会话不影响任何行为。
+func DBSession() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		c.Next(dbreplica.WithSession(ctx))
+	}
+}
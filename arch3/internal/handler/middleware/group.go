@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"slices"
+
+	"arch3/pkg/logger"
+	"arch3/pkg/response"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"go.uber.org/zap"
+)
+
+// GroupRoleResolver 查询用户在组织中的角色，不是成员返回空字符串（由使用方定义）
+type GroupRoleResolver func(ctx context.Context, groupID, userID string) (string, error)
+
+// RequireGroupRole 组织权限中间件
+// 从路径参数 group_id 读取组织，要求当前用户是该组织成员；roles 非空时角色必须在其中。
+// 通过后角色写入上下文，可用 GetGroupRole 读取。需注册在认证中间件之后。
+func RequireGroupRole(resolve GroupRoleResolver, roles ...string) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		userID := GetUserID(c)
+		if userID == "" {
+			response.Error(c, response.Err(response.CodeUnauthorized, "未登录"))
+			c.Abort()
+			return
+		}
+
+		groupID := c.Param("group_id")
+		role, err := resolve(ctx, groupID, userID)
+		if err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+		if role == "" || len(roles) > 0 && !slices.Contains(roles, role) {
+			logger.Ctx(ctx).Warn("group access denied",
+				zap.String("user_id", userID),
+				zap.String("group_id", groupID),
+				zap.String("role", role),
+				zap.String("path", string(c.Path())),
+			)
+			response.Error(c, response.Err(response.CodeForbidden, "无权限访问该组织"))
+			c.Abort()
+			return
+		}
+
+		c.Set("groupRole", role)
+		c.Next(ctx)
+	}
+}
+
+// GetGroupRole 从上下文获取当前用户在路径所指组织中的角色
+func GetGroupRole(c *app.RequestContext) string {
+	if v, exists := c.Get("groupRole"); exists {
+		if role, ok := v.(string); ok {
+			return role
+		}
+	}
+	return ""
+}
@@ -11,9 +11,9 @@ import (
 //
 // 中间件执行顺序（洋葱模型，请求从外到内，响应从内到外）:
 //
-//	Request → Recovery → Metrics → Tracing → AccessLog → CORS → Gzip → Limiter → Auth → Handler
-//	         ↑                                                                           ↓
-//	         └─────────────────────────────── Response ──────────────────────────────────┘
+//	Request → Recovery → Metrics → Tracing → AccessLog → CORS → Gzip → Limiter → ClientInfo → DBSession → Auth → Handler
+//	         ↑                                                                                                    ↓
+//	         └──────────────────────────────────────────── Response ──────────────────────────────────────────────┘
 //
 // 顺序设计原则:
 //  1. Recovery 最外层 - 捕获所有 panic，确保服务稳定
@@ -21,7 +21,9 @@ import (
 //  3. Tracing 提供 trace_id - 后续中间件和 handler 都可使用
 //  4. AccessLog 记录访问 - 需要 trace_id 关联日志
 //  5. CORS/Gzip/Limiter 业务相关 - 按需启用
-//  6. Auth 最内层 - 认证检查，放在业务路由前
+//  6. ClientInfo 解析客户端标准请求头（版本、平台、渠道、设备），写入 context
+//  7. DBSession 创建数据库读写会话，请求内写入后的读取走主库
+//  8. Auth 最内层 - 认证检查，放在业务路由前
 //
 // 使用说明:
 //   - logger.Ctx(ctx) 记录日志会自动包含 trace_id
@@ -65,13 +67,23 @@ func Register(h *server.Hertz, cfg *config.Config, tracerCfg *TracerConfig, jwtM
 		h.Use(Limiter(&cfg.Middleware.Limiter))
 	}
 
-	// 8. Auth - JWT 认证（检查公开路径白名单，验证 token）
+	// 8. ClientInfo - 解析并校验客户端标准请求头
+	h.Use(ClientInfo())
+
+	// 9. DBSession - 数据库读写会话（启用从库时保证读己之写）
+	h.Use(DBSession())
+
+	// 10. Auth - JWT 认证（检查公开路径白名单，验证 token）
 	if cfg.Middleware.Auth.Enabled && jwtManager != nil {
-		authMiddleware := NewAuthMiddleware(jwtManager, cfg.Middleware.Auth.Enabled)
+		var publicPrefixes []string
+		if cfg.OSS.IsLocal() && cfg.OSS.Local.ServePath != "" {
+			publicPrefixes = append(publicPrefixes, cfg.OSS.Local.ServePath) // 本地存储的上传文件
+		}
+		authMiddleware := NewAuthMiddleware(jwtManager, cfg.Middleware.Auth.Enabled, publicPrefixes...)
 		h.Use(authMiddleware.Handle())
 	}
 
-	// 9. Pprof - 性能分析端点（仅 debug 模式）
+	// 11. Pprof - 性能分析端点（仅 debug 模式）
 	if cfg.Server.IsDebug() {
 		RegisterPprof(h)
 	}
@@ -0,0 +1,17 @@
+package notification
+
+import (
+	"arch3/internal/service/notification"
+)
+
+// Handler 通知 HTTP 处理器
+type Handler struct {
+	notificationService notification.Service
+}
+
+// NewHandler 创建通知处理器实例
+func NewHandler(notificationService notification.Service) *Handler {
+	return &Handler{
+		notificationService: notificationService,
+	}
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"context"
+
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// defaultPageSize 默认每页数量
+const defaultPageSize = 20
+
+// ListMessages 站内信列表
+// @Summary 站内信列表
+// @Description 按时间倒序分页查询当前用户的站内信
+// @Tags notifications
+// @Produce json
+// @Param unread_only query bool false "仅未读"
+// @Param limit query int false "每页数量 (1-100，默认 20)"
+// @Param offset query int false "偏移量"
+// @Success 200 {object} response.Result{data=ListMessagesResponse}
+// @Router /api/v1/notification/messages [get]
+func (h *Handler) ListMessages(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ListMessages")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req ListMessagesRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultPageSize
+	}
+
+	msgs, total, err := h.notificationService.ListMessages(ctx, userID, req.UnreadOnly, req.Limit, req.Offset)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewListMessagesResponse(msgs, total))
+}
+
+// MarkRead 标记站内信已读
+// @Summary 标记站内信已读
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body MarkReadRequest true "标记已读请求"
+// @Success 200 {object} response.Result
+// @Router /api/v1/notification/messages/read [post]
+func (h *Handler) MarkRead(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.MarkRead")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req MarkReadRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	if err := h.notificationService.MarkRead(ctx, userID, req.MessageID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
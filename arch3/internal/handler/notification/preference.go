@@ -0,0 +1,69 @@
+package notification
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/notification"
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// ListPreferences 通知偏好列表
+// @Summary 通知偏好列表
+// @Description 返回所有通知模板在各渠道的接收设置，mandatory 为 true 的通知不可关闭
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} response.Result{data=[]PreferenceResponse}
+// @Router /api/v1/notification/preferences [get]
+func (h *Handler) ListPreferences(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ListPreferences")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	items, err := h.notificationService.ListPreferences(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewPreferencesResponse(items))
+}
+
+// SetPreference 设置通知偏好
+// @Summary 设置通知偏好
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body SetPreferenceRequest true "设置偏好请求"
+// @Success 200 {object} response.Result
+// @Router /api/v1/notification/preferences [put]
+func (h *Handler) SetPreference(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SetPreference")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req SetPreferenceRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	err := h.notificationService.SetPreference(ctx, userID, req.Template, domain.Channel(req.Channel), req.Enabled)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
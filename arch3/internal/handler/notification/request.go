@@ -0,0 +1,27 @@
+package notification
+
+// ListMessagesRequest 站内信列表请求
+type ListMessagesRequest struct {
+	// 仅未读：可选，默认 false
+	UnreadOnly bool `query:"unread_only"`
+	// 每页数量：可选，1-100，默认 20
+	Limit int `query:"limit" vd:"$==0 || ($>=1 && $<=100); msg:'limit 取值范围 1-100'"`
+	// 偏移量：可选，默认 0
+	Offset int `query:"offset" vd:"$>=0; msg:'offset 不能为负数'"`
+}
+
+// MarkReadRequest 标记已读请求
+type MarkReadRequest struct {
+	// 消息 ID：必填
+	MessageID string `json:"message_id" vd:"len($)==26; msg:'消息 ID 格式无效'"`
+}
+
+// SetPreferenceRequest 设置通知偏好请求
+type SetPreferenceRequest struct {
+	// 模板名称：必填
+	Template string `json:"template" vd:"len($)>0; msg:'模板名称不能为空'"`
+	// 渠道：必填，sms/email/inapp
+	Channel string `json:"channel" vd:"in($,'sms','email','inapp'); msg:'渠道必须是 sms、email 或 inapp'"`
+	// 是否接收
+	Enabled bool `json:"enabled"`
+}
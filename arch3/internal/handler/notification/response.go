@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"time"
+
+	domain "arch3/internal/domain/notification"
+	"arch3/internal/service/notification"
+)
+
+// MessageResponse 站内信响应
+type MessageResponse struct {
+	ID        string     `json:"id"`
+	Template  string     `json:"template"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ListMessagesResponse 站内信列表响应
+type ListMessagesResponse struct {
+	Items []*MessageResponse `json:"items"`
+	Total int64              `json:"total"`
+}
+
+// NewListMessagesResponse 从领域模型创建站内信列表响应
+func NewListMessagesResponse(msgs []*domain.Message, total int64) *ListMessagesResponse {
+	items := make([]*MessageResponse, 0, len(msgs))
+	for _, m := range msgs {
+		items = append(items, &MessageResponse{
+			ID:        m.MessageID,
+			Template:  m.Template,
+			Title:     m.Title,
+			Body:      m.Body,
+			ReadAt:    m.ReadAt,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return &ListMessagesResponse{Items: items, Total: total}
+}
+
+// PreferenceResponse 通知偏好响应
+type PreferenceResponse struct {
+	Template  string `json:"template"`
+	Channel   string `json:"channel"`
+	Enabled   bool   `json:"enabled"`
+	Mandatory bool   `json:"mandatory"` // 强制通知，不可关闭
+}
+
+// NewPreferencesResponse 创建通知偏好列表响应
+func NewPreferencesResponse(items []*notification.PreferenceItem) []*PreferenceResponse {
+	resp := make([]*PreferenceResponse, 0, len(items))
+	for _, it := range items {
+		resp = append(resp, &PreferenceResponse{
+			Template:  it.Template,
+			Channel:   string(it.Channel),
+			Enabled:   it.Enabled,
+			Mandatory: it.Mandatory,
+		})
+	}
+	return resp
+}
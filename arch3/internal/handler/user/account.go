@@ -0,0 +1,134 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SendDeleteAccountCode 发送注销验证码
+// @Summary 发送注销验证码
+// @Description 注销账号第一步: 向当前绑定的手机号发送验证码
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body SendDeleteAccountCodeRequest true "发送注销验证码请求"
+// @Success 200 {object} response.Result{data=SendSMSResponse}
+// @Router /api/v1/user/me/deletion/code [post]
+func (h *Handler) SendDeleteAccountCode(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SendDeleteAccountCode")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req SendDeleteAccountCodeRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	span.SetAttributes(tracer.String(tracer.AttrOTPChannel, req.Channel))
+
+	dispatchID, err := h.userService.SendDeleteAccountCode(ctx, userID, req.Channel)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, &SendSMSResponse{DispatchID: dispatchID})
+}
+
+// RequestAccountDeletion 申请注销账号
+// @Summary 申请注销账号
+// @Description 校验验证码后账号进入冷静期，冷静期内可撤销；冷静期结束后账号数据被删除且无法恢复
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body RequestAccountDeletionRequest true "申请注销请求"
+// @Success 200 {object} response.Result{data=ProfileResponse}
+// @Router /api/v1/user/me/deletion [post]
+func (h *Handler) RequestAccountDeletion(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.RequestAccountDeletion")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req RequestAccountDeletionRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	u, err := h.userService.RequestAccountDeletion(ctx, userID, req.SMSCode)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewProfileResponse(u))
+}
+
+// CancelAccountDeletion 撤销注销
+// @Summary 撤销注销
+// @Description 冷静期内撤销注销申请，账号恢复正常
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Result{data=ProfileResponse}
+// @Router /api/v1/user/me/deletion [delete]
+func (h *Handler) CancelAccountDeletion(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.CancelAccountDeletion")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	u, err := h.userService.CancelAccountDeletion(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewProfileResponse(u))
+}
+
+// ExportAccountData 导出账号数据
+// @Summary 导出账号数据
+// @Description 以 JSON 文件下载当前账号的全部数据（资料、实名信息及其他模块的数据），不使用统一响应包装
+// @Tags users
+// @Produce json
+// @Success 200 {object} AccountExportResponse
+// @Router /api/v1/user/me/export [get]
+func (h *Handler) ExportAccountData(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ExportAccountData")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	export, err := h.userService.ExportAccountData(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="account-%s.json"`, userID))
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, NewAccountExportResponse(export))
+	return nil
+}
@@ -0,0 +1,171 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SearchUsers 查询用户列表
+// @Summary 查询用户（管理员）
+// @Description 按手机号（完整或后 4 位）、用户名前缀、状态、来源、组织与注册时间过滤，按注册时间倒序游标分页
+// @Tags admin
+// @Produce json
+// @Param phone query string false "完整手机号或后 4 位"
+// @Param user_name query string false "用户名前缀"
+// @Param status query string false "状态"
+// @Param source query string false "注册来源"
+// @Param group_id query string false "组织 ID"
+// @Param created_from query string false "注册时间下限（含），RFC3339 或 2006-01-02"
+// @Param created_to query string false "注册时间上限（不含），RFC3339 或 2006-01-02"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页条数，1-100，默认 20"
+// @Success 200 {object} response.Result{data=AdminUserListResponse}
+// @Router /api/v1/admin/users [get]
+func (h *Handler) SearchUsers(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SearchUsers")
+	defer span.End()
+
+	var req SearchUsersRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	createdFrom, err := parseTimeParam(req.CreatedFrom)
+	if err != nil {
+		return response.Validation("created_from 格式无效")
+	}
+	createdTo, err := parseTimeParam(req.CreatedTo)
+	if err != nil {
+		return response.Validation("created_to 格式无效")
+	}
+
+	users, nextCursor, err := h.userService.SearchUsers(ctx, &domain.UserFilter{
+		Phone:       req.Phone,
+		UserName:    req.UserName,
+		Status:      req.Status,
+		Source:      req.Source,
+		GroupID:     req.GroupID,
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+		Cursor:      req.Cursor,
+		Limit:       req.Limit,
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	items := make([]*AdminUserResponse, 0, len(users))
+	for _, u := range users {
+		items = append(items, NewAdminUserResponse(u))
+	}
+	return response.Success(c, &AdminUserListResponse{Items: items, NextCursor: nextCursor})
+}
+
+// GetUserDetail 查询用户详情
+// @Summary 用户详情（管理员）
+// @Description 返回用户信息（实名信息脱敏）与最近的状态变更记录
+// @Tags admin
+// @Produce json
+// @Param user_id path string true "用户 ID"
+// @Success 200 {object} response.Result{data=AdminUserDetailResponse}
+// @Router /api/v1/admin/users/{user_id} [get]
+func (h *Handler) GetUserDetail(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.GetUserDetail")
+	defer span.End()
+
+	var req UserIDPathRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	detail, err := h.userService.GetUserDetail(ctx, req.UserID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewAdminUserDetailResponse(detail))
+}
+
+// BanUser 封禁用户
+// @Summary 封禁用户（管理员）
+// @Description 封禁后用户全部会话失效且无法登录，并通知用户封禁原因
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user_id path string true "用户 ID"
+// @Param request body ChangeUserStatusRequest true "封禁原因"
+// @Success 200 {object} response.Result{data=AdminUserResponse}
+// @Router /api/v1/admin/users/{user_id}/ban [post]
+func (h *Handler) BanUser(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.BanUser")
+	defer span.End()
+
+	var req ChangeUserStatusRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	u, err := h.userService.BanUser(ctx, middleware.GetUserID(c), req.UserID, req.Reason)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewAdminUserResponse(u))
+}
+
+// UnbanUser 解封用户
+// @Summary 解封用户（管理员）
+// @Description 恢复到封禁前的状态
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user_id path string true "用户 ID"
+// @Param request body ChangeUserStatusRequest true "解封原因"
+// @Success 200 {object} response.Result{data=AdminUserResponse}
+// @Router /api/v1/admin/users/{user_id}/unban [post]
+func (h *Handler) UnbanUser(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.UnbanUser")
+	defer span.End()
+
+	var req ChangeUserStatusRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	u, err := h.userService.UnbanUser(ctx, middleware.GetUserID(c), req.UserID, req.Reason)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewAdminUserResponse(u))
+}
+
+// parseTimeParam 解析 RFC3339 或日期格式的查询参数，日期按 UTC 零点处理；空字符串返回 nil
+func parseTimeParam(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
@@ -34,7 +34,7 @@ func (h *Handler) SMSLogin(ctx context.Context, c *app.RequestContext) error {
 		tracer.String(tracer.AttrPhoneMasked, tracer.MaskPhone(req.PhoneNumber)),
 	)
 
-	result, err := h.userService.SMSLogin(ctx, req.PhoneNumber, req.SMSCode)
+	result, err := h.userService.SMSLogin(ctx, req.PhoneNumber, req.SMSCode, req.DeviceID)
 	if err != nil {
 		tracer.RecordError(span, err)
 		return err
@@ -0,0 +1,96 @@
+package user
+
+import (
+	"context"
+
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// BindEmail 绑定邮箱
+// @Summary 绑定或更换邮箱
+// @Description 写入新邮箱（未验证状态）并发送验证邮件；邮箱已被其他账号绑定时返回邮箱已注册
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body BindEmailRequest true "绑定邮箱请求"
+// @Success 200 {object} response.Result{data=ProfileResponse}
+// @Router /api/v1/user/me/email [post]
+func (h *Handler) BindEmail(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.BindEmail")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req BindEmailRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	u, err := h.userService.BindEmail(ctx, userID, req.Email)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewProfileResponse(u))
+}
+
+// ResendEmailVerification 重发验证邮件
+// @Summary 重发邮箱验证邮件
+// @Description 向当前绑定且未验证的邮箱重新发送验证链接，同一用户有发送间隔限制
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Result
+// @Router /api/v1/user/me/email/verification [post]
+func (h *Handler) ResendEmailVerification(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ResendEmailVerification")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	if err := h.userService.ResendEmailVerification(ctx, userID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
+
+// VerifyEmail 验证邮箱
+// @Summary 验证邮箱
+// @Description 校验验证邮件中链接携带的 token，无需登录。链接过期或邮箱已更换时返回参数无效
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "验证邮箱请求"
+// @Success 200 {object} response.Result
+// @Router /api/v1/user/email/verify [post]
+func (h *Handler) VerifyEmail(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.VerifyEmail")
+	defer span.End()
+
+	var req VerifyEmailRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	// 未登录也可验证，只返回结果，不返回用户资料
+	if _, err := h.userService.VerifyEmail(ctx, req.Token); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, nil)
+}
@@ -0,0 +1,160 @@
+package user
+
+import (
+	"context"
+
+	"arch3/internal/handler/middleware"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SendChangePhoneOldCode 发送原手机号验证码
+// @Summary 发送原手机号验证码
+// @Description 更换手机号第一步: 向当前绑定的手机号发送验证码；原手机号无法接收时可选 email 渠道投递到绑定邮箱
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body SendChangePhoneOldCodeRequest true "发送原手机号验证码请求"
+// @Success 200 {object} response.Result{data=SendSMSResponse}
+// @Router /api/v1/user/me/phone/old-code [post]
+func (h *Handler) SendChangePhoneOldCode(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SendChangePhoneOldCode")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req SendChangePhoneOldCodeRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	span.SetAttributes(tracer.String(tracer.AttrOTPChannel, req.Channel))
+
+	dispatchID, err := h.userService.SendChangePhoneOldCode(ctx, userID, req.Channel)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, &SendSMSResponse{DispatchID: dispatchID})
+}
+
+// VerifyChangePhoneOld 校验原手机号验证码
+// @Summary 校验原手机号验证码
+// @Description 更换手机号第二步: 校验通过后返回凭证，凭证在有效期内用于验证并绑定新手机号
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body VerifyChangePhoneOldRequest true "校验原手机号验证码请求"
+// @Success 200 {object} response.Result{data=ChangePhoneTicketResponse}
+// @Router /api/v1/user/me/phone/verify-old [post]
+func (h *Handler) VerifyChangePhoneOld(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.VerifyChangePhoneOld")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req VerifyChangePhoneOldRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	ticket, err := h.userService.VerifyChangePhoneOld(ctx, userID, req.SMSCode)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, &ChangePhoneTicketResponse{
+		Ticket:    ticket,
+		ExpiresIn: int(userservice.PhoneChangeTicketTTL.Seconds()),
+	})
+}
+
+// SendChangePhoneNewCode 发送新手机号验证码
+// @Summary 发送新手机号验证码
+// @Description 更换手机号第三步: 凭证有效且新手机号未被绑定时，向新手机号发送验证码
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body SendChangePhoneNewCodeRequest true "发送新手机号验证码请求"
+// @Success 200 {object} response.Result{data=SendSMSResponse}
+// @Router /api/v1/user/me/phone/new-code [post]
+func (h *Handler) SendChangePhoneNewCode(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SendChangePhoneNewCode")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req SendChangePhoneNewCodeRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	span.SetAttributes(
+		tracer.String(tracer.AttrPhoneMasked, tracer.MaskPhone(req.PhoneNumber)),
+		tracer.String(tracer.AttrOTPChannel, req.Channel),
+	)
+
+	dispatchID, err := h.userService.SendChangePhoneNewCode(ctx, userID, req.Ticket, req.PhoneNumber, req.Channel)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, &SendSMSResponse{DispatchID: dispatchID})
+}
+
+// ChangePhone 更换手机号
+// @Summary 更换手机号
+// @Description 更换手机号第四步: 校验新手机号验证码并完成更换。
+// @Description 成功后其他设备上的登录全部失效，当前会话通过 cookie 下发新 token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body ChangePhoneRequest true "更换手机号请求"
+// @Success 200 {object} response.Result{data=ProfileResponse}
+// @Router /api/v1/user/me/phone [put]
+func (h *Handler) ChangePhone(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ChangePhone")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req ChangePhoneRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	span.SetAttributes(tracer.String(tracer.AttrPhoneMasked, tracer.MaskPhone(req.PhoneNumber)))
+
+	result, err := h.userService.ChangePhone(ctx, userID, req.Ticket, req.PhoneNumber, req.SMSCode)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	// 旧 token 已撤销，为当前会话下发新 token
+	h.jwtManager.SetTokensInCookie(c, result.TokenPair)
+
+	return response.Success(c, NewProfileResponse(result.User))
+}
@@ -0,0 +1,163 @@
+package user
+
+import (
+	"context"
+	"io"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/handler/middleware"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// GetMe 获取当前用户资料
+// @Summary 获取当前用户资料
+// @Description 返回当前登录用户的资料，不含密码、身份证号等敏感信息。version 为修改资料时的版本
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Result{data=ProfileResponse}
+// @Router /api/v1/user/me [get]
+func (h *Handler) GetMe(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.GetMe")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	u, err := h.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewProfileResponse(u))
+}
+
+// ListLoginHistory 查询当前用户的登录记录
+// @Summary 登录记录
+// @Description 按时间倒序返回最近的登录记录，包含设备、平台、版本、渠道与 IP
+// @Tags users
+// @Produce json
+// @Param limit query int false "条数，1-100，默认 20"
+// @Success 200 {object} response.Result{data=[]LoginRecordResponse}
+// @Router /api/v1/user/me/login-history [get]
+func (h *Handler) ListLoginHistory(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ListLoginHistory")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req ListLoginHistoryRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	records, err := h.userService.ListLoginHistory(ctx, userID, req.Limit)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewLoginHistoryResponse(records))
+}
+
+// UpdateMe 修改当前用户资料
+// @Summary 修改当前用户资料
+// @Description 部分更新: 仅修改请求中出现的字段，email/avatar_url 传空字符串表示清空；更换邮箱后需重新验证。
+// @Description 携带 version 时仅在资料未被他人修改的情况下写入，否则返回资源冲突
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body UpdateProfileRequest true "修改资料请求"
+// @Success 200 {object} response.Result{data=ProfileResponse}
+// @Router /api/v1/user/me [patch]
+func (h *Handler) UpdateMe(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.UpdateMe")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req UpdateProfileRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	upd := &domain.ProfileUpdate{
+		UserName:  req.UserName,
+		Gender:    req.Gender,
+		Email:     req.Email,
+		AvatarURL: req.AvatarURL,
+		Version:   req.Version,
+	}
+
+	u, err := h.userService.UpdateProfile(ctx, userID, upd)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewProfileResponse(u))
+}
+
+// UploadAvatar 上传头像
+// @Summary 上传头像
+// @Description 支持 JPEG、PNG，不超过 5MB。服务端去除 EXIF 信息并生成 512/128/48 像素的正方形缩略图，512 像素版本作为头像地址
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "头像图片"
+// @Success 200 {object} response.Result{data=AvatarResponse}
+// @Router /api/v1/user/me/avatar [post]
+func (h *Handler) UploadAvatar(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.UploadAvatar")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		tracer.RecordError(span, err)
+		return response.Err(response.CodeMissingParam, "缺少头像文件")
+	}
+	if fh.Size > userservice.MaxAvatarBytes {
+		return response.Err(response.CodeFileTooLarge, "头像文件不能超过 5MB")
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return response.Err(response.CodeUploadFailed, "读取文件失败")
+	}
+	defer f.Close()
+
+	// 多读 1 字节用于识别超限（不信任 multipart 头中的大小）
+	data, err := io.ReadAll(io.LimitReader(f, userservice.MaxAvatarBytes+1))
+	if err != nil {
+		tracer.RecordError(span, err)
+		return response.Err(response.CodeUploadFailed, "读取文件失败")
+	}
+
+	avatar, err := h.userService.UploadAvatar(ctx, userID, data)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewAvatarResponse(avatar))
+}
@@ -0,0 +1,130 @@
+package user
+
+import (
+	"context"
+
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// SubmitRealName 提交实名信息
+// @Summary 提交实名认证
+// @Description 提交姓名与身份证号。核验一致直接通过；无法自动判定时进入人工审核（status=under_review）
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body SubmitRealNameRequest true "实名信息"
+// @Success 200 {object} response.Result{data=RealNameResponse}
+// @Router /api/v1/user/me/real-name [post]
+func (h *Handler) SubmitRealName(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.SubmitRealName")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	var req SubmitRealNameRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	u, err := h.userService.SubmitRealName(ctx, userID, req.RealName, req.IDNumber)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewRealNameResponse(u))
+}
+
+// GetRealName 查询实名认证状态
+// @Summary 查询实名认证状态
+// @Description 返回实名认证状态与脱敏后的姓名、身份证号
+// @Tags users
+// @Produce json
+// @Success 200 {object} response.Result{data=RealNameResponse}
+// @Router /api/v1/user/me/real-name [get]
+func (h *Handler) GetRealName(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.GetRealName")
+	defer span.End()
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		return response.Err(response.CodeUnauthorized, "未登录")
+	}
+
+	u, err := h.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewRealNameResponse(u))
+}
+
+// ListRealNameReviews 查询待人工审核的实名信息
+// @Summary 待审核实名列表（管理员）
+// @Description 按提交顺序分页返回 under_review 状态的用户，包含完整姓名与身份证号
+// @Tags admin
+// @Produce json
+// @Param after_id query int false "上一页最后一条的 id"
+// @Param limit query int false "每页条数，1-100，默认 20"
+// @Success 200 {object} response.Result{data=[]RealNameReviewResponse}
+// @Router /api/v1/admin/real-name/reviews [get]
+func (h *Handler) ListRealNameReviews(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ListRealNameReviews")
+	defer span.End()
+
+	var req ListRealNameReviewsRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	users, err := h.userService.ListRealNameReviews(ctx, req.AfterID, req.Limit)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	items := make([]*RealNameReviewResponse, 0, len(users))
+	for _, u := range users {
+		items = append(items, NewRealNameReviewResponse(u))
+	}
+	return response.Success(c, items)
+}
+
+// ReviewRealName 人工审核实名信息
+// @Summary 审核实名信息（管理员）
+// @Description 通过后状态变为 real_name_verified；驳回后清除已提交的信息，用户可重新提交
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param user_id path string true "用户 ID"
+// @Param request body ReviewRealNameRequest true "审核结果"
+// @Success 200 {object} response.Result{data=RealNameResponse}
+// @Router /api/v1/admin/real-name/reviews/{user_id} [post]
+func (h *Handler) ReviewRealName(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.ReviewRealName")
+	defer span.End()
+
+	var req ReviewRealNameRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	u, err := h.userService.ReviewRealName(ctx, middleware.GetUserID(c), req.UserID, req.Approve, req.Reason)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, NewRealNameResponse(u))
+}
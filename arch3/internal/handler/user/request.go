@@ -6,6 +6,14 @@ type SendSMSRequest struct {
 	PhoneNumber string `json:"phone_number" vd:"len($)==11 && regexp('^1[3-9]\\d{9}$'); msg:'手机号格式无效，需要11位有效手机号'"`
 	// 类型：必填，只能是 login/register/forget
 	From string `json:"from" vd:"in($,'login','register','forget'); msg:'类型必须是 login、register 或 forget'"`
+	// 下发渠道：可选，sms/voice/email，默认 sms（email 投递到账号绑定的邮箱）
+	Channel string `json:"channel" vd:"in($,'','sms','voice','email'); msg:'渠道必须是 sms、voice 或 email'"`
+}
+
+// SMSStatusRequest 查询验证码投递状态请求
+type SMSStatusRequest struct {
+	// 投递 ID：必填，发送验证码时返回
+	DispatchID string `query:"dispatch_id" vd:"len($)==26; msg:'投递 ID 格式无效'"`
 }
 
 // SMSLoginRequest 短信验证码登录请求
@@ -14,4 +22,145 @@ type SMSLoginRequest struct {
 	PhoneNumber string `json:"phone_number" vd:"len($)==11 && regexp('^1[3-9]\\d{9}$'); msg:'手机号格式无效，需要11位有效手机号'"`
 	// 短信验证码：必填，6位数字
 	SMSCode string `json:"sms_code" vd:"len($)==6 && regexp('^\\d{6}$'); msg:'验证码格式无效，需要6位数字'"`
+	// 设备标识：可选，缺省时取请求头 X-Device-ID；已有用户在新设备登录时发送提醒
+	DeviceID string `json:"device_id" vd:"len($)==0 || regexp('^[0-9A-Za-z_.:\\-]{1,128}$'); msg:'设备标识格式无效'"`
+}
+
+// UpdateProfileRequest 修改资料请求
+// 字段缺省表示不修改，具体格式由 Service 层校验
+type UpdateProfileRequest struct {
+	// 用户名：1-50 个字符
+	UserName *string `json:"user_name"`
+	// 性别：male/female/other
+	Gender *string `json:"gender"`
+	// 邮箱：空字符串表示清空
+	Email *string `json:"email"`
+	// 头像地址：http(s) 链接，空字符串表示清空
+	AvatarURL *string `json:"avatar_url"`
+	// 资料版本：可选，取自 GET /user/me 返回的 version
+	Version int64 `json:"version" vd:"$>=0; msg:'version 不能为负数'"`
+}
+
+// SendChangePhoneOldCodeRequest 发送原手机号验证码请求
+type SendChangePhoneOldCodeRequest struct {
+	// 下发渠道：可选，sms/voice/email，默认 sms（原手机号无法接收时可选 email，投递到绑定邮箱）
+	Channel string `json:"channel" vd:"in($,'','sms','voice','email'); msg:'渠道必须是 sms、voice 或 email'"`
+}
+
+// VerifyChangePhoneOldRequest 校验原手机号验证码请求
+type VerifyChangePhoneOldRequest struct {
+	// 验证码：必填，6位数字
+	SMSCode string `json:"sms_code" vd:"len($)==6 && regexp('^\\d{6}$'); msg:'验证码格式无效，需要6位数字'"`
+}
+
+// SendChangePhoneNewCodeRequest 发送新手机号验证码请求
+type SendChangePhoneNewCodeRequest struct {
+	// 凭证：必填，校验原手机号时返回
+	Ticket string `json:"ticket" vd:"len($)>0 && len($)<=64; msg:'凭证格式无效'"`
+	// 新手机号：必填，11位数字，以1开头
+	PhoneNumber string `json:"phone_number" vd:"len($)==11 && regexp('^1[3-9]\\d{9}$'); msg:'手机号格式无效，需要11位有效手机号'"`
+	// 下发渠道：可选，sms/voice，默认 sms
+	Channel string `json:"channel" vd:"in($,'','sms','voice'); msg:'渠道必须是 sms 或 voice'"`
+}
+
+// ChangePhoneRequest 更换手机号请求
+type ChangePhoneRequest struct {
+	// 凭证：必填，校验原手机号时返回
+	Ticket string `json:"ticket" vd:"len($)>0 && len($)<=64; msg:'凭证格式无效'"`
+	// 新手机号：必填，需与发送验证码时一致
+	PhoneNumber string `json:"phone_number" vd:"len($)==11 && regexp('^1[3-9]\\d{9}$'); msg:'手机号格式无效，需要11位有效手机号'"`
+	// 新手机号验证码：必填，6位数字
+	SMSCode string `json:"sms_code" vd:"len($)==6 && regexp('^\\d{6}$'); msg:'验证码格式无效，需要6位数字'"`
+}
+
+// BindEmailRequest 绑定邮箱请求
+type BindEmailRequest struct {
+	// 邮箱：必填，格式由 Service 层校验
+	Email string `json:"email" vd:"len($)>0 && len($)<=254; msg:'邮箱格式无效'"`
+}
+
+// VerifyEmailRequest 验证邮箱请求
+type VerifyEmailRequest struct {
+	// token：必填，取自验证邮件中的链接
+	Token string `json:"token" vd:"len($)>0 && len($)<=1024; msg:'验证链接无效'"`
+}
+
+// SubmitRealNameRequest 提交实名信息请求
+type SubmitRealNameRequest struct {
+	// 姓名：必填，2-50 个字符
+	RealName string `json:"real_name" vd:"len($)>0 && len($)<=200; msg:'姓名格式无效'"`
+	// 身份证号：必填，18 位
+	IDNumber string `json:"id_number" vd:"len($)==18; msg:'身份证号格式无效，需要18位'"`
+}
+
+// ListRealNameReviewsRequest 查询待审核列表请求
+type ListRealNameReviewsRequest struct {
+	// 游标：上一页最后一条的 id，首页为 0
+	AfterID uint `query:"after_id"`
+	// 每页条数：1-100，默认 20
+	Limit int `query:"limit" vd:"$==0 || ($>=1 && $<=100); msg:'limit 取值范围 1-100'"`
+}
+
+// ReviewRealNameRequest 人工审核请求
+type ReviewRealNameRequest struct {
+	// 用户 ID：路径参数
+	UserID string `path:"user_id" vd:"len($)>0; msg:'用户 ID 不能为空'"`
+	// 是否通过
+	Approve bool `json:"approve"`
+	// 驳回原因：驳回时展示给用户
+	Reason string `json:"reason" vd:"len($)<=200; msg:'原因过长'"`
+}
+
+// SendDeleteAccountCodeRequest 发送注销验证码请求
+type SendDeleteAccountCodeRequest struct {
+	// 下发渠道：可选，sms/voice，默认 sms
+	Channel string `json:"channel" vd:"in($,'','sms','voice'); msg:'渠道必须是 sms 或 voice'"`
+}
+
+// RequestAccountDeletionRequest 申请注销请求
+type RequestAccountDeletionRequest struct {
+	// 验证码：必填，6位数字
+	SMSCode string `json:"sms_code" vd:"len($)==6 && regexp('^\\d{6}$'); msg:'验证码格式无效，需要6位数字'"`
+}
+
+// SearchUsersRequest 管理后台查询用户请求
+type SearchUsersRequest struct {
+	// 手机号：可选，完整 11 位精确匹配，或 4 位数字匹配手机号后 4 位
+	Phone string `query:"phone" vd:"len($)==0 || regexp('^(\\d{4}|1[3-9]\\d{9})$'); msg:'手机号需为完整 11 位或后 4 位'"`
+	// 用户名：可选，前缀匹配
+	UserName string `query:"user_name" vd:"len($)<=50; msg:'用户名过长'"`
+	// 状态：可选
+	Status string `query:"status" vd:"in($,'','real_name_unverified','under_review','real_name_verified','banned'); msg:'状态无效'"`
+	// 注册来源：可选
+	Source string `query:"source" vd:"len($)<=50; msg:'来源过长'"`
+	// 所属组织：可选
+	GroupID string `query:"group_id" vd:"len($)<=32; msg:'组织 ID 过长'"`
+	// 注册时间下限（含）：可选，RFC3339 或 2006-01-02
+	CreatedFrom string `query:"created_from"`
+	// 注册时间上限（不含）：可选，RFC3339 或 2006-01-02
+	CreatedTo string `query:"created_to"`
+	// 游标：上一页返回的 next_cursor，首页为空
+	Cursor string `query:"cursor" vd:"len($)<=32; msg:'游标无效'"`
+	// 每页条数：1-100，默认 20
+	Limit int `query:"limit" vd:"$==0 || ($>=1 && $<=100); msg:'limit 取值范围 1-100'"`
+}
+
+// UserIDPathRequest 路径中仅包含用户 ID 的请求
+type UserIDPathRequest struct {
+	// 用户 ID：路径参数
+	UserID string `path:"user_id" vd:"len($)>0; msg:'用户 ID 不能为空'"`
+}
+
+// ChangeUserStatusRequest 封禁/解封用户请求
+type ChangeUserStatusRequest struct {
+	// 用户 ID：路径参数
+	UserID string `path:"user_id" vd:"len($)>0; msg:'用户 ID 不能为空'"`
+	// 原因：必填，封禁原因会通知用户
+	Reason string `json:"reason" vd:"len($)>0 && len($)<=200; msg:'原因不能为空且不超过 200 字'"`
+}
+
+// ListLoginHistoryRequest 查询登录记录请求
+type ListLoginHistoryRequest struct {
+	// 条数：1-100，默认 20
+	Limit int `query:"limit" vd:"$==0 || ($>=1 && $<=100); msg:'limit 取值范围 1-100'"`
 }
@@ -1,6 +1,10 @@
 package user
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	domain "arch3/internal/domain/user"
 	"arch3/pkg/ptr"
 )
@@ -33,3 +37,298 @@ func NewSMSLoginResponse(u *domain.User, isNew bool) *SMSLoginResponse {
 		Type:        loginType,
 	}
 }
+
+// SendSMSResponse 发送验证码响应
+type SendSMSResponse struct {
+	DispatchID string `json:"dispatch_id"` // 投递 ID，用于查询投递状态
+}
+
+// SMSStatusResponse 验证码投递状态响应
+type SMSStatusResponse struct {
+	DispatchID string `json:"dispatch_id"`
+	Status     string `json:"status"` // pending(投递中)/sent(已发送)/failed(发送失败，需重新获取)
+}
+
+// ChangePhoneTicketResponse 原手机号验证通过响应
+type ChangePhoneTicketResponse struct {
+	Ticket    string `json:"ticket"`     // 更换手机号凭证，用于后续步骤
+	ExpiresIn int    `json:"expires_in"` // 凭证有效期（秒）
+}
+
+// ProfileResponse 当前用户资料响应
+// 注意：不返回密码摘要、身份证号等敏感信息
+type ProfileResponse struct {
+	ID            string    `json:"id"`
+	PhoneNumber   string    `json:"phone_number"`
+	UserName      string    `json:"user_name"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	AvatarURL     string    `json:"avatar_url"`
+	Gender        string    `json:"gender"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Version       int64     `json:"version"` // 资料版本，修改资料时回传
+	// 已申请注销时为冷静期截止时间，此前可撤销
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// NewProfileResponse 从 domain.User 创建资料响应
+func NewProfileResponse(u *domain.User) *ProfileResponse {
+	return &ProfileResponse{
+		ID:            u.UserID,
+		PhoneNumber:   u.PhoneNumber,
+		UserName:      u.UserName,
+		Email:         ptr.Value(u.Email),
+		EmailVerified: u.EmailVerified,
+		AvatarURL:     ptr.Value(u.AvatarURL),
+		Gender:        u.Gender,
+		Status:        u.Status,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+		Version:       u.Version,
+
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
+
+// AvatarResponse 头像上传响应
+type AvatarResponse struct {
+	AvatarURL  string            `json:"avatar_url"`
+	Thumbnails map[string]string `json:"thumbnails"` // key 为边长（像素），如 "128"
+}
+
+// NewAvatarResponse 从 domain.Avatar 创建头像响应
+func NewAvatarResponse(a *domain.Avatar) *AvatarResponse {
+	thumbnails := make(map[string]string, len(a.Thumbnails))
+	for size, url := range a.Thumbnails {
+		thumbnails[strconv.Itoa(size)] = url
+	}
+	return &AvatarResponse{AvatarURL: a.URL, Thumbnails: thumbnails}
+}
+
+// RealNameResponse 实名认证状态响应（脱敏）
+type RealNameResponse struct {
+	Status   string `json:"status"`    // real_name_verified/under_review/real_name_unverified
+	RealName string `json:"real_name"` // 仅保留姓氏，如 "张*"
+	IDNumber string `json:"id_number"` // 仅保留前 4 位与后 4 位
+}
+
+// NewRealNameResponse 从 domain.User 创建实名认证状态响应
+func NewRealNameResponse(u *domain.User) *RealNameResponse {
+	return &RealNameResponse{
+		Status:   u.Status,
+		RealName: maskRealName(ptr.Value(u.RealName)),
+		IDNumber: maskIDNumber(ptr.Value(u.IDNumber)),
+	}
+}
+
+// RealNameReviewResponse 待审核实名信息（仅管理员可见，不脱敏）
+type RealNameReviewResponse struct {
+	ID          uint      `json:"id"` // 分页游标
+	UserID      string    `json:"user_id"`
+	PhoneNumber string    `json:"phone_number"`
+	RealName    string    `json:"real_name"`
+	IDNumber    string    `json:"id_number"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// NewRealNameReviewResponse 从 domain.User 创建待审核实名信息
+func NewRealNameReviewResponse(u *domain.User) *RealNameReviewResponse {
+	return &RealNameReviewResponse{
+		ID:          u.ID,
+		UserID:      u.UserID,
+		PhoneNumber: u.PhoneNumber,
+		RealName:    ptr.Value(u.RealName),
+		IDNumber:    ptr.Value(u.IDNumber),
+		SubmittedAt: u.UpdatedAt,
+	}
+}
+
+// maskRealName 保留姓名首字
+func maskRealName(name string) string {
+	r := []rune(name)
+	if len(r) == 0 {
+		return ""
+	}
+	return string(r[0]) + strings.Repeat("*", len(r)-1)
+}
+
+// maskIDNumber 保留身份证号前 4 位与后 4 位
+func maskIDNumber(id string) string {
+	if len(id) <= 8 {
+		return strings.Repeat("*", len(id))
+	}
+	return id[:4] + strings.Repeat("*", len(id)-8) + id[len(id)-4:]
+}
+
+// AccountExportResponse 账号数据导出
+// 导出的是用户本人的完整数据，身份证号等字段不脱敏
+type AccountExportResponse struct {
+	ExportedAt   time.Time              `json:"exported_at"`
+	Account      *AccountExportUser     `json:"account"`
+	LoginHistory []*LoginRecordResponse `json:"login_history"`
+	Modules      map[string]any         `json:"modules"` // 其他模块的数据，键为模块名
+}
+
+// AccountExportUser 导出的用户记录（不含密码摘要）
+type AccountExportUser struct {
+	ID                  string     `json:"id"`
+	GroupID             string     `json:"group_id,omitempty"`
+	UserName            string     `json:"user_name"`
+	RealName            string     `json:"real_name,omitempty"`
+	IDNumber            string     `json:"id_number,omitempty"`
+	PhoneNumber         string     `json:"phone_number"`
+	Email               string     `json:"email,omitempty"`
+	EmailVerified       bool       `json:"email_verified"`
+	AvatarURL           string     `json:"avatar_url,omitempty"`
+	Gender              string     `json:"gender"`
+	Status              string     `json:"status"`
+	Source              string     `json:"source,omitempty"`
+	DeviceID            string     `json:"device_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// NewAccountExportResponse 从 domain.AccountExport 创建导出响应
+func NewAccountExportResponse(e *domain.AccountExport) *AccountExportResponse {
+	u := e.User
+	return &AccountExportResponse{
+		ExportedAt: e.ExportedAt,
+		Account: &AccountExportUser{
+			ID:                  u.UserID,
+			GroupID:             ptr.Value(u.GroupID),
+			UserName:            u.UserName,
+			RealName:            ptr.Value(u.RealName),
+			IDNumber:            ptr.Value(u.IDNumber),
+			PhoneNumber:         u.PhoneNumber,
+			Email:               ptr.Value(u.Email),
+			EmailVerified:       u.EmailVerified,
+			AvatarURL:           ptr.Value(u.AvatarURL),
+			Gender:              u.Gender,
+			Status:              u.Status,
+			Source:              ptr.Value(u.Source),
+			DeviceID:            ptr.Value(u.DeviceID),
+			CreatedAt:           u.CreatedAt,
+			UpdatedAt:           u.UpdatedAt,
+			DeletionScheduledAt: u.DeletionScheduledAt,
+		},
+		LoginHistory: NewLoginHistoryResponse(e.LoginHistory),
+		Modules:      e.Modules,
+	}
+}
+
+// AdminUserResponse 管理后台用户信息
+// 手机号与邮箱不脱敏以便联系用户，实名信息脱敏
+type AdminUserResponse struct {
+	ID                  string     `json:"id"`
+	GroupID             string     `json:"group_id,omitempty"`
+	UserName            string     `json:"user_name"`
+	PhoneNumber         string     `json:"phone_number"`
+	Email               string     `json:"email,omitempty"`
+	EmailVerified       bool       `json:"email_verified"`
+	RealName            string     `json:"real_name,omitempty"`
+	IDNumber            string     `json:"id_number,omitempty"`
+	Gender              string     `json:"gender"`
+	Status              string     `json:"status"`
+	Source              string     `json:"source,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// NewAdminUserResponse 从 domain.User 创建管理后台用户信息
+func NewAdminUserResponse(u *domain.User) *AdminUserResponse {
+	return &AdminUserResponse{
+		ID:                  u.UserID,
+		GroupID:             ptr.Value(u.GroupID),
+		UserName:            u.UserName,
+		PhoneNumber:         u.PhoneNumber,
+		Email:               ptr.Value(u.Email),
+		EmailVerified:       u.EmailVerified,
+		RealName:            maskRealName(ptr.Value(u.RealName)),
+		IDNumber:            maskIDNumber(ptr.Value(u.IDNumber)),
+		Gender:              u.Gender,
+		Status:              u.Status,
+		Source:              ptr.Value(u.Source),
+		CreatedAt:           u.CreatedAt,
+		UpdatedAt:           u.UpdatedAt,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
+
+// AdminUserListResponse 管理后台用户列表
+type AdminUserListResponse struct {
+	Items      []*AdminUserResponse `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"` // 为空表示没有更多数据
+}
+
+// StatusChangeResponse 用户状态变更记录
+type StatusChangeResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	OperatorID string    `json:"operator_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AdminUserDetailResponse 管理后台用户详情
+type AdminUserDetailResponse struct {
+	*AdminUserResponse
+	StatusChanges []*StatusChangeResponse `json:"status_changes"`
+}
+
+// NewAdminUserDetailResponse 从 domain.UserDetail 创建管理后台用户详情
+func NewAdminUserDetailResponse(d *domain.UserDetail) *AdminUserDetailResponse {
+	changes := make([]*StatusChangeResponse, 0, len(d.StatusChanges))
+	for _, c := range d.StatusChanges {
+		changes = append(changes, &StatusChangeResponse{
+			FromStatus: c.FromStatus,
+			ToStatus:   c.ToStatus,
+			Reason:     c.Reason,
+			OperatorID: c.OperatorID,
+			CreatedAt:  c.CreatedAt,
+		})
+	}
+	return &AdminUserDetailResponse{
+		AdminUserResponse: NewAdminUserResponse(d.User),
+		StatusChanges:     changes,
+	}
+}
+
+// LoginRecordResponse 登录记录
+type LoginRecordResponse struct {
+	Event       string    `json:"event"` // register/login
+	DeviceID    string    `json:"device_id,omitempty"`
+	Platform    string    `json:"platform,omitempty"`
+	AppVersion  string    `json:"app_version,omitempty"`
+	Channel     string    `json:"channel,omitempty"`
+	UTMSource   string    `json:"utm_source,omitempty"`
+	UTMMedium   string    `json:"utm_medium,omitempty"`
+	UTMCampaign string    `json:"utm_campaign,omitempty"`
+	IP          string    `json:"ip,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewLoginHistoryResponse 从 domain.LoginRecord 列表创建登录记录
+func NewLoginHistoryResponse(records []*domain.LoginRecord) []*LoginRecordResponse {
+	items := make([]*LoginRecordResponse, 0, len(records))
+	for _, r := range records {
+		items = append(items, &LoginRecordResponse{
+			Event:       r.Event,
+			DeviceID:    r.DeviceID,
+			Platform:    r.Platform,
+			AppVersion:  r.AppVersion,
+			Channel:     r.Channel,
+			UTMSource:   r.UTMSource,
+			UTMMedium:   r.UTMMedium,
+			UTMCampaign: r.UTMCampaign,
+			IP:          r.IP,
+			UserAgent:   r.UserAgent,
+			CreatedAt:   r.CreatedAt,
+		})
+	}
+	return items
+}
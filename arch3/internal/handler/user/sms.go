@@ -9,14 +9,15 @@ import (
 	"github.com/cloudwego/hertz/pkg/app"
 )
 
-// SendSMS 发送短信验证码
-// @Summary 发送短信验证码
-// @Description 发送短信验证码，支持登录、注册、忘记密码三种类型
+// SendSMS 发送验证码
+// @Summary 发送验证码
+// @Description 发送验证码，支持登录、注册、忘记密码三种类型；可选短信、语音电话或绑定邮箱下发
+// @Description 投递为异步，返回的 dispatch_id 可用于查询投递状态
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body SendSMSRequest true "发送短信请求"
-// @Success 200 {object} response.Result
+// @Success 200 {object} response.Result{data=SendSMSResponse}
 // @Router /api/v1/user/sms [post]
 func (h *Handler) SendSMS(ctx context.Context, c *app.RequestContext) error {
 	ctx, span := tracer.Start(ctx, "handler.SendSMS")
@@ -31,12 +32,41 @@ func (h *Handler) SendSMS(ctx context.Context, c *app.RequestContext) error {
 	// 记录关键属性（手机号已脱敏）
 	span.SetAttributes(
 		tracer.String(tracer.AttrSMSType, req.From),
+		tracer.String(tracer.AttrOTPChannel, req.Channel),
 	)
 
-	if err := h.userService.SendSMS(ctx, req.PhoneNumber, req.From); err != nil {
+	dispatchID, err := h.userService.SendSMS(ctx, req.PhoneNumber, req.From, req.Channel)
+	if err != nil {
 		tracer.RecordError(span, err)
 		return err
 	}
 
-	return response.Success(c, nil)
+	return response.Success(c, &SendSMSResponse{DispatchID: dispatchID})
+}
+
+// GetSMSStatus 查询验证码投递状态
+// @Summary 查询验证码投递状态
+// @Description 查询异步投递结果，status 为 failed 时客户端应提示用户重新获取验证码
+// @Tags users
+// @Produce json
+// @Param dispatch_id query string true "发送验证码时返回的投递 ID"
+// @Success 200 {object} response.Result{data=SMSStatusResponse}
+// @Router /api/v1/user/sms/status [get]
+func (h *Handler) GetSMSStatus(ctx context.Context, c *app.RequestContext) error {
+	ctx, span := tracer.Start(ctx, "handler.GetSMSStatus")
+	defer span.End()
+
+	var req SMSStatusRequest
+	if err := c.BindAndValidate(&req); err != nil {
+		tracer.RecordError(span, err)
+		return response.Validation(err.Error())
+	}
+
+	status, err := h.userService.GetSMSStatus(ctx, req.DispatchID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	return response.Success(c, &SMSStatusResponse{DispatchID: req.DispatchID, Status: status})
 }
@@ -0,0 +1,22 @@
+package email
+
+import "time"
+
+// Config SMTP 邮件服务配置
+type Config struct {
+	Host     string        // SMTP 服务器地址
+	Port     int           // SMTP 端口: 25/587(STARTTLS), 465(隐式 TLS)
+	Username string        // 认证用户名，为空时不认证
+	Password string        // 认证密码
+	From     string        // 发件人地址
+	FromName string        // 发件人名称
+	TLSMode  string        // TLS 模式: starttls, tls, none
+	Timeout  time.Duration // 连接与收发超时
+}
+
+// TLS 模式
+const (
+	TLSModeStartTLS = "starttls" // 明文连接后升级（服务器支持时）
+	TLSModeTLS      = "tls"      // 隐式 TLS（通常为 465 端口）
+	TLSModeNone     = "none"     // 不使用 TLS（仅限本地开发/测试）
+)
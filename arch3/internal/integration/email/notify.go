@@ -0,0 +1,34 @@
+package email
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/notification"
+	notificationservice "arch3/internal/service/notification"
+)
+
+// NotificationSender 邮件通知发送器
+type NotificationSender struct {
+	client *Client
+}
+
+// NewNotificationSender 创建邮件通知发送器
+func NewNotificationSender(client *Client) *NotificationSender {
+	return &NotificationSender{client: client}
+}
+
+var _ notificationservice.Sender = (*NotificationSender)(nil)
+
+// Channel 渠道名称
+func (s *NotificationSender) Channel() domain.Channel {
+	return domain.ChannelEmail
+}
+
+// Send 发送通知邮件
+func (s *NotificationSender) Send(ctx context.Context, d *notificationservice.Delivery) error {
+	return s.client.Send(ctx, &Message{
+		To:      d.To,
+		Subject: d.Subject,
+		Body:    d.Body,
+	})
+}
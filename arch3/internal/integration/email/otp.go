@@ -0,0 +1,46 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"arch3/internal/integration/otp"
+)
+
+// otpSubjects 各用途验证码邮件主题
+var otpSubjects = map[otp.Type]string{
+	otp.TypeLogin:    "登录验证码",
+	otp.TypeRegister: "注册验证码",
+	otp.TypeForget:   "找回密码验证码",
+
+	otp.TypeChangePhoneOld: "更换手机号身份验证",
+	otp.TypeChangePhoneNew: "更换手机号验证码",
+
+	otp.TypeDeleteAccount: "注销账号验证码",
+}
+
+var _ otp.Channel = (*Client)(nil)
+
+// Name 渠道名称
+func (c *Client) Name() otp.ChannelName {
+	return otp.ChannelEmail
+}
+
+// Validate 邮件渠道支持所有用途
+func (c *Client) Validate(otp.Type) error {
+	return nil
+}
+
+// Deliver 发送验证码邮件
+func (c *Client) Deliver(ctx context.Context, msg *otp.Message) error {
+	subject, ok := otpSubjects[msg.Type]
+	if !ok {
+		subject = "验证码"
+	}
+	return c.Send(ctx, &Message{
+		To:      msg.Address,
+		Subject: subject,
+		Body: fmt.Sprintf("您的验证码为 %s，%d 分钟内有效。\n如非本人操作，请忽略本邮件。",
+			msg.Code, int(msg.TTL.Minutes())),
+	})
+}
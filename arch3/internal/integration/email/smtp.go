@@ -0,0 +1,157 @@
+// Package email 提供邮件发送能力
+//
+// Client 基于 SMTP 协议实现 mailer.Mailer，同时作为验证码邮件渠道适配器（otp.Channel）。
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"arch3/pkg/mailer"
+	"arch3/pkg/tracer"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// ErrInvalidAddress 收件人地址无效
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Message 邮件消息
+type Message = mailer.Message
+
+// Client SMTP 邮件客户端
+type Client struct {
+	config *Config
+}
+
+var _ mailer.Mailer = (*Client)(nil)
+
+// New 创建 SMTP 邮件客户端
+func New(cfg *Config) *Client {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.TLSMode == "" {
+		cfg.TLSMode = TLSModeStartTLS
+	}
+	return &Client{config: cfg}
+}
+
+// Send 发送邮件
+func (c *Client) Send(ctx context.Context, msg *Message) error {
+	ctx, span := tracer.Start(ctx, "email.smtp.Send")
+	defer span.End()
+
+	span.SetAttributes(tracer.String("email.host", c.config.Host))
+
+	if err := validateAddress(msg.To); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	if err := c.send(ctx, msg); err != nil {
+		tracer.RecordError(span, err)
+		return fmt.Errorf("smtp send: %w", err)
+	}
+
+	return nil
+}
+
+// send 建立 SMTP 会话并投递邮件
+func (c *Client) send(ctx context.Context, msg *Message) error {
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if c.config.TLSMode == TLSModeTLS {
+		conn = tls.Client(conn, &tls.Config{ServerName: c.config.Host})
+	}
+
+	client, err := smtp.NewClient(conn, c.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if c.config.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: c.config.Host}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if c.config.Username != "" {
+		auth := smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(c.config.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(c.buildMessage(msg)); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// buildMessage 构建 RFC 5322 邮件内容
+func (c *Client) buildMessage(msg *Message) []byte {
+	from := c.config.From
+	if c.config.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", mime.BEncoding.Encode("UTF-8", c.config.FromName), c.config.From)
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// validateAddress 校验收件人地址，拒绝包含换行等可用于头注入的字符
+func validateAddress(addr string) error {
+	if addr == "" || strings.ContainsAny(addr, "\r\n<>") || !strings.Contains(addr, "@") {
+		return ErrInvalidAddress
+	}
+	return nil
+}
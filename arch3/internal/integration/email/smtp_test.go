@@ -0,0 +1,246 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+	"arch3/internal/integration/otp/otptest"
+)
+
+// fakeSMTPServer 本地 SMTP 替身，仅实现投递所需的最小命令集
+type fakeSMTPServer struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	from     string
+	rcpts    []string
+	data     string
+	rejectTo string // 拒绝该收件人（模拟服务器错误）
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTPServer{ln: ln}
+	go s.serve()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake.smtp ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250-fake.smtp")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			s.mu.Lock()
+			s.from = parsePath(cmd[len("MAIL FROM:"):])
+			s.mu.Unlock()
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			rcpt := parsePath(cmd[len("RCPT TO:"):])
+			s.mu.Lock()
+			reject := rcpt == s.rejectTo
+			if !reject {
+				s.rcpts = append(s.rcpts, rcpt)
+			}
+			s.mu.Unlock()
+			if reject {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var b strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.mu.Lock()
+			s.data = b.String()
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case upper == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// parsePath 解析 MAIL/RCPT 命令中的地址，忽略 BODY=8BITMIME 等参数
+func parsePath(arg string) string {
+	fields := strings.Fields(arg)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.Trim(fields[0], "<>")
+}
+
+func newTestClient(port int) *Client {
+	return New(&Config{
+		Host:    "127.0.0.1",
+		Port:    port,
+		From:    "noreply@example.com",
+		TLSMode: TLSModeNone,
+		Timeout: 2 * time.Second,
+	})
+}
+
+func TestClient_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	client := newTestClient(server.port())
+
+	err := client.Send(context.Background(), &Message{
+		To:      "user@example.com",
+		Subject: "测试主题",
+		Body:    "第一行\n第二行",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.from != "noreply@example.com" {
+		t.Errorf("Expected from noreply@example.com, got %s", server.from)
+	}
+	if len(server.rcpts) != 1 || server.rcpts[0] != "user@example.com" {
+		t.Errorf("Expected rcpt user@example.com, got %v", server.rcpts)
+	}
+	if !strings.Contains(server.data, "To: user@example.com\r\n") {
+		t.Errorf("Expected To header in data, got %q", server.data)
+	}
+	if !strings.Contains(server.data, "第一行\r\n第二行") {
+		t.Errorf("Expected CRLF body in data, got %q", server.data)
+	}
+}
+
+func TestClient_Send_Errors(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.rejectTo = "blocked@example.com"
+	client := newTestClient(server.port())
+
+	tests := []struct {
+		name string
+		to   string
+	}{
+		{name: "地址为空", to: ""},
+		{name: "地址包含换行", to: "user@example.com\r\nBcc: evil@example.com"},
+		{name: "服务器拒绝收件人", to: "blocked@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.Send(context.Background(), &Message{To: tt.to, Subject: "s", Body: "b"})
+			if err == nil {
+				t.Error("Expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestClient_Send_ConnectFailed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	_ = ln.Close()
+
+	client := newTestClient(port)
+	if err := client.Send(context.Background(), &Message{To: "user@example.com"}); err == nil {
+		t.Error("Expected connect error but got nil")
+	}
+}
+
+func TestClient_Deliver(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	client := newTestClient(server.port())
+
+	err := client.Deliver(context.Background(), &otp.Message{
+		Type:    otp.TypeLogin,
+		Address: "user@example.com",
+		Code:    "123456",
+		TTL:     5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if !strings.Contains(server.data, "123456") {
+		t.Errorf("Expected code in mail body, got %q", server.data)
+	}
+	if !strings.Contains(server.data, "5 分钟") {
+		t.Errorf("Expected TTL in mail body, got %q", server.data)
+	}
+}
+
+func TestClient_ChannelContract(t *testing.T) {
+	otptest.TestChannel(t, func(t *testing.T) *otptest.ChannelHarness {
+		server := newFakeSMTPServer(t)
+		const address = "user@example.com"
+		return &otptest.ChannelHarness{
+			Channel: newTestClient(server.port()),
+			Address: address,
+			Delivered: func() []string {
+				server.mu.Lock()
+				defer server.mu.Unlock()
+				if server.data == "" {
+					return nil
+				}
+				return []string{server.data}
+			},
+			Fail: func() {
+				server.mu.Lock()
+				defer server.mu.Unlock()
+				server.rejectTo = address
+			},
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package identity
+
+import (
+	"context"
+	"sync"
+
+	domain "arch3/internal/domain/user"
+	userservice "arch3/internal/service/user"
+)
+
+// Fake 模拟核验器（开发/测试用）
+//
+// 按身份证号第 15-17 位顺序码决定结果，便于联调各分支:
+//   - 000: 无法判定（转人工审核）
+//   - 999: 姓名与身份证号不一致
+//   - 其他: 一致
+type Fake struct {
+	mu    sync.Mutex
+	calls int
+	err   error // 模拟服务商错误
+}
+
+// NewFake 创建模拟核验器
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+var _ userservice.IdentityVerifier = (*Fake)(nil)
+
+// Verify 按顺序码返回模拟结果
+func (f *Fake) Verify(_ context.Context, _ string, idNumber string) (domain.IdentityResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+
+	if len(idNumber) != 18 {
+		return domain.IdentityMismatch, nil
+	}
+	switch idNumber[14:17] {
+	case "000":
+		return domain.IdentityUncertain, nil
+	case "999":
+		return domain.IdentityMismatch, nil
+	default:
+		return domain.IdentityMatch, nil
+	}
+}
+
+// SetError 设置模拟错误，传 nil 恢复正常
+func (f *Fake) SetError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// Calls 返回调用次数
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
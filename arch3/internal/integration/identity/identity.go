@@ -0,0 +1,28 @@
+// Package identity 提供实名身份核验服务商适配
+//
+// 实现 userservice.IdentityVerifier:
+//   - Manual: 不调用服务商，全部转人工审核（未接入服务商时的生产选项）
+//   - Fake: 按规则模拟核验结果，仅用于开发与测试
+package identity
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/user"
+	userservice "arch3/internal/service/user"
+)
+
+// Manual 人工审核核验器
+type Manual struct{}
+
+// NewManual 创建人工审核核验器
+func NewManual() *Manual {
+	return &Manual{}
+}
+
+var _ userservice.IdentityVerifier = (*Manual)(nil)
+
+// Verify 始终返回无法自动判定
+func (*Manual) Verify(context.Context, string, string) (domain.IdentityResult, error) {
+	return domain.IdentityUncertain, nil
+}
@@ -0,0 +1,15 @@
+package oss
+
+import "time"
+
+// S3Config S3 兼容存储配置
+type S3Config struct {
+	Endpoint        string        // 服务地址，如 https://s3.amazonaws.com、http://127.0.0.1:9000
+	Region          string        // 区域
+	Bucket          string        // 桶名称
+	AccessKeyID     string        // 访问密钥 ID
+	SecretAccessKey string        // 访问密钥
+	PathStyle       bool          // 路径风格访问（endpoint/bucket/key）
+	PublicBaseURL   string        // 公开访问地址前缀，为空时使用桶地址
+	Timeout         time.Duration // 请求超时
+}
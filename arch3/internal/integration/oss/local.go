@@ -0,0 +1,96 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"arch3/pkg/tracer"
+)
+
+// LocalStorage 本地文件系统存储
+//
+// 适用于开发环境与单实例部署，对象通过本服务的静态路由或 Nginx 访问。
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage 创建本地存储，dir 不存在时自动创建
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("oss: create local dir: %w", err)
+	}
+	return &LocalStorage{dir: dir, baseURL: baseURL}, nil
+}
+
+// Put 写入对象
+// 先写临时文件再重命名，读者不会看到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	_, span := tracer.Start(ctx, "oss.local.Put")
+	defer span.End()
+
+	if err := validateKey(key); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 重命名成功后为空操作
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		tracer.RecordError(span, err)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// Delete 删除对象
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	_, span := tracer.Start(ctx, "oss.local.Delete")
+	defer span.End()
+
+	if err := validateKey(key); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// URL 返回对象访问地址
+func (s *LocalStorage) URL(key string) string {
+	return joinURL(s.baseURL, key)
+}
+
+func (s *LocalStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
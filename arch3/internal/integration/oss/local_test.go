@@ -0,0 +1,65 @@
+package oss
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	storage, err := NewLocalStorage(dir, "/uploads/")
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+
+	key := "avatars/u1/01HX/512.jpg"
+	if err := storage.Put(ctx, key, []byte("jpeg-data"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "avatars", "u1", "01HX", "512.jpg"))
+	if err != nil || string(data) != "jpeg-data" {
+		t.Errorf("Expected file written, got %q, %v", data, err)
+	}
+
+	if got := storage.URL(key); got != "/uploads/"+key {
+		t.Errorf("Expected URL /uploads/%s, got %s", key, got)
+	}
+
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := storage.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() missing object error = %v", err)
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "正常路径", key: "avatars/u1/a.jpg"},
+		{name: "空 key", key: "", wantErr: true},
+		{name: "绝对路径", key: "/etc/passwd", wantErr: true},
+		{name: "路径穿越", key: "avatars/../../etc/passwd", wantErr: true},
+		{name: "空片段", key: "avatars//a.jpg", wantErr: true},
+		{name: "反斜杠", key: `avatars\..\a.jpg`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateKey(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Expected ErrInvalidKey, got %v", err)
+			}
+		})
+	}
+}
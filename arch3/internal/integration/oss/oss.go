@@ -0,0 +1,47 @@
+// Package oss 提供对象存储能力
+//
+// Storage 屏蔽存储后端差异，当前提供本地文件系统（LocalStorage）
+// 与 S3 兼容服务（S3Storage，适用于 AWS S3、MinIO 等）两种实现。
+package oss
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+const maxKeyLen = 1024
+
+// ErrInvalidKey 对象 key 非法（为空、以 / 开头或包含 .. 等路径片段）
+var ErrInvalidKey = errors.New("oss: invalid object key")
+
+// Storage 对象存储接口
+//
+// key 为 / 分隔的相对路径，如 avatars/{user_id}/{id}/512.jpg。
+// 对象内容整体传入: 上传文件在业务层已做大小限制，且 S3 签名需要内容摘要。
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 返回对象的公开访问地址
+	URL(key string) string
+}
+
+// validateKey 校验对象 key，防止路径穿越
+func validateKey(key string) error {
+	if key == "" || len(key) > maxKeyLen || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return ErrInvalidKey
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// joinURL 拼接访问地址前缀与 key
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
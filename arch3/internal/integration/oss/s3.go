@@ -0,0 +1,159 @@
+package oss
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"arch3/pkg/tracer"
+)
+
+const (
+	s3Service         = "s3"
+	defaultS3Timeout  = 10 * time.Second
+	maxS3ErrorBodyLen = 1024
+)
+
+// S3Storage S3 兼容对象存储
+//
+// 仅实现业务需要的 PutObject/DeleteObject，请求使用 Signature V4 签名，
+// 兼容 AWS S3、MinIO 及提供 S3 协议的云厂商。
+type S3Storage struct {
+	config   *S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Storage 创建 S3 兼容存储
+func NewS3Storage(cfg *S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("oss: s3 bucket and credentials are required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("oss: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultS3Timeout
+	}
+	return &S3Storage{
+		config:   cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		now:      time.Now,
+	}, nil
+}
+
+// Put 上传对象（PutObject）
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	ctx, span := tracer.Start(ctx, "oss.s3.Put")
+	defer span.End()
+
+	span.SetAttributes(tracer.String("oss.bucket", s.config.Bucket), tracer.Int("oss.size", len(data)))
+
+	if err := validateKey(key); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, data, contentType)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	if err := s.do(req, http.StatusOK); err != nil {
+		tracer.RecordError(span, err)
+		return fmt.Errorf("oss: s3 put %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete 删除对象（DeleteObject）
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	ctx, span := tracer.Start(ctx, "oss.s3.Delete")
+	defer span.End()
+
+	if err := validateKey(key); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, "")
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	// S3 删除不存在的对象同样返回 204，部分兼容实现返回 404
+	if err := s.do(req, http.StatusNoContent, http.StatusOK, http.StatusNotFound); err != nil {
+		tracer.RecordError(span, err)
+		return fmt.Errorf("oss: s3 delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// URL 返回对象访问地址
+func (s *S3Storage) URL(key string) string {
+	if s.config.PublicBaseURL != "" {
+		return joinURL(s.config.PublicBaseURL, key)
+	}
+	return s.objectURL(key).String()
+}
+
+// objectURL 对象请求地址，按配置使用路径风格或虚拟主机风格
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	basePath := strings.TrimRight(u.Path, "/")
+	objectPath := "/" + key
+	if s.config.PathStyle {
+		objectPath = "/" + s.config.Bucket + objectPath
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	u.Path = basePath + objectPath
+	u.RawPath = uriEncode(basePath, false) + uriEncode(objectPath, false)
+	return &u
+}
+
+// newRequest 构造已签名的对象请求
+func (s *S3Storage) newRequest(ctx context.Context, method, key string, body []byte, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	// 签名覆盖请求头，须在签名前设置
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set(headerContentSHA256, hexSHA256(body))
+	signV4(req, s.config.AccessKeyID, s.config.SecretAccessKey, s.config.Region, s3Service, s.now())
+	return req, nil
+}
+
+// do 发送请求并检查状态码
+func (s *S3Storage) do(req *http.Request, okStatus ...int) error {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	for _, status := range okStatus {
+		if resp.StatusCode == status {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			return nil
+		}
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxS3ErrorBodyLen))
+	return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
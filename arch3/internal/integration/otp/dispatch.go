@@ -0,0 +1,250 @@
+package otp
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// 投递任务状态别名，指向 service 层定义
+const (
+	DispatchPending = userservice.OTPDispatchPending // 待投递/重试中
+	DispatchSent    = userservice.OTPDispatchSent    // 已投递
+	DispatchFailed  = userservice.OTPDispatchFailed  // 最终失败
+)
+
+// ErrDispatchNotFound 投递任务不存在或已过期
+var ErrDispatchNotFound = userservice.ErrOTPDispatchNotFound
+
+// DispatchJob 验证码投递任务
+//
+// 任务在请求内写入 outbox，由 Dispatcher 异步投递。
+// SealedCode 为加密后的验证码，仅 Dispatcher 投递时解密，任务进入终态后清除。
+type DispatchJob struct {
+	ID         string                        `json:"id"`
+	Channel    ChannelName                   `json:"channel"`
+	Type       Type                          `json:"type"`
+	Target     string                        `json:"target"`
+	Address    string                        `json:"address"`
+	SealedCode string                        `json:"sealed_code,omitempty"`
+	CodeDigest string                        `json:"code_digest"` // 本任务签发的验证码摘要，用于识别是否已被重新签发
+	ExpiresAt  time.Time                     `json:"expires_at"`  // 验证码失效时间，之后不再投递
+	Attempts   int                           `json:"attempts"`
+	Status     userservice.OTPDispatchStatus `json:"status"`
+	LastError  string                        `json:"last_error,omitempty"`
+	Trace      map[string]string             `json:"trace,omitempty"` // W3C trace context，用于关联请求链路
+	CreatedAt  time.Time                     `json:"created_at"`
+}
+
+// DispatchQueue 投递任务 outbox 接口
+//
+// 语义为至少一次投递: Claim 以租约方式领取任务，
+// 租约到期仍未确认（如 worker 崩溃）的任务会被重新领取。
+type DispatchQueue interface {
+	// Enqueue 写入任务，pending 任务立即可被领取；其他状态的任务仅保存记录
+	Enqueue(ctx context.Context, job *DispatchJob) error
+	// Claim 领取最多 limit 个到期任务，领取后在 lease 时长内对其他 worker 不可见
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*DispatchJob, error)
+	// Get 查询任务，不存在时返回 ErrDispatchNotFound
+	Get(ctx context.Context, id string) (*DispatchJob, error)
+	// MarkSent 标记投递成功并移出队列
+	MarkSent(ctx context.Context, job *DispatchJob) error
+	// Retry 记录失败并在 next 时刻重新可领取
+	Retry(ctx context.Context, job *DispatchJob, next time.Time) error
+	// MarkFailed 标记最终失败并移出队列
+	MarkFailed(ctx context.Context, job *DispatchJob) error
+}
+
+// DispatcherConfig 投递 worker 配置
+type DispatcherConfig struct {
+	BatchSize   int           // 每轮最多处理的任务数，任务逐个领取
+	Lease       time.Duration // 单个任务的领取租约时长，应大于单次投递超时（邮件最长约 10 秒）
+	MaxAttempts int           // 最大投递次数
+	BaseBackoff time.Duration // 首次重试间隔，之后按 2 倍递增
+	MaxBackoff  time.Duration // 重试间隔上限
+}
+
+// DefaultDispatcherConfig 默认投递配置
+// 5 次尝试的累计退避约 15 秒，远小于验证码有效期
+func DefaultDispatcherConfig() DispatcherConfig {
+	return DispatcherConfig{
+		BatchSize:   50,
+		Lease:       30 * time.Second,
+		MaxAttempts: 5,
+		BaseBackoff: time.Second,
+		MaxBackoff:  30 * time.Second,
+	}
+}
+
+// Dispatcher 验证码异步投递 worker
+type Dispatcher struct {
+	queue    DispatchQueue
+	repo     CodeRepository
+	hasher   *CodeHasher
+	channels map[ChannelName]Channel
+	cfg      DispatcherConfig
+}
+
+// NewDispatcher 创建投递 worker
+// hasher 须与 Manager 使用同一 secret，用于解密任务中的验证码
+func NewDispatcher(queue DispatchQueue, repo CodeRepository, hasher *CodeHasher, cfg DispatcherConfig, channels ...Channel) *Dispatcher {
+	d := &Dispatcher{
+		queue:    queue,
+		repo:     repo,
+		hasher:   hasher,
+		channels: make(map[ChannelName]Channel, len(channels)),
+		cfg:      cfg,
+	}
+	for _, ch := range channels {
+		d.channels[ch.Name()] = ch
+	}
+	return d
+}
+
+// RunOnce 处理最多 BatchSize 个到期任务
+// 供调度器周期调用，单个任务失败不影响同批其他任务
+//
+// 任务投递是串行的，单次投递可能耗时数秒；若一次领取整批，排在后面的任务
+// 会在等待期间租约到期、被其他 worker 重新领取而重复下发。
+// 因此每次只领取一个任务，租约从该任务开始投递时计算。
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	for range d.cfg.BatchSize {
+		if ctx.Err() != nil {
+			return nil
+		}
+		jobs, err := d.queue.Claim(ctx, 1, d.cfg.Lease)
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		d.process(ctx, jobs[0])
+	}
+	return nil
+}
+
+// process 投递单个任务
+func (d *Dispatcher) process(ctx context.Context, job *DispatchJob) {
+	// 恢复请求的 trace context，投递 span 挂在原请求链路下
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(job.Trace))
+	ctx, span := tracer.Start(ctx, "otp.Dispatch", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(
+		tracer.String("otp.dispatch_id", job.ID),
+		tracer.String(tracer.AttrOTPChannel, string(job.Channel)),
+		tracer.String(tracer.AttrSMSType, string(job.Type)),
+		tracer.Int("otp.attempt", job.Attempts+1),
+	)
+
+	// 幂等: 已有终态的任务（如租约过期后被重复领取）直接确认
+	if job.Status != DispatchPending {
+		tracer.AddEvent(span, "dispatch.already_done", tracer.String("status", string(job.Status)))
+		return
+	}
+
+	// 验证码已失效，不再投递
+	if time.Now().After(job.ExpiresAt) {
+		d.fail(ctx, span, job, "code expired before delivery")
+		return
+	}
+
+	ch, ok := d.channels[job.Channel]
+	if !ok {
+		d.fail(ctx, span, job, ErrChannelUnavailable.Error())
+		return
+	}
+
+	code, err := d.hasher.Open(job.SealedCode)
+	if err != nil {
+		d.fail(ctx, span, job, err.Error())
+		return
+	}
+
+	job.Attempts++
+	err = ch.Deliver(ctx, &Message{
+		Type:           job.Type,
+		Address:        job.Address,
+		Code:           code,
+		TTL:            time.Until(job.ExpiresAt).Round(time.Minute),
+		IdempotencyKey: job.ID,
+	})
+	if err == nil {
+		job.SealedCode = ""
+		if err := d.queue.MarkSent(ctx, job); err != nil {
+			tracer.RecordError(span, err)
+			logger.Ctx(ctx).Error("mark otp dispatch sent failed", zap.String("dispatch_id", job.ID), zap.Error(err))
+		}
+		return
+	}
+
+	tracer.RecordError(span, err)
+	job.LastError = err.Error()
+	if job.Attempts >= d.cfg.MaxAttempts {
+		d.fail(ctx, span, job, job.LastError)
+		return
+	}
+
+	next := time.Now().Add(d.backoff(job.Attempts))
+	if next.After(job.ExpiresAt) {
+		d.fail(ctx, span, job, job.LastError)
+		return
+	}
+
+	logger.Ctx(ctx).Warn("otp delivery failed, will retry",
+		zap.String("dispatch_id", job.ID),
+		zap.String("channel", string(job.Channel)),
+		zap.Int("attempt", job.Attempts),
+		zap.Time("next_attempt", next),
+		zap.Error(err),
+	)
+	if err := d.queue.Retry(ctx, job, next); err != nil {
+		tracer.RecordError(span, err)
+		logger.Ctx(ctx).Error("reschedule otp dispatch failed", zap.String("dispatch_id", job.ID), zap.Error(err))
+	}
+}
+
+// fail 标记最终失败，并删除已失去意义的验证码
+func (d *Dispatcher) fail(ctx context.Context, span trace.Span, job *DispatchJob, reason string) {
+	job.LastError = reason
+	job.SealedCode = ""
+	tracer.AddEvent(span, "dispatch.failed", tracer.String("reason", reason))
+
+	logger.Ctx(ctx).Error("otp delivery failed permanently",
+		zap.String("dispatch_id", job.ID),
+		zap.String("channel", string(job.Channel)),
+		zap.Int("attempts", job.Attempts),
+		zap.String("reason", reason),
+	)
+
+	// 仅删除本任务签发的验证码，避免误删用户随后重新获取的验证码
+	if stored, err := d.repo.GetCode(ctx, job.Type, job.Target); err == nil && stored == job.CodeDigest {
+		if err := d.repo.DeleteCode(ctx, job.Type, job.Target); err != nil {
+			tracer.AddEvent(span, "delete_code_on_send_fail_failed", tracer.String("error", err.Error()))
+		}
+	}
+	if err := d.queue.MarkFailed(ctx, job); err != nil {
+		tracer.RecordError(span, err)
+	}
+}
+
+// backoff 计算第 attempt 次失败后的重试间隔（指数退避 + 抖动）
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.BaseBackoff << (attempt - 1)
+	if delay <= 0 || delay > d.cfg.MaxBackoff {
+		delay = d.cfg.MaxBackoff
+	}
+	// ±10% 抖动，避免同批失败任务同时重试
+	jitter := time.Duration(rand.Int64N(int64(delay)/5+1)) - delay/10
+	return delay + jitter
+}
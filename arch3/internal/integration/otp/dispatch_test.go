@@ -0,0 +1,200 @@
+package otp_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+	userservice "arch3/internal/service/user"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDispatcher_RetryThenSent(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+
+	dispatchID, err := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// 首次投递失败，任务保持 pending 并等待退避
+	o.voice.SetCallError(errors.New("provider timeout"))
+	_ = o.dispatcher.RunOnce(ctx)
+
+	job, _ := o.queue.Get(ctx, dispatchID)
+	if job.Status != otp.DispatchPending || job.Attempts != 1 || job.LastError == "" {
+		t.Fatalf("Expected pending job with 1 attempt, got %+v", job)
+	}
+
+	// 退避未到期时不会被重新领取
+	o.voice.Reset()
+	_ = o.dispatcher.RunOnce(ctx)
+	if len(o.voice.GetRecords()) != 0 {
+		t.Fatal("Expected no delivery before backoff elapses")
+	}
+
+	o.queue.MakeDue(dispatchID)
+	_ = o.dispatcher.RunOnce(ctx)
+
+	if status, _ := o.manager.DispatchStatus(ctx, dispatchID); status != otp.DispatchSent {
+		t.Errorf("Expected status sent, got %s", status)
+	}
+	code := o.voice.LastCode()
+	if err := o.manager.Verify(ctx, otp.TypeLogin, "13800138000", code); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+}
+
+func TestDispatcher_MaxAttempts_Failed(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+	o.voice.SetCallError(errors.New("provider down"))
+
+	dispatchID, _ := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+
+	for i := 0; i < 3; i++ {
+		o.queue.MakeDue(dispatchID)
+		_ = o.dispatcher.RunOnce(ctx)
+	}
+
+	if status, _ := o.manager.DispatchStatus(ctx, dispatchID); status != otp.DispatchFailed {
+		t.Fatalf("Expected status failed, got %s", status)
+	}
+
+	// 最终失败后验证码被删除
+	if _, err := o.repo.GetCode(ctx, otp.TypeLogin, "13800138000"); !errors.Is(err, otp.ErrCodeInvalid) {
+		t.Errorf("Expected code deleted after failure, got %v", err)
+	}
+}
+
+func TestDispatcher_Fail_KeepsNewerCode(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+	o.voice.SetCallError(errors.New("provider down"))
+
+	dispatchID, _ := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+
+	// 用户在投递失败前重新获取了验证码
+	newer, _ := o.hasher.Hash(otp.TypeLogin, "13800138000", "654321")
+	_ = o.repo.StoreCode(ctx, otp.TypeLogin, "13800138000", newer, 5*time.Minute)
+
+	for i := 0; i < 3; i++ {
+		o.queue.MakeDue(dispatchID)
+		_ = o.dispatcher.RunOnce(ctx)
+	}
+
+	if err := o.manager.Verify(ctx, otp.TypeLogin, "13800138000", "654321"); err != nil {
+		t.Errorf("Expected newer code kept, got %v", err)
+	}
+}
+
+func TestDispatcher_RedeliveredJob_Idempotent(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+
+	dispatchID, _ := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+	job, _ := o.queue.Get(ctx, dispatchID)
+
+	// 模拟 worker 投递成功后、确认前崩溃：租约到期任务被再次领取
+	_ = o.dispatcher.RunOnce(ctx)
+	_ = o.queue.Retry(ctx, job, time.Now())
+	_ = o.dispatcher.RunOnce(ctx)
+
+	if records := o.voice.GetRecords(); len(records) != 1 {
+		t.Errorf("Expected exactly 1 call, got %d", len(records))
+	}
+}
+
+// slowChannel 每次投递耗时 delay 的渠道，记录每个投递任务的投递次数
+type slowChannel struct {
+	delay time.Duration
+	mu    sync.Mutex
+	sent  map[string]int
+}
+
+func (c *slowChannel) Name() otp.ChannelName { return otp.ChannelVoice }
+
+func (c *slowChannel) Validate(otp.Type) error { return nil }
+
+func (c *slowChannel) Deliver(_ context.Context, msg *otp.Message) error {
+	time.Sleep(c.delay)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sent[msg.IdempotencyKey]++
+	return nil
+}
+
+func TestDispatcher_LeasePerJob(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+	ch := &slowChannel{delay: 150 * time.Millisecond, sent: make(map[string]int)}
+	manager := otp.NewManager(o.repo, o.queue, o.hasher, ch)
+	cfg := otp.DefaultDispatcherConfig()
+	cfg.Lease = 200 * time.Millisecond
+
+	for _, target := range []string{"13800138001", "13800138002", "13800138003"} {
+		if _, err := manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: target}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	// 第一个 worker 串行投递 3 个任务，耗时超过租约；
+	// 第二个 worker 在第一个投递期间启动，只能领取尚未开始投递的任务
+	var wg sync.WaitGroup
+	for _, start := range []time.Duration{0, 250 * time.Millisecond} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(start)
+			_ = otp.NewDispatcher(o.queue, o.repo, o.hasher, cfg, ch).RunOnce(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if len(ch.sent) != 3 {
+		t.Errorf("Expected 3 jobs delivered, got %v", ch.sent)
+	}
+	for id, n := range ch.sent {
+		if n != 1 {
+			t.Errorf("Expected job %s delivered once, got %d", id, n)
+		}
+	}
+}
+
+func TestDispatcher_PropagatesTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+
+	ctx, reqSpan := tp.Tracer("test").Start(context.Background(), "request")
+	o := newTestOTP()
+	_, _ = o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+	reqSpan.End()
+
+	// worker 使用独立的 context
+	_ = o.dispatcher.RunOnce(context.Background())
+
+	for _, s := range exporter.GetSpans() {
+		if s.Name == "otp.Dispatch" {
+			if s.SpanContext.TraceID() != reqSpan.SpanContext().TraceID() {
+				t.Errorf("Expected dispatch span in request trace %s, got %s", reqSpan.SpanContext().TraceID(), s.SpanContext.TraceID())
+			}
+			return
+		}
+	}
+	t.Error("Expected otp.Dispatch span")
+}
@@ -0,0 +1,125 @@
+package otp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+const nonceSize = 16
+
+// errSealedCodeInvalid 投递任务中的验证码密文无法解密（密钥变更或数据损坏）
+var errSealedCodeInvalid = errors.New("otp: sealed code invalid")
+
+// CodeHasher 验证码摘要与加密
+//
+// 存储层只保存验证码的 HMAC 摘要，格式为 "nonce:mac"（base64url）:
+//   - mac = HMAC-SHA256(key, type | target | nonce | code)
+//   - nonce 每次签发随机生成，同一验证码两次签发的摘要不同
+//   - type 与 target 参与计算，摘要无法挪用到其他用途或手机号
+//
+// 投递任务需要明文下发，使用 AES-GCM 加密后存入队列。
+// 两个用途的密钥都由同一 secret 派生，互不相同。
+type CodeHasher struct {
+	macKey []byte
+	aead   cipher.AEAD
+}
+
+// NewCodeHasher 创建验证码摘要器
+func NewCodeHasher(secret []byte) (*CodeHasher, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("otp: secret must not be empty")
+	}
+
+	block, err := aes.NewCipher(deriveKey(secret, "otp-dispatch-seal"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CodeHasher{
+		macKey: deriveKey(secret, "otp-code-mac"),
+		aead:   aead,
+	}, nil
+}
+
+// Hash 生成验证码摘要（含随机 nonce）
+func (h *CodeHasher) Hash(smsType Type, target, code string) (string, error) {
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	mac := h.mac(smsType, target, nonce, code)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(nonce) + ":" + enc.EncodeToString(mac), nil
+}
+
+// Match 常量时间比较验证码与摘要
+func (h *CodeHasher) Match(digest string, smsType Type, target, code string) bool {
+	nonceStr, macStr, ok := strings.Cut(digest, ":")
+	if !ok {
+		return false
+	}
+	enc := base64.RawURLEncoding
+	nonce, err := enc.DecodeString(nonceStr)
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(macStr)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(h.mac(smsType, target, nonce, code), want)
+}
+
+// Seal 加密验证码明文，供投递任务存储
+func (h *CodeHasher) Seal(code string) (string, error) {
+	nonce := make([]byte, h.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := h.aead.Seal(nonce, nonce, []byte(code), nil)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密投递任务中的验证码
+func (h *CodeHasher) Open(sealed string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil || len(data) < h.aead.NonceSize() {
+		return "", errSealedCodeInvalid
+	}
+	nonce, ciphertext := data[:h.aead.NonceSize()], data[h.aead.NonceSize():]
+	code, err := h.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errSealedCodeInvalid
+	}
+	return string(code), nil
+}
+
+func (h *CodeHasher) mac(smsType Type, target string, nonce []byte, code string) []byte {
+	m := hmac.New(sha256.New, h.macKey)
+	// 各字段以 0 分隔，避免拼接歧义
+	m.Write([]byte(smsType))
+	m.Write([]byte{0})
+	m.Write([]byte(target))
+	m.Write([]byte{0})
+	m.Write(nonce)
+	m.Write([]byte{0})
+	m.Write([]byte(code))
+	return m.Sum(nil)
+}
+
+// deriveKey 按用途从 secret 派生 32 字节子密钥
+func deriveKey(secret []byte, purpose string) []byte {
+	m := hmac.New(sha256.New, secret)
+	m.Write([]byte(purpose))
+	return m.Sum(nil)
+}
@@ -0,0 +1,72 @@
+package otp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCodeHasher_Match(t *testing.T) {
+	h, err := NewCodeHasher([]byte("test-otp-secret"))
+	if err != nil {
+		t.Fatalf("NewCodeHasher() error = %v", err)
+	}
+	digest, err := h.Hash(TypeLogin, "13800138000", "123456")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	other, _ := NewCodeHasher([]byte("other-secret"))
+
+	tests := []struct {
+		name   string
+		hasher *CodeHasher
+		digest string
+		typ    Type
+		target string
+		code   string
+		want   bool
+	}{
+		{name: "正确验证码", hasher: h, digest: digest, typ: TypeLogin, target: "13800138000", code: "123456", want: true},
+		{name: "错误验证码", hasher: h, digest: digest, typ: TypeLogin, target: "13800138000", code: "654321", want: false},
+		{name: "用途不同", hasher: h, digest: digest, typ: TypeRegister, target: "13800138000", code: "123456", want: false},
+		{name: "手机号不同", hasher: h, digest: digest, typ: TypeLogin, target: "13800138001", code: "123456", want: false},
+		{name: "密钥不同", hasher: other, digest: digest, typ: TypeLogin, target: "13800138000", code: "123456", want: false},
+		{name: "明文存储值", hasher: h, digest: "123456", typ: TypeLogin, target: "13800138000", code: "123456", want: false},
+		{name: "格式损坏", hasher: h, digest: "!!:??", typ: TypeLogin, target: "13800138000", code: "123456", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.Match(tt.digest, tt.typ, tt.target, tt.code); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCodeHasher_Hash_UniqueNonce(t *testing.T) {
+	h, _ := NewCodeHasher([]byte("test-otp-secret"))
+	a, _ := h.Hash(TypeLogin, "13800138000", "123456")
+	b, _ := h.Hash(TypeLogin, "13800138000", "123456")
+	if a == b {
+		t.Error("Expected different digests for repeated issuance")
+	}
+	if strings.Contains(a, "123456") {
+		t.Errorf("Expected digest without plaintext, got %q", a)
+	}
+}
+
+func TestCodeHasher_SealOpen(t *testing.T) {
+	h, _ := NewCodeHasher([]byte("test-otp-secret"))
+	sealed, err := h.Seal("123456")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if code, err := h.Open(sealed); err != nil || code != "123456" {
+		t.Errorf("Open() = %q, %v", code, err)
+	}
+
+	other, _ := NewCodeHasher([]byte("other-secret"))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Expected error opening with different secret")
+	}
+}
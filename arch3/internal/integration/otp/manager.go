@@ -0,0 +1,379 @@
+package otp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"math/big"
+	"time"
+
+	"arch3/internal/service/common"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+	"arch3/pkg/ulid"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	codeTTL        = 5 * time.Minute
+	maxVerifyFails = 5 // 最大验证失败次数
+)
+
+// sendLimit 发送频率限制（各渠道共享计数）
+type sendLimit struct {
+	perMinute int
+	perDay    int
+}
+
+var (
+	defaultSendLimit = sendLimit{perMinute: 1, perDay: 6}
+
+	// typeSendLimits 按用途覆盖默认限制
+	// 更换手机号、注销账号为低频敏感操作，日上限更低
+	typeSendLimits = map[Type]sendLimit{
+		TypeChangePhoneOld: {perMinute: 1, perDay: 3},
+		TypeChangePhoneNew: {perMinute: 1, perDay: 3},
+		TypeDeleteAccount:  {perMinute: 1, perDay: 3},
+	}
+)
+
+// sendLimitFor 返回用途对应的发送限制
+func sendLimitFor(smsType Type) sendLimit {
+	if limit, ok := typeSendLimits[smsType]; ok {
+		return limit
+	}
+	return defaultSendLimit
+}
+
+// Manager 渠道无关的验证码管理器，实现 userservice.OTPClient
+//
+// Send 只负责签发、存储验证码并写入投递队列，实际投递由 Dispatcher 异步完成，
+// 服务商延迟不再计入请求耗时。
+// 存储与队列中均不出现验证码明文: 存储层保存 HMAC 摘要，队列保存密文。
+type Manager struct {
+	repo       CodeRepository
+	queue      DispatchQueue
+	hasher     *CodeHasher
+	channels   map[ChannelName]Channel
+	testPhones map[string]*TestPhone
+}
+
+// NewManager 创建验证码管理器
+// 未注册的渠道在发送时返回 ErrChannelUnavailable
+func NewManager(repo CodeRepository, queue DispatchQueue, hasher *CodeHasher, channels ...Channel) *Manager {
+	m := &Manager{
+		repo:     repo,
+		queue:    queue,
+		hasher:   hasher,
+		channels: make(map[ChannelName]Channel, len(channels)),
+	}
+	for _, ch := range channels {
+		m.channels[ch.Name()] = ch
+	}
+	return m
+}
+
+var _ userservice.OTPClient = (*Manager)(nil)
+
+// SetTestPhones 设置测试号码白名单，须在处理请求前调用
+func (m *Manager) SetTestPhones(phones []TestPhone) {
+	m.testPhones = make(map[string]*TestPhone, len(phones))
+	for i := range phones {
+		m.testPhones[phones[i].Phone] = &phones[i]
+	}
+}
+
+// Send 生成验证码并提交异步投递，返回投递 ID
+func (m *Manager) Send(ctx context.Context, req *userservice.OTPSendRequest) (string, error) {
+	ctx, span := tracer.Start(ctx, "otp.Send")
+	defer span.End()
+
+	span.SetAttributes(
+		tracer.String(tracer.AttrOTPChannel, string(req.Channel)),
+		tracer.String(tracer.AttrSMSType, string(req.Type)),
+	)
+
+	ch, ok := m.channels[req.Channel]
+	if !ok {
+		tracer.RecordError(span, ErrChannelUnavailable)
+		return "", ErrChannelUnavailable
+	}
+
+	// 先检查渠道是否支持该用途（避免存储验证码后发现无法投递）
+	if err := ch.Validate(req.Type); err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	// 测试号码: 使用固定验证码，跳过服务商与发送限制
+	if tp := m.usableTestPhone(ctx, req.Target); tp != nil {
+		return m.sendTestCode(ctx, span, req, tp)
+	}
+
+	// 检查发送限制
+	if err := m.checkSendLimit(ctx, span, req); err != nil {
+		return "", err
+	}
+
+	dispatchID, err := ulid.New()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	span.SetAttributes(tracer.String("otp.dispatch_id", dispatchID))
+
+	// 生成验证码，存储摘要，密文随任务投递
+	code := generateCode()
+	digest, err := m.hasher.Hash(req.Type, req.Target, code)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	sealed, err := m.hasher.Seal(code)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	if err := m.repo.StoreCode(ctx, req.Type, req.Target, digest, codeTTL); err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	address := req.Address
+	if address == "" {
+		address = req.Target
+	}
+
+	now := time.Now()
+	job := &DispatchJob{
+		ID:         dispatchID,
+		Channel:    req.Channel,
+		Type:       req.Type,
+		Target:     req.Target,
+		Address:    address,
+		SealedCode: sealed,
+		CodeDigest: digest,
+		ExpiresAt:  now.Add(codeTTL),
+		Status:     DispatchPending,
+		Trace:      make(map[string]string),
+		CreatedAt:  now,
+	}
+	// 注入 trace context，worker 投递时恢复链路
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(job.Trace))
+
+	// 写入投递队列
+	if err := m.queue.Enqueue(ctx, job); err != nil {
+		tracer.RecordError(span, err)
+		// 入队失败时删除已存储的验证码
+		if delErr := m.repo.DeleteCode(ctx, req.Type, req.Target); delErr != nil {
+			tracer.AddEvent(span, "delete_code_on_enqueue_fail_failed", tracer.String("error", delErr.Error()))
+		}
+		return "", ErrSendFailed
+	}
+
+	// 记录发送次数（失败不影响主流程）
+	if err := m.repo.IncrSendCount(ctx, req.Type, req.Target); err != nil {
+		tracer.AddEvent(span, "record_send_failed", tracer.String("error", err.Error()))
+	}
+
+	// 重新发送验证码成功后重置验证失败计数
+	if err := m.repo.ResetVerifyFailCount(ctx, req.Type, req.Target); err != nil {
+		tracer.AddEvent(span, "reset_verify_fail_count_failed", tracer.String("error", err.Error()))
+	}
+
+	return dispatchID, nil
+}
+
+// DispatchStatus 查询投递状态
+func (m *Manager) DispatchStatus(ctx context.Context, dispatchID string) (userservice.OTPDispatchStatus, error) {
+	job, err := m.queue.Get(ctx, dispatchID)
+	if err != nil {
+		return "", err
+	}
+	return job.Status, nil
+}
+
+// Verify 验证验证码
+func (m *Manager) Verify(ctx context.Context, smsType Type, target, code string) error {
+	ctx, span := tracer.Start(ctx, "otp.Verify")
+	defer span.End()
+
+	span.SetAttributes(tracer.String(tracer.AttrSMSType, string(smsType)))
+
+	// 检查验证失败次数
+	failCount, err := m.repo.GetVerifyFailCount(ctx, smsType, target)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if failCount >= maxVerifyFails {
+		tracer.RecordError(span, ErrVerifyTooMany)
+		return ErrVerifyTooMany
+	}
+
+	digest, err := m.repo.GetCode(ctx, smsType, target)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return ErrCodeInvalid
+	}
+
+	// 常量时间比较，摘要绑定用途与手机号
+	// 测试号码的固定验证码仅在白名单条件（有效期、IP）满足时有效
+	if !m.hasher.Match(digest, smsType, target, code) || m.rejectTestCode(ctx, target, code) {
+		// 增加验证失败次数
+		if err := m.repo.IncrVerifyFailCount(ctx, smsType, target); err != nil {
+			tracer.AddEvent(span, "incr_verify_fail_count_failed", tracer.String("error", err.Error()))
+		}
+		tracer.RecordError(span, ErrCodeInvalid)
+		return ErrCodeInvalid
+	}
+
+	// 验证成功后删除验证码和失败计数
+	if err := m.repo.DeleteCode(ctx, smsType, target); err != nil {
+		tracer.AddEvent(span, "delete_code_failed", tracer.String("error", err.Error()))
+	}
+	if err := m.repo.ResetVerifyFailCount(ctx, smsType, target); err != nil {
+		tracer.AddEvent(span, "reset_verify_fail_count_failed", tracer.String("error", err.Error()))
+	}
+
+	if tp := m.testPhones[target]; tp != nil && subtle.ConstantTimeCompare([]byte(code), []byte(tp.Code)) == 1 {
+		logger.Ctx(ctx).Warn("TEST PHONE OTP VERIFIED",
+			zap.String("phone", target),
+			zap.String("type", string(smsType)),
+			zap.String("client_ip", common.ClientIP(ctx)),
+		)
+	}
+
+	return nil
+}
+
+// Purge 清除主体的全部验证码、发送计数与验证失败计数
+func (m *Manager) Purge(ctx context.Context, target string) error {
+	ctx, span := tracer.Start(ctx, "otp.Purge")
+	defer span.End()
+
+	if err := m.repo.PurgeTarget(ctx, target); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// usableTestPhone 返回当前请求可用的测试号码，未命中返回 nil
+func (m *Manager) usableTestPhone(ctx context.Context, target string) *TestPhone {
+	tp := m.testPhones[target]
+	if tp == nil {
+		return nil
+	}
+	if !tp.usable(common.ClientIP(ctx), time.Now()) {
+		logger.Ctx(ctx).Warn("Test phone rejected, falling back to normal delivery",
+			zap.String("phone", target),
+			zap.String("client_ip", common.ClientIP(ctx)),
+		)
+		return nil
+	}
+	return tp
+}
+
+// rejectTestCode 测试号码的固定验证码在白名单条件不满足时拒绝
+func (m *Manager) rejectTestCode(ctx context.Context, target, code string) bool {
+	tp := m.testPhones[target]
+	if tp == nil || subtle.ConstantTimeCompare([]byte(code), []byte(tp.Code)) != 1 {
+		return false
+	}
+	return !tp.usable(common.ClientIP(ctx), time.Now())
+}
+
+// sendTestCode 为测试号码签发固定验证码
+// 投递记录直接以 sent 状态写入，不进入投递队列
+func (m *Manager) sendTestCode(ctx context.Context, span trace.Span, req *userservice.OTPSendRequest, tp *TestPhone) (string, error) {
+	dispatchID, err := ulid.New()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	logger.Ctx(ctx).Warn("TEST PHONE OTP ISSUED, provider call skipped",
+		zap.String("phone", req.Target),
+		zap.String("type", string(req.Type)),
+		zap.String("channel", string(req.Channel)),
+		zap.String("client_ip", common.ClientIP(ctx)),
+		zap.String("dispatch_id", dispatchID),
+	)
+	tracer.AddEvent(span, "otp.test_phone")
+
+	digest, err := m.hasher.Hash(req.Type, req.Target, tp.Code)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	if err := m.repo.StoreCode(ctx, req.Type, req.Target, digest, codeTTL); err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	now := time.Now()
+	if err := m.queue.Enqueue(ctx, &DispatchJob{
+		ID:         dispatchID,
+		Channel:    req.Channel,
+		Type:       req.Type,
+		Target:     req.Target,
+		Address:    req.Target,
+		CodeDigest: digest,
+		ExpiresAt:  now.Add(codeTTL),
+		Status:     DispatchSent,
+		CreatedAt:  now,
+	}); err != nil {
+		tracer.RecordError(span, err)
+		return "", ErrSendFailed
+	}
+
+	if err := m.repo.ResetVerifyFailCount(ctx, req.Type, req.Target); err != nil {
+		tracer.AddEvent(span, "reset_verify_fail_count_failed", tracer.String("error", err.Error()))
+	}
+
+	return dispatchID, nil
+}
+
+// checkSendLimit 检查发送限制（各渠道共享计数，限制按用途区分）
+func (m *Manager) checkSendLimit(ctx context.Context, span trace.Span, req *userservice.OTPSendRequest) error {
+	minuteCount, dayCount, err := m.repo.GetSendCount(ctx, req.Type, req.Target)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	span.SetAttributes(
+		tracer.Int("otp.minute_count", minuteCount),
+		tracer.Int("otp.day_count", dayCount),
+	)
+
+	limit := sendLimitFor(req.Type)
+	if minuteCount >= limit.perMinute {
+		tracer.RecordError(span, ErrSendTooFrequent)
+		return ErrSendTooFrequent
+	}
+
+	if dayCount >= limit.perDay {
+		tracer.RecordError(span, ErrDailyLimitExceeded)
+		return ErrDailyLimitExceeded
+	}
+
+	return nil
+}
+
+// generateCode 生成6位验证码 (使用 crypto/rand)
+func generateCode() string {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		// fallback: 使用时间戳生成
+		return fmt.Sprintf("%06d", time.Now().UnixNano()%1000000)
+	}
+	return fmt.Sprintf("%06d", n.Int64())
+}
@@ -0,0 +1,222 @@
+package otp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+	"arch3/internal/integration/otp/otptest"
+	"arch3/internal/integration/voice"
+	userservice "arch3/internal/service/user"
+)
+
+// testOTP 组装测试用的验证码管理器与投递 worker
+type testOTP struct {
+	repo       *otptest.CodeRepository
+	queue      *otptest.DispatchQueue
+	hasher     *otp.CodeHasher
+	voice      *voice.MockClient
+	manager    *otp.Manager
+	dispatcher *otp.Dispatcher
+}
+
+func newTestOTP() *testOTP {
+	repo := otptest.NewCodeRepository()
+	queue := otptest.NewDispatchQueue()
+	hasher, _ := otp.NewCodeHasher([]byte("test-otp-secret"))
+	voiceClient := voice.NewMockClient()
+	cfg := otp.DefaultDispatcherConfig()
+	cfg.MaxAttempts = 3
+	return &testOTP{
+		repo:       repo,
+		queue:      queue,
+		hasher:     hasher,
+		voice:      voiceClient,
+		manager:    otp.NewManager(repo, queue, hasher, voiceClient),
+		dispatcher: otp.NewDispatcher(queue, repo, hasher, cfg, voiceClient),
+	}
+}
+
+func TestManager_SendAndVerify_Voice(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+
+	dispatchID, err := o.manager.Send(ctx, &userservice.OTPSendRequest{
+		Channel: otp.ChannelVoice,
+		Type:    otp.TypeLogin,
+		Target:  "13800138000",
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// Send 只入队，不同步投递
+	if records := o.voice.GetRecords(); len(records) != 0 {
+		t.Fatalf("Expected no call before dispatch, got %+v", records)
+	}
+	job, _ := o.queue.Get(ctx, dispatchID)
+	if job.SealedCode == "" {
+		t.Error("Expected sealed code in pending job")
+	}
+	if status, _ := o.manager.DispatchStatus(ctx, dispatchID); status != otp.DispatchPending {
+		t.Errorf("Expected status pending, got %s", status)
+	}
+
+	if err := o.dispatcher.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	records := o.voice.GetRecords()
+	if len(records) != 1 || records[0].Phone != "13800138000" {
+		t.Fatalf("Expected 1 call to 13800138000, got %+v", records)
+	}
+	if status, _ := o.manager.DispatchStatus(ctx, dispatchID); status != otp.DispatchSent {
+		t.Errorf("Expected status sent, got %s", status)
+	}
+
+	// 存储与队列中均不出现验证码明文
+	stored, _ := o.repo.GetCode(ctx, otp.TypeLogin, "13800138000")
+	if strings.Contains(stored, records[0].Code) {
+		t.Errorf("Expected digest in store, got %q", stored)
+	}
+	if job, _ := o.queue.Get(ctx, dispatchID); job.SealedCode != "" {
+		t.Errorf("Expected sealed code cleared after sent, got %q", job.SealedCode)
+	}
+
+	if err := o.manager.Verify(ctx, otp.TypeLogin, "13800138000", records[0].Code); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// 验证成功后验证码失效
+	if err := o.manager.Verify(ctx, otp.TypeLogin, "13800138000", records[0].Code); !errors.Is(err, otp.ErrCodeInvalid) {
+		t.Errorf("Expected ErrCodeInvalid after use, got %v", err)
+	}
+}
+
+func TestManager_Send_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		setup   func(o *testOTP)
+		channel otp.ChannelName
+		wantErr error
+	}{
+		{
+			name:    "渠道未启用",
+			channel: otp.ChannelEmail,
+			wantErr: otp.ErrChannelUnavailable,
+		},
+		{
+			name: "一分钟内重复发送",
+			setup: func(o *testOTP) {
+				_, _ = o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+			},
+			channel: otp.ChannelVoice,
+			wantErr: otp.ErrSendTooFrequent,
+		},
+		{
+			name: "入队失败",
+			setup: func(o *testOTP) {
+				o.queue.SetEnqueueError(errors.New("redis down"))
+			},
+			channel: otp.ChannelVoice,
+			wantErr: otp.ErrSendFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newTestOTP()
+			if tt.setup != nil {
+				tt.setup(o)
+			}
+
+			_, err := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: tt.channel, Type: otp.TypeLogin, Target: "13800138000"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManager_Send_LimitSharedAcrossChannels(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+	sms := otptest.NewChannel(otp.ChannelSMS)
+	manager := otp.NewManager(o.repo, o.queue, o.hasher, o.voice, sms)
+
+	send := func(channel otp.ChannelName) error {
+		_, err := manager.Send(ctx, &userservice.OTPSendRequest{Channel: channel, Type: otp.TypeLogin, Target: "13800138000"})
+		return err
+	}
+	if err := send(otp.ChannelSMS); err != nil {
+		t.Fatalf("Send(sms) error = %v", err)
+	}
+	// 切换渠道不重置分钟计数
+	if err := send(otp.ChannelVoice); !errors.Is(err, otp.ErrSendTooFrequent) {
+		t.Errorf("Expected ErrSendTooFrequent on voice after sms, got %v", err)
+	}
+
+	// 日上限按用途与主体累计，轮换渠道也不能超过
+	for i := 1; i < 6; i++ {
+		o.repo.Advance(time.Minute + time.Second)
+		channel := otp.ChannelSMS
+		if i%2 == 1 {
+			channel = otp.ChannelVoice
+		}
+		if err := send(channel); err != nil {
+			t.Fatalf("Send #%d via %s error = %v", i+1, channel, err)
+		}
+	}
+	o.repo.Advance(time.Minute + time.Second)
+	if err := send(otp.ChannelVoice); !errors.Is(err, otp.ErrDailyLimitExceeded) {
+		t.Errorf("Expected ErrDailyLimitExceeded after 6 sends across channels, got %v", err)
+	}
+}
+
+func TestManager_Send_EnqueueFailed_DeletesCode(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+	o.queue.SetEnqueueError(errors.New("redis down"))
+
+	_, _ = o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+
+	if _, err := o.repo.GetCode(ctx, otp.TypeLogin, "13800138000"); !errors.Is(err, otp.ErrCodeInvalid) {
+		t.Errorf("Expected code deleted after enqueue failure, got %v", err)
+	}
+}
+
+func TestManager_DispatchStatus_NotFound(t *testing.T) {
+	o := newTestOTP()
+	if _, err := o.manager.DispatchStatus(context.Background(), "unknown"); !errors.Is(err, otp.ErrDispatchNotFound) {
+		t.Errorf("Expected ErrDispatchNotFound, got %v", err)
+	}
+}
+
+func TestManager_Verify_TooMany(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+
+	_, _ = o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13800138000"})
+	_ = o.dispatcher.RunOnce(ctx)
+	code := o.voice.LastCode()
+
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	for i := 0; i < 5; i++ {
+		if err := o.manager.Verify(ctx, otp.TypeLogin, "13800138000", wrong); !errors.Is(err, otp.ErrCodeInvalid) {
+			t.Fatalf("attempt %d: expected ErrCodeInvalid, got %v", i+1, err)
+		}
+	}
+
+	// 失败次数达到上限后，正确验证码也被拒绝
+	if err := o.manager.Verify(ctx, otp.TypeLogin, "13800138000", code); !errors.Is(err, otp.ErrVerifyTooMany) {
+		t.Errorf("Expected ErrVerifyTooMany, got %v", err)
+	}
+}
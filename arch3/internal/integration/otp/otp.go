@@ -0,0 +1,101 @@
+// Package otp 提供与渠道无关的一次性验证码签发与校验
+//
+// Manager 负责验证码生成、存储、发送限流和验证失败计数，
+// 具体投递由各渠道适配器（短信、邮件、语音）实现 Channel 接口完成。
+package otp
+
+import (
+	"context"
+	"time"
+
+	userservice "arch3/internal/service/user"
+)
+
+// Type 验证码用途别名，指向 service 层定义
+type Type = userservice.SMSType
+
+const (
+	TypeRegister = userservice.SMSTypeRegister // 注册
+	TypeLogin    = userservice.SMSTypeLogin    // 登录
+	TypeForget   = userservice.SMSTypeForget   // 忘记密码
+
+	TypeChangePhoneOld = userservice.SMSTypeChangePhoneOld // 更换手机号: 验证原手机号
+	TypeChangePhoneNew = userservice.SMSTypeChangePhoneNew // 更换手机号: 验证新手机号
+
+	TypeDeleteAccount = userservice.SMSTypeDeleteAccount // 注销账号
+)
+
+// Types 全部验证码用途，按主体清理时遍历
+var Types = []Type{TypeRegister, TypeLogin, TypeForget, TypeChangePhoneOld, TypeChangePhoneNew, TypeDeleteAccount}
+
+// ChannelName 渠道名称别名，指向 service 层定义
+type ChannelName = userservice.OTPChannel
+
+const (
+	ChannelSMS   = userservice.OTPChannelSMS   // 短信
+	ChannelEmail = userservice.OTPChannelEmail // 邮件
+	ChannelVoice = userservice.OTPChannelVoice // 语音电话
+)
+
+// Channels 全部渠道，按主体清理时遍历
+var Channels = []ChannelName{ChannelSMS, ChannelEmail, ChannelVoice}
+
+// Message 待投递的验证码消息
+type Message struct {
+	Type    Type          // 验证码用途
+	Address string        // 投递地址（手机号/邮箱）
+	Code    string        // 验证码明文
+	TTL     time.Duration // 有效期，用于渠道文案
+
+	// IdempotencyKey 投递幂等键（同一投递任务重试时保持不变）
+	// 支持幂等键的服务商应透传，避免重试导致重复下发；
+	// 不支持的服务商（如火山引擎短信）可透传到备注类字段用于关联，但不能据此去重
+	IdempotencyKey string
+}
+
+// Channel 验证码投递渠道适配器
+type Channel interface {
+	// Name 渠道名称
+	Name() ChannelName
+	// Validate 发送前校验渠道是否支持该用途（如模板是否配置）
+	// 在存储验证码之前调用，避免存储后才发现无法投递
+	Validate(smsType Type) error
+	// Deliver 投递验证码
+	Deliver(ctx context.Context, msg *Message) error
+}
+
+// CodeRepository 验证码存储接口
+//
+// 验证码、发送次数与验证失败计数均按 (用途, 主体) 维度存储，与渠道无关，
+// 切换渠道不会增加可发送次数。
+// 存储层只接触验证码摘要（见 CodeHasher），不保存明文。
+type CodeRepository interface {
+	// StoreCode 存储验证码摘要
+	StoreCode(ctx context.Context, smsType Type, target, digest string, ttl time.Duration) error
+	// GetCode 获取验证码摘要，不存在时返回 ErrCodeInvalid
+	GetCode(ctx context.Context, smsType Type, target string) (string, error)
+	// DeleteCode 删除验证码
+	DeleteCode(ctx context.Context, smsType Type, target string) error
+	// GetSendCount 获取发送次数 (minute, day)
+	GetSendCount(ctx context.Context, smsType Type, target string) (minuteCount, dayCount int, err error)
+	// IncrSendCount 增加发送次数
+	IncrSendCount(ctx context.Context, smsType Type, target string) error
+	// GetVerifyFailCount 获取验证失败次数
+	GetVerifyFailCount(ctx context.Context, smsType Type, target string) (int, error)
+	// IncrVerifyFailCount 增加验证失败次数
+	IncrVerifyFailCount(ctx context.Context, smsType Type, target string) error
+	// ResetVerifyFailCount 重置验证失败次数
+	ResetVerifyFailCount(ctx context.Context, smsType Type, target string) error
+	// PurgeTarget 删除主体在所有用途下的验证码与计数
+	PurgeTarget(ctx context.Context, target string) error
+}
+
+// 验证码错误别名，指向 service 层定义
+var (
+	ErrSendTooFrequent    = userservice.ErrSMSTooFrequent
+	ErrDailyLimitExceeded = userservice.ErrSMSDailyLimit
+	ErrSendFailed         = userservice.ErrSMSSendFailed
+	ErrChannelUnavailable = userservice.ErrOTPChannelUnavailable
+	ErrCodeInvalid        = userservice.ErrSMSCodeInvalid
+	ErrVerifyTooMany      = userservice.ErrSMSVerifyTooMany
+)
@@ -0,0 +1,99 @@
+package otptest
+
+import (
+	"context"
+	"sync"
+
+	"arch3/internal/integration/otp"
+)
+
+// Channel 内存投递渠道，实现 otp.Channel
+// 不真实发送，仅记录投递的消息；与真实服务商一致，相同幂等键只投递一次
+type Channel struct {
+	name otp.ChannelName
+
+	mu          sync.Mutex
+	messages    []otp.Message
+	seen        map[string]bool // 已处理的幂等键
+	unsupported map[otp.Type]bool
+	err         error
+}
+
+// NewChannel 创建名为 name 的内存渠道，默认支持所有用途
+func NewChannel(name otp.ChannelName) *Channel {
+	return &Channel{
+		name:        name,
+		seen:        make(map[string]bool),
+		unsupported: make(map[otp.Type]bool),
+	}
+}
+
+var _ otp.Channel = (*Channel)(nil)
+
+// Name 渠道名称
+func (c *Channel) Name() otp.ChannelName {
+	return c.name
+}
+
+// Unsupport 标记不支持的用途（模拟模板未配置），Validate 与 Deliver 对其返回 otp.ErrChannelUnavailable
+func (c *Channel) Unsupport(types ...otp.Type) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, t := range types {
+		c.unsupported[t] = true
+	}
+}
+
+// SetError 设置投递错误，nil 恢复正常（模拟服务商故障）
+func (c *Channel) SetError(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// Validate 校验渠道是否支持该用途
+func (c *Channel) Validate(smsType otp.Type) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsupported[smsType] {
+		return otp.ErrChannelUnavailable
+	}
+	return nil
+}
+
+// Deliver 记录投递的消息
+func (c *Channel) Deliver(_ context.Context, msg *otp.Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unsupported[msg.Type] {
+		return otp.ErrChannelUnavailable
+	}
+	if c.err != nil {
+		return c.err
+	}
+	if msg.IdempotencyKey != "" {
+		if c.seen[msg.IdempotencyKey] {
+			return nil
+		}
+		c.seen[msg.IdempotencyKey] = true
+	}
+	c.messages = append(c.messages, *msg)
+	return nil
+}
+
+// Messages 已投递的消息
+func (c *Channel) Messages() []otp.Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]otp.Message(nil), c.messages...)
+}
+
+// LastCode 最近一次投递的验证码，没有投递时为空
+func (c *Channel) LastCode() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.messages) == 0 {
+		return ""
+	}
+	return c.messages[len(c.messages)-1].Code
+}
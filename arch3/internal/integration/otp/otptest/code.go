@@ -0,0 +1,174 @@
+// Package otptest 验证码模块接口的内存实现与契约测试，供测试使用
+//
+// 内存实现与 Redis 实现遵循同一套契约（见 TestCodeRepository、TestDispatchQueue、TestChannel），
+// 新增存储或渠道实现时应运行对应的契约测试，证明其行为一致。
+package otptest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"arch3/internal/integration/otp"
+)
+
+// 计数过期时间，与 Redis 实现一致
+const (
+	minuteWindow  = time.Minute
+	dayWindow     = 24 * time.Hour
+	verifyFailTTL = time.Hour
+)
+
+// item 带过期时间的值，expiresAt 为零表示不过期
+type item struct {
+	code      string
+	count     int
+	expiresAt time.Time
+}
+
+type codeKey struct {
+	kind    string
+	smsType otp.Type
+	target  string
+}
+
+// CodeRepository 内存验证码存储，实现 otp.CodeRepository
+// 过期按内部时钟判断，测试中用 Advance 模拟时间流逝
+type CodeRepository struct {
+	mu     sync.Mutex
+	offset time.Duration
+	items  map[codeKey]*item
+}
+
+// NewCodeRepository 创建内存验证码存储
+func NewCodeRepository() *CodeRepository {
+	return &CodeRepository{items: make(map[codeKey]*item)}
+}
+
+var _ otp.CodeRepository = (*CodeRepository)(nil)
+
+// Advance 把内部时钟向后拨 d，之后到期的验证码与计数不再可见
+func (r *CodeRepository) Advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.offset += d
+}
+
+func (r *CodeRepository) now() time.Time {
+	return time.Now().Add(r.offset)
+}
+
+// get 查询未过期的值，调用方须持有锁
+func (r *CodeRepository) get(k codeKey) (*item, bool) {
+	it, ok := r.items[k]
+	if !ok {
+		return nil, false
+	}
+	if !it.expiresAt.IsZero() && !r.now().Before(it.expiresAt) {
+		delete(r.items, k)
+		return nil, false
+	}
+	return it, true
+}
+
+// incr 计数加一并刷新过期时间，与 Redis 的 INCR + EXPIRE 一致，调用方须持有锁
+func (r *CodeRepository) incr(k codeKey, ttl time.Duration) {
+	it, ok := r.get(k)
+	if !ok {
+		it = &item{}
+		r.items[k] = it
+	}
+	it.count++
+	it.expiresAt = r.now().Add(ttl)
+}
+
+func (r *CodeRepository) count(k codeKey) int {
+	if it, ok := r.get(k); ok {
+		return it.count
+	}
+	return 0
+}
+
+// StoreCode 存储验证码摘要
+func (r *CodeRepository) StoreCode(_ context.Context, smsType otp.Type, target, digest string, ttl time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	it := &item{code: digest}
+	if ttl > 0 {
+		it.expiresAt = r.now().Add(ttl)
+	}
+	r.items[codeKey{kind: "code", smsType: smsType, target: target}] = it
+	return nil
+}
+
+// GetCode 获取验证码摘要，不存在或已过期时返回 otp.ErrCodeInvalid
+func (r *CodeRepository) GetCode(_ context.Context, smsType otp.Type, target string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	it, ok := r.get(codeKey{kind: "code", smsType: smsType, target: target})
+	if !ok {
+		return "", otp.ErrCodeInvalid
+	}
+	return it.code, nil
+}
+
+// DeleteCode 删除验证码
+func (r *CodeRepository) DeleteCode(_ context.Context, smsType otp.Type, target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, codeKey{kind: "code", smsType: smsType, target: target})
+	return nil
+}
+
+// GetSendCount 获取发送次数
+func (r *CodeRepository) GetSendCount(_ context.Context, smsType otp.Type, target string) (minuteCount, dayCount int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	minuteCount = r.count(codeKey{kind: "minute", smsType: smsType, target: target})
+	dayCount = r.count(codeKey{kind: "day", smsType: smsType, target: target})
+	return minuteCount, dayCount, nil
+}
+
+// IncrSendCount 增加发送次数
+func (r *CodeRepository) IncrSendCount(_ context.Context, smsType otp.Type, target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.incr(codeKey{kind: "minute", smsType: smsType, target: target}, minuteWindow)
+	r.incr(codeKey{kind: "day", smsType: smsType, target: target}, dayWindow)
+	return nil
+}
+
+// GetVerifyFailCount 获取验证失败次数
+func (r *CodeRepository) GetVerifyFailCount(_ context.Context, smsType otp.Type, target string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.count(codeKey{kind: "verify_fail", smsType: smsType, target: target}), nil
+}
+
+// IncrVerifyFailCount 增加验证失败次数
+func (r *CodeRepository) IncrVerifyFailCount(_ context.Context, smsType otp.Type, target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.incr(codeKey{kind: "verify_fail", smsType: smsType, target: target}, verifyFailTTL)
+	return nil
+}
+
+// ResetVerifyFailCount 重置验证失败次数
+func (r *CodeRepository) ResetVerifyFailCount(_ context.Context, smsType otp.Type, target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.items, codeKey{kind: "verify_fail", smsType: smsType, target: target})
+	return nil
+}
+
+// PurgeTarget 删除主体在所有用途下的验证码与计数
+func (r *CodeRepository) PurgeTarget(_ context.Context, target string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k := range r.items {
+		if k.target == target {
+			delete(r.items, k)
+		}
+	}
+	return nil
+}
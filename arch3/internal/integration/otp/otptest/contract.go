@@ -0,0 +1,343 @@
+package otptest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+)
+
+// CodeRepositoryFactory 创建待测的验证码存储
+// advance 把存储的时钟向后拨 d（Redis 实现对应 miniredis.FastForward），用于验证过期
+type CodeRepositoryFactory func(t *testing.T) (repo otp.CodeRepository, advance func(d time.Duration))
+
+// TestCodeRepository 验证码存储契约测试
+func TestCodeRepository(t *testing.T, newRepo CodeRepositoryFactory) {
+	ctx := context.Background()
+	const target = "13800138000"
+
+	t.Run("验证码存取与过期", func(t *testing.T) {
+		repo, advance := newRepo(t)
+		if _, err := repo.GetCode(ctx, otp.TypeLogin, target); !errors.Is(err, otp.ErrCodeInvalid) {
+			t.Fatalf("Expected ErrCodeInvalid for missing code, got %v", err)
+		}
+		if err := repo.StoreCode(ctx, otp.TypeLogin, target, "digest-1", 5*time.Minute); err != nil {
+			t.Fatalf("StoreCode() error = %v", err)
+		}
+		// 按用途隔离
+		if _, err := repo.GetCode(ctx, otp.TypeRegister, target); !errors.Is(err, otp.ErrCodeInvalid) {
+			t.Errorf("Expected code isolated by type, got %v", err)
+		}
+		// 重新存储覆盖旧摘要
+		_ = repo.StoreCode(ctx, otp.TypeLogin, target, "digest-2", 5*time.Minute)
+		if got, err := repo.GetCode(ctx, otp.TypeLogin, target); err != nil || got != "digest-2" {
+			t.Errorf("Expected digest-2, got %q, %v", got, err)
+		}
+
+		advance(5*time.Minute + time.Second)
+		if _, err := repo.GetCode(ctx, otp.TypeLogin, target); !errors.Is(err, otp.ErrCodeInvalid) {
+			t.Errorf("Expected ErrCodeInvalid after ttl, got %v", err)
+		}
+	})
+
+	t.Run("删除验证码", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		_ = repo.StoreCode(ctx, otp.TypeLogin, target, "digest", time.Minute)
+		if err := repo.DeleteCode(ctx, otp.TypeLogin, target); err != nil {
+			t.Fatalf("DeleteCode() error = %v", err)
+		}
+		if _, err := repo.GetCode(ctx, otp.TypeLogin, target); !errors.Is(err, otp.ErrCodeInvalid) {
+			t.Errorf("Expected ErrCodeInvalid after delete, got %v", err)
+		}
+		// 删除不存在的验证码不报错
+		if err := repo.DeleteCode(ctx, otp.TypeLogin, target); err != nil {
+			t.Errorf("DeleteCode() on missing code error = %v", err)
+		}
+	})
+
+	t.Run("发送次数按分钟与天统计", func(t *testing.T) {
+		repo, advance := newRepo(t)
+		for range 2 {
+			if err := repo.IncrSendCount(ctx, otp.TypeLogin, target); err != nil {
+				t.Fatalf("IncrSendCount() error = %v", err)
+			}
+		}
+		if minute, day, err := repo.GetSendCount(ctx, otp.TypeLogin, target); err != nil || minute != 2 || day != 2 {
+			t.Fatalf("Expected (2, 2), got (%d, %d), %v", minute, day, err)
+		}
+		// 各用途独立计数，各渠道共享计数（接口不区分渠道）
+		if minute, day, _ := repo.GetSendCount(ctx, otp.TypeRegister, target); minute != 0 || day != 0 {
+			t.Errorf("Expected register count isolated, got (%d, %d)", minute, day)
+		}
+
+		advance(time.Minute + time.Second)
+		if minute, day, _ := repo.GetSendCount(ctx, otp.TypeLogin, target); minute != 0 || day != 2 {
+			t.Errorf("Expected (0, 2) after a minute, got (%d, %d)", minute, day)
+		}
+		advance(24 * time.Hour)
+		if minute, day, _ := repo.GetSendCount(ctx, otp.TypeLogin, target); minute != 0 || day != 0 {
+			t.Errorf("Expected (0, 0) after a day, got (%d, %d)", minute, day)
+		}
+	})
+
+	t.Run("验证失败计数", func(t *testing.T) {
+		repo, advance := newRepo(t)
+		for range 3 {
+			if err := repo.IncrVerifyFailCount(ctx, otp.TypeLogin, target); err != nil {
+				t.Fatalf("IncrVerifyFailCount() error = %v", err)
+			}
+		}
+		if n, err := repo.GetVerifyFailCount(ctx, otp.TypeLogin, target); err != nil || n != 3 {
+			t.Fatalf("Expected 3 failures, got %d, %v", n, err)
+		}
+		if err := repo.ResetVerifyFailCount(ctx, otp.TypeLogin, target); err != nil {
+			t.Fatalf("ResetVerifyFailCount() error = %v", err)
+		}
+		if n, _ := repo.GetVerifyFailCount(ctx, otp.TypeLogin, target); n != 0 {
+			t.Errorf("Expected 0 failures after reset, got %d", n)
+		}
+
+		// 一小时后过期
+		_ = repo.IncrVerifyFailCount(ctx, otp.TypeLogin, target)
+		advance(time.Hour + time.Second)
+		if n, _ := repo.GetVerifyFailCount(ctx, otp.TypeLogin, target); n != 0 {
+			t.Errorf("Expected failures expired after an hour, got %d", n)
+		}
+	})
+
+	t.Run("按主体清理", func(t *testing.T) {
+		repo, _ := newRepo(t)
+		const other = "13900139000"
+		for _, tgt := range []string{target, other} {
+			for _, typ := range otp.Types {
+				_ = repo.StoreCode(ctx, typ, tgt, "digest", time.Minute)
+				_ = repo.IncrVerifyFailCount(ctx, typ, tgt)
+				_ = repo.IncrSendCount(ctx, typ, tgt)
+			}
+		}
+
+		if err := repo.PurgeTarget(ctx, target); err != nil {
+			t.Fatalf("PurgeTarget() error = %v", err)
+		}
+		for _, typ := range otp.Types {
+			if _, err := repo.GetCode(ctx, typ, target); !errors.Is(err, otp.ErrCodeInvalid) {
+				t.Errorf("Expected %s code purged, got %v", typ, err)
+			}
+			if n, _ := repo.GetVerifyFailCount(ctx, typ, target); n != 0 {
+				t.Errorf("Expected %s failures purged, got %d", typ, n)
+			}
+			if minute, day, _ := repo.GetSendCount(ctx, typ, target); minute != 0 || day != 0 {
+				t.Errorf("Expected %s counts purged, got (%d, %d)", typ, minute, day)
+			}
+		}
+		// 其他主体不受影响
+		if got, err := repo.GetCode(ctx, otp.TypeLogin, other); err != nil || got != "digest" {
+			t.Errorf("Expected other target kept, got %q, %v", got, err)
+		}
+		if minute, day, _ := repo.GetSendCount(ctx, otp.TypeLogin, other); minute != 1 || day != 1 {
+			t.Errorf("Expected other target counts kept, got (%d, %d)", minute, day)
+		}
+	})
+}
+
+// DispatchQueueFactory 创建空的待测投递队列
+type DispatchQueueFactory func(t *testing.T) otp.DispatchQueue
+
+// TestDispatchQueue 投递队列契约测试
+func TestDispatchQueue(t *testing.T, newQueue DispatchQueueFactory) {
+	ctx := context.Background()
+	newJob := func(id string) *otp.DispatchJob {
+		return &otp.DispatchJob{
+			ID:        id,
+			Channel:   otp.ChannelSMS,
+			Type:      otp.TypeLogin,
+			Target:    "13800138000",
+			Address:   "13800138000",
+			ExpiresAt: time.Now().Add(5 * time.Minute),
+			Status:    otp.DispatchPending,
+			CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		}
+	}
+	claimIDs := func(t *testing.T, q otp.DispatchQueue, limit int, lease time.Duration) []string {
+		t.Helper()
+		jobs, err := q.Claim(ctx, limit, lease)
+		if err != nil {
+			t.Fatalf("Claim() error = %v", err)
+		}
+		ids := make([]string, 0, len(jobs))
+		for _, j := range jobs {
+			ids = append(ids, j.ID)
+		}
+		return ids
+	}
+
+	t.Run("领取与租约", func(t *testing.T) {
+		q := newQueue(t)
+		for _, id := range []string{"j1", "j2", "j3"} {
+			if err := q.Enqueue(ctx, newJob(id)); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+		}
+		// 非 pending 任务只保存记录，不进入队列
+		sent := newJob("j4")
+		sent.Status = otp.DispatchSent
+		_ = q.Enqueue(ctx, sent)
+
+		if ids := claimIDs(t, q, 2, time.Minute); len(ids) != 2 {
+			t.Fatalf("Expected 2 jobs within limit, got %v", ids)
+		}
+		if ids := claimIDs(t, q, 10, time.Minute); len(ids) != 1 {
+			t.Fatalf("Expected 1 remaining job, got %v", ids)
+		}
+		if ids := claimIDs(t, q, 10, time.Minute); len(ids) != 0 {
+			t.Errorf("Expected leased jobs invisible, got %v", ids)
+		}
+		if job, err := q.Get(ctx, "j4"); err != nil || job.Status != otp.DispatchSent {
+			t.Errorf("Expected non-pending job saved, got %+v, %v", job, err)
+		}
+	})
+
+	t.Run("租约到期后重新领取", func(t *testing.T) {
+		q := newQueue(t)
+		_ = q.Enqueue(ctx, newJob("j1"))
+		if ids := claimIDs(t, q, 10, 0); len(ids) != 1 {
+			t.Fatalf("Expected 1 job, got %v", ids)
+		}
+		if ids := claimIDs(t, q, 10, time.Minute); len(ids) != 1 || ids[0] != "j1" {
+			t.Errorf("Expected j1 reclaimed after lease, got %v", ids)
+		}
+	})
+
+	t.Run("重试与终态", func(t *testing.T) {
+		q := newQueue(t)
+		for _, id := range []string{"j1", "j2", "j3"} {
+			_ = q.Enqueue(ctx, newJob(id))
+		}
+		jobs, _ := q.Claim(ctx, 10, time.Minute)
+		if len(jobs) != 3 {
+			t.Fatalf("Expected 3 jobs, got %d", len(jobs))
+		}
+		byID := make(map[string]*otp.DispatchJob, len(jobs))
+		for _, j := range jobs {
+			byID[j.ID] = j
+		}
+
+		if err := q.MarkSent(ctx, byID["j1"]); err != nil {
+			t.Fatalf("MarkSent() error = %v", err)
+		}
+		byID["j2"].Attempts, byID["j2"].LastError = 1, "timeout"
+		if err := q.Retry(ctx, byID["j2"], time.Now().Add(-time.Second)); err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+		byID["j3"].Attempts = 5
+		if err := q.MarkFailed(ctx, byID["j3"]); err != nil {
+			t.Fatalf("MarkFailed() error = %v", err)
+		}
+
+		// 只有到期的重试任务可领取，且带上失败信息
+		jobs, _ = q.Claim(ctx, 10, time.Minute)
+		if len(jobs) != 1 || jobs[0].ID != "j2" || jobs[0].Attempts != 1 || jobs[0].LastError != "timeout" {
+			t.Fatalf("Expected retried j2 with attempts, got %+v", jobs)
+		}
+		if err := q.Retry(ctx, jobs[0], time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+		if ids := claimIDs(t, q, 10, time.Minute); len(ids) != 0 {
+			t.Errorf("Expected nothing due before retry time, got %v", ids)
+		}
+
+		for id, want := range map[string]otp.DispatchJob{
+			"j1": {Status: otp.DispatchSent},
+			"j2": {Status: otp.DispatchPending, Attempts: 1},
+			"j3": {Status: otp.DispatchFailed, Attempts: 5},
+		} {
+			job, err := q.Get(ctx, id)
+			if err != nil || job.Status != want.Status || job.Attempts != want.Attempts {
+				t.Errorf("Expected %s %s with %d attempts, got %+v, %v", id, want.Status, want.Attempts, job, err)
+			}
+		}
+	})
+
+	t.Run("查询不存在的任务", func(t *testing.T) {
+		q := newQueue(t)
+		if _, err := q.Get(ctx, "unknown"); !errors.Is(err, otp.ErrDispatchNotFound) {
+			t.Errorf("Expected ErrDispatchNotFound, got %v", err)
+		}
+	})
+}
+
+// ChannelHarness 渠道契约测试的被测对象与观测手段
+type ChannelHarness struct {
+	Channel otp.Channel
+	// Address 渠道可投递的地址（手机号或邮箱）
+	Address string
+	// Delivered 返回已送达 Address 的内容（如短信正文、邮件原文），按投递顺序
+	Delivered func() []string
+	// Fail 让之后的投递失败（模拟服务商故障）
+	Fail func()
+}
+
+// TestChannel 投递渠道契约测试
+func TestChannel(t *testing.T, newHarness func(t *testing.T) *ChannelHarness) {
+	ctx := context.Background()
+
+	t.Run("渠道名称", func(t *testing.T) {
+		h := newHarness(t)
+		name := h.Channel.Name()
+		found := false
+		for _, ch := range otp.Channels {
+			found = found || ch == name
+		}
+		if !found {
+			t.Errorf("Expected name in %v, got %q", otp.Channels, name)
+		}
+	})
+
+	t.Run("投递验证码", func(t *testing.T) {
+		h := newHarness(t)
+		for i, typ := range otp.Types {
+			if err := h.Channel.Validate(typ); err != nil {
+				continue
+			}
+			code := fmt.Sprintf("%06d", 100000+i)
+			err := h.Channel.Deliver(ctx, &otp.Message{
+				Type:           typ,
+				Address:        h.Address,
+				Code:           code,
+				TTL:            5 * time.Minute,
+				IdempotencyKey: fmt.Sprintf("dispatch-%d", i),
+			})
+			if err != nil {
+				t.Fatalf("Deliver(%s) error = %v", typ, err)
+			}
+			delivered := h.Delivered()
+			if len(delivered) == 0 || !strings.Contains(delivered[len(delivered)-1], code) {
+				t.Errorf("Expected %s code %s delivered to %s, got %q", typ, code, h.Address, delivered)
+			}
+		}
+		if len(h.Delivered()) == 0 {
+			t.Error("Expected channel to support at least one type")
+		}
+	})
+
+	t.Run("投递失败返回错误", func(t *testing.T) {
+		h := newHarness(t)
+		h.Fail()
+		err := h.Channel.Deliver(ctx, &otp.Message{
+			Type:    otp.TypeLogin,
+			Address: h.Address,
+			Code:    "123456",
+			TTL:     5 * time.Minute,
+		})
+		if err == nil {
+			t.Error("Expected error but got nil")
+		}
+		if delivered := h.Delivered(); len(delivered) != 0 {
+			t.Errorf("Expected nothing delivered, got %q", delivered)
+		}
+	})
+}
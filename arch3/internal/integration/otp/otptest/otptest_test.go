@@ -0,0 +1,41 @@
+package otptest_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+	"arch3/internal/integration/otp/otptest"
+)
+
+func TestCodeRepository(t *testing.T) {
+	otptest.TestCodeRepository(t, func(t *testing.T) (otp.CodeRepository, func(time.Duration)) {
+		repo := otptest.NewCodeRepository()
+		return repo, repo.Advance
+	})
+}
+
+func TestDispatchQueue(t *testing.T) {
+	otptest.TestDispatchQueue(t, func(t *testing.T) otp.DispatchQueue {
+		return otptest.NewDispatchQueue()
+	})
+}
+
+func TestChannel(t *testing.T) {
+	otptest.TestChannel(t, func(t *testing.T) *otptest.ChannelHarness {
+		ch := otptest.NewChannel(otp.ChannelSMS)
+		return &otptest.ChannelHarness{
+			Channel: ch,
+			Address: "13800138000",
+			Delivered: func() []string {
+				var codes []string
+				for _, m := range ch.Messages() {
+					codes = append(codes, m.Code)
+				}
+				return codes
+			},
+			Fail: func() { ch.SetError(errors.New("provider down")) },
+		}
+	})
+}
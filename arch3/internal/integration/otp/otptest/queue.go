@@ -0,0 +1,129 @@
+package otptest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"arch3/internal/integration/otp"
+	userservice "arch3/internal/service/user"
+)
+
+// DispatchQueue 内存投递队列，实现 otp.DispatchQueue
+type DispatchQueue struct {
+	mu         sync.Mutex
+	jobs       map[string]otp.DispatchJob
+	due        map[string]time.Time // 仍在队列中的任务及其可领取时间
+	enqueueErr error
+}
+
+// NewDispatchQueue 创建内存投递队列
+func NewDispatchQueue() *DispatchQueue {
+	return &DispatchQueue{
+		jobs: make(map[string]otp.DispatchJob),
+		due:  make(map[string]time.Time),
+	}
+}
+
+var _ otp.DispatchQueue = (*DispatchQueue)(nil)
+
+// SetEnqueueError 设置入队错误，nil 恢复正常（模拟存储故障）
+func (q *DispatchQueue) SetEnqueueError(err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.enqueueErr = err
+}
+
+// MakeDue 让仍在队列中的任务立即可领取（跳过退避与租约等待）
+func (q *DispatchQueue) MakeDue(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.due[id]; ok {
+		q.due[id] = time.Now()
+	}
+}
+
+// Enqueue 写入任务，仅 pending 任务进入队列
+func (q *DispatchQueue) Enqueue(_ context.Context, job *otp.DispatchJob) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.enqueueErr != nil {
+		return q.enqueueErr
+	}
+	q.jobs[job.ID] = *job
+	if job.Status == otp.DispatchPending {
+		q.due[job.ID] = time.Now()
+	}
+	return nil
+}
+
+// Claim 按可领取时间顺序领取到期任务
+func (q *DispatchQueue) Claim(_ context.Context, limit int, lease time.Duration) ([]*otp.DispatchJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	ids := make([]string, 0, len(q.due))
+	for id, due := range q.due {
+		if !due.After(now) {
+			ids = append(ids, id)
+		}
+	}
+	slices.SortFunc(ids, func(a, b string) int {
+		if c := q.due[a].Compare(q.due[b]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	jobs := make([]*otp.DispatchJob, 0, len(ids))
+	for _, id := range ids {
+		q.due[id] = now.Add(lease)
+		job := q.jobs[id]
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Get 查询任务，不存在时返回 otp.ErrDispatchNotFound
+func (q *DispatchQueue) Get(_ context.Context, id string) (*otp.DispatchJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, otp.ErrDispatchNotFound
+	}
+	return &job, nil
+}
+
+// MarkSent 标记投递成功并移出队列
+func (q *DispatchQueue) MarkSent(_ context.Context, job *otp.DispatchJob) error {
+	return q.finish(job, otp.DispatchSent)
+}
+
+// Retry 保存失败信息并在 next 时刻重新可领取
+func (q *DispatchQueue) Retry(_ context.Context, job *otp.DispatchJob, next time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs[job.ID] = *job
+	q.due[job.ID] = next
+	return nil
+}
+
+// MarkFailed 标记最终失败并移出队列
+func (q *DispatchQueue) MarkFailed(_ context.Context, job *otp.DispatchJob) error {
+	return q.finish(job, otp.DispatchFailed)
+}
+
+func (q *DispatchQueue) finish(job *otp.DispatchJob, status userservice.OTPDispatchStatus) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job.Status = status
+	q.jobs[job.ID] = *job
+	delete(q.due, job.ID)
+	return nil
+}
@@ -0,0 +1,38 @@
+package otp
+
+import (
+	"net/netip"
+	"time"
+)
+
+// TestPhone 测试号码（应用商店审核、自动化 e2e 测试）
+//
+// 命中的号码使用固定验证码，不调用真实服务商，不受发送频率限制；
+// 验证失败次数限制仍然生效。每次使用都会记录 Warn 日志。
+type TestPhone struct {
+	Phone      string         // 手机号
+	Code       string         // 固定验证码
+	ExpiresAt  time.Time      // 失效时间，零值表示不过期
+	AllowedIPs []netip.Prefix // 允许的客户端 IP 段，为空表示不限制
+}
+
+// usable 判断测试号码在当前时间与客户端 IP 下是否可用
+func (p *TestPhone) usable(clientIP string, now time.Time) bool {
+	if !p.ExpiresAt.IsZero() && now.After(p.ExpiresAt) {
+		return false
+	}
+	if len(p.AllowedIPs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(clientIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.AllowedIPs {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
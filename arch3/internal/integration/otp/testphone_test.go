@@ -0,0 +1,114 @@
+package otp_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+	"arch3/internal/service/common"
+	userservice "arch3/internal/service/user"
+)
+
+func TestManager_TestPhone(t *testing.T) {
+	const reviewer = "13900000000"
+
+	tests := []struct {
+		name      string
+		phone     otp.TestPhone
+		clientIP  string
+		wantFixed bool // 是否使用固定验证码（跳过服务商）
+	}{
+		{
+			name:      "无限制",
+			phone:     otp.TestPhone{Phone: reviewer, Code: "246810"},
+			clientIP:  "203.0.113.9",
+			wantFixed: true,
+		},
+		{
+			name:      "IP 在白名单内",
+			phone:     otp.TestPhone{Phone: reviewer, Code: "246810", AllowedIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+			clientIP:  "10.1.2.3",
+			wantFixed: true,
+		},
+		{
+			name:      "IP 不在白名单内",
+			phone:     otp.TestPhone{Phone: reviewer, Code: "246810", AllowedIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}},
+			clientIP:  "203.0.113.9",
+			wantFixed: false,
+		},
+		{
+			name:      "已过期",
+			phone:     otp.TestPhone{Phone: reviewer, Code: "246810", ExpiresAt: time.Now().Add(-time.Hour)},
+			clientIP:  "203.0.113.9",
+			wantFixed: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := common.WithClientInfo(context.Background(), common.ClientInfo{IP: tt.clientIP})
+			o := newTestOTP()
+			o.manager.SetTestPhones([]otp.TestPhone{tt.phone})
+
+			dispatchID, err := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: reviewer})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			_ = o.dispatcher.RunOnce(ctx)
+
+			calls := len(o.voice.GetRecords())
+			if tt.wantFixed && calls != 0 {
+				t.Errorf("Expected provider skipped, got %d calls", calls)
+			}
+			if !tt.wantFixed && calls != 1 {
+				t.Errorf("Expected normal delivery, got %d calls", calls)
+			}
+			if status, _ := o.manager.DispatchStatus(ctx, dispatchID); status != otp.DispatchSent {
+				t.Errorf("Expected status sent, got %s", status)
+			}
+
+			err = o.manager.Verify(ctx, otp.TypeLogin, reviewer, "246810")
+			if tt.wantFixed && err != nil {
+				t.Errorf("Expected fixed code accepted, got %v", err)
+			}
+			if !tt.wantFixed && !errors.Is(err, otp.ErrCodeInvalid) {
+				t.Errorf("Expected fixed code rejected, got %v", err)
+			}
+		})
+	}
+}
+
+func TestManager_TestPhone_SkipsSendLimit(t *testing.T) {
+	ctx := context.Background()
+	o := newTestOTP()
+	o.manager.SetTestPhones([]otp.TestPhone{{Phone: "13900000000", Code: "246810"}})
+
+	for i := 0; i < 3; i++ {
+		if _, err := o.manager.Send(ctx, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13900000000"}); err != nil {
+			t.Fatalf("send %d: error = %v", i+1, err)
+		}
+	}
+}
+
+func TestManager_TestPhone_FixedCodeRejectedFromOtherIP(t *testing.T) {
+	o := newTestOTP()
+	o.manager.SetTestPhones([]otp.TestPhone{{
+		Phone:      "13900000000",
+		Code:       "246810",
+		AllowedIPs: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}})
+
+	allowed := common.WithClientInfo(context.Background(), common.ClientInfo{IP: "10.1.2.3"})
+	if _, err := o.manager.Send(allowed, &userservice.OTPSendRequest{Channel: otp.ChannelVoice, Type: otp.TypeLogin, Target: "13900000000"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// 白名单外的 IP 不能使用他人签发的固定验证码
+	other := common.WithClientInfo(context.Background(), common.ClientInfo{IP: "203.0.113.9"})
+	if err := o.manager.Verify(other, otp.TypeLogin, "13900000000", "246810"); !errors.Is(err, otp.ErrCodeInvalid) {
+		t.Errorf("Expected ErrCodeInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"arch3/internal/service/common"
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Handler 事件处理函数
+// 返回错误时消息留在待确认列表，空闲 MinIdle 后重新投递；处理必须按事件 ID 幂等
+type Handler func(ctx context.Context, e *common.Event) error
+
+// ConsumerConfig 事件消费配置
+type ConsumerConfig struct {
+	Stream        string        // Stream 名称，与 RedisStreamConfig.Stream 一致
+	Group         string        // 消费者组，同组的多个实例分摊消息
+	Consumer      string        // 本实例的消费者名称，组内唯一
+	BatchSize     int64         // 每轮最多读取的消息数
+	MinIdle       time.Duration // 未确认消息空闲超过该时长后被重新领取，应大于单条消息的处理耗时
+	MaxDeliveries int64         // 最大投递次数，超过后确认丢弃并记录日志
+}
+
+// DefaultConsumerConfig 默认消费配置
+func DefaultConsumerConfig() ConsumerConfig {
+	return ConsumerConfig{
+		BatchSize:     100,
+		MinIdle:       time.Minute,
+		MaxDeliveries: 5,
+	}
+}
+
+// Consumer 基于 Redis Stream 消费者组的事件消费 worker
+//
+// 按事件类型分发给注册的 Handler，未注册的事件类型直接确认；
+// 处理失败或实例崩溃时消息留在待确认列表，由任一实例在空闲 MinIdle 后重新领取，投递语义为至少一次。
+type Consumer struct {
+	rdb      *redis.Client
+	cfg      ConsumerConfig
+	handlers map[string]Handler
+
+	groupReady atomic.Bool
+}
+
+// NewConsumer 创建事件消费 worker
+func NewConsumer(rdb *redis.Client, cfg ConsumerConfig) *Consumer {
+	return &Consumer{rdb: rdb, cfg: cfg, handlers: make(map[string]Handler)}
+}
+
+// Handle 注册事件处理函数，应在调度器启动前调用
+func (c *Consumer) Handle(eventType string, h Handler) {
+	c.handlers[eventType] = h
+}
+
+// RunOnce 重新领取超时未确认的消息并读取一批新消息，供调度器周期调用
+func (c *Consumer) RunOnce(ctx context.Context) error {
+	if err := c.ensureGroup(ctx); err != nil {
+		return err
+	}
+	if err := c.reclaim(ctx); err != nil {
+		return err
+	}
+
+	streams, err := c.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    c.cfg.Group,
+		Consumer: c.cfg.Consumer,
+		Streams:  []string{c.cfg.Stream, ">"},
+		Count:    c.cfg.BatchSize,
+		Block:    -1, // 不阻塞，由调度器控制轮询间隔
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, s := range streams {
+		for _, msg := range s.Messages {
+			c.process(ctx, msg)
+		}
+	}
+	return nil
+}
+
+// ensureGroup 创建消费者组，只消费创建之后写入的消息
+func (c *Consumer) ensureGroup(ctx context.Context) error {
+	if c.groupReady.Load() {
+		return nil
+	}
+	err := c.rdb.XGroupCreateMkStream(ctx, c.cfg.Stream, c.cfg.Group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	c.groupReady.Store(true)
+	return nil
+}
+
+// reclaim 领取空闲超过 MinIdle 的未确认消息，超过最大投递次数的消息确认丢弃
+func (c *Consumer) reclaim(ctx context.Context) error {
+	pending, err := c.rdb.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: c.cfg.Stream,
+		Group:  c.cfg.Group,
+		Idle:   c.cfg.MinIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  c.cfg.BatchSize,
+	}).Result()
+	if err != nil {
+		return err
+	}
+
+	var ids, dropped []string
+	for _, p := range pending {
+		if p.RetryCount >= c.cfg.MaxDeliveries {
+			dropped = append(dropped, p.ID)
+			continue
+		}
+		ids = append(ids, p.ID)
+	}
+	if len(dropped) > 0 {
+		logger.Ctx(ctx).Error("outbox messages dropped after max deliveries",
+			zap.String("group", c.cfg.Group),
+			zap.Strings("message_ids", dropped),
+		)
+		if err := c.rdb.XAck(ctx, c.cfg.Stream, c.cfg.Group, dropped...).Err(); err != nil {
+			return err
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	msgs, err := c.rdb.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   c.cfg.Stream,
+		Group:    c.cfg.Group,
+		Consumer: c.cfg.Consumer,
+		MinIdle:  c.cfg.MinIdle,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		c.process(ctx, msg)
+	}
+	return nil
+}
+
+// process 处理单条消息，成功或无需处理时确认
+func (c *Consumer) process(ctx context.Context, msg redis.XMessage) {
+	e, traceparent, err := decodeMessage(msg)
+	if err != nil {
+		// 格式错误的消息重试也无法处理
+		logger.Ctx(ctx).Error("decode outbox message failed", zap.String("message_id", msg.ID), zap.Error(err))
+		c.ack(ctx, msg.ID)
+		return
+	}
+	h, ok := c.handlers[e.Type]
+	if !ok {
+		c.ack(ctx, msg.ID)
+		return
+	}
+
+	// 恢复写入事件时的 trace context，处理 span 挂在原请求链路下
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+	ctx, span := tracer.Start(ctx, "outbox.Consume", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	span.SetAttributes(
+		tracer.String("event.id", e.ID),
+		tracer.String("event.type", e.Type),
+	)
+
+	if err := h(ctx, e); err != nil {
+		tracer.RecordError(span, err)
+		logger.Ctx(ctx).Warn("handle outbox event failed",
+			zap.String("event_id", e.ID),
+			zap.String("event_type", e.Type),
+			zap.Error(err),
+		)
+		return
+	}
+	c.ack(ctx, msg.ID)
+}
+
+func (c *Consumer) ack(ctx context.Context, id string) {
+	if err := c.rdb.XAck(ctx, c.cfg.Stream, c.cfg.Group, id).Err(); err != nil {
+		// 消息会在空闲 MinIdle 后再次处理，由 Handler 按事件 ID 去重
+		logger.Ctx(ctx).Error("ack outbox message failed", zap.String("message_id", id), zap.Error(err))
+	}
+}
+
+// decodeMessage 把 RedisStreamBroker 写入的消息字段还原为领域事件
+func decodeMessage(msg redis.XMessage) (*common.Event, string, error) {
+	field := func(name string) string {
+		s, _ := msg.Values[name].(string)
+		return s
+	}
+	e := &common.Event{
+		ID:          field("event_id"),
+		Type:        field("event_type"),
+		AggregateID: field("aggregate_id"),
+		Payload:     json.RawMessage(field("payload")),
+	}
+	if e.ID == "" || e.Type == "" {
+		return nil, "", errors.New("missing event_id or event_type")
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, field("occurred_at"))
+	if err != nil {
+		return nil, "", err
+	}
+	e.OccurredAt = occurredAt
+	return e, field("traceparent"), nil
+}
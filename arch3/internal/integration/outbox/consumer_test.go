@@ -0,0 +1,150 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/outbox"
+	"arch3/internal/service/common"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type testConsumer struct {
+	mr       *miniredis.Miniredis
+	rdb      *redis.Client
+	broker   *outbox.RedisStreamBroker
+	consumer *outbox.Consumer
+}
+
+func newTestConsumer(t *testing.T) *testConsumer {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Now())
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	cfg := outbox.DefaultConsumerConfig()
+	cfg.Stream = testStream
+	cfg.Group = "test"
+	cfg.Consumer = "c1"
+	cfg.MaxDeliveries = 2
+	c := &testConsumer{
+		mr:       mr,
+		rdb:      rdb,
+		broker:   outbox.NewRedisStreamBroker(rdb, outbox.RedisStreamConfig{Stream: testStream, MaxLen: 1000, Dedup: time.Hour}),
+		consumer: outbox.NewConsumer(rdb, cfg),
+	}
+	// 首次运行创建消费者组，之后写入的消息才会被消费
+	c.run(t)
+	return c
+}
+
+func (c *testConsumer) publish(t *testing.T, id, eventType string) {
+	t.Helper()
+	err := c.broker.Publish(context.Background(), &outbox.Record{
+		EventID:     id,
+		EventType:   eventType,
+		AggregateID: "u1",
+		Payload:     json.RawMessage(`{"user_id":"u1"}`),
+		OccurredAt:  time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+}
+
+func (c *testConsumer) run(t *testing.T) {
+	t.Helper()
+	if err := c.consumer.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+}
+
+func (c *testConsumer) pending(t *testing.T) int64 {
+	t.Helper()
+	p, err := c.rdb.XPending(context.Background(), testStream, "test").Result()
+	if err != nil {
+		t.Fatalf("XPending() error = %v", err)
+	}
+	return p.Count
+}
+
+func TestConsumer_Dispatch(t *testing.T) {
+	c := newTestConsumer(t)
+	var got []*common.Event
+	c.consumer.Handle("user.registered", func(_ context.Context, e *common.Event) error {
+		got = append(got, e)
+		return nil
+	})
+
+	c.publish(t, "e1", "user.registered")
+	c.publish(t, "e2", "user.banned")
+	c.run(t)
+
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 handled event, got %d", len(got))
+	}
+	if got[0].ID != "e1" || got[0].AggregateID != "u1" || string(got[0].Payload) != `{"user_id":"u1"}` {
+		t.Errorf("Expected event e1 for u1, got %+v", got[0])
+	}
+	// 未注册处理的事件类型同样确认
+	if n := c.pending(t); n != 0 {
+		t.Errorf("Expected no pending messages, got %d", n)
+	}
+}
+
+func TestConsumer_Redelivery(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int // 前几次处理失败
+		wantCalls   int
+		wantPending int64
+	}{
+		{"失败后重新投递", 1, 2, 0},
+		{"超过最大投递次数后丢弃", 2, 2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestConsumer(t)
+			calls := 0
+			c.consumer.Handle("user.registered", func(context.Context, *common.Event) error {
+				calls++
+				if calls <= tt.failures {
+					return errors.New("notifier down")
+				}
+				return nil
+			})
+
+			c.publish(t, "e1", "user.registered")
+			c.run(t)
+			if n := c.pending(t); n != 1 {
+				t.Fatalf("Expected failed message pending, got %d", n)
+			}
+
+			// 空闲未超过 MinIdle 时不重新投递
+			c.run(t)
+			if calls != 1 {
+				t.Fatalf("Expected no redelivery before min idle, got %d calls", calls)
+			}
+
+			now := time.Now()
+			for range 2 {
+				now = now.Add(2 * time.Minute)
+				c.mr.SetTime(now)
+				c.run(t)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("Expected %d calls, got %d", tt.wantCalls, calls)
+			}
+			if n := c.pending(t); n != tt.wantPending {
+				t.Errorf("Expected %d pending, got %d", tt.wantPending, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+// Package outbox 领域事件的事务性 outbox
+//
+// 业务代码通过 Publisher 把事件写入 outbox 表，与业务数据在同一事务中提交，
+// 避免"数据库已提交、事件未发出"或"事件已发出、事务却回滚"；
+// Relay 在后台领取未发布的事件发送到 Broker，失败按指数退避重试，投递语义为至少一次；
+// Consumer 从 Redis Stream 读取事件，在请求链路之外执行耗时的后续处理（如发送通知）。
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"arch3/internal/service/common"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Status 事件发布状态
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // 待发布/重试中
+	StatusPublished Status = "published" // 已发布
+	StatusDead      Status = "dead"      // 超过最大尝试次数，不再发布
+)
+
+// Record outbox 中的事件记录
+type Record struct {
+	EventID     string            // 事件 ID，幂等键
+	EventType   string            // 事件类型
+	AggregateID string            // 事件主体 ID
+	Payload     json.RawMessage   // 事件内容 (JSON)
+	Trace       map[string]string // W3C trace context，用于关联写入事件的请求链路
+	Status      Status
+	Attempts    int
+	LastError   string
+	OccurredAt  time.Time
+	PublishedAt *time.Time
+}
+
+// Stats outbox 积压统计
+type Stats struct {
+	Pending       int64     // 待发布事件数
+	Dead          int64     // 发布失败、等待人工处理的事件数
+	OldestPending time.Time // 最早的待发布事件的发生时间，无待发布事件时为零值
+}
+
+// Store outbox 存储接口
+//
+// Append 在 ctx 的事务中写入；Claim 以租约方式领取事件，
+// 租约到期仍未确认（如 Relay 崩溃）的事件会被重新领取，因此同一事件可能发布多次。
+type Store interface {
+	// Append 写入待发布事件，事件立即可被领取
+	Append(ctx context.Context, records ...*Record) error
+	// Claim 领取最多 limit 个到期事件，领取后在 lease 时长内对其他 Relay 不可见
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*Record, error)
+	// MarkPublished 标记发布成功
+	MarkPublished(ctx context.Context, r *Record) error
+	// Retry 记录失败并在 next 时刻重新可领取
+	Retry(ctx context.Context, r *Record, next time.Time) error
+	// MarkDead 标记最终失败
+	MarkDead(ctx context.Context, r *Record) error
+	// Purge 删除状态为 status 且最后更新早于 before 的事件，每次最多 limit 条，返回删除条数
+	Purge(ctx context.Context, status Status, before time.Time, limit int) (int64, error)
+	// Stats 统计积压情况
+	Stats(ctx context.Context) (Stats, error)
+}
+
+// Broker 消息中间件接口
+//
+// Publish 返回 nil 表示消息已被中间件持久化；实现应携带 EventID 供消费方去重。
+type Broker interface {
+	Publish(ctx context.Context, r *Record) error
+}
+
+// Publisher 基于 outbox 的领域事件发布器
+type Publisher struct {
+	store Store
+}
+
+// NewPublisher 创建事件发布器
+func NewPublisher(store Store) *Publisher {
+	return &Publisher{store: store}
+}
+
+var _ common.EventPublisher = (*Publisher)(nil)
+
+// Publish 把事件写入 outbox
+// ctx 在事务中时随事务提交，否则立即写入（仍保证至少一次发布，但与业务写入不再原子）
+func (p *Publisher) Publish(ctx context.Context, events ...*common.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	trace := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, trace)
+
+	records := make([]*Record, 0, len(events))
+	for _, e := range events {
+		records = append(records, &Record{
+			EventID:     e.ID,
+			EventType:   e.Type,
+			AggregateID: e.AggregateID,
+			Payload:     e.Payload,
+			Trace:       trace,
+			Status:      StatusPending,
+			OccurredAt:  e.OccurredAt,
+		})
+	}
+	return p.store.Append(ctx, records...)
+}
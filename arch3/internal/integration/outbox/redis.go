@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// publishedKeyPrefix 已发布事件 ID 的去重记录，outbox:published:{event_id}
+const publishedKeyPrefix = "outbox:published:"
+
+// xaddScript 去重后写入 Stream
+// 事件已发布过（如标记发布成功前 Relay 崩溃后重新领取）时跳过，返回已有的消息 ID；
+// 先 XADD 再写去重记录，XADD 失败时不会留下记录导致事件被跳过
+var xaddScript = redis.NewScript(`
+local id = redis.call('GET', KEYS[2])
+if id then
+	return id
+end
+id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[2], '*', unpack(ARGV, 3))
+redis.call('SET', KEYS[2], id, 'EX', ARGV[1])
+return id
+`)
+
+// RedisStreamConfig Redis Stream 配置
+type RedisStreamConfig struct {
+	Stream string        // Stream 名称
+	MaxLen int64         // Stream 近似最大长度，超出后裁剪最早的消息
+	Dedup  time.Duration // 已发布事件 ID 的去重时长，应大于租约时长
+}
+
+// RedisStreamBroker 基于 Redis Stream 的消息中间件
+//
+// 所有事件写入同一个 Stream，消息字段:
+//   - event_id: 事件 ID，消费方据此去重
+//   - event_type / aggregate_id: 事件类型与主体 ID，消费方据此过滤
+//   - payload: 事件内容 (JSON)
+//   - occurred_at: 事件发生时间 (RFC 3339)
+//   - traceparent: W3C trace context（写入事件的请求未采样时为空）
+//
+// 消费方使用消费者组（XREADGROUP）读取。发布端在去重时长内对同一事件 ID 只写入一次，
+// 超过去重时长的重复发布仍可能出现，消费方仍须按 event_id 幂等处理。
+type RedisStreamBroker struct {
+	rdb *redis.Client
+	cfg RedisStreamConfig
+}
+
+// NewRedisStreamBroker 创建 Redis Stream 消息中间件
+func NewRedisStreamBroker(rdb *redis.Client, cfg RedisStreamConfig) *RedisStreamBroker {
+	return &RedisStreamBroker{rdb: rdb, cfg: cfg}
+}
+
+var _ Broker = (*RedisStreamBroker)(nil)
+
+// Publish 写入事件
+func (b *RedisStreamBroker) Publish(ctx context.Context, r *Record) error {
+	return xaddScript.Run(ctx, b.rdb,
+		[]string{b.cfg.Stream, publishedKeyPrefix + r.EventID},
+		int64(b.cfg.Dedup.Seconds()), b.cfg.MaxLen,
+		"event_id", r.EventID,
+		"event_type", r.EventType,
+		"aggregate_id", r.AggregateID,
+		"payload", string(r.Payload),
+		"occurred_at", r.OccurredAt.UTC().Format(time.RFC3339Nano),
+		"traceparent", r.Trace["traceparent"],
+	).Err()
+}
@@ -0,0 +1,254 @@
+package outbox
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// maxErrorLen 记录的失败原因最大长度
+const maxErrorLen = 512
+
+// RelayConfig 事件发布 worker 配置
+type RelayConfig struct {
+	BatchSize     int           // 每轮最多领取的事件数
+	Lease         time.Duration // 领取租约时长，应大于一批事件的发布耗时
+	MaxAttempts   int           // 最大发布次数，超过后标记为 dead 等待人工处理
+	BaseBackoff   time.Duration // 首次重试间隔，之后按 2 倍递增
+	MaxBackoff    time.Duration // 重试间隔上限
+	Retention     time.Duration // 已发布事件的保留时长
+	DeadRetention time.Duration // 发布失败事件的保留时长，应留足排查与重放的时间
+}
+
+// DefaultRelayConfig 默认发布配置
+// 15 次尝试的累计退避约 1 小时，可覆盖消息中间件的短时故障
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		BatchSize:     100,
+		Lease:         time.Minute,
+		MaxAttempts:   15,
+		BaseBackoff:   time.Second,
+		MaxBackoff:    10 * time.Minute,
+		Retention:     7 * 24 * time.Hour,
+		DeadRetention: 30 * 24 * time.Hour,
+	}
+}
+
+// purgeBatchSize 清理时每批删除的事件数，避免长事务与大量锁
+const purgeBatchSize = 500
+
+// Relay 事件发布 worker
+//
+// 单个事件失败不影响同批其他事件；多个实例可同时运行，租约保证同一事件同一时刻只被一个实例发布。
+type Relay struct {
+	store  Store
+	broker Broker
+	cfg    RelayConfig
+
+	published atomic.Int64
+	retried   atomic.Int64
+	dead      atomic.Int64
+}
+
+// NewRelay 创建事件发布 worker
+func NewRelay(store Store, broker Broker, cfg RelayConfig) *Relay {
+	return &Relay{store: store, broker: broker, cfg: cfg}
+}
+
+// RunOnce 领取并发布一批到期事件，供调度器周期调用
+func (r *Relay) RunOnce(ctx context.Context) error {
+	records, err := r.store.Claim(ctx, r.cfg.BatchSize, r.cfg.Lease)
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		r.process(ctx, rec)
+	}
+	return nil
+}
+
+// Cleanup 删除超过保留时长的已发布与发布失败事件，供调度器周期调用
+func (r *Relay) Cleanup(ctx context.Context) error {
+	now := time.Now()
+	published, err := r.purge(ctx, StatusPublished, now.Add(-r.cfg.Retention))
+	if err != nil {
+		return err
+	}
+	dead, err := r.purge(ctx, StatusDead, now.Add(-r.cfg.DeadRetention))
+	if err != nil {
+		return err
+	}
+	if published+dead > 0 {
+		logger.Ctx(ctx).Info("outbox events purged",
+			zap.Int64("published", published),
+			zap.Int64("dead", dead),
+		)
+	}
+	return nil
+}
+
+// purge 分批删除，直到没有符合条件的事件
+func (r *Relay) purge(ctx context.Context, status Status, before time.Time) (int64, error) {
+	var total int64
+	for {
+		n, err := r.store.Purge(ctx, status, before, purgeBatchSize)
+		total += n
+		if err != nil || n < purgeBatchSize {
+			return total, err
+		}
+	}
+}
+
+// process 发布单个事件
+func (r *Relay) process(ctx context.Context, rec *Record) {
+	// 恢复写入事件时的 trace context，发布 span 挂在原请求链路下
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(rec.Trace))
+	ctx, span := tracer.Start(ctx, "outbox.Publish", trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	span.SetAttributes(
+		tracer.String("event.id", rec.EventID),
+		tracer.String("event.type", rec.EventType),
+		tracer.Int("outbox.attempt", rec.Attempts+1),
+	)
+
+	rec.Attempts++
+	err := r.broker.Publish(ctx, rec)
+	if err == nil {
+		r.published.Add(1)
+		if err := r.store.MarkPublished(ctx, rec); err != nil {
+			// 事件会在租约到期后再次发布，由消费方按事件 ID 去重
+			tracer.RecordError(span, err)
+			logger.Ctx(ctx).Error("mark outbox event published failed", zap.String("event_id", rec.EventID), zap.Error(err))
+		}
+		return
+	}
+
+	tracer.RecordError(span, err)
+	rec.LastError = truncate(err.Error(), maxErrorLen)
+	if rec.Attempts >= r.cfg.MaxAttempts {
+		r.dead.Add(1)
+		logger.Ctx(ctx).Error("outbox event publish failed permanently",
+			zap.String("event_id", rec.EventID),
+			zap.String("event_type", rec.EventType),
+			zap.Int("attempts", rec.Attempts),
+			zap.Error(err),
+		)
+		if err := r.store.MarkDead(ctx, rec); err != nil {
+			tracer.RecordError(span, err)
+		}
+		return
+	}
+
+	r.retried.Add(1)
+	next := time.Now().Add(r.backoff(rec.Attempts))
+	logger.Ctx(ctx).Warn("outbox event publish failed, will retry",
+		zap.String("event_id", rec.EventID),
+		zap.String("event_type", rec.EventType),
+		zap.Int("attempt", rec.Attempts),
+		zap.Time("next_attempt", next),
+		zap.Error(err),
+	)
+	if err := r.store.Retry(ctx, rec, next); err != nil {
+		tracer.RecordError(span, err)
+		logger.Ctx(ctx).Error("reschedule outbox event failed", zap.String("event_id", rec.EventID), zap.Error(err))
+	}
+}
+
+// backoff 计算第 attempt 次失败后的重试间隔（指数退避 + 抖动）
+func (r *Relay) backoff(attempt int) time.Duration {
+	delay := r.cfg.BaseBackoff << (attempt - 1)
+	if delay <= 0 || delay > r.cfg.MaxBackoff {
+		delay = r.cfg.MaxBackoff
+	}
+	// ±10% 抖动，避免同批失败事件同时重试
+	jitter := time.Duration(rand.Int64N(int64(delay)/5+1)) - delay/10
+	return delay + jitter
+}
+
+// outbox 指标:
+//   - arch3.outbox.pending (gauge) - 待发布事件数
+//   - arch3.outbox.dead (gauge) - 发布失败、等待人工处理的事件数
+//   - arch3.outbox.lag (gauge) - 最早的待发布事件距今的时长（秒），无积压时为 0
+//   - arch3.outbox.events (counter) - 本实例的发布结果计数，result 为 published、retried 或 dead
+
+// RegisterMetrics 注册 outbox 积压与发布结果指标
+// 积压指标在每次采集时查询 Store，多实例部署时各实例上报相同的值
+func (r *Relay) RegisterMetrics(meter metric.Meter) error {
+	pending, err := meter.Int64ObservableGauge("arch3.outbox.pending",
+		metric.WithDescription("Number of outbox events waiting to be published"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return err
+	}
+	dead, err := meter.Int64ObservableGauge("arch3.outbox.dead",
+		metric.WithDescription("Number of outbox events that exhausted all publish attempts"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return err
+	}
+	lag, err := meter.Float64ObservableGauge("arch3.outbox.lag",
+		metric.WithDescription("Age of the oldest outbox event waiting to be published"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+	events, err := meter.Int64ObservableCounter("arch3.outbox.events",
+		metric.WithDescription("Total number of outbox publish attempts by result"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return err
+	}
+
+	results := []struct {
+		attrs metric.ObserveOption
+		count *atomic.Int64
+	}{
+		{metric.WithAttributes(attribute.String("result", "published")), &r.published},
+		{metric.WithAttributes(attribute.String("result", "retried")), &r.retried},
+		{metric.WithAttributes(attribute.String("result", "dead")), &r.dead},
+	}
+	_, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for _, res := range results {
+			o.ObserveInt64(events, res.count.Load(), res.attrs)
+		}
+
+		stats, err := r.store.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		o.ObserveInt64(pending, stats.Pending)
+		o.ObserveInt64(dead, stats.Dead)
+		var age float64
+		if !stats.OldestPending.IsZero() {
+			age = max(time.Since(stats.OldestPending).Seconds(), 0)
+		}
+		o.ObserveFloat64(lag, age)
+		return nil
+	}, pending, dead, lag, events)
+	return err
+}
+
+// truncate 按字节截断，并去掉被截断的不完整字符
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
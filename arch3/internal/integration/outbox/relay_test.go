@@ -0,0 +1,196 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/outbox"
+	"arch3/internal/repository/dbtest"
+	outboxrepo "arch3/internal/repository/outbox"
+	"arch3/internal/service/common"
+	"arch3/pkg/dbtx"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const testStream = "test:events"
+
+type testRelay struct {
+	db     *gorm.DB
+	rdb    *redis.Client
+	store  outbox.Store
+	broker *flakyBroker
+	relay  *outbox.Relay
+	pub    *outbox.Publisher
+}
+
+func newTestRelay(t *testing.T, cfg outbox.RelayConfig) *testRelay {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	db := dbtest.SQLite(t)
+	store := outboxrepo.NewRepository(outboxrepo.NewDAO(db))
+	broker := &flakyBroker{next: outbox.NewRedisStreamBroker(rdb, outbox.RedisStreamConfig{
+		Stream: testStream,
+		MaxLen: 1000,
+		Dedup:  time.Hour,
+	})}
+	return &testRelay{
+		db:     db,
+		rdb:    rdb,
+		store:  store,
+		broker: broker,
+		relay:  outbox.NewRelay(store, broker, cfg),
+		pub:    outbox.NewPublisher(store),
+	}
+}
+
+// flakyBroker 可注入失败的消息中间件
+type flakyBroker struct {
+	next  outbox.Broker
+	err   error
+	calls int
+}
+
+func (b *flakyBroker) Publish(ctx context.Context, r *outbox.Record) error {
+	b.calls++
+	if b.err != nil {
+		return b.err
+	}
+	return b.next.Publish(ctx, r)
+}
+
+// makeDue 让所有事件立即可领取，并把最后更新时间提前，模拟退避与保留时长已过
+func (r *testRelay) makeDue(t *testing.T, age time.Duration) {
+	t.Helper()
+	past := time.Now().UTC().Add(-age)
+	err := r.db.Exec("UPDATE outbox_events SET available_at = ?, updated_at = ?", past, past).Error
+	if err != nil {
+		t.Fatalf("makeDue error = %v", err)
+	}
+}
+
+func (r *testRelay) streamLen(t *testing.T) int64 {
+	t.Helper()
+	n, err := r.rdb.XLen(context.Background(), testStream).Result()
+	if err != nil {
+		t.Fatalf("XLen() error = %v", err)
+	}
+	return n
+}
+
+func publish(t *testing.T, r *testRelay, eventType string) *common.Event {
+	t.Helper()
+	event, err := common.NewEvent(eventType, "u1", map[string]string{"user_id": "u1"})
+	if err != nil {
+		t.Fatalf("NewEvent() error = %v", err)
+	}
+	err = dbtx.New(r.db, dbtx.Config{}).Do(context.Background(), func(ctx context.Context) error {
+		return r.pub.Publish(ctx, event)
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	return event
+}
+
+func TestRelay_PublishToStream(t *testing.T) {
+	r := newTestRelay(t, outbox.DefaultRelayConfig())
+	ctx := context.Background()
+	event := publish(t, r, "user.registered")
+
+	if err := r.relay.RunOnce(ctx); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	msgs, err := r.rdb.XRange(ctx, testStream, "-", "+").Result()
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Expected 1 stream message, got %v, %v", msgs, err)
+	}
+	fields := msgs[0].Values
+	if fields["event_id"] != event.ID || fields["event_type"] != "user.registered" ||
+		fields["aggregate_id"] != "u1" || fields["payload"] != `{"user_id":"u1"}` {
+		t.Errorf("Expected event fields, got %v", fields)
+	}
+
+	stats, _ := r.store.Stats(ctx)
+	if stats.Pending != 0 {
+		t.Errorf("Expected no pending events, got %+v", stats)
+	}
+	if err := r.relay.RunOnce(ctx); err != nil || r.broker.calls != 1 {
+		t.Errorf("Expected published event not to be sent again, got %d calls, %v", r.broker.calls, err)
+	}
+}
+
+func TestRedisStreamBroker_Dedup(t *testing.T) {
+	r := newTestRelay(t, outbox.DefaultRelayConfig())
+	ctx := context.Background()
+	rec := &outbox.Record{EventID: "e1", EventType: "user.banned", AggregateID: "u1", Payload: []byte(`{}`)}
+
+	// 标记发布成功前崩溃、租约到期后重新发布: Stream 中只有一条
+	for range 2 {
+		if err := r.broker.Publish(ctx, rec); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	if n := r.streamLen(t); n != 1 {
+		t.Errorf("Expected 1 stream message, got %d", n)
+	}
+}
+
+func TestRelay_RetryThenDead(t *testing.T) {
+	cfg := outbox.DefaultRelayConfig()
+	cfg.MaxAttempts = 2
+	r := newTestRelay(t, cfg)
+	ctx := context.Background()
+	publish(t, r, "user.banned")
+	r.broker.err = errors.New("broker down")
+
+	// 首次失败: 保持 pending 并等待退避
+	_ = r.relay.RunOnce(ctx)
+	_ = r.relay.RunOnce(ctx)
+	if r.broker.calls != 1 {
+		t.Fatalf("Expected no retry before backoff elapses, got %d calls", r.broker.calls)
+	}
+	stats, _ := r.store.Stats(ctx)
+	if stats.Pending != 1 {
+		t.Fatalf("Expected event still pending, got %+v", stats)
+	}
+
+	// 达到最大次数: 标记为 dead，不再发布
+	r.makeDue(t, time.Minute)
+	_ = r.relay.RunOnce(ctx)
+	r.makeDue(t, time.Minute)
+	_ = r.relay.RunOnce(ctx)
+	if r.broker.calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", r.broker.calls)
+	}
+	stats, _ = r.store.Stats(ctx)
+	if stats.Pending != 0 || stats.Dead != 1 {
+		t.Fatalf("Expected event dead, got %+v", stats)
+	}
+	if n := r.streamLen(t); n != 0 {
+		t.Errorf("Expected nothing in stream, got %d", n)
+	}
+
+	// 保留期内不清理，超过后删除
+	if err := r.relay.Cleanup(ctx); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if stats, _ = r.store.Stats(ctx); stats.Dead != 1 {
+		t.Fatalf("Expected dead event kept within retention, got %+v", stats)
+	}
+	r.makeDue(t, cfg.DeadRetention+time.Hour)
+	if err := r.relay.Cleanup(ctx); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if stats, _ = r.store.Stats(ctx); stats.Dead != 0 {
+		t.Errorf("Expected dead event purged, got %+v", stats)
+	}
+}
@@ -9,4 +9,9 @@ const (
 	TypeRegister = userservice.SMSTypeRegister // 注册
 	TypeLogin    = userservice.SMSTypeLogin    // 登录
 	TypeForget   = userservice.SMSTypeForget   // 忘记密码
+
+	TypeChangePhoneOld = userservice.SMSTypeChangePhoneOld // 更换手机号: 验证原手机号
+	TypeChangePhoneNew = userservice.SMSTypeChangePhoneNew // 更换手机号: 验证新手机号
+
+	TypeDeleteAccount = userservice.SMSTypeDeleteAccount // 注销账号
 )
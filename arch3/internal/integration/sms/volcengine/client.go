@@ -2,174 +2,79 @@ package volcengine
 
 import (
 	"context"
-	"crypto/rand"
+	"encoding/json"
 	"fmt"
-	"math/big"
-	"time"
 
+	"arch3/internal/integration/otp"
 	"arch3/internal/integration/sms"
 	userservice "arch3/internal/service/user"
 	"arch3/pkg/tracer"
 
 	volcsms "github.com/volcengine/volc-sdk-golang/service/sms"
-	"go.opentelemetry.io/otel/trace"
 )
 
-const (
-	codeLength     = 6
-	codeTTL        = 5 * time.Minute
-	maxPerMin      = 1
-	maxPerDay      = 6
-	maxVerifyFails = 5 // 最大验证失败次数
-)
-
-// Client 火山引擎短信客户端
+// Client 火山引擎短信客户端，作为验证码短信渠道适配器
 type Client struct {
 	config *sms.Config
-	repo   sms.CodeRepository
+	api    *volcsms.SMS
 }
 
 // New 创建火山引擎短信客户端
-func New(cfg *sms.Config, repo sms.CodeRepository) (*Client, error) {
-	// 配置火山引擎SDK
-	volcsms.DefaultInstance.Client.SetAccessKey(cfg.AccessKey)
-	volcsms.DefaultInstance.Client.SetSecretKey(cfg.SecretKey)
+// 每个客户端使用独立的 SDK 实例，不修改 SDK 的全局默认实例
+func New(cfg *sms.Config) (*Client, error) {
+	api := volcsms.NewInstance()
+	api.Client.SetAccessKey(cfg.AccessKey)
+	api.Client.SetSecretKey(cfg.SecretKey)
 
 	return &Client{
 		config: cfg,
-		repo:   repo,
+		api:    api,
 	}, nil
 }
 
-// Send 发送短信验证码
-func (c *Client) Send(ctx context.Context, smsType userservice.SMSType, phone string) error {
-	ctx, span := tracer.Start(ctx, "sms.volcengine.Send")
-	defer span.End()
+var _ otp.Channel = (*Client)(nil)
 
-	span.SetAttributes(
-		tracer.String(tracer.AttrSMSProvider, "volcengine"),
-		tracer.String(tracer.AttrSMSType, string(smsType)),
-	)
+// Name 渠道名称
+func (c *Client) Name() otp.ChannelName {
+	return otp.ChannelSMS
+}
 
-	// 先检查模板是否存在（避免存储验证码后发现模板不存在）
-	templateID, ok := c.config.Templates[smsType]
-	if !ok {
-		tracer.RecordError(span, sms.ErrTemplateNotFound)
+// Validate 检查该用途的短信模板是否已配置
+func (c *Client) Validate(smsType userservice.SMSType) error {
+	if _, ok := c.config.Templates[smsType]; !ok {
 		return sms.ErrTemplateNotFound
 	}
-
-	// 检查发送限制
-	if err := c.checkSendLimit(ctx, span, smsType, phone); err != nil {
-		return err
-	}
-
-	// 生成验证码
-	code := generateCode()
-
-	// 存储验证码
-	if err := c.repo.StoreCode(ctx, smsType, phone, code, codeTTL); err != nil {
-		tracer.RecordError(span, err)
-		return err
-	}
-
-	// 发送短信
-	if err := c.sendSMS(ctx, phone, templateID, code); err != nil {
-		tracer.RecordError(span, err)
-		// 发送失败时删除已存储的验证码
-		if delErr := c.repo.DeleteCode(ctx, smsType, phone); delErr != nil {
-			tracer.AddEvent(span, "delete_code_on_send_fail_failed", tracer.String("error", delErr.Error()))
-		}
-		return sms.ErrSendFailed
-	}
-
-	// 记录发送次数（失败不影响主流程）
-	if err := c.repo.IncrSendCount(ctx, smsType, phone); err != nil {
-		tracer.AddEvent(span, "record_send_failed", tracer.String("error", err.Error()))
-	}
-
-	// 重新发送验证码成功后重置验证失败计数
-	if err := c.repo.ResetVerifyFailCount(ctx, smsType, phone); err != nil {
-		tracer.AddEvent(span, "reset_verify_fail_count_failed", tracer.String("error", err.Error()))
-	}
-
 	return nil
 }
 
-// Verify 验证短信验证码
-func (c *Client) Verify(ctx context.Context, smsType userservice.SMSType, phone, code string) error {
-	ctx, span := tracer.Start(ctx, "sms.volcengine.Verify")
+// Deliver 发送验证码短信
+func (c *Client) Deliver(ctx context.Context, msg *otp.Message) error {
+	ctx, span := tracer.Start(ctx, "sms.volcengine.Send")
 	defer span.End()
 
 	span.SetAttributes(
 		tracer.String(tracer.AttrSMSProvider, "volcengine"),
-		tracer.String(tracer.AttrSMSType, string(smsType)),
+		tracer.String(tracer.AttrSMSType, string(msg.Type)),
 	)
 
-	// 检查验证失败次数
-	failCount, err := c.repo.GetVerifyFailCount(ctx, smsType, phone)
-	if err != nil {
-		tracer.RecordError(span, err)
-		return err
-	}
-	if failCount >= maxVerifyFails {
-		tracer.RecordError(span, sms.ErrVerifyTooMany)
-		return sms.ErrVerifyTooMany
-	}
-
-	storedCode, err := c.repo.GetCode(ctx, smsType, phone)
-	if err != nil {
-		tracer.RecordError(span, err)
-		return sms.ErrCodeInvalid
-	}
-
-	if storedCode != code {
-		// 增加验证失败次数
-		if err := c.repo.IncrVerifyFailCount(ctx, smsType, phone); err != nil {
-			tracer.AddEvent(span, "incr_verify_fail_count_failed", tracer.String("error", err.Error()))
-		}
-		tracer.RecordError(span, sms.ErrCodeInvalid)
-		return sms.ErrCodeInvalid
-	}
-
-	// 验证成功后删除验证码和失败计数
-	if err := c.repo.DeleteCode(ctx, smsType, phone); err != nil {
-		tracer.AddEvent(span, "delete_code_failed", tracer.String("error", err.Error()))
-	}
-	if err := c.repo.ResetVerifyFailCount(ctx, smsType, phone); err != nil {
-		tracer.AddEvent(span, "reset_verify_fail_count_failed", tracer.String("error", err.Error()))
+	templateID, ok := c.config.Templates[msg.Type]
+	if !ok {
+		tracer.RecordError(span, sms.ErrTemplateNotFound)
+		return sms.ErrTemplateNotFound
 	}
 
-	return nil
-}
-
-// checkSendLimit 检查发送限制
-func (c *Client) checkSendLimit(ctx context.Context, span trace.Span, smsType userservice.SMSType, phone string) error {
-	minuteCount, dayCount, err := c.repo.GetSendCount(ctx, smsType, phone)
-	if err != nil {
+	if err := c.sendSMS(ctx, msg.Address, templateID, map[string]string{"code": msg.Code}, msg.IdempotencyKey); err != nil {
 		tracer.RecordError(span, err)
 		return err
 	}
 
-	span.SetAttributes(
-		tracer.Int("sms.minute_count", minuteCount),
-		tracer.Int("sms.day_count", dayCount),
-	)
-
-	if minuteCount >= maxPerMin {
-		tracer.RecordError(span, sms.ErrSendTooFrequent)
-		return sms.ErrSendTooFrequent
-	}
-
-	if dayCount >= maxPerDay {
-		tracer.RecordError(span, sms.ErrDailyLimitExceeded)
-		return sms.ErrDailyLimitExceeded
-	}
-
 	return nil
 }
 
 // sendSMS 调用火山引擎API发送短信
-func (c *Client) sendSMS(ctx context.Context, phone, templateID, code string) error {
+// params 为模板变量；tag 透传投递 ID，仅用于在服务商回执与控制台中关联同一任务的多次发送。
+// 火山引擎不按 tag 去重，超时后重试仍可能重复下发，重复投递由投递 worker 的租约控制（见 otp.Dispatcher）
+func (c *Client) sendSMS(ctx context.Context, phone, templateID string, params map[string]string, tag string) error {
 	ctx, span := tracer.Start(ctx, "sms.volcengine.API")
 	defer span.End()
 
@@ -177,15 +82,21 @@ func (c *Client) sendSMS(ctx context.Context, phone, templateID, code string) er
 		tracer.String("sms.template_id", templateID),
 	)
 
+	templateParam, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
 	req := &volcsms.SmsRequest{
 		SmsAccount:    c.config.SmsAccount,
 		Sign:          c.config.SignName,
 		TemplateID:    templateID,
-		TemplateParam: fmt.Sprintf(`{"code":"%s"}`, code),
+		TemplateParam: string(templateParam),
 		PhoneNumbers:  phone,
+		Tag:           tag,
 	}
 
-	result, statusCode, err := volcsms.DefaultInstance.Send(req)
+	result, statusCode, err := c.api.Send(req)
 
 	span.SetAttributes(tracer.Int(tracer.AttrHTTPStatusCode, statusCode))
 
@@ -205,13 +116,3 @@ func (c *Client) sendSMS(ctx context.Context, phone, templateID, code string) er
 	)
 	return nil
 }
-
-// generateCode 生成6位验证码 (使用 crypto/rand)
-func generateCode() string {
-	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
-	if err != nil {
-		// fallback: 使用时间戳生成
-		return fmt.Sprintf("%06d", time.Now().UnixNano()%1000000)
-	}
-	return fmt.Sprintf("%06d", n.Int64())
-}
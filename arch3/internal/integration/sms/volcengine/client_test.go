@@ -0,0 +1,138 @@
+package volcengine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"arch3/internal/integration/otp"
+	"arch3/internal/integration/otp/otptest"
+	"arch3/internal/integration/sms"
+	userservice "arch3/internal/service/user"
+
+	volcsms "github.com/volcengine/volc-sdk-golang/service/sms"
+)
+
+// fakeSMSServer 本地火山引擎短信 API 替身，仅实现 SendSms
+type fakeSMSServer struct {
+	srv *httptest.Server
+
+	mu     sync.Mutex
+	sent   []volcsms.SmsRequest
+	reject bool // 拒绝所有请求（模拟服务商错误）
+}
+
+func newFakeSMSServer(t *testing.T) *fakeSMSServer {
+	t.Helper()
+	s := &fakeSMSServer{}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.srv.Close)
+	return s
+}
+
+func (s *fakeSMSServer) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost || r.URL.Query().Get("Action") != "SendSms" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	var req volcsms.SmsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.reject {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"r1","Action":"SendSms","Error":{"Code":"RE:0001","Message":"rejected"}}}`))
+		return
+	}
+	s.sent = append(s.sent, req)
+	_, _ = w.Write([]byte(`{"ResponseMetadata":{"RequestId":"r1","Action":"SendSms"},"Result":{"MessageID":["m1"]}}`))
+}
+
+// delivered 发往 phone 的模板变量（JSON），按发送顺序
+func (s *fakeSMSServer) delivered(phone string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var params []string
+	for _, req := range s.sent {
+		if req.PhoneNumbers == phone {
+			params = append(params, req.TemplateParam)
+		}
+	}
+	return params
+}
+
+func (s *fakeSMSServer) fail() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reject = true
+}
+
+// newTestClient 创建指向本地替身的客户端
+func newTestClient(t *testing.T, srv *fakeSMSServer) *Client {
+	t.Helper()
+	templates := make(map[userservice.SMSType]string, len(otp.Types))
+	for _, typ := range otp.Types {
+		templates[typ] = "ST_" + string(typ)
+	}
+	c, err := New(&sms.Config{AccessKey: "ak", SecretKey: "sk", SmsAccount: "acc", SignName: "arch3", Templates: templates})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	u, err := url.Parse(srv.srv.URL)
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	c.api.SetHost(u.Host)
+	c.api.SetSchema(u.Scheme)
+	return c
+}
+
+func TestClient_Channel(t *testing.T) {
+	otptest.TestChannel(t, func(t *testing.T) *otptest.ChannelHarness {
+		srv := newFakeSMSServer(t)
+		const phone = "13800138000"
+		return &otptest.ChannelHarness{
+			Channel:   newTestClient(t, srv),
+			Address:   phone,
+			Delivered: func() []string { return srv.delivered(phone) },
+			Fail:      srv.fail,
+		}
+	})
+}
+
+func TestClient_Deliver_Request(t *testing.T) {
+	srv := newFakeSMSServer(t)
+	c := newTestClient(t, srv)
+
+	err := c.Deliver(context.Background(), &otp.Message{Type: otp.TypeLogin, Address: "13800138000", Code: "123456", IdempotencyKey: "d1"})
+	if err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.sent) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(srv.sent))
+	}
+	got := srv.sent[0]
+	want := volcsms.SmsRequest{
+		SmsAccount:    "acc",
+		Sign:          "arch3",
+		TemplateID:    "ST_login",
+		TemplateParam: `{"code":"123456"}`,
+		PhoneNumbers:  "13800138000",
+		Tag:           "d1",
+	}
+	if got != want {
+		t.Errorf("Expected request %+v, got %+v", want, got)
+	}
+}
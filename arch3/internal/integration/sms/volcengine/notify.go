@@ -0,0 +1,50 @@
+package volcengine
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/notification"
+	"arch3/internal/integration/sms"
+	notificationservice "arch3/internal/service/notification"
+	"arch3/pkg/tracer"
+)
+
+// NotificationSender 短信通知发送器
+// 使用服务商审核过的模板，模板变量原样透传
+type NotificationSender struct {
+	client *Client
+}
+
+// NewNotificationSender 创建短信通知发送器
+func NewNotificationSender(client *Client) *NotificationSender {
+	return &NotificationSender{client: client}
+}
+
+var _ notificationservice.Sender = (*NotificationSender)(nil)
+
+// Channel 渠道名称
+func (s *NotificationSender) Channel() domain.Channel {
+	return domain.ChannelSMS
+}
+
+// Send 发送模板短信
+func (s *NotificationSender) Send(ctx context.Context, d *notificationservice.Delivery) error {
+	ctx, span := tracer.Start(ctx, "sms.volcengine.Notify")
+	defer span.End()
+
+	span.SetAttributes(
+		tracer.String(tracer.AttrSMSProvider, "volcengine"),
+		tracer.String("notification.template", d.Template),
+	)
+
+	if d.ProviderTemplate == "" {
+		tracer.RecordError(span, sms.ErrTemplateNotFound)
+		return sms.ErrTemplateNotFound
+	}
+
+	if err := s.client.sendSMS(ctx, d.To, d.ProviderTemplate, d.Vars, ""); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
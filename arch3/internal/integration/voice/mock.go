@@ -0,0 +1,102 @@
+// Package voice 提供语音电话验证码渠道
+package voice
+
+import (
+	"context"
+	"sync"
+
+	"arch3/internal/integration/otp"
+)
+
+// CallRecord 语音呼叫记录
+type CallRecord struct {
+	Type  otp.Type
+	Phone string
+	Code  string
+}
+
+// MockClient 语音验证码 Mock 渠道（开发/测试用）
+// 不发起真实呼叫，仅记录呼叫内容；与真实服务商一致，相同幂等键只呼叫一次
+type MockClient struct {
+	mu      sync.Mutex
+	records []CallRecord
+	seen    map[string]bool // 已处理的幂等键
+	callErr error           // 模拟呼叫错误
+}
+
+// NewMockClient 创建语音 Mock 渠道
+func NewMockClient() *MockClient {
+	return &MockClient{
+		records: make([]CallRecord, 0),
+		seen:    make(map[string]bool),
+	}
+}
+
+var _ otp.Channel = (*MockClient)(nil)
+
+// Name 渠道名称
+func (m *MockClient) Name() otp.ChannelName {
+	return otp.ChannelVoice
+}
+
+// Validate 语音渠道支持所有用途
+func (m *MockClient) Validate(otp.Type) error {
+	return nil
+}
+
+// Deliver 模拟语音播报验证码
+func (m *MockClient) Deliver(ctx context.Context, msg *otp.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.callErr != nil {
+		return m.callErr
+	}
+
+	if msg.IdempotencyKey != "" {
+		if m.seen[msg.IdempotencyKey] {
+			return nil
+		}
+		m.seen[msg.IdempotencyKey] = true
+	}
+
+	m.records = append(m.records, CallRecord{
+		Type:  msg.Type,
+		Phone: msg.Address,
+		Code:  msg.Code,
+	})
+	return nil
+}
+
+// GetRecords 获取呼叫记录（测试用）
+func (m *MockClient) GetRecords() []CallRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]CallRecord(nil), m.records...)
+}
+
+// LastCode 获取最近一次呼叫播报的验证码（测试用）
+func (m *MockClient) LastCode() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.records) == 0 {
+		return ""
+	}
+	return m.records[len(m.records)-1].Code
+}
+
+// SetCallError 设置呼叫错误（测试用）
+func (m *MockClient) SetCallError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callErr = err
+}
+
+// Reset 重置状态（测试用）
+func (m *MockClient) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = make([]CallRecord, 0)
+	m.seen = make(map[string]bool)
+	m.callErr = nil
+}
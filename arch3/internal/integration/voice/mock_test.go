@@ -0,0 +1,27 @@
+package voice_test
+
+import (
+	"errors"
+	"testing"
+
+	"arch3/internal/integration/otp/otptest"
+	"arch3/internal/integration/voice"
+)
+
+func TestMockClient_ChannelContract(t *testing.T) {
+	otptest.TestChannel(t, func(t *testing.T) *otptest.ChannelHarness {
+		client := voice.NewMockClient()
+		return &otptest.ChannelHarness{
+			Channel: client,
+			Address: "13800138000",
+			Delivered: func() []string {
+				var codes []string
+				for _, r := range client.GetRecords() {
+					codes = append(codes, r.Code)
+				}
+				return codes
+			},
+			Fail: func() { client.SetCallError(errors.New("call failed")) },
+		}
+	})
+}
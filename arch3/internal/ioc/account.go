@@ -0,0 +1,34 @@
+package ioc
+
+import (
+	"context"
+
+	groupservice "arch3/internal/service/group"
+	notificationservice "arch3/internal/service/notification"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/jwt"
+)
+
+// initAccountHooks 注册各模块的账号注销与数据导出钩子
+// 新增保存用户数据的模块时在此注册
+func initAccountHooks(jwtMgr *jwt.Manager, notificationSvc notificationservice.Service, groupSvc groupservice.Service) *userservice.AccountHooks {
+	hooks := userservice.NewAccountHooks()
+
+	// 会话: 撤销已签发的全部 token
+	hooks.OnDelete("sessions", jwtMgr.RevokeUserTokens)
+
+	// 通知: 站内信与接收偏好
+	hooks.OnDelete("notification", notificationSvc.DeleteUserData)
+	hooks.OnExport("notification", func(ctx context.Context, userID string) (any, error) {
+		return notificationSvc.ExportUserData(ctx, userID)
+	})
+
+	// 组织: 注册时绑定发给该手机号的邀请；成员身份与收到的邀请，所有者注销时转让或解散组织
+	hooks.OnRegister("group", groupSvc.BindInvitations)
+	hooks.OnDelete("group", groupSvc.DeleteUserData)
+	hooks.OnExport("group", func(ctx context.Context, userID string) (any, error) {
+		return groupSvc.ExportUserData(ctx, userID)
+	})
+
+	return hooks
+}
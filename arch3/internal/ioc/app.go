@@ -15,7 +15,7 @@ import (
 //
 // 生命周期:
 //   - 初始化: InitApp() 按顺序初始化所有依赖
-//   - 运行: Run() 启动 HTTP 服务器
+//   - 运行: Run() 启动后台任务与 HTTP 服务器
 //   - 关闭: Shutdown() 按逆序优雅关闭所有组件
 //
 // 优雅关闭机制:
@@ -87,7 +87,8 @@ func InitApp(configPath string) (*App, error) {
 
 // Run 启动应用程序（阻塞）
 //
-// 调用 Hertz 的 Spin() 方法启动 HTTP 服务器，此方法会阻塞直到服务器关闭。
+// 先启动后台任务调度器，再调用 Hertz 的 Spin() 方法启动 HTTP 服务器，
+// 此方法会阻塞直到服务器关闭。
 // 优雅关闭由 Shutdown() 方法触发。
 //
 // 启动信号:
@@ -96,6 +97,8 @@ func InitApp(configPath string) (*App, error) {
 func (app *App) Run() {
 	addr := fmt.Sprintf("%s:%d", app.Config.Server.Host, app.Config.Server.Port)
 
+	app.Container.Scheduler.Start()
+
 	logger.Info("Starting server...",
 		zap.String("address", addr),
 		zap.String("mode", app.Config.Server.Mode),
@@ -118,12 +121,13 @@ func (app *App) Run() {
 //  1. 标记关闭状态  - 健康检查返回 503，LB 停止发送新请求
 //  2. 等待 LB 感知  - 默认 2 秒，可配合 K8s preStop hook
 //  3. 关闭 HTTP     - 等待当前请求完成（50% 超时时间）
-//  4. 关闭 Tracing  - 确保 trace/metrics 数据发送完成（25% 超时时间）
-//  5. 关闭 Redis    - 释放连接池资源（25% 超时时间）
-//  6. 同步日志      - 刷新日志缓冲区
+//  4. 停止后台任务  - 等待正在执行的一轮任务完成（与 HTTP 共享 50% 超时时间）
+//  5. 关闭 Tracing  - 确保 trace/metrics 数据发送完成（25% 超时时间）
+//  6. 关闭 Redis    - 释放连接池资源（25% 超时时间）
+//  7. 同步日志      - 刷新日志缓冲区
 //
 // 超时分配策略:
-//   - HTTP 关闭: 50% (需要等待请求完成)
+//   - HTTP + 后台任务: 50% (需要等待请求/任务完成)
 //   - Tracing 关闭: 25% (需要发送缓冲数据)
 //   - Redis 关闭: 25% (通常很快)
 //
@@ -160,9 +164,17 @@ func (app *App) Shutdown() error {
 			logger.Error("HTTP server shutdown error", zap.Error(err))
 		}
 	}
+
+	// 4. 停止后台任务 - 在关闭 Redis 前完成，避免任务使用已关闭的连接
+	if app.Container.Scheduler != nil {
+		logger.Info("Stopping job scheduler...")
+		if err := app.Container.Scheduler.Stop(httpCtx); err != nil {
+			logger.Error("Job scheduler stop error", zap.Error(err))
+		}
+	}
 	httpCancel()
 
-	// 4. 关闭 OpenTelemetry - 确保 trace/metrics 数据完整发送到后端
+	// 5. 关闭 OpenTelemetry - 确保 trace/metrics 数据完整发送到后端
 	if app.Container.Tracing != nil {
 		logger.Info("Shutting down tracing...", zap.Duration("timeout", tracingTimeout))
 		tracingCtx, tracingCancel := context.WithTimeout(context.Background(), tracingTimeout)
@@ -172,7 +184,7 @@ func (app *App) Shutdown() error {
 		tracingCancel()
 	}
 
-	// 5. 关闭基础设施资源（DB + Redis）
+	// 6. 关闭基础设施资源（DB + Redis）
 	if app.Container.Infra != nil {
 		logger.Info("Closing infrastructure resources...", zap.Duration("timeout", infraTimeout))
 		if err := app.Container.Infra.Close(); err != nil {
@@ -182,7 +194,7 @@ func (app *App) Shutdown() error {
 		}
 	}
 
-	// 6. 同步日志缓冲区 - 确保所有日志都已写入
+	// 7. 同步日志缓冲区 - 确保所有日志都已写入
 	if err := logger.Sync(); err != nil {
 		logger.Debug("Logger sync warning", zap.Error(err))
 	}
@@ -2,13 +2,21 @@ package ioc
 
 import (
 	"arch3/internal/config"
+	"arch3/pkg/dbreplica"
+	"arch3/pkg/dbtx"
 	"arch3/pkg/logger"
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/glebarez/sqlite"
+
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 	"gorm.io/plugin/opentelemetry/tracing"
@@ -17,15 +25,16 @@ import (
 // InitDB 初始化数据库连接
 //
 // 功能说明:
-//   - 使用 GORM 连接 MySQL 数据库
+//   - 按 db.driver 使用 GORM 连接 MySQL、PostgreSQL 或 SQLite
 //   - 配置连接池参数
 //   - 启用 OpenTelemetry tracing，自动为 SQL 操作创建 span
+//   - SQL 日志经 zap 输出并带有 trace_id，慢查询以 Warn 级别记录
 //   - 验证数据库连接
 //
 // Trace 效果:
 //
 //	每个 SQL 查询会自动创建 span，包含:
-//	- db.system: "mysql"
+//	- db.system: "mysql" / "postgresql" / "sqlite"
 //	- db.statement: SQL 语句
 //	- db.operation: 操作类型 (SELECT/INSERT/UPDATE/DELETE)
 //	- db.sql.table: 表名
@@ -37,8 +46,10 @@ import (
 //   - *gorm.DB: GORM 数据库连接实例
 //   - error: 连接失败时返回错误
 func InitDB(cfg *config.Config) (*gorm.DB, error) {
-	// 构建 DSN
-	dsn := cfg.DB.DSN()
+	dialector, err := openDialector(&cfg.DB)
+	if err != nil {
+		return nil, err
+	}
 
 	// GORM 日志配置
 	var logLevel gormlogger.LogLevel
@@ -52,8 +63,14 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	// 连接数据库
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: gormlogger.Default.LogMode(logLevel),
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: logger.NewGormLogger(logger.GormConfig{
+			Level:         logLevel,
+			SlowThreshold: time.Duration(cfg.DB.SlowThreshold) * time.Millisecond,
+			LogParams:     cfg.DB.LogParams,
+		}),
+		// 将驱动错误转换为 gorm.ErrDuplicatedKey 等通用错误，Repository 无需识别驱动错误码
+		TranslateError: true,
 	})
 	if err != nil {
 		return nil, err
@@ -64,10 +81,10 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	// 注意: WithDBSystem 设置的是 db.system.name (semconv v1.30.0)
 	// 火山引擎等平台可能使用旧版 db.system，需要通过 WithAttributes 额外设置
 	if err := db.Use(tracing.NewPlugin(
-		tracing.WithDBSystem(cfg.DB.Driver), // 设置 db.system.name
+		tracing.WithDBSystem(dbSystem(cfg.DB.Driver)), // 设置 db.system.name
 		tracing.WithAttributes(
-			attribute.String("db.system", cfg.DB.Driver), // 兼容旧版 db.system 属性
-			semconv.DBName(cfg.DB.Database),              // 数据库名称
+			attribute.String("db.system", dbSystem(cfg.DB.Driver)), // 兼容旧版 db.system 属性
+			semconv.DBName(cfg.DB.Database),                        // 数据库名称
 		),
 		tracing.WithoutQueryVariables(), // 不记录查询参数值，避免敏感数据泄露
 	)); err != nil {
@@ -83,6 +100,10 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	// 配置连接池
 	sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	if cfg.DB.Driver == "sqlite" {
+		// SQLite 同时只允许一个写入者；内存库每个连接各是一个独立的库，必须共用同一连接
+		sqlDB.SetMaxOpenConns(1)
+	}
 	sqlDB.SetConnMaxLifetime(time.Duration(cfg.DB.ConnMaxLifetime) * time.Second)
 
 	// 验证连接
@@ -91,6 +112,7 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 	}
 
 	logger.L().Info("database connected",
+		zap.String("driver", cfg.DB.Driver),
 		zap.String("host", cfg.DB.Host),
 		zap.Int("port", cfg.DB.Port),
 		zap.String("database", cfg.DB.Database),
@@ -99,3 +121,130 @@ func InitDB(cfg *config.Config) (*gorm.DB, error) {
 
 	return db, nil
 }
+
+// openDialector 按驱动类型创建 GORM Dialector
+func openDialector(cfg *config.DBConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "mysql":
+		return mysql.Open(cfg.DSN()), nil
+	case "postgres":
+		return postgres.Open(cfg.DSN()), nil
+	case "sqlite":
+		return sqlite.Open(cfg.DSN()), nil
+	default:
+		return nil, fmt.Errorf("unsupported db driver %q (mysql, postgres, sqlite)", cfg.Driver)
+	}
+}
+
+// dbSystem 返回 OpenTelemetry 语义约定中的 db.system 取值
+func dbSystem(driver string) string {
+	if driver == "postgres" {
+		return "postgresql"
+	}
+	return driver
+}
+
+// InitTransactor 初始化事务管理器
+// 事务在主库执行，死锁或序列化冲突时按配置整体重试
+func InitTransactor(cfg *config.Config, db *gorm.DB) *dbtx.Manager {
+	return dbtx.New(db, dbtx.Config{MaxRetries: cfg.DB.TxMaxRetries})
+}
+
+// InitReplicas 初始化从库并启用读写分离
+//
+// 功能说明:
+//   - 按 db.replicas 连接每个从库，连接池参数与主库相同
+//   - 读请求在健康的从库间轮询，写请求与事务使用主库
+//   - 后台定期检查从库健康，状态变化时记录日志
+//
+// 未配置从库时返回 nil，所有请求使用主库。
+// 启动时从库不可达不会导致启动失败，该从库在恢复前不接收读请求。
+func InitReplicas(cfg *config.Config, db *gorm.DB) (*dbreplica.Resolver, error) {
+	if len(cfg.DB.Replicas) == 0 {
+		return nil, nil
+	}
+	if cfg.DB.Driver == "sqlite" {
+		return nil, fmt.Errorf("db.replicas is not supported by the sqlite driver")
+	}
+
+	replicas := make([]*dbreplica.Replica, 0, len(cfg.DB.Replicas))
+	closeAll := func() {
+		for _, rep := range replicas {
+			_ = rep.DB.Close()
+		}
+	}
+	for _, rc := range cfg.DB.Replicas {
+		replicaCfg := cfg.DB.Replica(rc)
+		rep, err := openReplica(&replicaCfg)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("open replica %s: %w", replicaCfg.Addr(), err)
+		}
+		replicas = append(replicas, rep)
+	}
+
+	primary, err := db.DB()
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	resolver := dbreplica.New(primary, replicas, dbreplica.Config{
+		HealthCheckInterval: time.Duration(cfg.DB.ReplicaHealthCheckInterval) * time.Second,
+		OnStateChange: func(rep *dbreplica.Replica, healthy bool, err error) {
+			if healthy {
+				logger.Info("database replica recovered", zap.String("replica", rep.Name))
+			} else {
+				logger.Warn("database replica unhealthy, reads fall back to other replicas or primary",
+					zap.String("replica", rep.Name), zap.Error(err))
+			}
+		},
+	})
+	if err := resolver.Register(db); err != nil {
+		closeAll()
+		return nil, err
+	}
+	// 立即检查一次，启动时不可达的从库不接收读请求
+	resolver.CheckHealth(context.Background())
+	resolver.Start()
+
+	logger.L().Info("database replicas enabled", zap.Int("replicas", len(replicas)))
+	return resolver, nil
+}
+
+// openReplica 打开从库连接池，并基于该连接池创建交给 dbresolver 的 Dialector
+func openReplica(cfg *config.DBConfig) (*dbreplica.Replica, error) {
+	dialector, err := openDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// 只借用 GORM 打开连接池；SQL 回调（日志、tracing）由主库的 gorm.DB 统一执行
+	replicaDB, err := gorm.Open(dialector, &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := replicaDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+
+	rep := &dbreplica.Replica{Name: cfg.Addr(), DB: sqlDB}
+	switch cfg.Driver {
+	case "postgres":
+		rep.Dialector = postgres.New(postgres.Config{Conn: sqlDB})
+	default:
+		rep.Dialector = mysql.New(mysql.Config{Conn: sqlDB})
+	}
+	return rep, nil
+}
+
+// registerDBMetrics 注册主库与从库的连接池指标
+func registerDBMetrics(cfg *config.Config, db *gorm.DB, resolver *dbreplica.Resolver) error {
+	primary, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return dbreplica.RegisterMetrics(otel.Meter("arch3"), cfg.DB.Addr(), primary, resolver)
+}
@@ -1 +1,66 @@
 package ioc
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"arch3/internal/config"
+	"arch3/internal/integration/outbox"
+	"arch3/internal/job"
+	outboxrepo "arch3/internal/repository/outbox"
+	"arch3/internal/service/common"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+// outboxDedupWindow Redis Stream 发布端对同一事件 ID 的去重时长
+const outboxDedupWindow = 24 * time.Hour
+
+// InitEventPublisher 初始化领域事件发布
+//
+// 依赖链: DAO → Store → Publisher（业务写入）/ Relay（后台发布到 Redis Stream）/ Consumer（消费者组处理事件）
+// 发布、清理与消费任务注册到 scheduler 随应用启停，多实例部署时各实例的 Relay 以租约分摊事件，
+// Consumer 以同一消费者组分摊消息；业务模块在调度器启动前通过 Consumer.Handle 注册事件处理。
+func InitEventPublisher(cfg *config.Config, db *gorm.DB, rdb *redis.Client, scheduler *job.Scheduler) (common.EventPublisher, *outbox.Consumer, error) {
+	store := outboxrepo.NewRepository(outboxrepo.NewDAO(db))
+
+	broker := outbox.NewRedisStreamBroker(rdb, outbox.RedisStreamConfig{
+		Stream: cfg.Outbox.Stream,
+		MaxLen: cfg.Outbox.StreamMaxLen,
+		Dedup:  outboxDedupWindow,
+	})
+
+	relayCfg := outbox.DefaultRelayConfig()
+	relayCfg.BatchSize = cfg.Outbox.BatchSize
+	relayCfg.MaxAttempts = cfg.Outbox.MaxAttempts
+	relayCfg.Retention = time.Duration(cfg.Outbox.Retention) * 24 * time.Hour
+	relayCfg.DeadRetention = time.Duration(cfg.Outbox.DeadRetention) * 24 * time.Hour
+
+	relay := outbox.NewRelay(store, broker, relayCfg)
+	if err := relay.RegisterMetrics(otel.Meter("arch3")); err != nil {
+		return nil, nil, err
+	}
+	scheduler.Register("outbox_relay", time.Duration(cfg.Outbox.RelayInterval)*time.Millisecond, relay.RunOnce)
+	scheduler.Register("outbox_cleanup", time.Duration(cfg.Outbox.CleanupInterval)*time.Minute, relay.Cleanup)
+
+	consumerCfg := outbox.DefaultConsumerConfig()
+	consumerCfg.Stream = cfg.Outbox.Stream
+	consumerCfg.Group = cfg.Outbox.ConsumerGroup
+	consumerCfg.Consumer = consumerName()
+	consumerCfg.BatchSize = int64(cfg.Outbox.BatchSize)
+	consumerCfg.MaxDeliveries = cfg.Outbox.ConsumeMaxDeliveries
+	consumer := outbox.NewConsumer(rdb, consumerCfg)
+	scheduler.Register("outbox_consumer", time.Duration(cfg.Outbox.ConsumeInterval)*time.Millisecond, consumer.RunOnce)
+
+	return outbox.NewPublisher(store), consumer, nil
+}
+
+// consumerName 本实例在消费者组中的名称
+// 进程重启后使用新名称，旧名称下未确认的消息由其他实例在空闲超时后领取
+func consumerName() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
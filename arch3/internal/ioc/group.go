@@ -0,0 +1,31 @@
+package ioc
+
+import (
+	"arch3/internal/config"
+	grouphandler "arch3/internal/handler/group"
+	grouprepo "arch3/internal/repository/group"
+	userrepo "arch3/internal/repository/user"
+	groupservice "arch3/internal/service/group"
+
+	"gorm.io/gorm"
+)
+
+// InitGroupService 初始化组织服务
+//
+// 依赖链: DAO → Repository → UserLookup/Notifier → Service
+func InitGroupService(db *gorm.DB, notifier groupservice.Notifier, cfg *config.Config) (groupservice.Service, error) {
+	// 按手机号查找被邀请人、记录未注册的被邀请手机号（手机号加密存储，需要字段密钥计算盲索引）
+	fieldKeys, err := InitKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+	repo := grouprepo.NewRepository(grouprepo.NewDAO(db), fieldKeys)
+	users := userrepo.NewRepository(userrepo.NewDAO(db), fieldKeys)
+
+	return groupservice.NewService(InitTransactor(cfg, db), repo, users, notifier), nil
+}
+
+// InitGroupHandler 初始化组织处理器
+func InitGroupHandler(svc groupservice.Service) *grouphandler.Handler {
+	return grouphandler.NewHandler(svc)
+}
@@ -7,6 +7,7 @@ import (
 	"arch3/internal/handler/middleware"
 	"arch3/pkg/jwt"
 
+	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
 	hertzconfig "github.com/cloudwego/hertz/pkg/common/config"
 )
@@ -16,13 +17,20 @@ import (
 // 职责范围:
 //   - 配置服务器参数（端口、超时、请求体限制等）
 //   - 配置链路追踪的 Server Tracer（如果启用）
+//   - 配置客户端 IP 解析：只信任来自 server.trusted_proxies 的代理请求头
 //
 // 不包含中间件注册和路由注册，这些由调用方单独处理。
 //
 // 返回值:
 //   - *server.Hertz: 服务器实例
 //   - *middleware.TracerConfig: Tracing 中间件配置（如果启用），否则为 nil
-func initServer(cfg *config.Config) (*server.Hertz, *middleware.TracerConfig) {
+//   - error: 可信代理配置无效
+func initServer(cfg *config.Config) (*server.Hertz, *middleware.TracerConfig, error) {
+	trustedProxies, err := cfg.Server.ParseTrustedProxies()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	opts := []hertzconfig.Option{
 		server.WithHostPorts(cfg.Server.Address()),
 		server.WithReadTimeout(time.Duration(cfg.Server.ReadTimeout) * time.Second),
@@ -39,7 +47,14 @@ func initServer(cfg *config.Config) (*server.Hertz, *middleware.TracerConfig) {
 		tracerCfg = tc
 	}
 
-	return server.New(opts...), tracerCfg
+	h := server.New(opts...)
+	// Hertz 默认信任任意来源的 X-Forwarded-For / X-Real-IP，改为只信任配置的代理，
+	// 请求不是来自可信代理时 ClientIP 返回对端地址
+	h.SetClientIPFunc(app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		TrustedCIDRs:    trustedProxies,
+	}))
+	return h, tracerCfg, nil
 }
 
 // registerMiddleware 注册全局中间件
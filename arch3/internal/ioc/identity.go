@@ -0,0 +1,23 @@
+package ioc
+
+import (
+	"fmt"
+
+	"arch3/internal/config"
+	"arch3/internal/integration/identity"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/logger"
+)
+
+// initIdentityVerifier 初始化实名身份核验服务商
+func initIdentityVerifier(cfg *config.Config) (userservice.IdentityVerifier, error) {
+	switch cfg.Identity.Provider {
+	case "manual":
+		return identity.NewManual(), nil
+	case "fake":
+		logger.Warn("identity provider is fake, real-name verification is simulated")
+		return identity.NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unsupported identity provider: %s", cfg.Identity.Provider)
+	}
+}
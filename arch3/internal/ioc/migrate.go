@@ -0,0 +1,40 @@
+package ioc
+
+import (
+	"context"
+
+	"arch3/internal/config"
+	"arch3/internal/migration"
+	"arch3/pkg/logger"
+	"arch3/pkg/migrate"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InitMigrator 创建数据库迁移执行器，使用 internal/migration 中内嵌的对应驱动的迁移脚本
+func InitMigrator(cfg *config.Config, db *gorm.DB) (*migrate.Migrator, error) {
+	fsys, err := migration.FS(cfg.DB.Driver)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(sqlDB, cfg.DB.Driver, fsys)
+}
+
+// runAutoMigrate 启动时执行未执行的迁移（db.auto_migrate）
+// 多实例同时启动时由迁移执行器的咨询锁保证只有一个实例执行
+func runAutoMigrate(cfg *config.Config, db *gorm.DB) error {
+	m, err := InitMigrator(cfg, db)
+	if err != nil {
+		return err
+	}
+	applied, err := m.Up(context.Background())
+	for _, mig := range applied {
+		logger.Info("migration applied", zap.String("migration", mig.String()))
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package ioc
+
+import (
+	"arch3/internal/config"
+	notificationhandler "arch3/internal/handler/notification"
+	"arch3/internal/integration/email"
+	"arch3/internal/integration/sms/volcengine"
+	notificationrepo "arch3/internal/repository/notification"
+	notificationservice "arch3/internal/service/notification"
+	"arch3/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// InitNotificationService 初始化通知服务
+//
+// 站内信始终可用；短信渠道使用服务商模板，邮件渠道按配置启用。
+func InitNotificationService(db *gorm.DB, cfg *config.Config) (notificationservice.Service, error) {
+	registry, err := notificationservice.NewRegistry(notificationservice.BuiltinTemplates()...)
+	if err != nil {
+		return nil, err
+	}
+	for name, providerTemplate := range cfg.Notification.SMSTemplates {
+		registry.SetProviderTemplate(name, providerTemplate)
+	}
+
+	// Repository 层
+	repo := notificationrepo.NewRepository(notificationrepo.NewDAO(db))
+
+	// 外部渠道
+	smsClient, err := initSMSClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	senders := []notificationservice.Sender{volcengine.NewNotificationSender(smsClient)}
+	if cfg.Email.Enabled {
+		senders = append(senders, email.NewNotificationSender(initEmailClient(cfg)))
+	}
+
+	logger.Info("Notification service initialized",
+		zap.Int("sms_templates", len(cfg.Notification.SMSTemplates)),
+		zap.Bool("email", cfg.Email.Enabled),
+	)
+
+	return notificationservice.NewService(registry, repo, senders...), nil
+}
+
+// InitNotificationHandler 初始化通知处理器
+func InitNotificationHandler(svc notificationservice.Service) *notificationhandler.Handler {
+	return notificationhandler.NewHandler(svc)
+}
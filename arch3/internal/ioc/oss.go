@@ -0,0 +1,49 @@
+package ioc
+
+import (
+	"fmt"
+	"time"
+
+	"arch3/internal/config"
+	"arch3/internal/integration/oss"
+	"arch3/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// InitStorage 初始化对象存储
+func InitStorage(cfg *config.Config) (oss.Storage, error) {
+	switch {
+	case cfg.OSS.IsLocal():
+		baseURL := cfg.OSS.PublicBaseURL
+		if baseURL == "" {
+			baseURL = cfg.OSS.Local.ServePath
+		}
+		storage, err := oss.NewLocalStorage(cfg.OSS.Local.Dir, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Object storage initialized", zap.String("provider", "local"), zap.String("dir", cfg.OSS.Local.Dir))
+		return storage, nil
+
+	case cfg.OSS.Provider == "s3":
+		storage, err := oss.NewS3Storage(&oss.S3Config{
+			Endpoint:        cfg.OSS.S3.Endpoint,
+			Region:          cfg.OSS.S3.Region,
+			Bucket:          cfg.OSS.S3.Bucket,
+			AccessKeyID:     cfg.OSS.S3.AccessKeyID,
+			SecretAccessKey: cfg.OSS.S3.SecretAccessKey,
+			PathStyle:       cfg.OSS.S3.PathStyle,
+			PublicBaseURL:   cfg.OSS.PublicBaseURL,
+			Timeout:         time.Duration(cfg.OSS.S3.Timeout) * time.Second,
+		})
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Object storage initialized", zap.String("provider", "s3"), zap.String("bucket", cfg.OSS.S3.Bucket))
+		return storage, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported oss provider: %s", cfg.OSS.Provider)
+	}
+}
@@ -0,0 +1,213 @@
+package ioc
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+
+	"arch3/internal/config"
+	"arch3/internal/integration/email"
+	"arch3/internal/integration/otp"
+	"arch3/internal/integration/sms"
+	"arch3/internal/integration/sms/volcengine"
+	"arch3/internal/integration/voice"
+	"arch3/internal/job"
+	otprepo "arch3/internal/repository/otp"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// otpDispatchInterval 投递 worker 轮询间隔
+const otpDispatchInterval = 500 * time.Millisecond
+
+// InitOTPClient 初始化验证码客户端
+//
+// 短信渠道始终启用，邮件和语音渠道按配置启用。
+// 所有渠道共用同一验证码存储，发送次数与验证失败计数按 (用途, 主体) 跨渠道累计，
+// 切换渠道不会绕过发送限流。
+// 验证码经 Redis outbox 异步投递，投递 worker 注册到 scheduler 随应用启停。
+func InitOTPClient(cfg *config.Config, rdb *redis.Client, scheduler *job.Scheduler) (userservice.OTPClient, error) {
+	// 创建验证码存储与投递队列 Repository
+	codeRepo := otprepo.NewCacheRepository(rdb)
+	dispatchQueue := otprepo.NewDispatchQueue(rdb)
+
+	hasher, err := initCodeHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	smsChannel, err := initSMSChannel(cfg)
+	if err != nil {
+		return nil, err
+	}
+	channels := []otp.Channel{smsChannel}
+
+	if cfg.Email.Enabled {
+		channels = append(channels, initEmailClient(cfg))
+	}
+
+	if cfg.Voice.Enabled {
+		voiceChannel, err := initVoiceChannel(cfg)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, voiceChannel)
+	}
+
+	names := make([]string, 0, len(channels))
+	for _, ch := range channels {
+		names = append(names, string(ch.Name()))
+	}
+	logger.Info("OTP client initialized",
+		zap.String("sms_provider", cfg.SMS.Provider),
+		zap.Strings("channels", names),
+	)
+
+	dispatcher := otp.NewDispatcher(dispatchQueue, codeRepo, hasher, otp.DefaultDispatcherConfig(), channels...)
+	scheduler.Register("otp_dispatch", otpDispatchInterval, dispatcher.RunOnce)
+
+	manager := otp.NewManager(codeRepo, dispatchQueue, hasher, channels...)
+
+	if cfg.OTP.TestPhones.Enabled {
+		testPhones, err := parseTestPhones(cfg.OTP.TestPhones.Phones)
+		if err != nil {
+			return nil, err
+		}
+		manager.SetTestPhones(testPhones)
+
+		phones := make([]string, 0, len(testPhones))
+		for _, tp := range testPhones {
+			phones = append(phones, tp.Phone)
+		}
+		logger.Warn("OTP TEST PHONES ENABLED, fixed codes accepted without provider delivery",
+			zap.String("mode", cfg.Server.Mode),
+			zap.Strings("phones", phones),
+		)
+	}
+
+	return manager, nil
+}
+
+// initCodeHasher 初始化验证码摘要器
+// 未配置密钥时（仅非生产环境，生产环境由配置校验拦截）使用随机密钥
+func initCodeHasher(cfg *config.Config) (*otp.CodeHasher, error) {
+	secret := []byte(cfg.OTP.Secret)
+	if len(secret) == 0 {
+		logger.Warn("otp.secret not configured, using random per-process secret")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return otp.NewCodeHasher(secret)
+}
+
+// parseTestPhones 解析测试号码配置
+func parseTestPhones(entries []config.OTPTestPhoneConfig) ([]otp.TestPhone, error) {
+	phones := make([]otp.TestPhone, 0, len(entries))
+	for _, e := range entries {
+		if !testCodePattern.MatchString(e.Code) {
+			return nil, fmt.Errorf("otp test phone %s: code must be 6 digits", e.Phone)
+		}
+		tp := otp.TestPhone{Phone: e.Phone, Code: e.Code}
+
+		if e.ExpiresAt != "" {
+			t, err := time.Parse(time.RFC3339, e.ExpiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("otp test phone %s: invalid expires_at: %w", e.Phone, err)
+			}
+			tp.ExpiresAt = t
+		}
+
+		for _, raw := range e.AllowedIPs {
+			prefix, err := parseIPOrCIDR(raw)
+			if err != nil {
+				return nil, fmt.Errorf("otp test phone %s: invalid allowed_ips %q: %w", e.Phone, raw, err)
+			}
+			tp.AllowedIPs = append(tp.AllowedIPs, prefix)
+		}
+
+		phones = append(phones, tp)
+	}
+	return phones, nil
+}
+
+// testCodePattern 测试验证码格式，与登录接口的校验规则一致
+var testCodePattern = regexp.MustCompile(`^\d{6}$`)
+
+// parseIPOrCIDR 解析单个 IP 或 CIDR
+func parseIPOrCIDR(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		return netip.ParsePrefix(s)
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// initSMSChannel 初始化短信渠道
+func initSMSChannel(cfg *config.Config) (otp.Channel, error) {
+	return initSMSClient(cfg)
+}
+
+// initSMSClient 初始化短信服务商客户端
+func initSMSClient(cfg *config.Config) (*volcengine.Client, error) {
+	smsCfg := &sms.Config{
+		Provider:   cfg.SMS.Provider,
+		AccessKey:  cfg.SMS.AccessKey,
+		SecretKey:  cfg.SMS.SecretKey,
+		SmsAccount: cfg.SMS.SmsAccount,
+		SignName:   cfg.SMS.SignName,
+		Templates: map[userservice.SMSType]string{
+			userservice.SMSTypeLogin:    cfg.SMS.Templates.Login,
+			userservice.SMSTypeRegister: cfg.SMS.Templates.Register,
+			userservice.SMSTypeForget:   cfg.SMS.Templates.Forget,
+
+			userservice.SMSTypeChangePhoneOld: cfg.SMS.Templates.ChangePhoneOld,
+			userservice.SMSTypeChangePhoneNew: cfg.SMS.Templates.ChangePhoneNew,
+
+			userservice.SMSTypeDeleteAccount: cfg.SMS.Templates.DeleteAccount,
+		},
+	}
+
+	switch cfg.SMS.Provider {
+	case "volcengine":
+		return volcengine.New(smsCfg)
+	default:
+		// 默认使用火山引擎
+		return volcengine.New(smsCfg)
+	}
+}
+
+// initEmailClient 初始化 SMTP 邮件客户端
+func initEmailClient(cfg *config.Config) *email.Client {
+	return email.New(&email.Config{
+		Host:     cfg.Email.Host,
+		Port:     cfg.Email.Port,
+		Username: cfg.Email.Username,
+		Password: cfg.Email.Password,
+		From:     cfg.Email.From,
+		FromName: cfg.Email.FromName,
+		TLSMode:  cfg.Email.TLSMode,
+		Timeout:  time.Duration(cfg.Email.Timeout) * time.Second,
+	})
+}
+
+// initVoiceChannel 初始化语音渠道
+func initVoiceChannel(cfg *config.Config) (otp.Channel, error) {
+	switch cfg.Voice.Provider {
+	case "mock", "":
+		logger.Warn("Voice OTP channel uses mock provider, no real calls will be made")
+		return voice.NewMockClient(), nil
+	default:
+		return nil, fmt.Errorf("unsupported voice provider: %s", cfg.Voice.Provider)
+	}
+}
@@ -0,0 +1,57 @@
+package ioc
+
+import (
+	"time"
+
+	"arch3/internal/config"
+	"arch3/internal/job"
+	retentionrepo "arch3/internal/repository/retention"
+	userrepo "arch3/internal/repository/user"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"gorm.io/gorm"
+)
+
+// InitRetention 初始化数据保留任务
+//
+// 依赖链: Store（数据库）+ Locker（Redis）→ Retention → Scheduler
+// 各模块的保留策略在此注册，保留天数为 0 的策略不注册。
+func InitRetention(cfg *config.Config, db *gorm.DB, rdb *redis.Client, scheduler *job.Scheduler) error {
+	c := cfg.Retention
+	interval := time.Duration(c.Interval) * time.Minute
+
+	retentionCfg := job.DefaultRetentionConfig()
+	retentionCfg.BatchSize = c.BatchSize
+	retentionCfg.MaxBatches = c.MaxBatches
+	retentionCfg.DryRun = c.DryRun
+	// 锁最多持有一个执行间隔，实例崩溃后下一轮可由其他实例接手
+	retentionCfg.LockTTL = interval
+
+	retention, err := job.NewRetention(retentionrepo.NewRepository(db), retentionrepo.NewRedisLocker(rdb), otel.Meter("arch3"), retentionCfg)
+	if err != nil {
+		return err
+	}
+
+	// User 模块
+	policies := []struct {
+		days   int
+		policy func(maxAge time.Duration) job.Policy
+	}{
+		{c.LoginHistory, userrepo.LoginHistoryRetention},
+		{c.DeletedUsers, userrepo.DeletedUserRetention},
+	}
+	for _, p := range policies {
+		if p.days <= 0 {
+			continue
+		}
+		if err := retention.Register(p.policy(time.Duration(p.days) * 24 * time.Hour)); err != nil {
+			return err
+		}
+	}
+
+	if len(retention.Policies()) > 0 {
+		scheduler.Register("retention", interval, retention.RunOnce)
+	}
+	return nil
+}
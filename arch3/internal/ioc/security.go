@@ -0,0 +1,40 @@
+package ioc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"arch3/internal/config"
+	"arch3/pkg/fieldcrypt"
+	"arch3/pkg/logger"
+)
+
+// devFieldKeySeed 开发环境字段加密密钥种子
+// 使用固定密钥而非随机密钥，保证重启后仍能解密本地数据
+const devFieldKeySeed = "arch3-dev-field-key"
+
+// InitKeyring 初始化字段加密密钥环
+// 生产环境密钥由配置校验保证，未配置时（仅非生产环境）使用固定的开发密钥
+func InitKeyring(cfg *config.Config) (*fieldcrypt.Keyring, error) {
+	keks, err := cfg.Security.ParseFieldKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(keks) == 0 {
+		logger.Warn("security.field_keys not configured, using insecure development key")
+		key := sha256.Sum256([]byte(devFieldKeySeed))
+		keks[1] = key[:]
+	}
+
+	var indexKey []byte
+	if cfg.Security.BlindIndexKey == "" {
+		logger.Warn("security.blind_index_key not configured, using insecure development key")
+		key := sha256.Sum256([]byte(devFieldKeySeed + ":index"))
+		indexKey = key[:]
+	} else if indexKey, err = base64.StdEncoding.DecodeString(cfg.Security.BlindIndexKey); err != nil {
+		return nil, fmt.Errorf("decode security.blind_index_key: %w", err)
+	}
+
+	return fieldcrypt.NewKeyring(keks, indexKey)
+}
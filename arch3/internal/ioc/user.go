@@ -1,9 +1,18 @@
 package ioc
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"time"
+
 	"arch3/internal/config"
+	userdomain "arch3/internal/domain/user"
 	userhandler "arch3/internal/handler/user"
+	"arch3/internal/integration/outbox"
+	"arch3/internal/job"
 	userrepo "arch3/internal/repository/user"
+	"arch3/internal/service/common"
 	userservice "arch3/internal/service/user"
 	"arch3/pkg/jwt"
 
@@ -13,28 +22,102 @@ import (
 
 // InitUserHandler 初始化 User 模块的完整依赖链
 //
-// 依赖链: DAO → Repository → SMSClient → Service → Handler
+// 依赖链: DAO → Repository(+Cache) → OTPClient/EventPublisher/Notifier/Storage/Mailer → Service → Handler
+// 用户模块的事件处理注册到 consumer
 func InitUserHandler(
 	db *gorm.DB,
 	rdb *redis.Client,
 	jwtMgr *jwt.Manager,
+	scheduler *job.Scheduler,
+	events common.EventPublisher,
+	consumer *outbox.Consumer,
+	notifier userservice.Notifier,
+	accountHooks *userservice.AccountHooks,
+	storage userservice.ObjectStorage,
 	cfg *config.Config,
 ) (*userhandler.Handler, error) {
 	// DAO 层
 	userDAO := userrepo.NewDAO(db)
 
-	// Repository 层
-	userRepo := userrepo.NewRepository(userDAO)
+	// Repository 层（手机号、邮箱、姓名、身份证号加密存储）
+	fieldKeys, err := InitKeyring(cfg)
+	if err != nil {
+		return nil, err
+	}
+	userRepo := userrepo.NewRepository(userDAO, fieldKeys)
+	if c := cfg.Cache.User; c.Enabled {
+		userRepo = userrepo.NewCachedRepository(userRepo, rdb, fieldKeys, userrepo.CacheConfig{
+			TTL:         time.Duration(c.TTL) * time.Second,
+			NegativeTTL: time.Duration(c.NegativeTTL) * time.Second,
+			Jitter:      float64(c.Jitter) / 100,
+		})
+	}
+	phoneTickets := userrepo.NewPhoneChangeTicketCache(rdb)
+
+	// 验证码客户端
+	otpClient, err := InitOTPClient(cfg, rdb, scheduler)
+	if err != nil {
+		return nil, err
+	}
+
+	// 邮箱验证
+	emailVerify, err := initEmailVerification(cfg, rdb)
+	if err != nil {
+		return nil, err
+	}
 
-	// SMS 客户端
-	smsClient, err := InitSMSClient(cfg, rdb)
+	// 实名认证
+	verifier, err := initIdentityVerifier(cfg)
 	if err != nil {
 		return nil, err
 	}
+	realName := userservice.RealNameVerification{
+		Verifier: verifier,
+		Cooldown: userrepo.NewCooldownCache(rdb),
+	}
+
+	// 账号注销
+	account := userservice.AccountDeletion{
+		CoolingOff: time.Duration(cfg.Account.DeletionCoolingOff) * 24 * time.Hour,
+		Hooks:      accountHooks,
+
+		InactiveAfter: time.Duration(cfg.Retention.UnverifiedUsers) * 24 * time.Hour,
+	}
 
 	// Service 层
-	userSvc := userservice.NewService(smsClient, userRepo, jwtMgr)
+	userSvc := userservice.NewService(otpClient, InitTransactor(cfg, db), events, userRepo, userrepo.NewLoginHistoryRepository(db), jwtMgr, notifier, storage, phoneTickets, emailVerify, realName, account)
+	scheduler.Register("account_deletion", time.Duration(cfg.Account.PurgeInterval)*time.Minute, userSvc.PurgeDeletedAccounts)
+	if account.InactiveAfter > 0 {
+		scheduler.Register("account_inactive", time.Duration(cfg.Account.PurgeInterval)*time.Minute, userSvc.ScheduleInactiveDeletions)
+	}
+	consumer.Handle(userdomain.EventUserLoginNewDevice, userSvc.NotifyLoginNewDevice)
 
 	// Handler 层
 	return userhandler.NewHandler(userSvc, jwtMgr), nil
 }
+
+// initEmailVerification 初始化邮箱验证依赖
+// 签名密钥由 otp.secret 派生，未启用邮件服务时不支持绑定邮箱
+func initEmailVerification(cfg *config.Config, rdb *redis.Client) (userservice.EmailVerification, error) {
+	secret := []byte(cfg.OTP.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return userservice.EmailVerification{}, err
+		}
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("email-verify"))
+
+	ev := userservice.EmailVerification{
+		Cooldown:       userrepo.NewCooldownCache(rdb),
+		Secret:         mac.Sum(nil),
+		LinkURL:        cfg.Email.Verify.LinkURL,
+		TTL:            time.Duration(cfg.Email.Verify.TTL) * time.Minute,
+		ResendInterval: time.Duration(cfg.Email.Verify.ResendInterval) * time.Second,
+	}
+	if cfg.Email.Enabled {
+		ev.Mailer = initEmailClient(cfg)
+	}
+	return ev, nil
+}
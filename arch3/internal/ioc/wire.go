@@ -1,11 +1,14 @@
 package ioc
 
 import (
+	"fmt"
 	"time"
 
 	"arch3/internal/config"
 	"arch3/internal/handler/middleware"
+	"arch3/internal/job"
 	"arch3/internal/router"
+	"arch3/pkg/dbreplica"
 	"arch3/pkg/jwt"
 
 	"github.com/cloudwego/hertz/pkg/app/server"
@@ -16,24 +19,33 @@ import (
 // Infrastructure 基础设施资源
 // 用于统一管理需要在应用关闭时释放的资源
 type Infrastructure struct {
-	DB    *gorm.DB
-	Redis *redis.Client
+	DB       *gorm.DB
+	Replicas *dbreplica.Resolver // 未配置从库时为 nil
+	Redis    *redis.Client
 }
 
 // Container 依赖注入容器
 // 包含应用运行所需的所有核心组件
 type Container struct {
-	Infra   *Infrastructure
-	Tracing *TracingManager
-	JWT     *jwt.Manager
-	Server  *server.Hertz
-	Router  *router.Router
+	Infra     *Infrastructure
+	Tracing   *TracingManager
+	JWT       *jwt.Manager
+	Scheduler *job.Scheduler
+	Server    *server.Hertz
+	Router    *router.Router
 }
 
 // Close 关闭所有基础设施资源
 func (i *Infrastructure) Close() error {
 	var errs []error
 
+	// 停止从库健康检查并关闭从库连接
+	if i.Replicas != nil {
+		if err := i.Replicas.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	// 关闭数据库连接
 	if i.DB != nil {
 		if sqlDB, err := i.DB.DB(); err == nil {
@@ -61,9 +73,9 @@ func (i *Infrastructure) Close() error {
 // 初始化顺序:
 //  1. 基础设施层: DB, Redis
 //  2. 可观测性层: Tracing, Metrics
-//  3. 通用组件层: JWT
+//  3. 通用组件层: JWT, Scheduler, Storage
 //  4. HTTP 层: Server, Middleware
-//  5. 业务模块层: UserHandler
+//  5. 业务模块层: NotificationService, GroupService, EventPublisher, UserHandler, Retention
 //  6. 路由层: Router
 //
 // 扩展指南:
@@ -91,28 +103,66 @@ func ManualInitialize(cfg *config.Config, isShuttingDown router.ShutdownChecker)
 
 	// ========== 3. 通用组件层 ==========
 	jwtMgr := initJWT(cfg, infra.Redis)
+	scheduler := job.NewScheduler()
+	storage, err := InitStorage(cfg)
+	if err != nil {
+		infra.Close()
+		return nil, err
+	}
 
 	// ========== 4. HTTP 层 ==========
-	h, tracerCfg := initServer(cfg)
+	h, tracerCfg, err := initServer(cfg)
+	if err != nil {
+		infra.Close()
+		return nil, err
+	}
 	registerMiddleware(h, cfg, tracerCfg, jwtMgr)
 
 	// ========== 5. 业务模块层 ==========
-	userHandler, err := InitUserHandler(infra.DB, infra.Redis, jwtMgr, cfg)
+	notificationSvc, err := InitNotificationService(infra.DB, cfg)
+	if err != nil {
+		infra.Close()
+		return nil, err
+	}
+	notificationHandler := InitNotificationHandler(notificationSvc)
+
+	groupSvc, err := InitGroupService(infra.DB, notificationSvc, cfg)
+	if err != nil {
+		infra.Close()
+		return nil, err
+	}
+	groupHandler := InitGroupHandler(groupSvc)
+
+	accountHooks := initAccountHooks(jwtMgr, notificationSvc, groupSvc)
+
+	events, consumer, err := InitEventPublisher(cfg, infra.DB, infra.Redis, scheduler)
+	if err != nil {
+		infra.Close()
+		return nil, err
+	}
+
+	userHandler, err := InitUserHandler(infra.DB, infra.Redis, jwtMgr, scheduler, events, consumer, notificationSvc, accountHooks, storage, cfg)
 	if err != nil {
 		infra.Close()
 		return nil, err
 	}
 
+	if err := InitRetention(cfg, infra.DB, infra.Redis, scheduler); err != nil {
+		infra.Close()
+		return nil, err
+	}
+
 	// ========== 6. 路由层 ==========
-	r := router.NewRouter(cfg, userHandler, isShuttingDown)
+	r := router.NewRouter(cfg, userHandler, notificationHandler, groupHandler, isShuttingDown)
 	r.Register(h)
 
 	return &Container{
-		Infra:   infra,
-		Tracing: tracingMgr,
-		JWT:     jwtMgr,
-		Server:  h,
-		Router:  r,
+		Infra:     infra,
+		Tracing:   tracingMgr,
+		JWT:       jwtMgr,
+		Scheduler: scheduler,
+		Server:    h,
+		Router:    r,
 	}, nil
 }
 
@@ -126,6 +176,25 @@ func initInfrastructure(cfg *config.Config) (*Infrastructure, error) {
 	}
 	infra.DB = db
 
+	if cfg.DB.AutoMigrate {
+		if err := runAutoMigrate(cfg, db); err != nil {
+			infra.Close()
+			return nil, fmt.Errorf("auto migrate failed: %w", err)
+		}
+	}
+
+	replicas, err := InitReplicas(cfg, db)
+	if err != nil {
+		infra.Close()
+		return nil, err
+	}
+	infra.Replicas = replicas
+
+	if err := registerDBMetrics(cfg, db, replicas); err != nil {
+		infra.Close()
+		return nil, err
+	}
+
 	rdb, err := InitRedis(cfg)
 	if err != nil {
 		infra.Close()
@@ -0,0 +1,291 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// Action 过期数据的处理方式
+type Action string
+
+const (
+	ActionDelete    Action = "delete"    // 物理删除
+	ActionAnonymize Action = "anonymize" // 改写指定列，保留记录
+)
+
+// Policy 数据保留策略，由各模块按自己的表定义并注册
+//
+// 满足 AgeColumn < 当前时间 - MaxAge 且满足 Where 的记录为过期数据，
+// 按主键 id 升序分批处理。
+type Policy struct {
+	// Name 策略名称，用于加锁、日志与指标，全局唯一
+	Name string
+	// Table 表名
+	Table string
+	// AgeColumn 判断过期的时间列，值为 NULL 的记录不会过期
+	AgeColumn string
+	// MaxAge 保留时长
+	MaxAge time.Duration
+	// Where 额外过滤条件（SQL 片段），可引用 Args 中的命名参数与 @cutoff（过期时间点）
+	Where string
+	// Args Where 中的命名参数
+	Args map[string]any
+	// Action 处理方式
+	Action Action
+	// Set ActionAnonymize 时写入的列；写入后的记录必须不再满足 Where，否则会被重复处理
+	Set func(now time.Time) map[string]any
+	// DryRun 只统计过期数据、不修改，用于新策略上线前核对影响范围
+	DryRun bool
+}
+
+// identPattern 表名与列名，拼接到 SQL 中，只允许小写字母、数字与下划线
+var identPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// validate 校验策略定义
+func (p *Policy) validate() error {
+	switch {
+	case p.Name == "":
+		return errors.New("retention policy name is required")
+	case !identPattern.MatchString(p.Table):
+		return fmt.Errorf("retention policy %s: invalid table %q", p.Name, p.Table)
+	case !identPattern.MatchString(p.AgeColumn):
+		return fmt.Errorf("retention policy %s: invalid age column %q", p.Name, p.AgeColumn)
+	case p.MaxAge <= 0:
+		return fmt.Errorf("retention policy %s: max age must be positive", p.Name)
+	case p.Action == ActionAnonymize && p.Set == nil:
+		return fmt.Errorf("retention policy %s: anonymize requires Set", p.Name)
+	case p.Action != ActionDelete && p.Action != ActionAnonymize:
+		return fmt.Errorf("retention policy %s: unknown action %q", p.Name, p.Action)
+	}
+	return nil
+}
+
+// RetentionStore 执行保留策略的存储（由使用方定义）
+// 各方法只处理满足策略的记录，写入时重新校验条件，期间被修改而不再过期的记录不受影响
+type RetentionStore interface {
+	// Count 统计过期记录数
+	Count(ctx context.Context, p *Policy, cutoff time.Time) (int64, error)
+	// Delete 删除至多 limit 条过期记录，返回删除数
+	Delete(ctx context.Context, p *Policy, cutoff time.Time, limit int) (int64, error)
+	// Anonymize 对至多 limit 条过期记录写入 columns，返回更新数
+	Anonymize(ctx context.Context, p *Policy, cutoff time.Time, limit int, columns map[string]any) (int64, error)
+}
+
+// Locker 跨实例互斥锁（由使用方定义）
+type Locker interface {
+	// TryLock 尝试加锁，ttl 到期自动释放；已被其他实例持有时返回 ok=false
+	TryLock(ctx context.Context, key string, ttl time.Duration) (unlock func(ctx context.Context) error, ok bool, err error)
+}
+
+// RetentionConfig 数据保留任务配置
+type RetentionConfig struct {
+	BatchSize  int           // 每批处理的记录数
+	MaxBatches int           // 每个策略每轮最多处理的批数，剩余数据在下一轮处理
+	BatchPause time.Duration // 批之间的间隔，降低对主库的压力
+	LockTTL    time.Duration // 策略锁时长，应大于一轮的执行耗时
+	DryRun     bool          // 所有策略只统计、不修改
+}
+
+// DefaultRetentionConfig 默认保留任务配置
+func DefaultRetentionConfig() RetentionConfig {
+	return RetentionConfig{
+		BatchSize:  500,
+		MaxBatches: 20,
+		BatchPause: 100 * time.Millisecond,
+		LockTTL:    10 * time.Minute,
+	}
+}
+
+// retentionLockPrefix 策略锁的 key 前缀，retention:{policy}
+const retentionLockPrefix = "retention:"
+
+// 保留任务指标:
+//   - arch3.retention.rows (counter) - 处理的记录数，dry_run 时为统计到的过期记录数
+//   - arch3.retention.runs (counter) - 策略执行次数，result 为 success、failed 或 skipped（锁被其他实例持有）
+//   - arch3.retention.duration (histogram) - 策略单轮执行耗时（秒）
+type retentionMetrics struct {
+	rows     metric.Int64Counter
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+// Retention 数据保留任务
+//
+// 按注册顺序逐个执行策略，单个策略失败不影响其他策略。
+// 每个策略执行前加跨实例锁，多实例部署时同一策略同一时刻只有一个实例执行。
+type Retention struct {
+	store    RetentionStore
+	locker   Locker
+	cfg      RetentionConfig
+	policies []Policy
+	metrics  retentionMetrics
+}
+
+// NewRetention 创建数据保留任务并注册指标
+func NewRetention(store RetentionStore, locker Locker, meter metric.Meter, cfg RetentionConfig) (*Retention, error) {
+	r := &Retention{store: store, locker: locker, cfg: cfg}
+
+	var err error
+	r.metrics.rows, err = meter.Int64Counter("arch3.retention.rows",
+		metric.WithDescription("Number of rows deleted or anonymized by retention policies"),
+		metric.WithUnit("{row}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.metrics.runs, err = meter.Int64Counter("arch3.retention.runs",
+		metric.WithDescription("Number of retention policy runs"),
+		metric.WithUnit("{run}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	r.metrics.duration, err = meter.Float64Histogram("arch3.retention.duration",
+		metric.WithDescription("Duration of a retention policy run"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Register 注册保留策略，须在调度器 Start 之前调用
+func (r *Retention) Register(p Policy) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	for _, existing := range r.policies {
+		if existing.Name == p.Name {
+			return fmt.Errorf("retention policy %s already registered", p.Name)
+		}
+	}
+	r.policies = append(r.policies, p)
+	return nil
+}
+
+// Policies 已注册的策略
+func (r *Retention) Policies() []Policy {
+	return r.policies
+}
+
+// RunOnce 执行一轮所有策略，供调度器周期调用
+func (r *Retention) RunOnce(ctx context.Context) error {
+	var errs []error
+	for i := range r.policies {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := r.run(ctx, &r.policies[i]); err != nil {
+			errs = append(errs, fmt.Errorf("retention %s: %w", r.policies[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// run 加锁后执行单个策略
+func (r *Retention) run(ctx context.Context, p *Policy) error {
+	ctx, span := tracer.Start(ctx, "job.Retention")
+	defer span.End()
+
+	dryRun := r.cfg.DryRun || p.DryRun
+	attrs := metric.WithAttributes(
+		attribute.String("policy", p.Name),
+		attribute.String("action", string(p.Action)),
+		attribute.Bool("dry_run", dryRun),
+	)
+	span.SetAttributes(
+		tracer.String("retention.policy", p.Name),
+		tracer.Bool("retention.dry_run", dryRun),
+	)
+
+	unlock, ok, err := r.locker.TryLock(ctx, retentionLockPrefix+p.Name, r.cfg.LockTTL)
+	if err != nil {
+		tracer.RecordError(span, err)
+		r.metrics.runs.Add(ctx, 1, attrs, metric.WithAttributes(attribute.String("result", "failed")))
+		return fmt.Errorf("acquire lock: %w", err)
+	}
+	if !ok {
+		r.metrics.runs.Add(ctx, 1, attrs, metric.WithAttributes(attribute.String("result", "skipped")))
+		return nil
+	}
+	defer func() {
+		if err := unlock(context.WithoutCancel(ctx)); err != nil {
+			logger.Ctx(ctx).Warn("release retention lock failed", zap.String("policy", p.Name), zap.Error(err))
+		}
+	}()
+
+	start := time.Now()
+	cutoff := start.Add(-p.MaxAge)
+	var rows int64
+	if dryRun {
+		rows, err = r.store.Count(ctx, p, cutoff)
+	} else {
+		rows, err = r.apply(ctx, p, cutoff)
+	}
+	elapsed := time.Since(start)
+
+	r.metrics.rows.Add(ctx, rows, attrs)
+	r.metrics.duration.Record(ctx, elapsed.Seconds(), attrs)
+	result := "success"
+	if err != nil {
+		result = "failed"
+		tracer.RecordError(span, err)
+	}
+	r.metrics.runs.Add(ctx, 1, attrs, metric.WithAttributes(attribute.String("result", result)))
+	span.SetAttributes(tracer.Int64("retention.rows", rows))
+
+	if rows > 0 || err != nil {
+		fields := []zap.Field{
+			zap.String("policy", p.Name),
+			zap.String("action", string(p.Action)),
+			zap.Bool("dry_run", dryRun),
+			zap.Int64("rows", rows),
+			zap.Time("cutoff", cutoff),
+			zap.Duration("elapsed", elapsed),
+		}
+		if err != nil {
+			logger.Ctx(ctx).Error("retention policy failed", append(fields, zap.Error(err))...)
+		} else {
+			logger.Ctx(ctx).Info("retention policy applied", fields...)
+		}
+	}
+	return err
+}
+
+// apply 分批处理过期数据，直到没有剩余或达到每轮批数上限
+func (r *Retention) apply(ctx context.Context, p *Policy, cutoff time.Time) (int64, error) {
+	var total int64
+	for batch := 0; batch < r.cfg.MaxBatches; batch++ {
+		if batch > 0 && r.cfg.BatchPause > 0 {
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(r.cfg.BatchPause):
+			}
+		}
+
+		var n int64
+		var err error
+		if p.Action == ActionDelete {
+			n, err = r.store.Delete(ctx, p, cutoff, r.cfg.BatchSize)
+		} else {
+			n, err = r.store.Anonymize(ctx, p, cutoff, r.cfg.BatchSize, p.Set(time.Now()))
+		}
+		total += n
+		if err != nil || n < int64(r.cfg.BatchSize) {
+			return total, err
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,261 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// fakeStore 内存保留策略存储，每张表保存记录的时间列
+type fakeStore struct {
+	rows       map[string][]time.Time
+	anonymized map[string]int
+	calls      int
+	err        error
+}
+
+func newFakeStore(table string, ages ...time.Duration) *fakeStore {
+	s := &fakeStore{rows: make(map[string][]time.Time), anonymized: make(map[string]int)}
+	for _, age := range ages {
+		s.rows[table] = append(s.rows[table], time.Now().Add(-age))
+	}
+	return s
+}
+
+func (s *fakeStore) Count(_ context.Context, p *Policy, cutoff time.Time) (int64, error) {
+	var n int64
+	for _, t := range s.rows[p.Table] {
+		if t.Before(cutoff) {
+			n++
+		}
+	}
+	return n, s.err
+}
+
+func (s *fakeStore) Delete(_ context.Context, p *Policy, cutoff time.Time, limit int) (int64, error) {
+	s.calls++
+	if s.err != nil {
+		return 0, s.err
+	}
+	var kept []time.Time
+	var n int64
+	for _, t := range s.rows[p.Table] {
+		if t.Before(cutoff) && n < int64(limit) {
+			n++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	s.rows[p.Table] = kept
+	return n, nil
+}
+
+func (s *fakeStore) Anonymize(ctx context.Context, p *Policy, cutoff time.Time, limit int, columns map[string]any) (int64, error) {
+	if columns == nil {
+		return 0, errors.New("missing columns")
+	}
+	n, err := s.Delete(ctx, p, cutoff, limit)
+	s.anonymized[p.Table] += int(n)
+	return n, err
+}
+
+// fakeLocker 内存锁
+type fakeLocker struct {
+	held map[string]bool
+	err  error
+}
+
+func (l *fakeLocker) TryLock(_ context.Context, key string, _ time.Duration) (func(context.Context) error, bool, error) {
+	if l.err != nil {
+		return nil, false, l.err
+	}
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+	return func(context.Context) error {
+		delete(l.held, key)
+		return nil
+	}, true, nil
+}
+
+func newTestRetention(t *testing.T, store RetentionStore, locker Locker, cfg RetentionConfig) *Retention {
+	t.Helper()
+	r, err := NewRetention(store, locker, noop.NewMeterProvider().Meter("test"), cfg)
+	if err != nil {
+		t.Fatalf("NewRetention() error = %v", err)
+	}
+	return r
+}
+
+func deletePolicy(name string) Policy {
+	return Policy{Name: name, Table: name, AgeColumn: "created_at", MaxAge: 24 * time.Hour, Action: ActionDelete}
+}
+
+func TestRetention_Register(t *testing.T) {
+	anonymize := deletePolicy("anon")
+	anonymize.Action = ActionAnonymize
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr string
+	}{
+		{name: "有效策略", policy: deletePolicy("logs")},
+		{name: "重复名称", policy: deletePolicy("dup"), wantErr: "already registered"},
+		{name: "缺少名称", policy: Policy{Table: "logs", AgeColumn: "created_at", MaxAge: time.Hour, Action: ActionDelete}, wantErr: "name is required"},
+		{name: "非法表名", policy: Policy{Name: "x", Table: "logs; DROP", AgeColumn: "created_at", MaxAge: time.Hour, Action: ActionDelete}, wantErr: "invalid table"},
+		{name: "非法列名", policy: Policy{Name: "x", Table: "logs", AgeColumn: "Created", MaxAge: time.Hour, Action: ActionDelete}, wantErr: "invalid age column"},
+		{name: "保留时长为 0", policy: Policy{Name: "x", Table: "logs", AgeColumn: "created_at", Action: ActionDelete}, wantErr: "max age"},
+		{name: "匿名化缺少 Set", policy: anonymize, wantErr: "requires Set"},
+		{name: "未知处理方式", policy: Policy{Name: "x", Table: "logs", AgeColumn: "created_at", MaxAge: time.Hour, Action: "archive"}, wantErr: "unknown action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestRetention(t, newFakeStore("logs"), &fakeLocker{held: map[string]bool{}}, DefaultRetentionConfig())
+			if err := r.Register(deletePolicy("dup")); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			err := r.Register(tt.policy)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRetention_RunOnce(t *testing.T) {
+	day := 24 * time.Hour
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name          string
+		ages          []time.Duration
+		cfg           func(c *RetentionConfig)
+		policy        func(p *Policy)
+		setup         func(s *fakeStore, l *fakeLocker)
+		wantRemaining int
+		wantCalls     int
+		wantErr       error
+	}{
+		{
+			name:          "分批删除过期记录，保留未过期记录",
+			ages:          []time.Duration{2 * day, 3 * day, 4 * day, 5 * day, time.Hour},
+			wantRemaining: 1,
+			wantCalls:     3, // 2 + 2 + 0
+		},
+		{
+			name:          "达到每轮批数上限后停止",
+			ages:          []time.Duration{2 * day, 3 * day, 4 * day, 5 * day, 6 * day},
+			cfg:           func(c *RetentionConfig) { c.MaxBatches = 1 },
+			wantRemaining: 3,
+			wantCalls:     1,
+		},
+		{
+			name:          "全局 dry-run 不修改",
+			ages:          []time.Duration{2 * day, 3 * day},
+			cfg:           func(c *RetentionConfig) { c.DryRun = true },
+			wantRemaining: 2,
+		},
+		{
+			name:          "策略 dry-run 不修改",
+			ages:          []time.Duration{2 * day, 3 * day},
+			policy:        func(p *Policy) { p.DryRun = true },
+			wantRemaining: 2,
+		},
+		{
+			name:          "锁被其他实例持有时跳过",
+			ages:          []time.Duration{2 * day},
+			setup:         func(_ *fakeStore, l *fakeLocker) { l.held["retention:logs"] = true },
+			wantRemaining: 1,
+		},
+		{
+			name:          "加锁失败返回错误",
+			ages:          []time.Duration{2 * day},
+			setup:         func(_ *fakeStore, l *fakeLocker) { l.err = errBoom },
+			wantRemaining: 1,
+			wantErr:       errBoom,
+		},
+		{
+			name:          "存储失败返回错误",
+			ages:          []time.Duration{2 * day},
+			setup:         func(s *fakeStore, _ *fakeLocker) { s.err = errBoom },
+			wantRemaining: 1,
+			wantCalls:     1,
+			wantErr:       errBoom,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore("logs", tt.ages...)
+			locker := &fakeLocker{held: map[string]bool{}}
+			if tt.setup != nil {
+				tt.setup(store, locker)
+			}
+			cfg := RetentionConfig{BatchSize: 2, MaxBatches: 10, LockTTL: time.Minute}
+			if tt.cfg != nil {
+				tt.cfg(&cfg)
+			}
+			policy := deletePolicy("logs")
+			if tt.policy != nil {
+				tt.policy(&policy)
+			}
+			r := newTestRetention(t, store, locker, cfg)
+			if err := r.Register(policy); err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+
+			err := r.RunOnce(context.Background())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := len(store.rows["logs"]); got != tt.wantRemaining {
+				t.Errorf("Expected %d remaining rows, got %d", tt.wantRemaining, got)
+			}
+			if store.calls != tt.wantCalls {
+				t.Errorf("Expected %d store calls, got %d", tt.wantCalls, store.calls)
+			}
+			if tt.setup == nil && len(locker.held) != 0 {
+				t.Errorf("Expected lock released, got %v", locker.held)
+			}
+		})
+	}
+}
+
+func TestRetention_RunOnceRunsEachPolicy(t *testing.T) {
+	store := newFakeStore("logs", 48*time.Hour)
+	store.rows["events"] = []time.Time{time.Now().Add(-48 * time.Hour)}
+	locker := &fakeLocker{held: map[string]bool{"retention:logs": true}}
+	r := newTestRetention(t, store, locker, RetentionConfig{BatchSize: 10, MaxBatches: 1, LockTTL: time.Minute})
+
+	anonymize := deletePolicy("events")
+	anonymize.Action = ActionAnonymize
+	anonymize.Set = func(time.Time) map[string]any { return map[string]any{"ip": ""} }
+	for _, p := range []Policy{deletePolicy("logs"), anonymize} {
+		if err := r.Register(p); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	if err := r.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+	if len(store.rows["logs"]) != 1 {
+		t.Errorf("Expected locked policy skipped, got %d rows", len(store.rows["logs"]))
+	}
+	if store.anonymized["events"] != 1 {
+		t.Errorf("Expected 1 anonymized event, got %d", store.anonymized["events"])
+	}
+}
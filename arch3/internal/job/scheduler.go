@@ -1 +1,107 @@
 package job
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"arch3/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Func 后台任务函数，每次调度执行一轮
+type Func func(ctx context.Context) error
+
+// task 已注册的周期任务
+type task struct {
+	name     string
+	interval time.Duration
+	fn       Func
+}
+
+// Scheduler 进程内周期任务调度器
+//
+// 每个任务在独立 goroutine 中按固定间隔执行，同一任务不会并发运行。
+// 多实例部署时各实例都会执行，任务自身需保证并发安全（如领取任务时加租约）。
+type Scheduler struct {
+	tasks []task
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler 创建调度器
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register 注册周期任务，须在 Start 之前调用
+func (s *Scheduler) Register(name string, interval time.Duration, fn Func) {
+	s.tasks = append(s.tasks, task{name: name, interval: interval, fn: fn})
+}
+
+// Start 启动所有任务
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, t := range s.tasks {
+		s.wg.Add(1)
+		go s.loop(ctx, t)
+	}
+	logger.Info("Job scheduler started", zap.Int("tasks", len(s.tasks)))
+}
+
+// Stop 停止调度并等待正在执行的任务完成
+// ctx 到期时不再等待，返回 ctx.Err()
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop 按间隔执行单个任务
+func (s *Scheduler) loop(ctx context.Context, t task) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.run(ctx, t)
+		}
+	}
+}
+
+// run 执行一轮任务，panic 不会终止调度
+func (s *Scheduler) run(ctx context.Context, t task) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Job panicked", zap.String("job", t.name), zap.String("panic", fmt.Sprint(r)))
+		}
+	}()
+
+	if err := t.fn(ctx); err != nil && ctx.Err() == nil {
+		logger.Error("Job failed", zap.String("job", t.name), zap.Error(err))
+	}
+}
@@ -0,0 +1,35 @@
+// Package migration 内嵌数据库迁移脚本
+//
+// 表结构以 sql/{驱动}/ 下的迁移文件为准，修改 Entity 的 GORM 标签不会改变数据库。
+// 每个驱动各有一套迁移，版本号保持一致；新增迁移:
+// go run ./cmd/server migrate create {name}，会在所有驱动目录下生成同一版本的文件，
+// 分别编写各驱动的 up 与 down。
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// Dir 迁移文件在仓库中的根路径，供 migrate create 使用
+const Dir = "internal/migration/sql"
+
+// Drivers 提供迁移脚本的驱动
+var Drivers = []string{"mysql", "postgres", "sqlite"}
+
+//go:embed sql
+var files embed.FS
+
+// FS 返回指定驱动的迁移文件系统，根目录下直接存放迁移文件
+func FS(driver string) (fs.FS, error) {
+	sub, err := fs.Sub(files, path.Join("sql", driver))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.ReadDir(sub, "."); err != nil {
+		return nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+	return sub, nil
+}
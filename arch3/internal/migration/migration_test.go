@@ -0,0 +1,85 @@
+package migration_test
+
+import (
+	"context"
+	"testing"
+
+	"arch3/internal/migration"
+	"arch3/internal/repository/dbtest"
+	"arch3/pkg/migrate"
+)
+
+// TestEmbeddedMigrations 各驱动的迁移文件命名合法、版本连续且一致
+func TestEmbeddedMigrations(t *testing.T) {
+	var names []string
+	for _, driver := range migration.Drivers {
+		t.Run(driver, func(t *testing.T) {
+			fsys, err := migration.FS(driver)
+			if err != nil {
+				t.Fatalf("FS() error = %v", err)
+			}
+			migrations, err := migrate.Load(fsys)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if len(migrations) == 0 {
+				t.Fatal("Expected embedded migrations")
+			}
+			for i, mig := range migrations {
+				if mig.Version != int64(i+1) {
+					t.Errorf("Expected version %d, got %s", i+1, mig)
+				}
+				if len(migrate.SplitStatements(mig.Up)) == 0 || len(migrate.SplitStatements(mig.Down)) == 0 {
+					t.Errorf("Expected statements in both directions of %s", mig)
+				}
+				if i >= len(names) {
+					names = append(names, mig.String())
+				} else if names[i] != mig.String() {
+					t.Errorf("Expected %s to match other drivers, got %s", names[i], mig)
+				}
+			}
+		})
+	}
+
+	if _, err := migration.FS("oracle"); err == nil {
+		t.Error("Expected error for unsupported driver, got nil")
+	}
+}
+
+// TestRoundTrip_SQLite 全部迁移可以回滚并重新执行
+func TestRoundTrip_SQLite(t *testing.T) {
+	db := dbtest.SQLite(t) // 已执行全部迁移
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys, _ := migration.FS("sqlite")
+	m, err := migrate.New(sqlDB, "sqlite", fsys)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	ctx := context.Background()
+	total := len(m.Migrations())
+
+	reverted, err := m.Down(ctx, total)
+	if err != nil || len(reverted) != total {
+		t.Fatalf("Expected %d reverted, got %d, %v", total, len(reverted), err)
+	}
+	if db.Migrator().HasTable("users") {
+		t.Error("Expected users dropped after full rollback")
+	}
+
+	applied, err := m.Up(ctx)
+	if err != nil || len(applied) != total {
+		t.Fatalf("Expected %d applied, got %d, %v", total, len(applied), err)
+	}
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	for _, st := range statuses {
+		if !st.Applied || st.Dirty || st.AppliedAt.IsZero() {
+			t.Errorf("Expected %06d applied and clean, got %+v", st.Version, st)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS user_login_history;
+DROP TABLE IF EXISTS user_status_changes;
+DROP TABLE IF EXISTS users;
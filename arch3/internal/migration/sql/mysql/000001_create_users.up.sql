@@ -0,0 +1,69 @@
+-- 用户表及其附属记录
+-- 手机号、邮箱、姓名、身份证号为信封加密后的密文，*_hash 为对应的盲索引
+
+CREATE TABLE users (
+    id                    BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id               VARCHAR(32)     NOT NULL,
+    group_id              VARCHAR(32)     NULL,
+    user_name             VARCHAR(50)     NOT NULL,
+    real_name             VARCHAR(512)    NULL,
+    password_hash         CHAR(64)        NOT NULL,
+    email                 VARCHAR(512)    NULL,
+    email_hash            CHAR(64)        NULL,
+    email_verified        BOOLEAN         NOT NULL DEFAULT FALSE,
+    phone_number          VARCHAR(255)    NOT NULL,
+    phone_hash            CHAR(64)        NULL,
+    phone_tail_hash       CHAR(64)        NULL,
+    avatar_url            VARCHAR(255)    NULL,
+    gender                ENUM('male','female','other') DEFAULT 'other',
+    created_at            DATETIME(3)     NULL,
+    updated_at            DATETIME(3)     NULL,
+    status                ENUM('real_name_verified','real_name_unverified','banned','under_review') NOT NULL DEFAULT 'real_name_unverified',
+    id_number             VARCHAR(255)    NULL,
+    id_number_hash        CHAR(64)        NULL,
+    source                VARCHAR(50)     NULL,
+    device_id             VARCHAR(128)    NULL,
+    deletion_scheduled_at DATETIME(3)     NULL,
+    deleted_at            DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_users_user_id (user_id),
+    UNIQUE KEY idx_users_email_hash (email_hash),
+    UNIQUE KEY idx_users_phone_hash (phone_hash),
+    UNIQUE KEY idx_users_id_number_hash (id_number_hash),
+    KEY idx_users_user_name (user_name),
+    KEY idx_users_phone_tail_hash (phone_tail_hash),
+    KEY idx_users_source (source),
+    KEY idx_users_deletion_scheduled_at (deletion_scheduled_at),
+    KEY idx_users_deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_status_changes (
+    id          BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id     VARCHAR(32)     NOT NULL,
+    from_status VARCHAR(32)     NOT NULL,
+    to_status   VARCHAR(32)     NOT NULL,
+    reason      VARCHAR(200)    NOT NULL,
+    operator_id VARCHAR(32)     NOT NULL,
+    created_at  DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_user_created (user_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_login_history (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id      VARCHAR(32)     NOT NULL,
+    event        VARCHAR(16)     NOT NULL,
+    device_id    VARCHAR(128)    NOT NULL,
+    platform     VARCHAR(16)     NOT NULL,
+    app_version  VARCHAR(64)     NOT NULL,
+    channel      VARCHAR(64)     NOT NULL,
+    utm_source   VARCHAR(64)     NOT NULL,
+    utm_medium   VARCHAR(64)     NOT NULL,
+    utm_campaign VARCHAR(64)     NOT NULL,
+    ip           VARCHAR(45)     NOT NULL,
+    user_agent   VARCHAR(255)    NOT NULL,
+    created_at   DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    KEY idx_user_created (user_id, created_at),
+    KEY idx_user_login_history_channel (channel)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,26 @@
+-- 站内信与通知偏好
+
+CREATE TABLE notification_messages (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    message_id VARCHAR(32)     NOT NULL,
+    user_id    VARCHAR(32)     NOT NULL,
+    template   VARCHAR(64)     NOT NULL,
+    title      VARCHAR(255)    NOT NULL,
+    body       TEXT            NOT NULL,
+    read_at    DATETIME(3)     NULL,
+    created_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_notification_messages_message_id (message_id),
+    KEY idx_user_created (user_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE notification_preferences (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id    VARCHAR(32)     NOT NULL,
+    template   VARCHAR(64)     NOT NULL,
+    channel    VARCHAR(16)     NOT NULL,
+    enabled    BOOLEAN         NOT NULL,
+    updated_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uk_user_template_channel (user_id, template, channel)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,42 @@
+-- 组织、成员与邀请
+-- 组织表名避开 MySQL 8 保留字 groups；group_members.user_id 唯一，一个用户同时只能属于一个组织
+
+CREATE TABLE user_groups (
+    id         BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    group_id   VARCHAR(32)     NOT NULL,
+    name       VARCHAR(64)     NOT NULL,
+    owner_id   VARCHAR(32)     NOT NULL,
+    created_at DATETIME(3)     NULL,
+    updated_at DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_user_groups_group_id (group_id),
+    KEY idx_user_groups_owner_id (owner_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE group_members (
+    id        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    group_id  VARCHAR(32)     NOT NULL,
+    user_id   VARCHAR(32)     NOT NULL,
+    role      VARCHAR(16)     NOT NULL,
+    joined_at DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_group_members_user_id (user_id),
+    KEY idx_group_members_group_id (group_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE group_invitations (
+    id            BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    invitation_id VARCHAR(32)     NOT NULL,
+    group_id      VARCHAR(32)     NOT NULL,
+    invitee_id    VARCHAR(32)     NOT NULL,
+    inviter_id    VARCHAR(32)     NOT NULL,
+    role          VARCHAR(16)     NOT NULL,
+    status        VARCHAR(16)     NOT NULL,
+    created_at    DATETIME(3)     NULL,
+    expires_at    DATETIME(3)     NOT NULL,
+    responded_at  DATETIME(3)     NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_group_invitations_invitation_id (invitation_id),
+    KEY idx_group_invitations_group_id (group_id),
+    KEY idx_invitee_status (invitee_id, status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
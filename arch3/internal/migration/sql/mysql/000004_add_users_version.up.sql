@@ -0,0 +1,4 @@
+-- 用户乐观锁版本号，经用户仓储的每次写入递增，更新时以版本号作为前置条件
+-- 已有数据从 1 开始
+
+ALTER TABLE users ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
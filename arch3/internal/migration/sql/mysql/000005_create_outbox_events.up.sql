@@ -0,0 +1,21 @@
+-- 领域事件 outbox
+
+CREATE TABLE outbox_events (
+    id           BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    event_id     VARCHAR(32)     NOT NULL,
+    event_type   VARCHAR(64)     NOT NULL,
+    aggregate_id VARCHAR(64)     NOT NULL,
+    payload      TEXT            NOT NULL,
+    trace        VARCHAR(512)    NULL,
+    status       VARCHAR(16)     NOT NULL,
+    attempts     INT             NOT NULL DEFAULT 0,
+    last_error   VARCHAR(512)    NULL,
+    available_at DATETIME(3)     NOT NULL,
+    occurred_at  DATETIME(3)     NOT NULL,
+    published_at DATETIME(3)     NULL,
+    updated_at   DATETIME(3)     NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_outbox_events_event_id (event_id),
+    KEY idx_outbox_events_status_available (status, available_at),
+    KEY idx_outbox_events_status_updated (status, updated_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,11 @@
+ALTER TABLE group_invitations RENAME INDEX idx_group_invitations_invitee_status TO idx_invitee_status;
+ALTER TABLE notification_preferences RENAME INDEX uk_notification_preferences_user_template_channel TO uk_user_template_channel;
+ALTER TABLE notification_messages RENAME INDEX idx_notification_messages_user_created TO idx_user_created;
+ALTER TABLE user_login_history RENAME INDEX idx_user_login_history_user_created TO idx_user_created;
+ALTER TABLE user_status_changes RENAME INDEX idx_user_status_changes_user_created TO idx_user_created;
+
+ALTER TABLE users
+    DROP CHECK chk_users_status,
+    DROP CHECK chk_users_gender,
+    MODIFY COLUMN status ENUM('real_name_verified','real_name_unverified','banned','under_review') NOT NULL DEFAULT 'real_name_unverified',
+    MODIFY COLUMN gender ENUM('male','female','other') DEFAULT 'other';
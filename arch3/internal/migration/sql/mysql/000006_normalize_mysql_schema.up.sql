@@ -0,0 +1,16 @@
+-- 统一 MySQL 表结构与 PostgreSQL、SQLite 的迁移
+-- 枚举列改为 VARCHAR + CHECK 约束；索引名加表名前缀，避免不同表的同名索引
+
+UPDATE users SET gender = 'other' WHERE gender IS NULL;
+
+ALTER TABLE users
+    MODIFY COLUMN gender VARCHAR(16) NOT NULL DEFAULT 'other',
+    MODIFY COLUMN status VARCHAR(32) NOT NULL DEFAULT 'real_name_unverified',
+    ADD CONSTRAINT chk_users_gender CHECK (gender IN ('male', 'female', 'other')),
+    ADD CONSTRAINT chk_users_status CHECK (status IN ('real_name_verified', 'real_name_unverified', 'banned', 'under_review'));
+
+ALTER TABLE user_status_changes RENAME INDEX idx_user_created TO idx_user_status_changes_user_created;
+ALTER TABLE user_login_history RENAME INDEX idx_user_created TO idx_user_login_history_user_created;
+ALTER TABLE notification_messages RENAME INDEX idx_user_created TO idx_notification_messages_user_created;
+ALTER TABLE notification_preferences RENAME INDEX uk_user_template_channel TO uk_notification_preferences_user_template_channel;
+ALTER TABLE group_invitations RENAME INDEX idx_invitee_status TO idx_group_invitations_invitee_status;
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP INDEX idx_users_verified_email_hash,
+    DROP COLUMN verified_email_hash,
+    DROP INDEX idx_users_email_hash,
+    ADD UNIQUE KEY idx_users_email_hash (email_hash);
@@ -0,0 +1,9 @@
+-- 邮箱唯一约束只作用于已验证的邮箱
+-- 未验证的邮箱不再占用唯一位置，避免他人抢先绑定未验证的地址；验证时再检查唯一性
+-- MySQL 不支持部分索引，通过生成列 verified_email_hash（仅已验证时等于 email_hash）建立唯一索引
+
+ALTER TABLE users
+    ADD COLUMN verified_email_hash CHAR(64) AS (IF(email_verified, email_hash, NULL)) STORED,
+    DROP INDEX idx_users_email_hash,
+    ADD INDEX idx_users_email_hash (email_hash),
+    ADD UNIQUE KEY idx_users_verified_email_hash (verified_email_hash);
@@ -0,0 +1,3 @@
+ALTER TABLE group_invitations
+    DROP INDEX idx_group_invitations_invitee_phone_status,
+    DROP COLUMN invitee_phone_hash;
@@ -0,0 +1,6 @@
+-- 组织邀请按手机号记录被邀请人，未注册的手机号也可邀请，注册时绑定到新用户
+-- invitee_phone_hash 为手机号盲索引；未绑定的邀请 invitee_id 为空字符串
+
+ALTER TABLE group_invitations
+    ADD COLUMN invitee_phone_hash CHAR(64) NULL,
+    ADD INDEX idx_group_invitations_invitee_phone_status (invitee_phone_hash, status);
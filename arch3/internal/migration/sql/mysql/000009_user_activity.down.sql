@@ -0,0 +1,4 @@
+ALTER TABLE users
+    DROP INDEX idx_users_last_active_at,
+    DROP COLUMN last_active_at,
+    DROP COLUMN onboarded_at;
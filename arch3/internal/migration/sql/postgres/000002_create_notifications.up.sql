@@ -0,0 +1,24 @@
+-- 站内信与通知偏好
+
+CREATE TABLE notification_messages (
+    id         BIGSERIAL    PRIMARY KEY,
+    message_id VARCHAR(32)  NOT NULL,
+    user_id    VARCHAR(32)  NOT NULL,
+    template   VARCHAR(64)  NOT NULL,
+    title      VARCHAR(255) NOT NULL,
+    body       TEXT         NOT NULL,
+    read_at    TIMESTAMPTZ  NULL,
+    created_at TIMESTAMPTZ  NULL
+);
+CREATE UNIQUE INDEX idx_notification_messages_message_id ON notification_messages (message_id);
+CREATE INDEX idx_notification_messages_user_created ON notification_messages (user_id, created_at);
+
+CREATE TABLE notification_preferences (
+    id         BIGSERIAL   PRIMARY KEY,
+    user_id    VARCHAR(32) NOT NULL,
+    template   VARCHAR(64) NOT NULL,
+    channel    VARCHAR(16) NOT NULL,
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX uk_notification_preferences_user_template_channel ON notification_preferences (user_id, template, channel);
@@ -0,0 +1,39 @@
+-- 组织、成员与邀请
+-- group_members.user_id 唯一，一个用户同时只能属于一个组织
+
+CREATE TABLE user_groups (
+    id         BIGSERIAL   PRIMARY KEY,
+    group_id   VARCHAR(32) NOT NULL,
+    name       VARCHAR(64) NOT NULL,
+    owner_id   VARCHAR(32) NOT NULL,
+    created_at TIMESTAMPTZ NULL,
+    updated_at TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_user_groups_group_id ON user_groups (group_id);
+CREATE INDEX idx_user_groups_owner_id ON user_groups (owner_id);
+
+CREATE TABLE group_members (
+    id        BIGSERIAL   PRIMARY KEY,
+    group_id  VARCHAR(32) NOT NULL,
+    user_id   VARCHAR(32) NOT NULL,
+    role      VARCHAR(16) NOT NULL,
+    joined_at TIMESTAMPTZ NOT NULL
+);
+CREATE UNIQUE INDEX idx_group_members_user_id ON group_members (user_id);
+CREATE INDEX idx_group_members_group_id ON group_members (group_id);
+
+CREATE TABLE group_invitations (
+    id            BIGSERIAL   PRIMARY KEY,
+    invitation_id VARCHAR(32) NOT NULL,
+    group_id      VARCHAR(32) NOT NULL,
+    invitee_id    VARCHAR(32) NOT NULL,
+    inviter_id    VARCHAR(32) NOT NULL,
+    role          VARCHAR(16) NOT NULL,
+    status        VARCHAR(16) NOT NULL,
+    created_at    TIMESTAMPTZ NULL,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    responded_at  TIMESTAMPTZ NULL
+);
+CREATE UNIQUE INDEX idx_group_invitations_invitation_id ON group_invitations (invitation_id);
+CREATE INDEX idx_group_invitations_group_id ON group_invitations (group_id);
+CREATE INDEX idx_group_invitations_invitee_status ON group_invitations (invitee_id, status);
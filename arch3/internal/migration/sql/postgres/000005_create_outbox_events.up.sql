@@ -0,0 +1,20 @@
+-- 领域事件 outbox
+
+CREATE TABLE outbox_events (
+    id           BIGSERIAL    PRIMARY KEY,
+    event_id     VARCHAR(32)  NOT NULL,
+    event_type   VARCHAR(64)  NOT NULL,
+    aggregate_id VARCHAR(64)  NOT NULL,
+    payload      TEXT         NOT NULL,
+    trace        VARCHAR(512) NULL,
+    status       VARCHAR(16)  NOT NULL,
+    attempts     INTEGER      NOT NULL DEFAULT 0,
+    last_error   VARCHAR(512) NULL,
+    available_at TIMESTAMPTZ  NOT NULL,
+    occurred_at  TIMESTAMPTZ  NOT NULL,
+    published_at TIMESTAMPTZ  NULL,
+    updated_at   TIMESTAMPTZ  NOT NULL
+);
+CREATE UNIQUE INDEX idx_outbox_events_event_id ON outbox_events (event_id);
+CREATE INDEX idx_outbox_events_status_available ON outbox_events (status, available_at);
+CREATE INDEX idx_outbox_events_status_updated ON outbox_events (status, updated_at);
@@ -0,0 +1,4 @@
+-- 仅 MySQL 需要: 统一早期 MySQL 迁移中的枚举列与索引名
+-- 本驱动的初始迁移已是统一后的结构，保留同一版本号以与其他驱动一致
+
+SELECT 1;
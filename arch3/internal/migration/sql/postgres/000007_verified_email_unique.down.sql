@@ -0,0 +1,3 @@
+DROP INDEX idx_users_verified_email_hash;
+DROP INDEX idx_users_email_hash;
+CREATE UNIQUE INDEX idx_users_email_hash ON users (email_hash);
@@ -0,0 +1,6 @@
+-- 邮箱唯一约束只作用于已验证的邮箱
+-- 未验证的邮箱不再占用唯一位置，避免他人抢先绑定未验证的地址；验证时再检查唯一性
+
+DROP INDEX idx_users_email_hash;
+CREATE INDEX idx_users_email_hash ON users (email_hash);
+CREATE UNIQUE INDEX idx_users_verified_email_hash ON users (email_hash) WHERE email_verified;
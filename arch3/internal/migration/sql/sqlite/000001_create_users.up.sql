@@ -0,0 +1,68 @@
+-- 用户表及其附属记录
+-- 手机号、邮箱、姓名、身份证号为信封加密后的密文，*_hash 为对应的盲索引
+
+CREATE TABLE users (
+    id                    INTEGER      PRIMARY KEY AUTOINCREMENT,
+    user_id               VARCHAR(32)  NOT NULL,
+    group_id              VARCHAR(32)  NULL,
+    user_name             VARCHAR(50)  NOT NULL,
+    real_name             VARCHAR(512) NULL,
+    password_hash         CHAR(64)     NOT NULL,
+    email                 VARCHAR(512) NULL,
+    email_hash            CHAR(64)     NULL,
+    email_verified        BOOLEAN      NOT NULL DEFAULT FALSE,
+    phone_number          VARCHAR(255) NOT NULL,
+    phone_hash            CHAR(64)     NULL,
+    phone_tail_hash       CHAR(64)     NULL,
+    avatar_url            VARCHAR(255) NULL,
+    gender                VARCHAR(16)  NOT NULL DEFAULT 'other',
+    created_at            DATETIME     NULL,
+    updated_at            DATETIME     NULL,
+    status                VARCHAR(32)  NOT NULL DEFAULT 'real_name_unverified',
+    id_number             VARCHAR(255) NULL,
+    id_number_hash        CHAR(64)     NULL,
+    source                VARCHAR(50)  NULL,
+    device_id             VARCHAR(128) NULL,
+    deletion_scheduled_at DATETIME     NULL,
+    deleted_at            DATETIME     NULL,
+    CONSTRAINT chk_users_gender CHECK (gender IN ('male', 'female', 'other')),
+    CONSTRAINT chk_users_status CHECK (status IN ('real_name_verified', 'real_name_unverified', 'banned', 'under_review'))
+);
+CREATE UNIQUE INDEX idx_users_user_id ON users (user_id);
+CREATE UNIQUE INDEX idx_users_email_hash ON users (email_hash);
+CREATE UNIQUE INDEX idx_users_phone_hash ON users (phone_hash);
+CREATE UNIQUE INDEX idx_users_id_number_hash ON users (id_number_hash);
+CREATE INDEX idx_users_user_name ON users (user_name);
+CREATE INDEX idx_users_phone_tail_hash ON users (phone_tail_hash);
+CREATE INDEX idx_users_source ON users (source);
+CREATE INDEX idx_users_deletion_scheduled_at ON users (deletion_scheduled_at);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+
+CREATE TABLE user_status_changes (
+    id          INTEGER      PRIMARY KEY AUTOINCREMENT,
+    user_id     VARCHAR(32)  NOT NULL,
+    from_status VARCHAR(32)  NOT NULL,
+    to_status   VARCHAR(32)  NOT NULL,
+    reason      VARCHAR(200) NOT NULL,
+    operator_id VARCHAR(32)  NOT NULL,
+    created_at  DATETIME     NULL
+);
+CREATE INDEX idx_user_status_changes_user_created ON user_status_changes (user_id, created_at);
+
+CREATE TABLE user_login_history (
+    id           INTEGER      PRIMARY KEY AUTOINCREMENT,
+    user_id      VARCHAR(32)  NOT NULL,
+    event        VARCHAR(16)  NOT NULL,
+    device_id    VARCHAR(128) NOT NULL,
+    platform     VARCHAR(16)  NOT NULL,
+    app_version  VARCHAR(64)  NOT NULL,
+    channel      VARCHAR(64)  NOT NULL,
+    utm_source   VARCHAR(64)  NOT NULL,
+    utm_medium   VARCHAR(64)  NOT NULL,
+    utm_campaign VARCHAR(64)  NOT NULL,
+    ip           VARCHAR(45)  NOT NULL,
+    user_agent   VARCHAR(255) NOT NULL,
+    created_at   DATETIME     NULL
+);
+CREATE INDEX idx_user_login_history_user_created ON user_login_history (user_id, created_at);
+CREATE INDEX idx_user_login_history_channel ON user_login_history (channel);
@@ -0,0 +1,20 @@
+-- 领域事件 outbox
+
+CREATE TABLE outbox_events (
+    id           INTEGER      PRIMARY KEY AUTOINCREMENT,
+    event_id     VARCHAR(32)  NOT NULL,
+    event_type   VARCHAR(64)  NOT NULL,
+    aggregate_id VARCHAR(64)  NOT NULL,
+    payload      TEXT         NOT NULL,
+    trace        VARCHAR(512) NULL,
+    status       VARCHAR(16)  NOT NULL,
+    attempts     INTEGER      NOT NULL DEFAULT 0,
+    last_error   VARCHAR(512) NULL,
+    available_at DATETIME     NOT NULL,
+    occurred_at  DATETIME     NOT NULL,
+    published_at DATETIME     NULL,
+    updated_at   DATETIME     NOT NULL
+);
+CREATE UNIQUE INDEX idx_outbox_events_event_id ON outbox_events (event_id);
+CREATE INDEX idx_outbox_events_status_available ON outbox_events (status, available_at);
+CREATE INDEX idx_outbox_events_status_updated ON outbox_events (status, updated_at);
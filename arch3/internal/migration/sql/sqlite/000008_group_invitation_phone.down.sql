@@ -0,0 +1,2 @@
+DROP INDEX idx_group_invitations_invitee_phone_status;
+ALTER TABLE group_invitations DROP COLUMN invitee_phone_hash;
@@ -0,0 +1,3 @@
+DROP INDEX idx_users_last_active_at;
+ALTER TABLE users DROP COLUMN last_active_at;
+ALTER TABLE users DROP COLUMN onboarded_at;
@@ -0,0 +1,9 @@
+-- 用户最近活跃时间与完成引导时间，供清理未完成引导的不活跃账号使用
+-- last_active_at 在登录与刷新令牌时更新；onboarded_at 在用户首次修改资料、验证邮箱或提交实名认证时写入
+-- 已有用户视为已完成引导，不受清理影响
+
+ALTER TABLE users ADD COLUMN onboarded_at DATETIME NULL;
+ALTER TABLE users ADD COLUMN last_active_at DATETIME NULL;
+CREATE INDEX idx_users_last_active_at ON users (last_active_at);
+
+UPDATE users SET onboarded_at = created_at, last_active_at = updated_at;
@@ -0,0 +1,115 @@
+// Package dbtest 为仓储测试提供已执行全部迁移的数据库
+//
+// SQLite 内存库用于常规单元测试，每次调用得到一个独立的空库；
+// PostgreSQL 用于集成测试（go test -tags integration），
+// 通过环境变量 TEST_POSTGRES_DSN 指定连接，每次调用创建独立的 schema，测试结束后删除。
+package dbtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"arch3/internal/config"
+	"arch3/internal/migration"
+	"arch3/pkg/migrate"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// PostgresDSNEnv PostgreSQL 集成测试的连接串环境变量
+const PostgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// SQLite 返回已执行迁移的 SQLite 内存库
+func SQLite(t testing.TB) *gorm.DB {
+	t.Helper()
+	cfg := config.DBConfig{Driver: "sqlite", Database: ":memory:"}
+	db := open(t, sqlite.Open(cfg.DSN()))
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB() error = %v", err)
+	}
+	// 内存库每个连接各是一个独立的库
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	runMigrations(t, db, cfg.Driver)
+	return db
+}
+
+// Postgres 返回已执行迁移的 PostgreSQL 库，未设置 TEST_POSTGRES_DSN 时跳过测试
+func Postgres(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv(PostgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", PostgresDSNEnv)
+	}
+
+	admin := open(t, postgres.Open(dsn))
+	schema := "test_" + randomSuffix(t)
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error
+		if sqlDB, err := admin.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+
+	db := open(t, postgres.Open(dsn+" search_path="+schema))
+	if sqlDB, err := db.DB(); err == nil {
+		t.Cleanup(func() { _ = sqlDB.Close() })
+	}
+
+	runMigrations(t, db, "postgres")
+	return db
+}
+
+// open 以与生产一致的选项打开数据库（驱动错误转换为 gorm.ErrDuplicatedKey 等）
+func open(t testing.TB, dialector gorm.Dialector) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         gormlogger.Discard,
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+// runMigrations 执行驱动对应的全部迁移
+func runMigrations(t testing.TB, db *gorm.DB, driver string) {
+	t.Helper()
+	fsys, err := migration.FS(driver)
+	if err != nil {
+		t.Fatalf("migration.FS() error = %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("db.DB() error = %v", err)
+	}
+	m, err := migrate.New(sqlDB, driver, fsys)
+	if err != nil {
+		t.Fatalf("migrate.New() error = %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up error = %v", err)
+	}
+}
+
+// randomSuffix 返回 schema 名后缀
+func randomSuffix(t testing.TB) string {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand.Read() error = %v", err)
+	}
+	return hex.EncodeToString(b)
+}
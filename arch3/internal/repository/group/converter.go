@@ -0,0 +1,75 @@
+package group
+
+import (
+	"database/sql"
+
+	domain "arch3/internal/domain/group"
+	"arch3/pkg/fieldcrypt"
+	"arch3/pkg/sqlx"
+)
+
+// indexInviteePhone 被邀请手机号盲索引的列名，与用户表的手机号索引相互独立
+const indexInviteePhone = "group_invitations.invitee_phone"
+
+// groupToDomain 将组织实体转换为领域模型
+func groupToDomain(e *GroupEntity) *domain.Group {
+	return &domain.Group{
+		ID:        e.ID,
+		GroupID:   e.GroupID,
+		Name:      e.Name,
+		OwnerID:   e.OwnerID,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// memberToDomain 将成员实体转换为领域模型
+func memberToDomain(e *MemberEntity) *domain.Member {
+	return &domain.Member{
+		GroupID:  e.GroupID,
+		UserID:   e.UserID,
+		Role:     e.Role,
+		JoinedAt: e.JoinedAt,
+	}
+}
+
+// invitationToDomain 将邀请实体转换为领域模型
+func invitationToDomain(e *InvitationEntity) *domain.Invitation {
+	return &domain.Invitation{
+		ID:           e.ID,
+		InvitationID: e.InvitationID,
+		GroupID:      e.GroupID,
+		InviteeID:    e.InviteeID,
+		InviterID:    e.InviterID,
+		Role:         e.Role,
+		Status:       e.Status,
+		CreatedAt:    e.CreatedAt,
+		ExpiresAt:    e.ExpiresAt,
+		RespondedAt:  sqlx.NullTimeToPtr(e.RespondedAt),
+	}
+}
+
+// invitationToEntity 将邀请领域模型转换为实体，手机号只保存盲索引
+func invitationToEntity(i *domain.Invitation, k *fieldcrypt.Keyring) *InvitationEntity {
+	return &InvitationEntity{
+		ID:               i.ID,
+		InvitationID:     i.InvitationID,
+		GroupID:          i.GroupID,
+		InviteeID:        i.InviteeID,
+		InviteePhoneHash: phoneIndex(k, i.InviteePhone),
+		InviterID:        i.InviterID,
+		Role:             i.Role,
+		Status:           i.Status,
+		CreatedAt:        i.CreatedAt,
+		ExpiresAt:        i.ExpiresAt,
+		RespondedAt:      sqlx.PtrToNullTime(i.RespondedAt),
+	}
+}
+
+// phoneIndex 计算被邀请手机号的盲索引，手机号为空时返回 NULL
+func phoneIndex(k *fieldcrypt.Keyring, phoneNumber string) sql.NullString {
+	if phoneNumber == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: k.BlindIndex(indexInviteePhone, phoneNumber), Valid: true}
+}
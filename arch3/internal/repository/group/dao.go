@@ -0,0 +1,301 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/group"
+	"arch3/pkg/dbtx"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound 记录不存在错误
+var ErrNotFound = errors.New("record not found")
+
+// errNotApplied 事务中的条件更新未命中，用于回滚已执行的语句
+var errNotApplied = errors.New("conditional update not applied")
+
+// usersTable 用户表，成员变更时同步 users.group_id
+// 仅写 group_id 列，不更新 updated_at，避免干扰资料更新的乐观锁
+const usersTable = "users"
+
+// DAO 组织数据访问对象
+type DAO struct {
+	db *gorm.DB
+}
+
+// NewDAO 创建组织 DAO
+func NewDAO(db *gorm.DB) *DAO {
+	return &DAO{db: db}
+}
+
+// conn 返回 ctx 对应的连接，ctx 在事务中时加入该事务
+func (d *DAO) conn(ctx context.Context) *gorm.DB {
+	return dbtx.Conn(ctx, d.db)
+}
+
+// CreateGroup 创建组织并加入所有者
+// 所有者已属于其他组织时返回 gorm.ErrDuplicatedKey
+func (d *DAO) CreateGroup(ctx context.Context, group *GroupEntity, owner *MemberEntity) error {
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(owner).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		return setUserGroup(tx, owner.UserID, group.GroupID)
+	})
+}
+
+// FindGroup 根据组织 ID 查询
+func (d *DAO) FindGroup(ctx context.Context, groupID string) (*GroupEntity, error) {
+	var entity GroupEntity
+	err := d.conn(ctx).Where("group_id = ?", groupID).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &entity, err
+}
+
+// FindMember 查询组织成员
+func (d *DAO) FindMember(ctx context.Context, groupID, userID string) (*MemberEntity, error) {
+	var entity MemberEntity
+	err := d.conn(ctx).Where("group_id = ? AND user_id = ?", groupID, userID).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &entity, err
+}
+
+// FindMemberByUser 查询用户所在组织的成员记录
+func (d *DAO) FindMemberByUser(ctx context.Context, userID string) (*MemberEntity, error) {
+	var entity MemberEntity
+	err := d.conn(ctx).Where("user_id = ?", userID).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &entity, err
+}
+
+// ListMembers 查询组织全部成员，按加入时间升序
+func (d *DAO) ListMembers(ctx context.Context, groupID string) ([]*MemberEntity, error) {
+	var entities []*MemberEntity
+	err := d.conn(ctx).Where("group_id = ?", groupID).Order("joined_at, id").Find(&entities).Error
+	return entities, err
+}
+
+// CreateInvitation 创建邀请
+func (d *DAO) CreateInvitation(ctx context.Context, entity *InvitationEntity) error {
+	return d.conn(ctx).Create(entity).Error
+}
+
+// FindInvitation 根据邀请 ID 查询
+func (d *DAO) FindInvitation(ctx context.Context, invitationID string) (*InvitationEntity, error) {
+	var entity InvitationEntity
+	err := d.conn(ctx).Where("invitation_id = ?", invitationID).First(&entity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	return &entity, err
+}
+
+// ListOpenInvitations 查询用户未处理且未过期的邀请，按创建时间倒序
+func (d *DAO) ListOpenInvitations(ctx context.Context, inviteeID string, now time.Time) ([]*InvitationEntity, error) {
+	var entities []*InvitationEntity
+	err := d.conn(ctx).
+		Where("invitee_id = ? AND status = ? AND expires_at > ?", inviteeID, domain.InvitationPending, now).
+		Order("created_at DESC, id DESC").
+		Find(&entities).Error
+	return entities, err
+}
+
+// ListOpenInvitationsByPhone 查询发给手机号（盲索引）未处理且未过期的邀请，按创建时间倒序
+func (d *DAO) ListOpenInvitationsByPhone(ctx context.Context, phoneHash string, now time.Time) ([]*InvitationEntity, error) {
+	var entities []*InvitationEntity
+	err := d.conn(ctx).
+		Where("invitee_phone_hash = ? AND status = ? AND expires_at > ?", phoneHash, domain.InvitationPending, now).
+		Order("created_at DESC, id DESC").
+		Find(&entities).Error
+	return entities, err
+}
+
+// BindPhoneInvitations 把发给手机号（盲索引）且尚未绑定的未处理邀请绑定到用户，返回绑定的邀请
+func (d *DAO) BindPhoneInvitations(ctx context.Context, phoneHash, inviteeID string, now time.Time) ([]*InvitationEntity, error) {
+	var entities []*InvitationEntity
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("invitee_phone_hash = ? AND invitee_id = '' AND status = ? AND expires_at > ?", phoneHash, domain.InvitationPending, now).
+			Order("created_at DESC, id DESC").
+			Find(&entities).Error
+		if err != nil || len(entities) == 0 {
+			return err
+		}
+		ids := make([]uint, 0, len(entities))
+		for _, e := range entities {
+			ids = append(ids, e.ID)
+			e.InviteeID = inviteeID
+		}
+		return tx.Model(&InvitationEntity{}).
+			Where("id IN ? AND invitee_id = ''", ids).
+			Update("invitee_id", inviteeID).Error
+	})
+	return entities, err
+}
+
+// ListInvitationsByInvitee 查询用户收到的全部邀请，按创建时间倒序
+func (d *DAO) ListInvitationsByInvitee(ctx context.Context, inviteeID string) ([]*InvitationEntity, error) {
+	var entities []*InvitationEntity
+	err := d.conn(ctx).
+		Where("invitee_id = ?", inviteeID).
+		Order("created_at DESC, id DESC").
+		Find(&entities).Error
+	return entities, err
+}
+
+// AcceptInvitation 接受邀请并加入组织
+// 邀请未处理且未过期时写入，否则返回受影响行数 0；
+// 组织已解散返回 ErrNotFound，用户已属于其他组织返回 gorm.ErrDuplicatedKey
+func (d *DAO) AcceptInvitation(ctx context.Context, invitationID string, member *MemberEntity, now time.Time) (int64, error) {
+	var affected int64
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&InvitationEntity{}).
+			Where("invitation_id = ? AND status = ? AND expires_at > ?", invitationID, domain.InvitationPending, now).
+			Updates(map[string]any{
+				"status":       domain.InvitationAccepted,
+				"responded_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+
+		var count int64
+		if err := tx.Model(&GroupEntity{}).Where("group_id = ?", member.GroupID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrNotFound
+		}
+		if err := tx.Create(member).Error; err != nil {
+			return err
+		}
+		return setUserGroup(tx, member.UserID, member.GroupID)
+	})
+	return affected, err
+}
+
+// UpdateInvitationStatus 条件更新邀请状态
+// 仅当邀请未处理且未过期时写入，返回受影响行数
+func (d *DAO) UpdateInvitationStatus(ctx context.Context, invitationID, status string, now time.Time) (int64, error) {
+	result := d.conn(ctx).Model(&InvitationEntity{}).
+		Where("invitation_id = ? AND status = ? AND expires_at > ?", invitationID, domain.InvitationPending, now).
+		Updates(map[string]any{
+			"status":       status,
+			"responded_at": now,
+		})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteInvitationsByInvitee 删除用户收到的全部邀请
+func (d *DAO) DeleteInvitationsByInvitee(ctx context.Context, inviteeID string) error {
+	return d.conn(ctx).Where("invitee_id = ?", inviteeID).Delete(&InvitationEntity{}).Error
+}
+
+// UpdateMemberRole 条件更新成员角色
+// 仅当当前角色为 fromRole 时写入，返回受影响行数
+func (d *DAO) UpdateMemberRole(ctx context.Context, groupID, userID, fromRole, toRole string) (int64, error) {
+	result := d.conn(ctx).Model(&MemberEntity{}).
+		Where("group_id = ? AND user_id = ? AND role = ?", groupID, userID, fromRole).
+		Update("role", toRole)
+	return result.RowsAffected, result.Error
+}
+
+// TransferOwnership 转让所有权，原所有者降为管理员
+// 原所有者不再是所有者或新所有者不是非所有者成员时不写入，返回受影响行数 0
+func (d *DAO) TransferOwnership(ctx context.Context, groupID, fromUserID, toUserID string, now time.Time) (int64, error) {
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&MemberEntity{}).
+			Where("group_id = ? AND user_id = ? AND role <> ?", groupID, toUserID, domain.RoleOwner).
+			Update("role", domain.RoleOwner)
+		if err := applied(result); err != nil {
+			return err
+		}
+		result = tx.Model(&MemberEntity{}).
+			Where("group_id = ? AND user_id = ? AND role = ?", groupID, fromUserID, domain.RoleOwner).
+			Update("role", domain.RoleAdmin)
+		if err := applied(result); err != nil {
+			return err
+		}
+		result = tx.Model(&GroupEntity{}).
+			Where("group_id = ? AND owner_id = ?", groupID, fromUserID).
+			Updates(map[string]any{
+				"owner_id":   toUserID,
+				"updated_at": now,
+			})
+		return applied(result)
+	})
+	if errors.Is(err, errNotApplied) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+// DeleteMember 移除非所有者成员，返回受影响行数
+func (d *DAO) DeleteMember(ctx context.Context, groupID, userID string) (int64, error) {
+	var affected int64
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("group_id = ? AND user_id = ? AND role <> ?", groupID, userID, domain.RoleOwner).
+			Delete(&MemberEntity{})
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return tx.Table(usersTable).
+			Where("user_id = ? AND group_id = ?", userID, groupID).
+			UpdateColumn("group_id", nil).Error
+	})
+	return affected, err
+}
+
+// DeleteGroup 解散组织: 删除组织、成员与邀请，并清除成员的 users.group_id
+func (d *DAO) DeleteGroup(ctx context.Context, groupID string) error {
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(usersTable).Where("group_id = ?", groupID).UpdateColumn("group_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", groupID).Delete(&MemberEntity{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", groupID).Delete(&InvitationEntity{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("group_id = ?", groupID).Delete(&GroupEntity{}).Error
+	})
+}
+
+// setUserGroup 同步 users.group_id
+func setUserGroup(tx *gorm.DB, userID, groupID string) error {
+	return tx.Table(usersTable).Where("user_id = ?", userID).UpdateColumn("group_id", groupID).Error
+}
+
+// applied 条件更新未命中时返回 errNotApplied 以回滚事务
+func applied(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errNotApplied
+	}
+	return nil
+}
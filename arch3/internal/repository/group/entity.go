@@ -0,0 +1,58 @@
+package group
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GroupEntity 组织数据库实体
+// 表名避开 MySQL 8 保留字 groups
+type GroupEntity struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	GroupID   string    `gorm:"column:group_id;type:varchar(32);uniqueIndex;not null"`
+	Name      string    `gorm:"column:name;type:varchar(64);not null"`
+	OwnerID   string    `gorm:"column:owner_id;type:varchar(32);not null;index"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (GroupEntity) TableName() string {
+	return "user_groups"
+}
+
+// MemberEntity 组织成员数据库实体
+// user_id 唯一: 一个用户同时只能属于一个组织
+type MemberEntity struct {
+	ID       uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	GroupID  string    `gorm:"column:group_id;type:varchar(32);not null;index"`
+	UserID   string    `gorm:"column:user_id;type:varchar(32);not null;uniqueIndex"`
+	Role     string    `gorm:"column:role;type:varchar(16);not null"`
+	JoinedAt time.Time `gorm:"column:joined_at;not null"`
+}
+
+// TableName 返回表名
+func (MemberEntity) TableName() string {
+	return "group_members"
+}
+
+// InvitationEntity 组织邀请数据库实体
+// 邀请未注册的手机号时 invitee_id 为空字符串，注册后按 invitee_phone_hash 绑定
+type InvitationEntity struct {
+	ID               uint           `gorm:"column:id;primaryKey;autoIncrement"`
+	InvitationID     string         `gorm:"column:invitation_id;type:varchar(32);uniqueIndex;not null"`
+	GroupID          string         `gorm:"column:group_id;type:varchar(32);not null;index"`
+	InviteeID        string         `gorm:"column:invitee_id;type:varchar(32);not null;index:idx_group_invitations_invitee_status,priority:1"`
+	InviteePhoneHash sql.NullString `gorm:"column:invitee_phone_hash;type:char(64);index:idx_group_invitations_invitee_phone_status,priority:1"`
+	InviterID        string         `gorm:"column:inviter_id;type:varchar(32);not null"`
+	Role             string         `gorm:"column:role;type:varchar(16);not null"`
+	Status           string         `gorm:"column:status;type:varchar(16);not null;index:idx_group_invitations_invitee_status,priority:2;index:idx_group_invitations_invitee_phone_status,priority:2"`
+	CreatedAt        time.Time      `gorm:"column:created_at;autoCreateTime"`
+	ExpiresAt        time.Time      `gorm:"column:expires_at;not null"`
+	RespondedAt      sql.NullTime   `gorm:"column:responded_at"`
+}
+
+// TableName 返回表名
+func (InvitationEntity) TableName() string {
+	return "group_invitations"
+}
@@ -0,0 +1,250 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/group"
+	groupservice "arch3/internal/service/group"
+	"arch3/pkg/fieldcrypt"
+
+	"gorm.io/gorm"
+)
+
+// Repository 组织仓储实现
+type Repository struct {
+	dao  *DAO
+	keys *fieldcrypt.Keyring // 计算被邀请手机号的盲索引
+}
+
+// NewRepository 创建组织仓储实例
+func NewRepository(dao *DAO, keys *fieldcrypt.Keyring) groupservice.Repository {
+	return &Repository{dao: dao, keys: keys}
+}
+
+// CreateGroup 创建组织并以 owner 角色加入创建者
+func (r *Repository) CreateGroup(ctx context.Context, g *domain.Group) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	entity := &GroupEntity{
+		GroupID:   g.GroupID,
+		Name:      g.Name,
+		OwnerID:   g.OwnerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	owner := &MemberEntity{
+		GroupID:  g.GroupID,
+		UserID:   g.OwnerID,
+		Role:     domain.RoleOwner,
+		JoinedAt: now,
+	}
+	if err := r.dao.CreateGroup(ctx, entity, owner); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrAlreadyMember
+		}
+		return err
+	}
+	g.ID = entity.ID
+	g.CreatedAt = entity.CreatedAt
+	g.UpdatedAt = entity.UpdatedAt
+	return nil
+}
+
+// FindGroup 查询组织
+func (r *Repository) FindGroup(ctx context.Context, groupID string) (*domain.Group, error) {
+	entity, err := r.dao.FindGroup(ctx, groupID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, domain.ErrGroupNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return groupToDomain(entity), nil
+}
+
+// FindMember 查询组织成员
+func (r *Repository) FindMember(ctx context.Context, groupID, userID string) (*domain.Member, error) {
+	entity, err := r.dao.FindMember(ctx, groupID, userID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, domain.ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return memberToDomain(entity), nil
+}
+
+// FindMembership 查询用户所在组织的成员记录
+func (r *Repository) FindMembership(ctx context.Context, userID string) (*domain.Member, error) {
+	entity, err := r.dao.FindMemberByUser(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, domain.ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return memberToDomain(entity), nil
+}
+
+// ListMembers 查询组织全部成员
+func (r *Repository) ListMembers(ctx context.Context, groupID string) ([]*domain.Member, error) {
+	entities, err := r.dao.ListMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]*domain.Member, 0, len(entities))
+	for _, e := range entities {
+		members = append(members, memberToDomain(e))
+	}
+	return members, nil
+}
+
+// CreateInvitation 创建邀请
+func (r *Repository) CreateInvitation(ctx context.Context, inv *domain.Invitation) error {
+	entity := invitationToEntity(inv, r.keys)
+	if err := r.dao.CreateInvitation(ctx, entity); err != nil {
+		return err
+	}
+	inv.ID = entity.ID
+	inv.CreatedAt = entity.CreatedAt
+	return nil
+}
+
+// FindInvitation 查询邀请
+func (r *Repository) FindInvitation(ctx context.Context, invitationID string) (*domain.Invitation, error) {
+	entity, err := r.dao.FindInvitation(ctx, invitationID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, domain.ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return invitationToDomain(entity), nil
+}
+
+// ListOpenInvitations 查询用户未处理且未过期的邀请
+func (r *Repository) ListOpenInvitations(ctx context.Context, inviteeID string) ([]*domain.Invitation, error) {
+	entities, err := r.dao.ListOpenInvitations(ctx, inviteeID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return invitationsToDomain(entities), nil
+}
+
+// ListOpenInvitationsByPhone 查询发给手机号未处理且未过期的邀请（含已绑定用户的邀请）
+func (r *Repository) ListOpenInvitationsByPhone(ctx context.Context, phoneNumber string) ([]*domain.Invitation, error) {
+	entities, err := r.dao.ListOpenInvitationsByPhone(ctx, phoneIndex(r.keys, phoneNumber).String, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return invitationsToDomain(entities), nil
+}
+
+// BindPhoneInvitations 把发给手机号且尚未绑定的未处理邀请绑定到用户
+func (r *Repository) BindPhoneInvitations(ctx context.Context, phoneNumber, userID string) ([]*domain.Invitation, error) {
+	entities, err := r.dao.BindPhoneInvitations(ctx, phoneIndex(r.keys, phoneNumber).String, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return invitationsToDomain(entities), nil
+}
+
+// ListInvitationsByInvitee 查询用户收到的全部邀请
+func (r *Repository) ListInvitationsByInvitee(ctx context.Context, inviteeID string) ([]*domain.Invitation, error) {
+	entities, err := r.dao.ListInvitationsByInvitee(ctx, inviteeID)
+	if err != nil {
+		return nil, err
+	}
+	return invitationsToDomain(entities), nil
+}
+
+// AcceptInvitation 接受邀请并加入组织
+// 邀请状态更新与成员写入在同一事务中
+func (r *Repository) AcceptInvitation(ctx context.Context, inv *domain.Invitation) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	affected, err := r.dao.AcceptInvitation(ctx, inv.InvitationID, &MemberEntity{
+		GroupID:  inv.GroupID,
+		UserID:   inv.InviteeID,
+		Role:     inv.Role,
+		JoinedAt: now,
+	}, now)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return domain.ErrGroupNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return domain.ErrAlreadyMember
+	case err != nil:
+		return err
+	case affected == 0:
+		return domain.ErrInvitationClosed
+	}
+	return nil
+}
+
+// DeclineInvitation 拒绝邀请
+func (r *Repository) DeclineInvitation(ctx context.Context, invitationID string) error {
+	affected, err := r.dao.UpdateInvitationStatus(ctx, invitationID, domain.InvitationDeclined, time.Now().UTC().Truncate(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrInvitationClosed
+	}
+	return nil
+}
+
+// DeleteInvitationsByInvitee 删除用户收到的全部邀请
+func (r *Repository) DeleteInvitationsByInvitee(ctx context.Context, inviteeID string) error {
+	return r.dao.DeleteInvitationsByInvitee(ctx, inviteeID)
+}
+
+// UpdateMemberRole 更新成员角色
+func (r *Repository) UpdateMemberRole(ctx context.Context, groupID, userID, fromRole, toRole string) error {
+	affected, err := r.dao.UpdateMemberRole(ctx, groupID, userID, fromRole, toRole)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrGroupConflict
+	}
+	return nil
+}
+
+// TransferOwnership 转让所有权
+func (r *Repository) TransferOwnership(ctx context.Context, groupID, fromUserID, toUserID string) error {
+	affected, err := r.dao.TransferOwnership(ctx, groupID, fromUserID, toUserID, time.Now().UTC().Truncate(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrGroupConflict
+	}
+	return nil
+}
+
+// RemoveMember 移除非所有者成员
+func (r *Repository) RemoveMember(ctx context.Context, groupID, userID string) error {
+	affected, err := r.dao.DeleteMember(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrMemberNotFound
+	}
+	return nil
+}
+
+// DeleteGroup 解散组织
+func (r *Repository) DeleteGroup(ctx context.Context, groupID string) error {
+	return r.dao.DeleteGroup(ctx, groupID)
+}
+
+// invitationsToDomain 批量转换邀请实体
+func invitationsToDomain(entities []*InvitationEntity) []*domain.Invitation {
+	invitations := make([]*domain.Invitation, 0, len(entities))
+	for _, e := range entities {
+		invitations = append(invitations, invitationToDomain(e))
+	}
+	return invitations
+}
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"database/sql"
+
+	domain "arch3/internal/domain/notification"
+)
+
+// messageToDomain 将站内信实体转换为领域模型
+func messageToDomain(entity *MessageEntity) *domain.Message {
+	msg := &domain.Message{
+		ID:        entity.ID,
+		MessageID: entity.MessageID,
+		UserID:    entity.UserID,
+		Template:  entity.Template,
+		Title:     entity.Title,
+		Body:      entity.Body,
+		CreatedAt: entity.CreatedAt,
+	}
+	if entity.ReadAt.Valid {
+		readAt := entity.ReadAt.Time
+		msg.ReadAt = &readAt
+	}
+	return msg
+}
+
+// messageToEntity 将站内信领域模型转换为实体
+func messageToEntity(msg *domain.Message) *MessageEntity {
+	entity := &MessageEntity{
+		ID:        msg.ID,
+		MessageID: msg.MessageID,
+		UserID:    msg.UserID,
+		Template:  msg.Template,
+		Title:     msg.Title,
+		Body:      msg.Body,
+		CreatedAt: msg.CreatedAt,
+	}
+	if msg.ReadAt != nil {
+		entity.ReadAt = sql.NullTime{Time: *msg.ReadAt, Valid: true}
+	}
+	return entity
+}
+
+// preferenceToDomain 将偏好实体转换为领域模型
+func preferenceToDomain(entity *PreferenceEntity) *domain.Preference {
+	return &domain.Preference{
+		UserID:    entity.UserID,
+		Template:  entity.Template,
+		Channel:   domain.Channel(entity.Channel),
+		Enabled:   entity.Enabled,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+// preferenceToEntity 将偏好领域模型转换为实体
+func preferenceToEntity(pref *domain.Preference) *PreferenceEntity {
+	return &PreferenceEntity{
+		UserID:    pref.UserID,
+		Template:  pref.Template,
+		Channel:   string(pref.Channel),
+		Enabled:   pref.Enabled,
+		UpdatedAt: pref.UpdatedAt,
+	}
+}
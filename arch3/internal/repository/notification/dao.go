@@ -0,0 +1,104 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"arch3/pkg/dbtx"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound 记录不存在错误
+var ErrNotFound = errors.New("record not found")
+
+// DAO 通知数据访问对象
+type DAO struct {
+	db *gorm.DB
+}
+
+// NewDAO 创建通知 DAO
+func NewDAO(db *gorm.DB) *DAO {
+	return &DAO{db: db}
+}
+
+// conn 返回 ctx 对应的连接，ctx 在事务中时加入该事务
+func (d *DAO) conn(ctx context.Context) *gorm.DB {
+	return dbtx.Conn(ctx, d.db)
+}
+
+// CreateMessage 创建站内信
+func (d *DAO) CreateMessage(ctx context.Context, entity *MessageEntity) error {
+	return d.conn(ctx).Create(entity).Error
+}
+
+// ListMessages 分页查询站内信
+func (d *DAO) ListMessages(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*MessageEntity, int64, error) {
+	query := d.conn(ctx).Model(&MessageEntity{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entities []*MessageEntity
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&entities).Error
+	return entities, total, err
+}
+
+// MarkRead 标记已读，消息不存在或不属于该用户时返回 ErrNotFound
+// 已读消息再次标记不更新 read_at
+func (d *DAO) MarkRead(ctx context.Context, userID, messageID string, readAt time.Time) error {
+	res := d.conn(ctx).Model(&MessageEntity{}).
+		Where("message_id = ? AND user_id = ? AND read_at IS NULL", messageID, userID).
+		Update("read_at", readAt)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	// 未更新: 区分已读与不存在
+	var count int64
+	err := d.conn(ctx).Model(&MessageEntity{}).
+		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListPreferences 查询用户偏好
+func (d *DAO) ListPreferences(ctx context.Context, userID string) ([]*PreferenceEntity, error) {
+	var entities []*PreferenceEntity
+	err := d.conn(ctx).Where("user_id = ?", userID).Find(&entities).Error
+	return entities, err
+}
+
+// DeleteByUser 删除用户的全部站内信与偏好
+func (d *DAO) DeleteByUser(ctx context.Context, userID string) error {
+	return d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&MessageEntity{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&PreferenceEntity{}).Error
+	})
+}
+
+// UpsertPreference 保存偏好（按 user_id + template + channel 唯一）
+func (d *DAO) UpsertPreference(ctx context.Context, entity *PreferenceEntity) error {
+	return d.conn(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "template"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(entity).Error
+}
@@ -0,0 +1,38 @@
+package notification
+
+import (
+	"database/sql"
+	"time"
+)
+
+// MessageEntity 站内信数据库实体
+type MessageEntity struct {
+	ID        uint         `gorm:"column:id;primaryKey;autoIncrement"`
+	MessageID string       `gorm:"column:message_id;type:varchar(32);uniqueIndex;not null"`
+	UserID    string       `gorm:"column:user_id;type:varchar(32);not null;index:idx_notification_messages_user_created,priority:1"`
+	Template  string       `gorm:"column:template;type:varchar(64);not null"`
+	Title     string       `gorm:"column:title;type:varchar(255);not null"`
+	Body      string       `gorm:"column:body;type:text;not null"`
+	ReadAt    sql.NullTime `gorm:"column:read_at"`
+	CreatedAt time.Time    `gorm:"column:created_at;autoCreateTime;index:idx_notification_messages_user_created,priority:2"`
+}
+
+// TableName 返回表名
+func (MessageEntity) TableName() string {
+	return "notification_messages"
+}
+
+// PreferenceEntity 通知偏好数据库实体
+type PreferenceEntity struct {
+	ID        uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID    string    `gorm:"column:user_id;type:varchar(32);not null;uniqueIndex:uk_notification_preferences_user_template_channel,priority:1"`
+	Template  string    `gorm:"column:template;type:varchar(64);not null;uniqueIndex:uk_notification_preferences_user_template_channel,priority:2"`
+	Channel   string    `gorm:"column:channel;type:varchar(16);not null;uniqueIndex:uk_notification_preferences_user_template_channel,priority:3"`
+	Enabled   bool      `gorm:"column:enabled;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+// TableName 返回表名
+func (PreferenceEntity) TableName() string {
+	return "notification_preferences"
+}
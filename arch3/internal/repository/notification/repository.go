@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/notification"
+	notificationservice "arch3/internal/service/notification"
+)
+
+// Repository 通知仓储实现
+type Repository struct {
+	dao *DAO
+}
+
+// NewRepository 创建通知仓储实例
+func NewRepository(dao *DAO) notificationservice.Repository {
+	return &Repository{dao: dao}
+}
+
+// CreateMessage 创建站内信
+func (r *Repository) CreateMessage(ctx context.Context, msg *domain.Message) error {
+	entity := messageToEntity(msg)
+	if err := r.dao.CreateMessage(ctx, entity); err != nil {
+		return err
+	}
+	msg.ID = entity.ID
+	msg.CreatedAt = entity.CreatedAt
+	return nil
+}
+
+// ListMessages 分页查询站内信
+func (r *Repository) ListMessages(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Message, int64, error) {
+	entities, total, err := r.dao.ListMessages(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	msgs := make([]*domain.Message, 0, len(entities))
+	for _, e := range entities {
+		msgs = append(msgs, messageToDomain(e))
+	}
+	return msgs, total, nil
+}
+
+// MarkRead 标记已读
+func (r *Repository) MarkRead(ctx context.Context, userID, messageID string) error {
+	err := r.dao.MarkRead(ctx, userID, messageID, time.Now().UTC())
+	if errors.Is(err, ErrNotFound) {
+		return domain.ErrMessageNotFound
+	}
+	return err
+}
+
+// ListPreferences 查询用户偏好
+func (r *Repository) ListPreferences(ctx context.Context, userID string) ([]*domain.Preference, error) {
+	entities, err := r.dao.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := make([]*domain.Preference, 0, len(entities))
+	for _, e := range entities {
+		prefs = append(prefs, preferenceToDomain(e))
+	}
+	return prefs, nil
+}
+
+// UpsertPreference 保存偏好
+func (r *Repository) UpsertPreference(ctx context.Context, pref *domain.Preference) error {
+	return r.dao.UpsertPreference(ctx, preferenceToEntity(pref))
+}
+
+// DeleteUserData 删除用户的全部站内信与偏好
+func (r *Repository) DeleteUserData(ctx context.Context, userID string) error {
+	return r.dao.DeleteByUser(ctx, userID)
+}
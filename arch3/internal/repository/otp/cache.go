@@ -0,0 +1,141 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"arch3/internal/integration/otp"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Redis key 格式
+	codeKeyFormat       = "otp:code:%s:%s"        // otp:code:{type}:{target}，值为验证码摘要
+	minuteKeyFormat     = "otp:minute:%s:%s"      // otp:minute:{type}:{target}，各渠道共享
+	dayKeyFormat        = "otp:day:%s:%s"         // otp:day:{type}:{target}，各渠道共享
+	verifyFailKeyFormat = "otp:verify_fail:%s:%s" // otp:verify_fail:{type}:{target}
+
+	// 验证失败计数过期时间（1小时，重新发送验证码成功后会重置）
+	verifyFailTTL = 1 * time.Hour
+)
+
+// CacheRepository Redis 实现的验证码存储
+type CacheRepository struct {
+	rdb *redis.Client
+}
+
+// NewCacheRepository 创建验证码存储
+func NewCacheRepository(rdb *redis.Client) *CacheRepository {
+	return &CacheRepository{rdb: rdb}
+}
+
+var _ otp.CodeRepository = (*CacheRepository)(nil)
+
+// StoreCode 存储验证码摘要
+func (r *CacheRepository) StoreCode(ctx context.Context, smsType otp.Type, target, digest string, ttl time.Duration) error {
+	key := r.codeKey(smsType, target)
+	return r.rdb.Set(ctx, key, digest, ttl).Err()
+}
+
+// GetCode 获取验证码摘要
+func (r *CacheRepository) GetCode(ctx context.Context, smsType otp.Type, target string) (string, error) {
+	key := r.codeKey(smsType, target)
+	code, err := r.rdb.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", otp.ErrCodeInvalid
+	}
+	return code, err
+}
+
+// DeleteCode 删除验证码
+func (r *CacheRepository) DeleteCode(ctx context.Context, smsType otp.Type, target string) error {
+	key := r.codeKey(smsType, target)
+	return r.rdb.Del(ctx, key).Err()
+}
+
+// GetSendCount 获取发送次数
+func (r *CacheRepository) GetSendCount(ctx context.Context, smsType otp.Type, target string) (minuteCount, dayCount int, err error) {
+	minuteKey := r.minuteKey(smsType, target)
+	dayKey := r.dayKey(smsType, target)
+
+	minuteCount, err = r.rdb.Get(ctx, minuteKey).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+
+	dayCount, err = r.rdb.Get(ctx, dayKey).Int()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return 0, 0, err
+	}
+
+	return minuteCount, dayCount, nil
+}
+
+// IncrSendCount 增加发送次数
+func (r *CacheRepository) IncrSendCount(ctx context.Context, smsType otp.Type, target string) error {
+	minuteKey := r.minuteKey(smsType, target)
+	dayKey := r.dayKey(smsType, target)
+
+	pipe := r.rdb.Pipeline()
+	pipe.Incr(ctx, minuteKey)
+	pipe.Expire(ctx, minuteKey, time.Minute)
+	pipe.Incr(ctx, dayKey)
+	pipe.Expire(ctx, dayKey, 24*time.Hour)
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// PurgeTarget 删除主体在所有用途下的验证码与计数
+func (r *CacheRepository) PurgeTarget(ctx context.Context, target string) error {
+	keys := make([]string, 0, len(otp.Types)*4)
+	for _, t := range otp.Types {
+		keys = append(keys, r.codeKey(t, target), r.verifyFailKey(t, target), r.minuteKey(t, target), r.dayKey(t, target))
+	}
+	return r.rdb.Del(ctx, keys...).Err()
+}
+
+func (r *CacheRepository) codeKey(smsType otp.Type, target string) string {
+	return fmt.Sprintf(codeKeyFormat, smsType, target)
+}
+
+func (r *CacheRepository) minuteKey(smsType otp.Type, target string) string {
+	return fmt.Sprintf(minuteKeyFormat, smsType, target)
+}
+
+func (r *CacheRepository) dayKey(smsType otp.Type, target string) string {
+	return fmt.Sprintf(dayKeyFormat, smsType, target)
+}
+
+func (r *CacheRepository) verifyFailKey(smsType otp.Type, target string) string {
+	return fmt.Sprintf(verifyFailKeyFormat, smsType, target)
+}
+
+// GetVerifyFailCount 获取验证失败次数
+func (r *CacheRepository) GetVerifyFailCount(ctx context.Context, smsType otp.Type, target string) (int, error) {
+	key := r.verifyFailKey(smsType, target)
+	count, err := r.rdb.Get(ctx, key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// IncrVerifyFailCount 增加验证失败次数
+func (r *CacheRepository) IncrVerifyFailCount(ctx context.Context, smsType otp.Type, target string) error {
+	key := r.verifyFailKey(smsType, target)
+	pipe := r.rdb.Pipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, verifyFailTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ResetVerifyFailCount 重置验证失败次数
+func (r *CacheRepository) ResetVerifyFailCount(ctx context.Context, smsType otp.Type, target string) error {
+	key := r.verifyFailKey(smsType, target)
+	return r.rdb.Del(ctx, key).Err()
+}
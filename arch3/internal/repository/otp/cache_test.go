@@ -0,0 +1,34 @@
+package otp
+
+import (
+	"testing"
+	"time"
+
+	"arch3/internal/integration/otp"
+	"arch3/internal/integration/otp/otptest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb, mr
+}
+
+func TestCacheRepository_Contract(t *testing.T) {
+	otptest.TestCodeRepository(t, func(t *testing.T) (otp.CodeRepository, func(time.Duration)) {
+		rdb, mr := newTestRedis(t)
+		return NewCacheRepository(rdb), mr.FastForward
+	})
+}
+
+func TestDispatchQueue_Contract(t *testing.T) {
+	otptest.TestDispatchQueue(t, func(t *testing.T) otp.DispatchQueue {
+		rdb, _ := newTestRedis(t)
+		return NewDispatchQueue(rdb)
+	})
+}
@@ -0,0 +1,161 @@
+package otp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"arch3/internal/integration/otp"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Redis key 格式
+	dispatchJobKeyFormat = "otp:dispatch:job:%s" // otp:dispatch:job:{id}，任务详情 (JSON)
+	dispatchQueueKey     = "otp:dispatch:queue"  // ZSET，score 为下次可领取时间 (毫秒)
+
+	// 任务记录在验证码失效后继续保留的时长，供客户端查询最终状态
+	dispatchRetention = 10 * time.Minute
+)
+
+// claimScript 原子领取到期任务
+// 领取即把 score 推迟到租约结束，租约内其他 worker 不可见；任务记录已过期的直接移出队列
+var claimScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, tonumber(ARGV[2]))
+local jobs = {}
+for _, id in ipairs(ids) do
+	local data = redis.call('GET', ARGV[4] .. id)
+	if data then
+		redis.call('ZADD', KEYS[1], ARGV[3], id)
+		table.insert(jobs, data)
+	else
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return jobs
+`)
+
+// DispatchQueue Redis 实现的验证码投递 outbox
+//
+// 任务详情与队列分开存储: 任务记录在终态后保留一段时间供状态查询，
+// 队列只保存待投递任务。
+type DispatchQueue struct {
+	rdb *redis.Client
+}
+
+// NewDispatchQueue 创建投递队列
+func NewDispatchQueue(rdb *redis.Client) *DispatchQueue {
+	return &DispatchQueue{rdb: rdb}
+}
+
+var _ otp.DispatchQueue = (*DispatchQueue)(nil)
+
+// Enqueue 写入任务，仅 pending 任务进入队列
+func (q *DispatchQueue) Enqueue(ctx context.Context, job *otp.DispatchJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.rdb.TxPipeline()
+	pipe.Set(ctx, q.jobKey(job.ID), data, q.jobTTL(job))
+	if job.Status == otp.DispatchPending {
+		pipe.ZAdd(ctx, dispatchQueueKey, redis.Z{Score: float64(time.Now().UnixMilli()), Member: job.ID})
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Claim 领取到期任务
+func (q *DispatchQueue) Claim(ctx context.Context, limit int, lease time.Duration) ([]*otp.DispatchJob, error) {
+	now := time.Now()
+	res, err := claimScript.Run(ctx, q.rdb, []string{dispatchQueueKey},
+		now.UnixMilli(), limit, now.Add(lease).UnixMilli(), q.jobKey(""),
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*otp.DispatchJob, 0, len(res))
+	for _, data := range res {
+		var job otp.DispatchJob
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			return nil, fmt.Errorf("decode dispatch job: %w", err)
+		}
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// Get 查询任务
+func (q *DispatchQueue) Get(ctx context.Context, id string) (*otp.DispatchJob, error) {
+	data, err := q.rdb.Get(ctx, q.jobKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, otp.ErrDispatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var job otp.DispatchJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("decode dispatch job: %w", err)
+	}
+	return &job, nil
+}
+
+// MarkSent 标记投递成功
+func (q *DispatchQueue) MarkSent(ctx context.Context, job *otp.DispatchJob) error {
+	job.Status = otp.DispatchSent
+	return q.finish(ctx, job)
+}
+
+// Retry 保存失败信息并在 next 时刻重新可领取
+func (q *DispatchQueue) Retry(ctx context.Context, job *otp.DispatchJob, next time.Time) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.rdb.TxPipeline()
+	pipe.Set(ctx, q.jobKey(job.ID), data, q.jobTTL(job))
+	pipe.ZAdd(ctx, dispatchQueueKey, redis.Z{Score: float64(next.UnixMilli()), Member: job.ID})
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// MarkFailed 标记最终失败
+func (q *DispatchQueue) MarkFailed(ctx context.Context, job *otp.DispatchJob) error {
+	job.Status = otp.DispatchFailed
+	return q.finish(ctx, job)
+}
+
+// finish 保存终态并移出队列
+func (q *DispatchQueue) finish(ctx context.Context, job *otp.DispatchJob) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.rdb.TxPipeline()
+	pipe.Set(ctx, q.jobKey(job.ID), data, q.jobTTL(job))
+	pipe.ZRem(ctx, dispatchQueueKey, job.ID)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (q *DispatchQueue) jobKey(id string) string {
+	return fmt.Sprintf(dispatchJobKeyFormat, id)
+}
+
+// jobTTL 任务记录保留到验证码失效后 dispatchRetention
+func (q *DispatchQueue) jobTTL(job *otp.DispatchJob) time.Duration {
+	ttl := time.Until(job.ExpiresAt) + dispatchRetention
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+	return ttl
+}
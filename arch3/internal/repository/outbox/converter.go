@@ -0,0 +1,61 @@
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"arch3/internal/integration/outbox"
+)
+
+// recordToEntity 将事件记录转换为实体
+func recordToEntity(rec *outbox.Record) (*EventEntity, error) {
+	entity := &EventEntity{
+		EventID:     rec.EventID,
+		EventType:   rec.EventType,
+		AggregateID: rec.AggregateID,
+		Payload:     string(rec.Payload),
+		Status:      string(rec.Status),
+		Attempts:    rec.Attempts,
+		LastError:   nullString(rec.LastError),
+		OccurredAt:  rec.OccurredAt.UTC(),
+	}
+	if len(rec.Trace) > 0 {
+		trace, err := json.Marshal(rec.Trace)
+		if err != nil {
+			return nil, err
+		}
+		entity.Trace = sql.NullString{String: string(trace), Valid: true}
+	}
+	if rec.PublishedAt != nil {
+		entity.PublishedAt = sql.NullTime{Time: rec.PublishedAt.UTC(), Valid: true}
+	}
+	return entity, nil
+}
+
+// entityToRecord 将实体转换为事件记录
+// trace 解析失败时忽略，只影响链路关联
+func entityToRecord(entity *EventEntity) *outbox.Record {
+	rec := &outbox.Record{
+		EventID:     entity.EventID,
+		EventType:   entity.EventType,
+		AggregateID: entity.AggregateID,
+		Payload:     json.RawMessage(entity.Payload),
+		Status:      outbox.Status(entity.Status),
+		Attempts:    entity.Attempts,
+		LastError:   entity.LastError.String,
+		OccurredAt:  entity.OccurredAt,
+	}
+	if entity.Trace.Valid {
+		_ = json.Unmarshal([]byte(entity.Trace.String), &rec.Trace)
+	}
+	if entity.PublishedAt.Valid {
+		publishedAt := entity.PublishedAt.Time
+		rec.PublishedAt = &publishedAt
+	}
+	return rec
+}
+
+// nullString 空字符串存为 NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
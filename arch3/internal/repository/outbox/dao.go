@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"arch3/pkg/dbtx"
+
+	"gorm.io/gorm"
+)
+
+// DAO outbox 数据访问对象
+type DAO struct {
+	db *gorm.DB
+}
+
+// NewDAO 创建 outbox DAO
+func NewDAO(db *gorm.DB) *DAO {
+	return &DAO{db: db}
+}
+
+// conn 返回 ctx 对应的连接，ctx 在事务中时加入该事务
+func (d *DAO) conn(ctx context.Context) *gorm.DB {
+	return dbtx.Conn(ctx, d.db)
+}
+
+// Create 批量写入事件
+func (d *DAO) Create(ctx context.Context, entities []*EventEntity) error {
+	return d.conn(ctx).Create(entities).Error
+}
+
+// ListDue 查询到期的待发布事件，按写入顺序
+func (d *DAO) ListDue(ctx context.Context, status string, now time.Time, limit int) ([]*EventEntity, error) {
+	var entities []*EventEntity
+	err := d.conn(ctx).
+		Where("status = ? AND available_at <= ?", status, now).
+		Order("id").Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// Lease 把到期事件的可领取时间推迟到 until，返回是否领取成功
+// 条件中重新检查 available_at，并发领取同一事件时只有一个能更新成功
+func (d *DAO) Lease(ctx context.Context, id uint, status string, now, until time.Time) (bool, error) {
+	res := d.conn(ctx).Model(&EventEntity{}).
+		Where("id = ? AND status = ? AND available_at <= ?", id, status, now).
+		Updates(map[string]any{"available_at": until, "updated_at": now})
+	return res.RowsAffected > 0, res.Error
+}
+
+// UpdateState 更新处于 fromStatus 的事件的发布状态
+// 租约过期后事件可能已被其他实例处理完，此时不覆盖其结果
+func (d *DAO) UpdateState(ctx context.Context, eventID, fromStatus string, updates map[string]any) error {
+	return d.conn(ctx).Model(&EventEntity{}).
+		Where("event_id = ? AND status = ?", eventID, fromStatus).
+		Updates(updates).Error
+}
+
+// DeleteBefore 删除状态为 status 且最后更新早于 before 的事件，最多 limit 条
+// 先查 ID 再删除，MySQL 不支持在 IN 子查询中使用 LIMIT
+func (d *DAO) DeleteBefore(ctx context.Context, status string, before time.Time, limit int) (int64, error) {
+	var ids []uint
+	err := d.conn(ctx).Model(&EventEntity{}).
+		Where("status = ? AND updated_at < ?", status, before).
+		Order("id").Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	res := d.conn(ctx).Where("id IN ? AND status = ?", ids, status).Delete(&EventEntity{})
+	return res.RowsAffected, res.Error
+}
+
+// Count 统计状态为 status 的事件数
+func (d *DAO) Count(ctx context.Context, status string) (int64, error) {
+	var count int64
+	err := d.conn(ctx).Model(&EventEntity{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+// OldestOccurredAt 状态为 status 的事件中最早的发生时间，没有事件时 Valid 为 false
+func (d *DAO) OldestOccurredAt(ctx context.Context, status string) (sql.NullTime, error) {
+	var entity EventEntity
+	err := d.conn(ctx).Select("occurred_at").
+		Where("status = ?", status).
+		Order("id").
+		Take(&entity).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return sql.NullTime{}, nil
+	case err != nil:
+		return sql.NullTime{}, err
+	}
+	return sql.NullTime{Time: entity.OccurredAt, Valid: true}, nil
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"database/sql"
+	"time"
+)
+
+// EventEntity outbox 事件数据库实体
+type EventEntity struct {
+	ID          uint           `gorm:"column:id;primaryKey;autoIncrement"`
+	EventID     string         `gorm:"column:event_id;type:varchar(32);uniqueIndex;not null"`
+	EventType   string         `gorm:"column:event_type;type:varchar(64);not null"`
+	AggregateID string         `gorm:"column:aggregate_id;type:varchar(64);not null"`
+	Payload     string         `gorm:"column:payload;type:text;not null"`
+	Trace       sql.NullString `gorm:"column:trace;type:varchar(512)"` // W3C trace context (JSON)
+	Status      string         `gorm:"column:status;type:varchar(16);not null;index:idx_outbox_events_status_available,priority:1;index:idx_outbox_events_status_updated,priority:1"`
+	Attempts    int            `gorm:"column:attempts;not null;default:0"`
+	LastError   sql.NullString `gorm:"column:last_error;type:varchar(512)"`
+	AvailableAt time.Time      `gorm:"column:available_at;not null;index:idx_outbox_events_status_available,priority:2"` // 下次可领取时间
+	OccurredAt  time.Time      `gorm:"column:occurred_at;not null"`
+	PublishedAt sql.NullTime   `gorm:"column:published_at"`
+	UpdatedAt   time.Time      `gorm:"column:updated_at;not null;index:idx_outbox_events_status_updated,priority:2"`
+}
+
+// TableName 返回表名
+func (EventEntity) TableName() string {
+	return "outbox_events"
+}
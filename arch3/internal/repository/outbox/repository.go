@@ -0,0 +1,122 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"arch3/internal/integration/outbox"
+)
+
+// Repository 基于数据库的 outbox 存储
+//
+// 事件与业务数据在同一个库中，Append 使用 ctx 中的事务写入。
+// 多个实例并发 Claim 时，以条件更新可领取时间的方式抢占，不依赖 SELECT ... FOR UPDATE SKIP LOCKED，
+// 三种数据库行为一致。
+type Repository struct {
+	dao *DAO
+}
+
+// NewRepository 创建 outbox 存储
+func NewRepository(dao *DAO) outbox.Store {
+	return &Repository{dao: dao}
+}
+
+// Append 写入待发布事件
+func (r *Repository) Append(ctx context.Context, records ...*outbox.Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	entities := make([]*EventEntity, 0, len(records))
+	for _, rec := range records {
+		entity, err := recordToEntity(rec)
+		if err != nil {
+			return err
+		}
+		entity.AvailableAt = now
+		entity.UpdatedAt = now
+		entities = append(entities, entity)
+	}
+	return r.dao.Create(ctx, entities)
+}
+
+// Claim 领取到期事件
+// 候选事件可能已被其他实例抢先领取，返回的数量可能少于 limit
+func (r *Repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*outbox.Record, error) {
+	now := time.Now().UTC()
+	entities, err := r.dao.ListDue(ctx, string(outbox.StatusPending), now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*outbox.Record, 0, len(entities))
+	for _, e := range entities {
+		ok, err := r.dao.Lease(ctx, e.ID, string(outbox.StatusPending), now, now.Add(lease))
+		if err != nil {
+			return records, err
+		}
+		if ok {
+			records = append(records, entityToRecord(e))
+		}
+	}
+	return records, nil
+}
+
+// MarkPublished 标记发布成功
+func (r *Repository) MarkPublished(ctx context.Context, rec *outbox.Record) error {
+	now := time.Now().UTC()
+	rec.Status = outbox.StatusPublished
+	rec.PublishedAt = &now
+	return r.dao.UpdateState(ctx, rec.EventID, string(outbox.StatusPending), map[string]any{
+		"status":       string(outbox.StatusPublished),
+		"attempts":     rec.Attempts,
+		"published_at": now,
+		"updated_at":   now,
+	})
+}
+
+// Retry 记录失败并推迟到 next 重新可领取
+func (r *Repository) Retry(ctx context.Context, rec *outbox.Record, next time.Time) error {
+	return r.dao.UpdateState(ctx, rec.EventID, string(outbox.StatusPending), map[string]any{
+		"attempts":     rec.Attempts,
+		"last_error":   nullString(rec.LastError),
+		"available_at": next.UTC(),
+		"updated_at":   time.Now().UTC(),
+	})
+}
+
+// MarkDead 标记最终失败
+func (r *Repository) MarkDead(ctx context.Context, rec *outbox.Record) error {
+	rec.Status = outbox.StatusDead
+	return r.dao.UpdateState(ctx, rec.EventID, string(outbox.StatusPending), map[string]any{
+		"status":     string(outbox.StatusDead),
+		"attempts":   rec.Attempts,
+		"last_error": nullString(rec.LastError),
+		"updated_at": time.Now().UTC(),
+	})
+}
+
+// Purge 删除超过保留时长的事件
+func (r *Repository) Purge(ctx context.Context, status outbox.Status, before time.Time, limit int) (int64, error) {
+	return r.dao.DeleteBefore(ctx, string(status), before.UTC(), limit)
+}
+
+// Stats 统计积压情况
+func (r *Repository) Stats(ctx context.Context) (outbox.Stats, error) {
+	var stats outbox.Stats
+	var err error
+	if stats.Pending, err = r.dao.Count(ctx, string(outbox.StatusPending)); err != nil {
+		return stats, err
+	}
+	if stats.Dead, err = r.dao.Count(ctx, string(outbox.StatusDead)); err != nil {
+		return stats, err
+	}
+	oldest, err := r.dao.OldestOccurredAt(ctx, string(outbox.StatusPending))
+	if err != nil {
+		return stats, err
+	}
+	if oldest.Valid {
+		stats.OldestPending = oldest.Time
+	}
+	return stats, nil
+}
@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"arch3/internal/integration/outbox"
+	"arch3/internal/repository/dbtest"
+	"arch3/pkg/dbtx"
+)
+
+func newRecord(eventID string) *outbox.Record {
+	return &outbox.Record{
+		EventID:     eventID,
+		EventType:   "user.registered",
+		AggregateID: "u1",
+		Payload:     []byte(`{"user_id":"u1"}`),
+		Trace:       map[string]string{"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		Status:      outbox.StatusPending,
+		OccurredAt:  time.Now().UTC(),
+	}
+}
+
+func claimedIDs(records []*outbox.Record) []string {
+	ids := make([]string, 0, len(records))
+	for _, r := range records {
+		ids = append(ids, r.EventID)
+	}
+	return ids
+}
+
+func TestRepository_AppendFollowsTransaction(t *testing.T) {
+	db := dbtest.SQLite(t)
+	store := NewRepository(NewDAO(db))
+	tx := dbtx.New(db, dbtx.Config{})
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	err := tx.Do(ctx, func(ctx context.Context) error {
+		if err := store.Append(ctx, newRecord("e1")); err != nil {
+			return err
+		}
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Expected errBoom, got %v", err)
+	}
+	err = tx.Do(ctx, func(ctx context.Context) error {
+		return store.Append(ctx, newRecord("e2"))
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	records, err := store.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if len(records) != 1 || records[0].EventID != "e2" {
+		t.Fatalf("Expected only committed event e2, got %v", claimedIDs(records))
+	}
+	if records[0].Trace["traceparent"] == "" || string(records[0].Payload) != `{"user_id":"u1"}` {
+		t.Errorf("Expected trace and payload round trip, got %+v", records[0])
+	}
+
+	// 事件 ID 唯一
+	if err := store.Append(ctx, newRecord("e2")); err == nil {
+		t.Error("Expected duplicate event id to be rejected")
+	}
+}
+
+func TestRepository_ClaimLifecycle(t *testing.T) {
+	db := dbtest.SQLite(t)
+	store := NewRepository(NewDAO(db))
+	ctx := context.Background()
+
+	if err := store.Append(ctx, newRecord("e1"), newRecord("e2"), newRecord("e3")); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	first, err := store.Claim(ctx, 2, time.Minute)
+	if err != nil || len(first) != 2 {
+		t.Fatalf("Expected 2 claimed, got %v, %v", claimedIDs(first), err)
+	}
+	// 租约内不会被重复领取
+	second, _ := store.Claim(ctx, 10, time.Minute)
+	if len(second) != 1 || second[0].EventID != "e3" {
+		t.Fatalf("Expected only e3 claimable, got %v", claimedIDs(second))
+	}
+
+	e1, e2, e3 := first[0], first[1], second[0]
+	e1.Attempts = 1
+	if err := store.MarkPublished(ctx, e1); err != nil {
+		t.Fatalf("MarkPublished() error = %v", err)
+	}
+	e2.Attempts, e2.LastError = 1, "broker down"
+	if err := store.Retry(ctx, e2, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	e3.Attempts, e3.LastError = 5, "broker down"
+	if err := store.MarkDead(ctx, e3); err != nil {
+		t.Fatalf("MarkDead() error = %v", err)
+	}
+	// 已有终态的事件不会被迟到的重试覆盖
+	if err := store.Retry(ctx, e1, time.Now()); err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+
+	stats, err := store.Stats(ctx)
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.Pending != 1 || stats.Dead != 1 || stats.OldestPending.IsZero() {
+		t.Errorf("Expected 1 pending and 1 dead, got %+v", stats)
+	}
+	if got, _ := store.Claim(ctx, 10, time.Minute); len(got) != 0 {
+		t.Errorf("Expected nothing due before backoff elapses, got %v", claimedIDs(got))
+	}
+
+	// 清理只删除指定状态且超过保留时长的事件
+	if n, err := store.Purge(ctx, outbox.StatusPublished, time.Now().Add(-time.Hour), 10); err != nil || n != 0 {
+		t.Errorf("Expected nothing purged within retention, got %d, %v", n, err)
+	}
+	if n, err := store.Purge(ctx, outbox.StatusPublished, time.Now().Add(time.Second), 10); err != nil || n != 1 {
+		t.Errorf("Expected 1 published event purged, got %d, %v", n, err)
+	}
+	stats, _ = store.Stats(ctx)
+	if stats.Pending != 1 || stats.Dead != 1 {
+		t.Errorf("Expected pending and dead events kept, got %+v", stats)
+	}
+}
@@ -0,0 +1,729 @@
+// Package repotest 用户仓储接口的契约测试
+//
+// 同一套测试分别运行在内存实现（usertest）、GORM 实现（SQLite/PostgreSQL）与缓存实现上，
+// 新增的存储实现须通过这些测试，证明其行为与现有实现一致。
+// 契约放在独立的包中: usertest 被用户服务自身的测试引用，不能依赖用户服务包。
+package repotest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/ptr"
+)
+
+// UserRepositoryFactory 创建空的待测用户仓储
+type UserRepositoryFactory func(t *testing.T) userservice.Repository
+
+// newUser 契约测试使用的用户
+func newUser(userID, name, phone string) *domain.User {
+	return &domain.User{
+		UserID:      userID,
+		UserName:    name,
+		PhoneNumber: phone,
+		Gender:      domain.GenderOther,
+		Status:      domain.StatusRealNameUnverified,
+		Source:      ptr.Of("wechat"),
+	}
+}
+
+// seed 依次创建用户
+func seed(t *testing.T, repo userservice.Repository, users ...*domain.User) {
+	t.Helper()
+	for _, u := range users {
+		if err := repo.Create(context.Background(), u); err != nil {
+			t.Fatalf("Create(%s) error = %v", u.UserID, err)
+		}
+	}
+}
+
+// find 查询用户，不存在时终止测试
+func find(t *testing.T, repo userservice.Repository, userID string) *domain.User {
+	t.Helper()
+	u, err := repo.FindByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("FindByUserID(%s) error = %v", userID, err)
+	}
+	return u
+}
+
+func userIDs(users []*domain.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.UserID)
+	}
+	return ids
+}
+
+// TestUserRepository 用户仓储契约测试，每个子测试使用 newRepo 创建的独立仓储
+func TestUserRepository(t *testing.T, newRepo UserRepositoryFactory) {
+	ctx := context.Background()
+
+	t.Run("创建与查询", func(t *testing.T) {
+		repo := newRepo(t)
+		u := newUser("u1", "alice", "13800000001")
+		u.Email = ptr.Of("alice@example.com")
+		u.DeviceID = ptr.Of("dev-1")
+		seed(t, repo, u)
+		if u.Version != 1 || u.CreatedAt.IsZero() || u.UpdatedAt.IsZero() {
+			t.Errorf("Expected version 1 and timestamps backfilled, got %+v", u)
+		}
+
+		got, err := repo.FindByPhoneNumber(ctx, "13800000001")
+		if err != nil {
+			t.Fatalf("FindByPhoneNumber() error = %v", err)
+		}
+		if got.UserID != "u1" || got.UserName != "alice" || got.PhoneNumber != "13800000001" ||
+			ptr.Value(got.Email) != "alice@example.com" || ptr.Value(got.DeviceID) != "dev-1" ||
+			ptr.Value(got.Source) != "wechat" || got.RealName != nil || got.Version != 1 || got.ID == 0 {
+			t.Errorf("Expected u1 round trip, got %+v", got)
+		}
+
+		if _, err := repo.FindByUserID(ctx, "missing"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound by ID, got %v", err)
+		}
+		if _, err := repo.FindByPhoneNumber(ctx, "13900000000"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound by phone, got %v", err)
+		}
+	})
+
+	t.Run("创建时的唯一约束", func(t *testing.T) {
+		repo := newRepo(t)
+		u := newUser("u1", "alice", "13800000001")
+		u.Email = ptr.Of("alice@example.com")
+		u.EmailVerified = true
+		seed(t, repo, u)
+
+		// 邮箱唯一约束只作用于已验证的邮箱
+		pending := newUser("u4", "dave", "13800000004")
+		pending.Email = ptr.Of("alice@example.com")
+		seed(t, repo, pending)
+
+		dupEmail := newUser("u3", "carol", "13800000003")
+		dupEmail.Email = ptr.Of("alice@example.com")
+		dupEmail.EmailVerified = true
+		for name, dup := range map[string]*domain.User{
+			"用户 ID": newUser("u1", "bob", "13800000002"),
+			"手机号":   newUser("u2", "bob", "13800000001"),
+			"邮箱":    dupEmail,
+		} {
+			if err := repo.Create(ctx, dup); err == nil {
+				t.Errorf("Expected duplicate %s rejected", name)
+			}
+		}
+		if got := find(t, repo, "u1"); got.UserName != "alice" {
+			t.Errorf("Expected u1 untouched, got %+v", got)
+		}
+	})
+
+	t.Run("搜索", func(t *testing.T) {
+		repo := newRepo(t)
+		u2 := newUser("u2", "axb", "13800000002")
+		u2.GroupID = ptr.Of("g1")
+		u3 := newUser("u3", "ab%", "13900000003")
+		u3.Source = ptr.Of("app")
+		u3.Status = domain.StatusRealNameVerified
+		seed(t, repo, newUser("u1", "a_b", "13800000001"), u2, u3)
+
+		tests := []struct {
+			name   string
+			filter domain.UserFilter
+			want   []string
+		}{
+			{"无条件按 user_id 倒序", domain.UserFilter{}, []string{"u3", "u2", "u1"}},
+			{"用户名前缀中的通配符按字面匹配", domain.UserFilter{UserName: "a_"}, []string{"u1"}},
+			{"百分号按字面匹配", domain.UserFilter{UserName: "ab%"}, []string{"u3"}},
+			{"手机号后 4 位", domain.UserFilter{Phone: "0003"}, []string{"u3"}},
+			{"完整手机号", domain.UserFilter{Phone: "13800000001"}, []string{"u1"}},
+			{"手机号不完整时不做前缀匹配", domain.UserFilter{Phone: "138000"}, nil},
+			{"状态", domain.UserFilter{Status: domain.StatusRealNameVerified}, []string{"u3"}},
+			{"注册来源", domain.UserFilter{Source: "wechat"}, []string{"u2", "u1"}},
+			{"所属组织", domain.UserFilter{GroupID: "g1"}, []string{"u2"}},
+			{"按 user_id 倒序分页", domain.UserFilter{Cursor: "u3", Limit: 1}, []string{"u2"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				f := tt.filter
+				if f.Limit == 0 {
+					f.Limit = 10
+				}
+				users, err := repo.Search(ctx, &f)
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				if got := userIDs(users); !slices.Equal(got, tt.want) {
+					t.Errorf("Expected %v, got %v", tt.want, got)
+				}
+			})
+		}
+
+		// 注册时间范围: 下限含、上限不含
+		created := find(t, repo, "u2").CreatedAt
+		users, err := repo.Search(ctx, &domain.UserFilter{CreatedFrom: &created, CreatedTo: ptr.Of(created.Add(time.Hour)), Limit: 10})
+		if err != nil || !slices.Contains(userIDs(users), "u2") {
+			t.Errorf("Expected u2 within created range, got %v, %v", userIDs(users), err)
+		}
+		users, _ = repo.Search(ctx, &domain.UserFilter{CreatedTo: &created, Limit: 10})
+		if slices.Contains(userIDs(users), "u2") {
+			t.Errorf("Expected created upper bound exclusive, got %v", userIDs(users))
+		}
+	})
+
+	t.Run("资料条件更新", func(t *testing.T) {
+		repo := newRepo(t)
+		other := newUser("u2", "bob", "13800000002")
+		other.Email = ptr.Of("bob@example.com")
+		other.EmailVerified = true
+		seed(t, repo, newUser("u1", "alice", "13800000001"), other)
+
+		u := find(t, repo, "u1")
+		upd := &domain.ProfileUpdate{
+			UserName:  ptr.Of("renamed"),
+			Email:     ptr.Of("alice@example.com"),
+			AvatarURL: ptr.Of("https://cdn.example.com/a.png"),
+			Version:   u.Version,
+		}
+		if err := repo.UpdateProfile(ctx, "u1", upd); err != nil {
+			t.Fatalf("UpdateProfile() error = %v", err)
+		}
+		// 版本已变化，再次使用旧版本更新应冲突
+		if err := repo.UpdateProfile(ctx, "u1", upd); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict, got %v", err)
+		}
+		got := find(t, repo, "u1")
+		if got.UserName != "renamed" || ptr.Value(got.Email) != "alice@example.com" || got.Gender != domain.GenderOther || got.Version != 2 {
+			t.Errorf("Expected profile written at version 2, got %+v", got)
+		}
+
+		// 修改资料与封禁读取同一版本: 封禁先写入后，资料更新不得覆盖（即使 UpdatedAt 相同）
+		ban := &domain.StatusChange{UserID: "u1", FromStatus: domain.StatusRealNameUnverified, ToStatus: domain.StatusBanned, OperatorID: "admin"}
+		if err := repo.ChangeStatus(ctx, ban); err != nil {
+			t.Fatalf("ChangeStatus() error = %v", err)
+		}
+		if err := repo.UpdateProfile(ctx, "u1", &domain.ProfileUpdate{Gender: ptr.Of(domain.GenderMale), Version: got.Version}); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict after concurrent ban, got %v", err)
+		}
+		got = find(t, repo, "u1")
+		if got.Status != domain.StatusBanned || got.Gender != domain.GenderOther {
+			t.Errorf("Expected ban kept and gender untouched, got %+v", got)
+		}
+
+		// 空字符串清空邮箱与头像
+		cleared := &domain.ProfileUpdate{Email: ptr.Of(""), AvatarURL: ptr.Of(""), Version: got.Version}
+		if err := repo.UpdateProfile(ctx, "u1", cleared); err != nil {
+			t.Fatalf("UpdateProfile(clear) error = %v", err)
+		}
+		got = find(t, repo, "u1")
+		if got.Email != nil || got.AvatarURL != nil {
+			t.Errorf("Expected email and avatar cleared, got %+v", got)
+		}
+
+		// 未验证的邮箱不占用唯一位置，已验证的邮箱唯一
+		pending := &domain.ProfileUpdate{Email: ptr.Of("bob@example.com"), EmailVerified: ptr.Of(false), Version: got.Version}
+		if err := repo.UpdateProfile(ctx, "u1", pending); err != nil {
+			t.Fatalf("UpdateProfile(unverified taken email) error = %v", err)
+		}
+		got = find(t, repo, "u1")
+		taken := &domain.ProfileUpdate{Email: ptr.Of("bob@example.com"), EmailVerified: ptr.Of(true), Version: got.Version}
+		if err := repo.UpdateProfile(ctx, "u1", taken); !errors.Is(err, domain.ErrEmailTaken) {
+			t.Errorf("Expected ErrEmailTaken, got %v", err)
+		}
+		if err := repo.UpdateProfile(ctx, "missing", cleared); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("更换手机号", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("u1", "alice", "13800000001"), newUser("u2", "bob", "13800000002"))
+
+		if err := repo.UpdatePhoneNumber(ctx, "u1", "13800000009", "13800000003"); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict for stale phone, got %v", err)
+		}
+		if err := repo.UpdatePhoneNumber(ctx, "u1", "13800000001", "13800000002"); !errors.Is(err, domain.ErrPhoneTaken) {
+			t.Errorf("Expected ErrPhoneTaken, got %v", err)
+		}
+		if err := repo.UpdatePhoneNumber(ctx, "u1", "13800000001", "13800000003"); err != nil {
+			t.Fatalf("UpdatePhoneNumber() error = %v", err)
+		}
+		if u, err := repo.FindByPhoneNumber(ctx, "13800000003"); err != nil || u.UserID != "u1" || u.Version != 2 {
+			t.Errorf("Expected u1 by new phone at version 2, got %+v, %v", u, err)
+		}
+		if _, err := repo.FindByPhoneNumber(ctx, "13800000001"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected old phone released, got %v", err)
+		}
+		if err := repo.UpdatePhoneNumber(ctx, "missing", "13800000001", "13800000004"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("邮箱验证", func(t *testing.T) {
+		repo := newRepo(t)
+		u := newUser("u1", "alice", "13800000001")
+		u.Email = ptr.Of("alice@example.com")
+		seed(t, repo, u)
+
+		// 验证期间邮箱已被更换
+		if err := repo.MarkEmailVerified(ctx, "u1", "old@example.com"); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict for stale email, got %v", err)
+		}
+		if err := repo.MarkEmailVerified(ctx, "u1", "alice@example.com"); err != nil {
+			t.Fatalf("MarkEmailVerified() error = %v", err)
+		}
+		if got := find(t, repo, "u1"); !got.EmailVerified || got.Version != 2 {
+			t.Errorf("Expected email verified at version 2, got %+v", got)
+		}
+		if err := repo.MarkEmailVerified(ctx, "missing", "alice@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+
+		// 同一邮箱可被多个账号绑定，只有先完成验证的账号占用
+		squatter := newUser("u2", "mallory", "13800000002")
+		squatter.Email = ptr.Of("alice@example.com")
+		seed(t, repo, squatter)
+		if err := repo.MarkEmailVerified(ctx, "u2", "alice@example.com"); !errors.Is(err, domain.ErrEmailTaken) {
+			t.Errorf("Expected ErrEmailTaken for email verified by another user, got %v", err)
+		}
+		if got := find(t, repo, "u2"); got.EmailVerified {
+			t.Errorf("Expected u2 email unverified, got %+v", got)
+		}
+	})
+
+	t.Run("实名信息", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("u1", "alice", "13800000001"), newUser("u2", "bob", "13800000002"))
+
+		submit := &domain.RealNameUpdate{RealName: "张三", IDNumber: "110101199003070011", Status: domain.StatusUnderReview}
+		if err := repo.UpdateRealName(ctx, "u1", domain.StatusRealNameVerified, submit); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict for stale status, got %v", err)
+		}
+		if err := repo.UpdateRealName(ctx, "u1", domain.StatusRealNameUnverified, submit); err != nil {
+			t.Fatalf("UpdateRealName() error = %v", err)
+		}
+		got := find(t, repo, "u1")
+		if ptr.Value(got.RealName) != "张三" || ptr.Value(got.IDNumber) != "110101199003070011" || got.Status != domain.StatusUnderReview {
+			t.Errorf("Expected real name under review, got %+v", got)
+		}
+
+		// 同一身份证号只能认证一个账号
+		if err := repo.UpdateRealName(ctx, "u2", domain.StatusRealNameUnverified, submit); !errors.Is(err, domain.ErrIDNumberTaken) {
+			t.Errorf("Expected ErrIDNumberTaken, got %v", err)
+		}
+
+		// 驳回时清除实名信息，身份证号可被其他账号使用
+		reject := &domain.RealNameUpdate{Status: domain.StatusRealNameUnverified}
+		if err := repo.UpdateRealName(ctx, "u1", domain.StatusUnderReview, reject); err != nil {
+			t.Fatalf("UpdateRealName(reject) error = %v", err)
+		}
+		if got := find(t, repo, "u1"); got.RealName != nil || got.IDNumber != nil {
+			t.Errorf("Expected real name cleared, got %+v", got)
+		}
+		if err := repo.UpdateRealName(ctx, "u2", domain.StatusRealNameUnverified, submit); err != nil {
+			t.Errorf("Expected ID number reusable after reject, got %v", err)
+		}
+		if err := repo.UpdateRealName(ctx, "missing", domain.StatusRealNameUnverified, submit); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("按状态分页", func(t *testing.T) {
+		repo := newRepo(t)
+		verified := newUser("u2", "bob", "13800000002")
+		verified.Status = domain.StatusRealNameVerified
+		seed(t, repo,
+			newUser("u1", "alice", "13800000001"), verified,
+			newUser("u3", "carol", "13800000003"), newUser("u4", "dave", "13800000004"))
+
+		first, err := repo.ListByStatus(ctx, domain.StatusRealNameUnverified, 0, 2)
+		if err != nil {
+			t.Fatalf("ListByStatus() error = %v", err)
+		}
+		if got := userIDs(first); !slices.Equal(got, []string{"u1", "u3"}) {
+			t.Fatalf("Expected [u1 u3], got %v", got)
+		}
+		next, _ := repo.ListByStatus(ctx, domain.StatusRealNameUnverified, first[1].ID, 2)
+		if got := userIDs(next); !slices.Equal(got, []string{"u4"}) {
+			t.Errorf("Expected [u4], got %v", got)
+		}
+	})
+
+	t.Run("状态变更与记录", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("u1", "alice", "13800000001"))
+
+		ban := &domain.StatusChange{
+			UserID:     "u1",
+			FromStatus: domain.StatusRealNameUnverified,
+			ToStatus:   domain.StatusBanned,
+			Reason:     "spam",
+			OperatorID: "admin",
+		}
+		if err := repo.ChangeStatus(ctx, ban); err != nil {
+			t.Fatalf("ChangeStatus() error = %v", err)
+		}
+		if err := repo.ChangeStatus(ctx, ban); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict on stale status, got %v", err)
+		}
+		unban := &domain.StatusChange{UserID: "u1", FromStatus: domain.StatusBanned, ToStatus: domain.StatusRealNameUnverified, OperatorID: "admin"}
+		if err := repo.ChangeStatus(ctx, unban); err != nil {
+			t.Fatalf("ChangeStatus(unban) error = %v", err)
+		}
+
+		changes, err := repo.ListStatusChanges(ctx, "u1", 10)
+		if err != nil {
+			t.Fatalf("ListStatusChanges() error = %v", err)
+		}
+		if len(changes) != 2 || changes[0].ToStatus != domain.StatusRealNameUnverified ||
+			changes[1].ToStatus != domain.StatusBanned || changes[1].Reason != "spam" || changes[1].CreatedAt.IsZero() {
+			t.Errorf("Expected unban then ban, newest first, got %+v", changes)
+		}
+		if changes, _ := repo.ListStatusChanges(ctx, "u1", 1); len(changes) != 1 {
+			t.Errorf("Expected limit respected, got %d", len(changes))
+		}
+		if got := find(t, repo, "u1"); got.Status != domain.StatusRealNameUnverified || got.Version != 3 {
+			t.Errorf("Expected unbanned at version 3, got %+v", got)
+		}
+		if err := repo.ChangeStatus(ctx, &domain.StatusChange{UserID: "missing", FromStatus: domain.StatusBanned, ToStatus: domain.StatusRealNameUnverified}); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("约束拒绝非法状态与性别", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("u1", "alice", "13800000001"))
+		err := repo.ChangeStatus(ctx, &domain.StatusChange{
+			UserID:     "u1",
+			FromStatus: domain.StatusRealNameUnverified,
+			ToStatus:   "bogus",
+			OperatorID: "admin",
+		})
+		if err == nil {
+			t.Error("Expected invalid status rejected")
+		}
+		if err := repo.UpdateProfile(ctx, "u1", &domain.ProfileUpdate{Gender: ptr.Of("bogus"), Version: find(t, repo, "u1").Version}); err == nil {
+			t.Error("Expected invalid gender rejected")
+		}
+		if got := find(t, repo, "u1"); got.Status != domain.StatusRealNameUnverified || got.Gender != domain.GenderOther || got.Version != 1 {
+			t.Errorf("Expected user untouched, got %+v", got)
+		}
+	})
+
+	t.Run("版本号条件更新", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("u1", "versioned", "13700000006"))
+		first := find(t, repo, "u1")
+		second := find(t, repo, "u1")
+		if first.Version != 1 {
+			t.Fatalf("Expected initial version 1, got %d", first.Version)
+		}
+
+		first.UserName = "renamed"
+		if err := repo.Update(ctx, first); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if first.Version != 2 {
+			t.Errorf("Expected version 2 after update, got %d", first.Version)
+		}
+
+		// 基于旧版本的更新被拒绝，不会覆盖已写入的用户名
+		second.Gender = domain.GenderFemale
+		if err := repo.Update(ctx, second); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict for stale version, got %v", err)
+		}
+
+		// 其他写入同样递增版本号
+		if err := repo.ChangeStatus(ctx, &domain.StatusChange{
+			UserID: "u1", FromStatus: domain.StatusRealNameUnverified, ToStatus: domain.StatusBanned, OperatorID: "admin",
+		}); err != nil {
+			t.Fatalf("ChangeStatus() error = %v", err)
+		}
+		if err := repo.Update(ctx, first); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict after status change, got %v", err)
+		}
+
+		got := find(t, repo, "u1")
+		if got.UserName != "renamed" || got.Gender != domain.GenderOther || got.Status != domain.StatusBanned || got.Version != 3 {
+			t.Errorf("Expected renamed banned user at version 3, got %+v", got)
+		}
+	})
+
+	t.Run("按字段更新", func(t *testing.T) {
+		repo := newRepo(t)
+		other := newUser("u2", "bob", "13800000002")
+		other.Email = ptr.Of("bob@example.com")
+		other.EmailVerified = true
+		seed(t, repo, newUser("u1", "alice", "13800000001"), other)
+
+		// 只写入设备号，内存中被改动的用户名与状态不写入
+		u := find(t, repo, "u1")
+		u.DeviceID = ptr.Of("dev-1")
+		u.UserName = "ignored"
+		u.Status = domain.StatusRealNameVerified
+		if err := repo.UpdateFields(ctx, u, domain.FieldDeviceID); err != nil {
+			t.Fatalf("UpdateFields() error = %v", err)
+		}
+		if u.Version != 2 {
+			t.Errorf("Expected version 2 after update, got %d", u.Version)
+		}
+		got := find(t, repo, "u1")
+		if ptr.Value(got.DeviceID) != "dev-1" || got.UserName != "alice" || got.Status != domain.StatusRealNameUnverified {
+			t.Errorf("Expected only device ID written, got %+v", got)
+		}
+		if !got.UpdatedAt.Equal(u.UpdatedAt) {
+			t.Errorf("Expected UpdatedAt backfilled as %v, got %v", got.UpdatedAt, u.UpdatedAt)
+		}
+
+		// 唯一冲突映射为对应的领域错误
+		got.PhoneNumber = "13700000066"
+		if err := repo.UpdateFields(ctx, got, domain.FieldPhoneNumber); err != nil {
+			t.Fatalf("UpdateFields(phone) error = %v", err)
+		}
+		if byPhone, err := repo.FindByPhoneNumber(ctx, "13700000066"); err != nil || byPhone.UserID != "u1" {
+			t.Errorf("Expected u1 by new phone, got %v, %v", byPhone, err)
+		}
+		got.PhoneNumber = "13800000002"
+		if err := repo.UpdateFields(ctx, got, domain.FieldPhoneNumber); !errors.Is(err, domain.ErrPhoneTaken) {
+			t.Errorf("Expected ErrPhoneTaken, got %v", err)
+		}
+		got.Email = ptr.Of("bob@example.com")
+		if err := repo.UpdateFields(ctx, got, domain.FieldEmail); err != nil {
+			t.Fatalf("UpdateFields(unverified email) error = %v", err)
+		}
+		got.EmailVerified = true
+		if err := repo.UpdateFields(ctx, got, domain.FieldEmail, domain.FieldEmailVerified); !errors.Is(err, domain.ErrEmailTaken) {
+			t.Errorf("Expected ErrEmailTaken, got %v", err)
+		}
+
+		if err := repo.UpdateFields(ctx, got, domain.Field("bogus")); err == nil {
+			t.Error("Expected unknown field rejected")
+		}
+		if err := repo.UpdateFields(ctx, &domain.User{UserID: "missing", Version: 1}, domain.FieldGender); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("注销冷静期", func(t *testing.T) {
+		repo := newRepo(t)
+		seed(t, repo, newUser("u1", "alice", "13800000001"))
+
+		if err := repo.CancelDeletion(ctx, "u1"); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict without schedule, got %v", err)
+		}
+		future := time.Now().Add(time.Hour)
+		if err := repo.ScheduleDeletion(ctx, "u1", future); err != nil {
+			t.Fatalf("ScheduleDeletion() error = %v", err)
+		}
+		// 重复申请不改变冷静期
+		if err := repo.ScheduleDeletion(ctx, "u1", future.Add(time.Hour)); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict on second schedule, got %v", err)
+		}
+		got := find(t, repo, "u1")
+		if got.DeletionScheduledAt == nil || !got.DeletionScheduledAt.Equal(future.UTC().Truncate(time.Millisecond)) {
+			t.Errorf("Expected deletion scheduled at %v, got %v", future, got.DeletionScheduledAt)
+		}
+		// 冷静期未结束不可匿名化，也不会出现在到期列表
+		if err := repo.Anonymize(ctx, "u1"); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict before due, got %v", err)
+		}
+		if due, _ := repo.ListDueDeletions(ctx, time.Now(), 10); len(due) != 0 {
+			t.Errorf("Expected nothing due, got %v", userIDs(due))
+		}
+
+		if err := repo.CancelDeletion(ctx, "u1"); err != nil {
+			t.Fatalf("CancelDeletion() error = %v", err)
+		}
+		if got := find(t, repo, "u1"); got.DeletionScheduledAt != nil {
+			t.Errorf("Expected deletion cancelled, got %v", got.DeletionScheduledAt)
+		}
+		if err := repo.ScheduleDeletion(ctx, "missing", future); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+
+	t.Run("未完成引导的不活跃账号", func(t *testing.T) {
+		repo := newRepo(t)
+		now := time.Now()
+		stale := now.Add(-48 * time.Hour)
+		users := []*domain.User{
+			newUser("u1", "alice", "13800000001"),
+			newUser("u2", "bob", "13800000002"),
+			newUser("u3", "carol", "13800000003"),
+			newUser("u4", "dave", "13800000004"),
+			newUser("u5", "erin", "13800000005"),
+		}
+		for _, u := range users[:4] {
+			u.LastActiveAt = &stale
+		}
+		seed(t, repo, users...)
+		before := now.Add(-24 * time.Hour)
+
+		// u2 刷新令牌，u3 完成引导，u5 未记录活跃时间
+		if err := repo.TouchLastActive(ctx, "u2", now); err != nil {
+			t.Fatalf("TouchLastActive() error = %v", err)
+		}
+		if err := repo.MarkOnboarded(ctx, "u3", now); err != nil {
+			t.Fatalf("MarkOnboarded() error = %v", err)
+		}
+		if got := find(t, repo, "u3"); got.OnboardedAt == nil || got.Version != 1 {
+			t.Errorf("Expected u3 onboarded without version bump, got %v, version %d", got.OnboardedAt, got.Version)
+		}
+		_ = repo.MarkOnboarded(ctx, "u3", now.Add(time.Hour))
+		if got := find(t, repo, "u3"); !got.OnboardedAt.Equal(now.UTC().Truncate(time.Millisecond)) {
+			t.Errorf("Expected first onboarding time kept, got %v", got.OnboardedAt)
+		}
+		if err := repo.TouchLastActive(ctx, "missing", now); err != nil {
+			t.Errorf("Expected no error for missing user, got %v", err)
+		}
+
+		inactive, err := repo.ListInactive(ctx, before, 10)
+		if err != nil || !slices.Equal(userIDs(inactive), []string{"u1", "u4"}) {
+			t.Fatalf("Expected [u1 u4] inactive, got %v, %v", userIDs(inactive), err)
+		}
+		if got, _ := repo.ListInactive(ctx, before, 1); len(got) != 1 {
+			t.Errorf("Expected limit respected, got %v", userIDs(got))
+		}
+
+		scheduledAt := now.Add(7 * 24 * time.Hour)
+		if err := repo.ScheduleInactiveDeletion(ctx, "u1", before, scheduledAt); err != nil {
+			t.Fatalf("ScheduleInactiveDeletion() error = %v", err)
+		}
+		got := find(t, repo, "u1")
+		if got.DeletionScheduledAt == nil || !got.DeletionScheduledAt.Equal(scheduledAt.UTC().Truncate(time.Millisecond)) {
+			t.Errorf("Expected deletion scheduled at %v, got %v", scheduledAt, got.DeletionScheduledAt)
+		}
+		if got.Version != 2 {
+			t.Errorf("Expected version bumped to 2, got %d", got.Version)
+		}
+		// 列出后活跃或已转入注销流程的账号不再写入
+		for _, userID := range []string{"u1", "u2", "u3"} {
+			if err := repo.ScheduleInactiveDeletion(ctx, userID, before, scheduledAt); !errors.Is(err, domain.ErrUserConflict) {
+				t.Errorf("Expected ErrUserConflict for %s, got %v", userID, err)
+			}
+		}
+		if err := repo.ScheduleInactiveDeletion(ctx, "missing", before, scheduledAt); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+		if got, _ := repo.ListInactive(ctx, before, 10); !slices.Equal(userIDs(got), []string{"u4"}) {
+			t.Errorf("Expected only u4 still inactive, got %v", userIDs(got))
+		}
+	})
+
+	t.Run("注销匿名化", func(t *testing.T) {
+		repo := newRepo(t)
+		u1 := newUser("u1", "alice", "13800000001")
+		u1.Email = ptr.Of("alice@example.com")
+		seed(t, repo, u1, newUser("u2", "bob", "13800000002"), newUser("u3", "carol", "13800000003"))
+		_ = repo.UpdateRealName(ctx, "u1", domain.StatusRealNameUnverified,
+			&domain.RealNameUpdate{RealName: "张三", IDNumber: "110101199003070011", Status: domain.StatusRealNameVerified})
+
+		now := time.Now()
+		_ = repo.ScheduleDeletion(ctx, "u2", now.Add(-time.Minute))
+		_ = repo.ScheduleDeletion(ctx, "u1", now.Add(-time.Hour))
+		_ = repo.ScheduleDeletion(ctx, "u3", now.Add(time.Hour))
+
+		// 冷静期已结束的不可撤销
+		if err := repo.CancelDeletion(ctx, "u1"); !errors.Is(err, domain.ErrUserConflict) {
+			t.Errorf("Expected ErrUserConflict after due, got %v", err)
+		}
+		due, err := repo.ListDueDeletions(ctx, now, 10)
+		if err != nil || !slices.Equal(userIDs(due), []string{"u1", "u2"}) {
+			t.Fatalf("Expected [u1 u2] due in schedule order, got %v, %v", userIDs(due), err)
+		}
+		if due, _ := repo.ListDueDeletions(ctx, now, 1); len(due) != 1 {
+			t.Errorf("Expected limit respected, got %v", userIDs(due))
+		}
+
+		if err := repo.Anonymize(ctx, "u1"); err != nil {
+			t.Fatalf("Anonymize() error = %v", err)
+		}
+		if _, err := repo.FindByUserID(ctx, "u1"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected anonymized user hidden by ID, got %v", err)
+		}
+		if _, err := repo.FindByPhoneNumber(ctx, "13800000001"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected anonymized user hidden by phone, got %v", err)
+		}
+		if users, _ := repo.Search(ctx, &domain.UserFilter{Limit: 10}); slices.Contains(userIDs(users), "u1") {
+			t.Errorf("Expected anonymized user hidden from search, got %v", userIDs(users))
+		}
+		if users, _ := repo.ListByStatus(ctx, domain.StatusRealNameVerified, 0, 10); len(users) != 0 {
+			t.Errorf("Expected anonymized user hidden from status list, got %v", userIDs(users))
+		}
+		if due, _ := repo.ListDueDeletions(ctx, now, 10); !slices.Equal(userIDs(due), []string{"u2"}) {
+			t.Errorf("Expected only u2 still due, got %v", userIDs(due))
+		}
+		if err := repo.Anonymize(ctx, "u1"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound on second anonymize, got %v", err)
+		}
+		if err := repo.ChangeStatus(ctx, &domain.StatusChange{UserID: "u1", FromStatus: domain.StatusRealNameVerified, ToStatus: domain.StatusBanned}); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound after anonymize, got %v", err)
+		}
+
+		// 手机号、邮箱、身份证号可被重新使用，用户 ID 不复用
+		again := newUser("u5", "again", "13800000001")
+		again.Email = ptr.Of("alice@example.com")
+		seed(t, repo, again)
+		if err := repo.UpdateRealName(ctx, "u5", domain.StatusRealNameUnverified,
+			&domain.RealNameUpdate{RealName: "张三", IDNumber: "110101199003070011", Status: domain.StatusRealNameVerified}); err != nil {
+			t.Errorf("Expected ID number reusable after anonymization, got %v", err)
+		}
+		if err := repo.Create(ctx, newUser("u1", "reuse", "13800000009")); err == nil {
+			t.Error("Expected user ID kept reserved after anonymization")
+		}
+	})
+}
+
+// LoginHistoryFactory 创建空的待测登录记录仓储
+type LoginHistoryFactory func(t *testing.T) userservice.LoginHistoryRepository
+
+// TestLoginHistory 登录记录仓储契约测试
+func TestLoginHistory(t *testing.T, newRepo LoginHistoryFactory) {
+	ctx := context.Background()
+	repo := newRepo(t)
+
+	// 与未指定时间的记录使用同一时区
+	base := time.Now().Truncate(time.Second)
+	records := []*domain.LoginRecord{
+		{UserID: "u1", Event: domain.LoginEventRegister, DeviceID: "d1", Platform: "ios", Channel: "appstore", IP: "10.0.0.1", CreatedAt: base.Add(-2 * time.Hour)},
+		{UserID: "u1", Event: domain.LoginEventLogin, DeviceID: "d1", Platform: "ios", IP: "10.0.0.2", CreatedAt: base.Add(-time.Hour)},
+		{UserID: "u2", Event: domain.LoginEventRegister, DeviceID: "d2", Platform: "android", IP: "10.0.0.3", CreatedAt: base.Add(-time.Hour)},
+		{UserID: "u1", Event: domain.LoginEventLogin, DeviceID: "d3", Platform: "web", IP: "10.0.0.4"},
+	}
+	for _, rec := range records {
+		if err := repo.Create(ctx, rec); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if rec.ID == 0 || rec.CreatedAt.IsZero() {
+			t.Errorf("Expected ID and CreatedAt backfilled, got %+v", rec)
+		}
+	}
+
+	got, err := repo.ListByUser(ctx, "u1", 10)
+	if err != nil {
+		t.Fatalf("ListByUser() error = %v", err)
+	}
+	var devices []string
+	for _, rec := range got {
+		devices = append(devices, rec.DeviceID)
+	}
+	if !slices.Equal(devices, []string{"d3", "d1", "d1"}) || got[2].Event != domain.LoginEventRegister || got[2].Channel != "appstore" {
+		t.Errorf("Expected u1 records newest first, got %+v", got)
+	}
+	if got, _ := repo.ListByUser(ctx, "u1", 1); len(got) != 1 || got[0].DeviceID != "d3" {
+		t.Errorf("Expected limit respected, got %+v", got)
+	}
+
+	if err := repo.DeleteByUser(ctx, "u1"); err != nil {
+		t.Fatalf("DeleteByUser() error = %v", err)
+	}
+	if got, _ := repo.ListByUser(ctx, "u1", 10); len(got) != 0 {
+		t.Errorf("Expected u1 records deleted, got %+v", got)
+	}
+	if got, _ := repo.ListByUser(ctx, "u2", 10); len(got) != 1 {
+		t.Errorf("Expected u2 records kept, got %+v", got)
+	}
+}
@@ -0,0 +1,51 @@
+package retention
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"arch3/internal/job"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key 格式: lock:{key}
+const lockKeyPrefix = "lock:"
+
+// unlockScript 仍持有锁时才删除，避免锁过期后删除其他实例新加的锁
+var unlockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker 基于 Redis SET NX 的跨实例互斥锁
+type RedisLocker struct {
+	rdb *redis.Client
+}
+
+// NewRedisLocker 创建 Redis 锁
+func NewRedisLocker(rdb *redis.Client) job.Locker {
+	return &RedisLocker{rdb: rdb}
+}
+
+// TryLock 尝试加锁，锁值为随机 token，释放时校验
+func (l *RedisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (func(ctx context.Context) error, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, false, err
+	}
+	token := hex.EncodeToString(buf)
+
+	ok, err := l.rdb.SetNX(ctx, lockKeyPrefix+key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	unlock := func(ctx context.Context) error {
+		return unlockScript.Run(ctx, l.rdb, []string{lockKeyPrefix + key}, token).Err()
+	}
+	return unlock, true, nil
+}
@@ -0,0 +1,55 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return rdb, mr
+}
+
+func TestRedisLocker_TryLock(t *testing.T) {
+	rdb, mr := newTestRedis(t)
+	locker := NewRedisLocker(rdb)
+	ctx := context.Background()
+
+	unlock, ok, err := locker.TryLock(ctx, "retention:logs", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Expected lock acquired, got %v, %v", ok, err)
+	}
+	if _, ok, _ := locker.TryLock(ctx, "retention:logs", time.Minute); ok {
+		t.Error("Expected lock held by first owner")
+	}
+	if _, ok, _ := locker.TryLock(ctx, "retention:events", time.Minute); !ok {
+		t.Error("Expected independent key lockable")
+	}
+
+	if err := unlock(ctx); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+	unlock, ok, _ = locker.TryLock(ctx, "retention:logs", time.Minute)
+	if !ok {
+		t.Fatal("Expected lock acquired after unlock")
+	}
+
+	// 锁过期后被其他实例获取，原持有者释放时不能删除新锁
+	mr.FastForward(2 * time.Minute)
+	if _, ok, _ := locker.TryLock(ctx, "retention:logs", time.Minute); !ok {
+		t.Fatal("Expected lock acquired after expiry")
+	}
+	if err := unlock(ctx); err != nil {
+		t.Fatalf("unlock() error = %v", err)
+	}
+	if _, ok, _ := locker.TryLock(ctx, "retention:logs", time.Minute); ok {
+		t.Error("Expected stale unlock to keep new owner's lock")
+	}
+}
@@ -0,0 +1,91 @@
+package retention
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"arch3/internal/job"
+	"arch3/pkg/dbreplica"
+	"arch3/pkg/dbtx"
+
+	"gorm.io/gorm"
+)
+
+// Repository 基于数据库的保留策略存储
+//
+// 先按主键升序选出一批过期记录，再以「主键在批内且仍满足策略条件」为条件删除或改写，
+// 选出后被修改而不再过期的记录（如期间重新登录的用户）不受影响。
+// 策略的表名与列名由 job.Policy 校验，Where 为模块内定义的 SQL 片段，不接受外部输入。
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository 创建保留策略存储
+func NewRepository(db *gorm.DB) job.RetentionStore {
+	return &Repository{db: db}
+}
+
+// conn 返回连接，读取同样走主库，避免复制延迟导致同一批记录被反复选出
+func (r *Repository) conn(ctx context.Context) *gorm.DB {
+	return dbtx.Conn(dbreplica.WithPrimary(ctx), r.db)
+}
+
+// Count 统计过期记录数
+func (r *Repository) Count(ctx context.Context, p *job.Policy, cutoff time.Time) (int64, error) {
+	cond, args := condition(p, cutoff)
+	var n int64
+	err := r.conn(ctx).Table(p.Table).Where(cond, args).Count(&n).Error
+	return n, err
+}
+
+// Delete 删除至多 limit 条过期记录
+func (r *Repository) Delete(ctx context.Context, p *job.Policy, cutoff time.Time, limit int) (int64, error) {
+	ids, err := r.pick(ctx, p, cutoff, limit)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	cond, args := condition(p, cutoff)
+	args["ids"] = ids
+	result := r.conn(ctx).Exec("DELETE FROM "+p.Table+" WHERE id IN @ids AND "+cond, args)
+	return result.RowsAffected, result.Error
+}
+
+// Anonymize 对至多 limit 条过期记录写入 columns
+func (r *Repository) Anonymize(ctx context.Context, p *job.Policy, cutoff time.Time, limit int, columns map[string]any) (int64, error) {
+	ids, err := r.pick(ctx, p, cutoff, limit)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+	cond, args := condition(p, cutoff)
+	result := r.conn(ctx).Table(p.Table).
+		Where("id IN ?", ids).
+		Where(cond, args).
+		UpdateColumns(columns)
+	return result.RowsAffected, result.Error
+}
+
+// pick 按主键升序选出一批过期记录
+func (r *Repository) pick(ctx context.Context, p *job.Policy, cutoff time.Time, limit int) ([]uint64, error) {
+	cond, args := condition(p, cutoff)
+	var ids []uint64
+	err := r.conn(ctx).Table(p.Table).
+		Where(cond, args).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// condition 返回策略的过滤条件与命名参数
+func condition(p *job.Policy, cutoff time.Time) (string, map[string]any) {
+	args := make(map[string]any, len(p.Args)+2)
+	maps.Copy(args, p.Args)
+	args["cutoff"] = cutoff.UTC()
+
+	cond := p.AgeColumn + " < @cutoff"
+	if p.Where != "" {
+		cond += " AND (" + p.Where + ")"
+	}
+	return cond, args
+}
@@ -0,0 +1,151 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/job"
+	"arch3/internal/repository/dbtest"
+	userrepo "arch3/internal/repository/user"
+
+	"go.opentelemetry.io/otel/metric/noop"
+	"gorm.io/gorm"
+)
+
+const day = 24 * time.Hour
+
+// seedUser 写入用户记录，opts 修改默认字段
+func seedUser(t *testing.T, db *gorm.DB, userID string, createdAgo time.Duration, opts ...func(e *userrepo.Entity)) {
+	t.Helper()
+	e := &userrepo.Entity{
+		UserID:       userID,
+		UserName:     userID,
+		PasswordHash: "hash",
+		PhoneNumber:  "enc-" + userID,
+		Gender:       domain.GenderOther,
+		Status:       domain.StatusRealNameUnverified,
+		CreatedAt:    time.Now().UTC().Add(-createdAgo),
+		UpdatedAt:    time.Now().UTC().Add(-createdAgo),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if err := db.Create(e).Error; err != nil {
+		t.Fatalf("create user %s error = %v", userID, err)
+	}
+}
+
+// seedLogin 写入登录记录
+func seedLogin(t *testing.T, db *gorm.DB, userID string, ago time.Duration) {
+	t.Helper()
+	e := &userrepo.LoginHistoryEntity{UserID: userID, Event: domain.LoginEventLogin, CreatedAt: time.Now().UTC().Add(-ago)}
+	if err := db.Create(e).Error; err != nil {
+		t.Fatalf("create login error = %v", err)
+	}
+}
+
+// findUser 查询用户记录（含已软删除）
+func findUser(t *testing.T, db *gorm.DB, userID string) *userrepo.Entity {
+	t.Helper()
+	var e userrepo.Entity
+	err := db.Unscoped().Where("user_id = ?", userID).First(&e).Error
+	if err != nil {
+		return nil
+	}
+	return &e
+}
+
+func TestRepository_LoginHistoryRetention(t *testing.T) {
+	db := dbtest.SQLite(t)
+	store := NewRepository(db)
+	ctx := context.Background()
+	policy := userrepo.LoginHistoryRetention(180 * day)
+	cutoff := time.Now().Add(-policy.MaxAge)
+
+	seedLogin(t, db, "u1", 200*day)
+	seedLogin(t, db, "u1", 190*day)
+	seedLogin(t, db, "u1", 10*day)
+
+	if n, err := store.Count(ctx, &policy, cutoff); err != nil || n != 2 {
+		t.Fatalf("Expected 2 expired records, got %d, %v", n, err)
+	}
+
+	for i, want := range []int64{1, 1, 0} {
+		n, err := store.Delete(ctx, &policy, cutoff, 1)
+		if err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if n != want {
+			t.Errorf("Expected batch %d to delete %d, got %d", i, want, n)
+		}
+	}
+
+	var remaining int64
+	db.Model(&userrepo.LoginHistoryEntity{}).Count(&remaining)
+	if remaining != 1 {
+		t.Errorf("Expected 1 remaining record, got %d", remaining)
+	}
+}
+
+func TestRepository_DeletedUserRetention(t *testing.T) {
+	db := dbtest.SQLite(t)
+	store := NewRepository(db)
+	ctx := context.Background()
+	policy := userrepo.DeletedUserRetention(365 * day)
+
+	deletedAgo := func(ago time.Duration) func(e *userrepo.Entity) {
+		return func(e *userrepo.Entity) {
+			e.DeletedAt = gorm.DeletedAt{Time: time.Now().UTC().Add(-ago), Valid: true}
+		}
+	}
+	seedUser(t, db, "expired", 800*day, deletedAgo(400*day))
+	seedUser(t, db, "recent", 800*day, deletedAgo(10*day))
+	seedUser(t, db, "active", 800*day)
+
+	n, err := store.Delete(ctx, &policy, time.Now().Add(-policy.MaxAge), 10)
+	if err != nil || n != 1 {
+		t.Fatalf("Expected 1 deleted user, got %d, %v", n, err)
+	}
+
+	tests := []struct {
+		userID     string
+		wantExists bool
+	}{
+		{userID: "expired", wantExists: false},
+		{userID: "recent", wantExists: true},
+		{userID: "active", wantExists: true},
+	}
+	for _, tt := range tests {
+		if got := findUser(t, db, tt.userID) != nil; got != tt.wantExists {
+			t.Errorf("Expected %s exists = %v, got %v", tt.userID, tt.wantExists, got)
+		}
+	}
+}
+
+func TestRepository_WithRetention(t *testing.T) {
+	db := dbtest.SQLite(t)
+	rdb, _ := newTestRedis(t)
+	cfg := job.RetentionConfig{BatchSize: 1, MaxBatches: 10, LockTTL: time.Minute}
+	retention, err := job.NewRetention(NewRepository(db), NewRedisLocker(rdb), noop.NewMeterProvider().Meter("test"), cfg)
+	if err != nil {
+		t.Fatalf("NewRetention() error = %v", err)
+	}
+	if err := retention.Register(userrepo.LoginHistoryRetention(180 * day)); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	for range 3 {
+		seedLogin(t, db, "u1", 200*day)
+	}
+	if err := retention.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce() error = %v", err)
+	}
+
+	var remaining int64
+	db.Model(&userrepo.LoginHistoryEntity{}).Count(&remaining)
+	if remaining != 0 {
+		t.Errorf("Expected all expired records deleted in batches, got %d remaining", remaining)
+	}
+}
@@ -0,0 +1,323 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/dbreplica"
+	"arch3/pkg/dbtx"
+	"arch3/pkg/fieldcrypt"
+	"arch3/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Redis key 格式:
+//   - user:cache:id:{user_id}         用户数据（整体加密）或不存在标记
+//   - user:cache:phone:{盲索引(手机号)} 用户 ID 或不存在标记
+//
+// 手机号只以盲索引出现在 key 中，用户数据以 user_id 作为 associated data 加密后存储，
+// Redis 数据泄露不会暴露明文个人信息，也无法把一个用户的密文挪给另一个用户。
+const (
+	userCacheIDPrefix    = "user:cache:id:"
+	userCachePhonePrefix = "user:cache:phone:"
+)
+
+// 缓存值的 associated data 前缀与手机号 key 的盲索引列名
+const (
+	aadUserCache     = "user_cache:"
+	indexCachedPhone = "cache:phone_number"
+)
+
+// userCacheMissing 不存在标记，加密数据与用户 ID 都不会等于该值
+const userCacheMissing = "-"
+
+// CacheConfig 用户缓存配置
+type CacheConfig struct {
+	// TTL 用户数据的缓存时间
+	TTL time.Duration
+	// NegativeTTL 用户不存在的缓存时间，应远小于 TTL，避免新注册用户长时间查不到
+	NegativeTTL time.Duration
+	// Jitter TTL 随机延长的最大比例（0~1），避免同一批写入的缓存同时过期
+	Jitter float64
+}
+
+// CachedRepository 带 Redis 缓存的用户仓储（cache-aside）
+//
+// 按用户 ID 与手机号缓存 FindByUserID / FindByPhoneNumber，其余查询直接透传。
+// 读未命中时用 singleflight 合并同一 key 的并发回源，回源强制走主库，避免把从库的旧数据写入缓存；
+// 任何写操作成功或失败后都删除相关缓存（失败的条件更新可能说明缓存已过期）。
+// 事务中的查询直接读数据库（需要看到事务内未提交的写入），写操作在事务提交后才删除缓存。
+// Redis 不可用时记录日志并回退到数据库，不影响业务。
+//
+// 不经过本仓储的写入（如组织成员变更同步 users.group_id），以及与写入并发、
+// 在删除缓存之后才写回的旧数据，在 TTL 到期前不会反映到缓存。
+type CachedRepository struct {
+	userservice.Repository
+
+	rdb   *redis.Client
+	keys  *fieldcrypt.Keyring
+	cfg   CacheConfig
+	group singleflight.Group
+}
+
+// NewCachedRepository 创建带缓存的用户仓储
+func NewCachedRepository(next userservice.Repository, rdb *redis.Client, keys *fieldcrypt.Keyring, cfg CacheConfig) userservice.Repository {
+	return &CachedRepository{Repository: next, rdb: rdb, keys: keys, cfg: cfg}
+}
+
+// FindByUserID 根据业务 ID 查询用户，优先读缓存
+func (r *CachedRepository) FindByUserID(ctx context.Context, userID string) (*domain.User, error) {
+	if dbtx.InTx(ctx) {
+		return r.Repository.FindByUserID(ctx, userID)
+	}
+	key := r.idKey(userID)
+	if u, hit := r.getUser(ctx, key, userID); hit {
+		if u == nil {
+			return nil, domain.ErrUserNotFound
+		}
+		return u, nil
+	}
+
+	v, err, _ := r.group.Do(key, func() (any, error) {
+		u, err := r.Repository.FindByUserID(dbreplica.WithPrimary(ctx), userID)
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			r.set(ctx, key, userCacheMissing, r.cfg.NegativeTTL)
+		case err == nil:
+			r.setUser(ctx, key, u)
+		}
+		return u, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(v.(*domain.User)), nil
+}
+
+// FindByPhoneNumber 根据手机号查询用户
+// 手机号 key 只保存用户 ID，用户数据仍从 ID 缓存读取，更新用户时无需知道其手机号也能保持一致
+func (r *CachedRepository) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
+	if dbtx.InTx(ctx) {
+		return r.Repository.FindByPhoneNumber(ctx, phoneNumber)
+	}
+	key := r.phoneKey(phoneNumber)
+	userID, err := r.rdb.Get(ctx, key).Result()
+	switch {
+	case err == nil && userID == userCacheMissing:
+		return nil, domain.ErrUserNotFound
+	case err == nil:
+		// 手机号已更换或账号已匿名化时指向的用户不再匹配，删除后回源
+		u, err := r.FindByUserID(ctx, userID)
+		if err == nil && u.PhoneNumber == phoneNumber {
+			return u, nil
+		}
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		r.del(ctx, key)
+	case !errors.Is(err, redis.Nil):
+		r.logError(ctx, "get", err)
+	}
+
+	v, err, _ := r.group.Do(key, func() (any, error) {
+		u, err := r.Repository.FindByPhoneNumber(dbreplica.WithPrimary(ctx), phoneNumber)
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
+			r.set(ctx, key, userCacheMissing, r.cfg.NegativeTTL)
+		case err == nil:
+			r.setUser(ctx, r.idKey(u.UserID), u)
+			r.set(ctx, key, u.UserID, r.cfg.TTL)
+		}
+		return u, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(v.(*domain.User)), nil
+}
+
+// Create 创建用户，并清除手机号与 ID 上的不存在标记
+func (r *CachedRepository) Create(ctx context.Context, u *domain.User) error {
+	defer r.invalidate(ctx, u.UserID, u.PhoneNumber)
+	return r.Repository.Create(ctx, u)
+}
+
+// Update 更新用户
+func (r *CachedRepository) Update(ctx context.Context, u *domain.User) error {
+	defer r.invalidate(ctx, u.UserID, u.PhoneNumber)
+	return r.Repository.Update(ctx, u)
+}
+
+// UpdateFields 更新指定字段
+func (r *CachedRepository) UpdateFields(ctx context.Context, u *domain.User, fields ...domain.Field) error {
+	defer r.invalidate(ctx, u.UserID, u.PhoneNumber)
+	return r.Repository.UpdateFields(ctx, u, fields...)
+}
+
+// UpdateProfile 更新个人资料
+func (r *CachedRepository) UpdateProfile(ctx context.Context, userID string, upd *domain.ProfileUpdate) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.UpdateProfile(ctx, userID, upd)
+}
+
+// UpdatePhoneNumber 更换手机号，新旧手机号的缓存都会清除
+func (r *CachedRepository) UpdatePhoneNumber(ctx context.Context, userID, oldPhone, newPhone string) error {
+	defer r.invalidate(ctx, userID, oldPhone, newPhone)
+	return r.Repository.UpdatePhoneNumber(ctx, userID, oldPhone, newPhone)
+}
+
+// MarkEmailVerified 标记邮箱已验证
+func (r *CachedRepository) MarkEmailVerified(ctx context.Context, userID, email string) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.MarkEmailVerified(ctx, userID, email)
+}
+
+// UpdateRealName 更新实名信息
+func (r *CachedRepository) UpdateRealName(ctx context.Context, userID, fromStatus string, upd *domain.RealNameUpdate) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.UpdateRealName(ctx, userID, fromStatus, upd)
+}
+
+// ScheduleDeletion 申请注销
+func (r *CachedRepository) ScheduleDeletion(ctx context.Context, userID string, scheduledAt time.Time) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.ScheduleDeletion(ctx, userID, scheduledAt)
+}
+
+// ScheduleInactiveDeletion 不活跃用户转入注销流程
+func (r *CachedRepository) ScheduleInactiveDeletion(ctx context.Context, userID string, before, scheduledAt time.Time) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.ScheduleInactiveDeletion(ctx, userID, before, scheduledAt)
+}
+
+// MarkOnboarded 记录完成引导时间
+// 最近活跃时间（TouchLastActive）写入频繁，不清除缓存，缓存中的值可能滞后
+func (r *CachedRepository) MarkOnboarded(ctx context.Context, userID string, at time.Time) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.MarkOnboarded(ctx, userID, at)
+}
+
+// CancelDeletion 撤销注销
+func (r *CachedRepository) CancelDeletion(ctx context.Context, userID string) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.CancelDeletion(ctx, userID)
+}
+
+// Anonymize 匿名化用户
+// 原手机号的缓存会在下次查询时因用户不再匹配而被清除
+func (r *CachedRepository) Anonymize(ctx context.Context, userID string) error {
+	defer r.invalidate(ctx, userID)
+	return r.Repository.Anonymize(ctx, userID)
+}
+
+// ChangeStatus 变更用户状态
+func (r *CachedRepository) ChangeStatus(ctx context.Context, change *domain.StatusChange) error {
+	defer r.invalidate(ctx, change.UserID)
+	return r.Repository.ChangeStatus(ctx, change)
+}
+
+// invalidate 删除用户 ID 与手机号对应的缓存，ctx 在事务中时推迟到提交后
+func (r *CachedRepository) invalidate(ctx context.Context, userID string, phones ...string) {
+	keys := make([]string, 0, 1+len(phones))
+	if userID != "" {
+		keys = append(keys, r.idKey(userID))
+	}
+	for _, p := range phones {
+		if p != "" {
+			keys = append(keys, r.phoneKey(p))
+		}
+	}
+	if len(keys) > 0 {
+		dbtx.AfterCommit(ctx, func(ctx context.Context) {
+			r.del(ctx, keys...)
+		})
+	}
+}
+
+// getUser 读取用户缓存，hit 为 false 表示未命中；命中不存在标记时返回 nil, true
+func (r *CachedRepository) getUser(ctx context.Context, key, userID string) (*domain.User, bool) {
+	data, err := r.rdb.Get(ctx, key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			r.logError(ctx, "get", err)
+		}
+		return nil, false
+	}
+	if data == userCacheMissing {
+		return nil, true
+	}
+
+	plain, err := r.keys.Decrypt(data, aadUserCache+userID)
+	if err != nil {
+		r.logError(ctx, "decrypt", err)
+		return nil, false
+	}
+	var u domain.User
+	if err := json.Unmarshal([]byte(plain), &u); err != nil {
+		r.logError(ctx, "decode", err)
+		return nil, false
+	}
+	return &u, true
+}
+
+// setUser 加密后写入用户缓存
+func (r *CachedRepository) setUser(ctx context.Context, key string, u *domain.User) {
+	data, err := json.Marshal(u)
+	if err != nil {
+		r.logError(ctx, "encode", err)
+		return
+	}
+	encrypted, err := r.keys.Encrypt(string(data), aadUserCache+u.UserID)
+	if err != nil {
+		r.logError(ctx, "encrypt", err)
+		return
+	}
+	r.set(ctx, key, encrypted, r.cfg.TTL)
+}
+
+func (r *CachedRepository) set(ctx context.Context, key, value string, ttl time.Duration) {
+	if err := r.rdb.Set(ctx, key, value, r.jitter(ttl)).Err(); err != nil {
+		r.logError(ctx, "set", err)
+	}
+}
+
+func (r *CachedRepository) del(ctx context.Context, keys ...string) {
+	if err := r.rdb.Del(ctx, keys...).Err(); err != nil {
+		r.logError(ctx, "del", err)
+	}
+}
+
+// jitter 在 [ttl, ttl*(1+Jitter)) 内随机取值
+func (r *CachedRepository) jitter(ttl time.Duration) time.Duration {
+	if r.cfg.Jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Float64()*r.cfg.Jitter*float64(ttl))
+}
+
+func (r *CachedRepository) idKey(userID string) string {
+	return userCacheIDPrefix + userID
+}
+
+func (r *CachedRepository) phoneKey(phoneNumber string) string {
+	return userCachePhonePrefix + r.keys.BlindIndex(indexCachedPhone, phoneNumber)
+}
+
+func (r *CachedRepository) logError(ctx context.Context, op string, err error) {
+	logger.Ctx(ctx).Warn("user cache unavailable, falling back to database",
+		zap.String("op", op), zap.Error(err))
+}
+
+// clone singleflight 的结果由多个调用方共享，返回副本避免互相修改
+func clone(u *domain.User) *domain.User {
+	c := *u
+	return &c
+}
@@ -0,0 +1,353 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/repository/dbtest"
+	"arch3/internal/repository/repotest"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/dbtx"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// countingRepo 统计回源次数，gate 非空时查询会阻塞到 gate 关闭
+type countingRepo struct {
+	userservice.Repository
+	byID    atomic.Int32
+	byPhone atomic.Int32
+	gate    chan struct{}
+}
+
+func (r *countingRepo) FindByUserID(ctx context.Context, userID string) (*domain.User, error) {
+	r.byID.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	return r.Repository.FindByUserID(ctx, userID)
+}
+
+func (r *countingRepo) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
+	r.byPhone.Add(1)
+	return r.Repository.FindByPhoneNumber(ctx, phoneNumber)
+}
+
+func newCachedRepo(t *testing.T) (userservice.Repository, *countingRepo, *miniredis.Miniredis) {
+	t.Helper()
+	return newCachedRepoOn(t, dbtest.SQLite(t))
+}
+
+func newCachedRepoOn(t *testing.T, db *gorm.DB) (userservice.Repository, *countingRepo, *miniredis.Miniredis) {
+	t.Helper()
+	keys := newTestKeyring(t)
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	inner := &countingRepo{Repository: NewRepository(NewDAO(db), keys)}
+	repo := NewCachedRepository(inner, rdb, keys, CacheConfig{
+		TTL:         10 * time.Minute,
+		NegativeTTL: 30 * time.Second,
+		Jitter:      0.1,
+	})
+	return repo, inner, mr
+}
+
+// 缓存层对外行为须与直接访问数据库一致
+func TestCachedRepository_Contract(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) userservice.Repository {
+		repo, _, _ := newCachedRepo(t)
+		return repo
+	})
+}
+
+func newCacheTestUser(userID, phone string) *domain.User {
+	return &domain.User{
+		UserID:      userID,
+		UserName:    "name_" + userID,
+		PhoneNumber: phone,
+		Gender:      domain.GenderOther,
+		Status:      domain.StatusRealNameUnverified,
+	}
+}
+
+func TestCachedRepository_FindByUserID(t *testing.T) {
+	repo, inner, mr := newCachedRepo(t)
+	ctx := context.Background()
+	if err := repo.Create(ctx, newCacheTestUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		u, err := repo.FindByUserID(ctx, "u1")
+		if err != nil {
+			t.Fatalf("FindByUserID() error = %v", err)
+		}
+		if u.PhoneNumber != "13800000001" {
+			t.Errorf("Expected phone 13800000001, got %s", u.PhoneNumber)
+		}
+	}
+	if n := inner.byID.Load(); n != 1 {
+		t.Errorf("Expected 1 database lookup, got %d", n)
+	}
+
+	raw, err := mr.Get(userCacheIDPrefix + "u1")
+	if err != nil {
+		t.Fatalf("cache key missing: %v", err)
+	}
+	if strings.Contains(raw, "13800000001") || strings.Contains(raw, "name_u1") {
+		t.Errorf("Expected cached value to be encrypted, got %s", raw)
+	}
+	ttl := mr.TTL(userCacheIDPrefix + "u1")
+	if ttl < 10*time.Minute || ttl >= 11*time.Minute {
+		t.Errorf("Expected TTL in [10m, 11m), got %v", ttl)
+	}
+}
+
+func TestCachedRepository_FindByPhoneNumber(t *testing.T) {
+	repo, inner, mr := newCachedRepo(t)
+	ctx := context.Background()
+	if err := repo.Create(ctx, newCacheTestUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		u, err := repo.FindByPhoneNumber(ctx, "13800000001")
+		if err != nil {
+			t.Fatalf("FindByPhoneNumber() error = %v", err)
+		}
+		if u.UserID != "u1" {
+			t.Errorf("Expected u1, got %s", u.UserID)
+		}
+	}
+	if n := inner.byPhone.Load(); n != 1 {
+		t.Errorf("Expected 1 phone lookup, got %d", n)
+	}
+	if n := inner.byID.Load(); n != 0 {
+		t.Errorf("Expected phone lookup to populate ID cache, got %d ID lookups", n)
+	}
+	for _, k := range mr.Keys() {
+		if strings.Contains(k, "13800000001") {
+			t.Errorf("Expected phone number not to appear in key, got %s", k)
+		}
+	}
+}
+
+func TestCachedRepository_Negative(t *testing.T) {
+	repo, inner, mr := newCachedRepo(t)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := repo.FindByPhoneNumber(ctx, "13800000009"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("Expected ErrUserNotFound, got %v", err)
+		}
+		if _, err := repo.FindByUserID(ctx, "u9"); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("Expected ErrUserNotFound, got %v", err)
+		}
+	}
+	if inner.byPhone.Load() != 1 || inner.byID.Load() != 1 {
+		t.Errorf("Expected negative results to be cached, got %d phone / %d ID lookups", inner.byPhone.Load(), inner.byID.Load())
+	}
+	if ttl := mr.TTL(userCacheIDPrefix + "u9"); ttl > 33*time.Second {
+		t.Errorf("Expected short negative TTL, got %v", ttl)
+	}
+
+	// 注册后不存在标记立即失效
+	if err := repo.Create(ctx, newCacheTestUser("u9", "13800000009")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if u, err := repo.FindByPhoneNumber(ctx, "13800000009"); err != nil || u.UserID != "u9" {
+		t.Errorf("Expected u9 after Create, got %v, %v", u, err)
+	}
+	if _, err := repo.FindByUserID(ctx, "u9"); err != nil {
+		t.Errorf("Expected u9 after Create, got %v", err)
+	}
+}
+
+func TestCachedRepository_Invalidation(t *testing.T) {
+	repo, _, _ := newCachedRepo(t)
+	ctx := context.Background()
+	if err := repo.Create(ctx, newCacheTestUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		write func() error
+		check func(t *testing.T)
+	}{
+		{
+			name: "Update",
+			write: func() error {
+				u, err := repo.FindByUserID(ctx, "u1")
+				if err != nil {
+					return err
+				}
+				u.UserName = "renamed"
+				return repo.Update(ctx, u)
+			},
+			check: func(t *testing.T) {
+				u, err := repo.FindByUserID(ctx, "u1")
+				if err != nil || u.UserName != "renamed" {
+					t.Errorf("Expected renamed user, got %v, %v", u, err)
+				}
+			},
+		},
+		{
+			name: "ChangeStatus",
+			write: func() error {
+				return repo.ChangeStatus(ctx, &domain.StatusChange{
+					UserID: "u1", FromStatus: domain.StatusRealNameUnverified, ToStatus: domain.StatusBanned, Reason: "test",
+				})
+			},
+			check: func(t *testing.T) {
+				u, err := repo.FindByUserID(ctx, "u1")
+				if err != nil || u.Status != domain.StatusBanned {
+					t.Errorf("Expected banned user, got %v, %v", u, err)
+				}
+			},
+		},
+		{
+			name: "UpdatePhoneNumber 新旧手机号都失效",
+			write: func() error {
+				return repo.UpdatePhoneNumber(ctx, "u1", "13800000001", "13900000001")
+			},
+			check: func(t *testing.T) {
+				if _, err := repo.FindByPhoneNumber(ctx, "13800000001"); !errors.Is(err, domain.ErrUserNotFound) {
+					t.Errorf("Expected old phone to be released, got %v", err)
+				}
+				u, err := repo.FindByPhoneNumber(ctx, "13900000001")
+				if err != nil || u.UserID != "u1" {
+					t.Errorf("Expected u1 by new phone, got %v, %v", u, err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 先填充缓存，确保验证的是失效而不是首次回源
+			if _, err := repo.FindByUserID(ctx, "u1"); err != nil {
+				t.Fatalf("FindByUserID() error = %v", err)
+			}
+			if _, err := repo.FindByPhoneNumber(ctx, "13800000001"); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+				t.Fatalf("FindByPhoneNumber() error = %v", err)
+			}
+			if _, err := repo.FindByPhoneNumber(ctx, "13900000001"); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+				t.Fatalf("FindByPhoneNumber() error = %v", err)
+			}
+			if err := tt.write(); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			tt.check(t)
+		})
+	}
+}
+
+func TestCachedRepository_Singleflight(t *testing.T) {
+	repo, inner, _ := newCachedRepo(t)
+	ctx := context.Background()
+	if err := repo.Create(ctx, newCacheTestUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	inner.gate = make(chan struct{})
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.FindByUserID(ctx, "u1")
+			errs <- err
+		}()
+	}
+	// 等第一个回源进入阻塞后放行，其余请求应合并到同一次回源
+	for inner.byID.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(inner.gate)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("FindByUserID() error = %v", err)
+		}
+	}
+	if n := inner.byID.Load(); n != 1 {
+		t.Errorf("Expected concurrent misses to share 1 lookup, got %d", n)
+	}
+}
+
+func TestCachedRepository_RedisDown(t *testing.T) {
+	repo, inner, mr := newCachedRepo(t)
+	ctx := context.Background()
+	if err := repo.Create(ctx, newCacheTestUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	mr.Close()
+
+	for i := 0; i < 2; i++ {
+		if u, err := repo.FindByUserID(ctx, "u1"); err != nil || u.UserID != "u1" {
+			t.Fatalf("Expected fallback to database, got %v, %v", u, err)
+		}
+	}
+	if n := inner.byID.Load(); n != 2 {
+		t.Errorf("Expected every lookup to hit the database, got %d", n)
+	}
+}
+
+func TestCachedRepository_Transaction(t *testing.T) {
+	db := dbtest.SQLite(t)
+	repo, inner, _ := newCachedRepoOn(t, db)
+	tx := dbtx.New(db, dbtx.Config{})
+	ctx := context.Background()
+	if err := repo.Create(ctx, newCacheTestUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := repo.FindByUserID(ctx, "u1"); err != nil {
+		t.Fatalf("FindByUserID() error = %v", err)
+	}
+
+	err := tx.Do(ctx, func(txCtx context.Context) error {
+		u, err := repo.FindByUserID(txCtx, "u1")
+		if err != nil {
+			return err
+		}
+		u.UserName = "renamed"
+		if err := repo.Update(txCtx, u); err != nil {
+			return err
+		}
+
+		// 事务内读到未提交的写入，事务外在提交前仍读到缓存中的已提交数据
+		if u, err := repo.FindByUserID(txCtx, "u1"); err != nil || u.UserName != "renamed" {
+			t.Errorf("Expected uncommitted write inside transaction, got %v, %v", u, err)
+		}
+		if u, err := repo.FindByUserID(ctx, "u1"); err != nil || u.UserName != "name_u1" {
+			t.Errorf("Expected committed data outside transaction, got %v, %v", u, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+
+	before := inner.byID.Load()
+	if u, err := repo.FindByUserID(ctx, "u1"); err != nil || u.UserName != "renamed" {
+		t.Errorf("Expected cache invalidated after commit, got %v, %v", u, err)
+	}
+	if inner.byID.Load() != before+1 {
+		t.Errorf("Expected lookup after commit to reload from database")
+	}
+}
@@ -1,50 +1,216 @@
 package user
 
 import (
+	"database/sql"
+	"fmt"
+
 	domain "arch3/internal/domain/user"
+	"arch3/pkg/fieldcrypt"
 	"arch3/pkg/sqlx"
 )
 
-// toDomain 将 DAO 实体转换为领域模型
-func toDomain(entity *Entity) *domain.User {
+// 加密列的 associated data 前缀，完整格式为 "{前缀}{user_id}"
+const (
+	aadPhoneNumber = "users.phone_number:"
+	aadEmail       = "users.email:"
+	aadRealName    = "users.real_name:"
+	aadIDNumber    = "users.id_number:"
+)
+
+// 盲索引的列名，参与 HMAC 计算，修改后须重建全部索引
+const (
+	indexPhoneNumber = "phone_number"
+	indexPhoneTail   = "phone_number_tail"
+	indexEmail       = "email"
+	indexIDNumber    = "id_number"
+)
+
+// toDomain 将 DAO 实体转换为领域模型，解密敏感字段
+func toDomain(entity *Entity, k *fieldcrypt.Keyring) (*domain.User, error) {
+	phoneNumber, err := k.Decrypt(entity.PhoneNumber, aadPhoneNumber+entity.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt phone_number of %s: %w", entity.UserID, err)
+	}
+	email, err := decryptNull(k, entity.Email, aadEmail+entity.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt email of %s: %w", entity.UserID, err)
+	}
+	realName, err := decryptNull(k, entity.RealName, aadRealName+entity.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt real_name of %s: %w", entity.UserID, err)
+	}
+	idNumber, err := decryptNull(k, entity.IDNumber, aadIDNumber+entity.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt id_number of %s: %w", entity.UserID, err)
+	}
+
 	return &domain.User{
-		ID:           entity.ID,
-		UserID:       entity.UserID,
-		GroupID:      sqlx.NullStringToPtr(entity.GroupID),
-		UserName:     entity.UserName,
-		RealName:     sqlx.NullStringToPtr(entity.RealName),
-		PasswordHash: entity.PasswordHash,
-		Email:        sqlx.NullStringToPtr(entity.Email),
-		PhoneNumber:  entity.PhoneNumber,
-		AvatarURL:    sqlx.NullStringToPtr(entity.AvatarURL),
-		Gender:       entity.Gender,
-		CreatedAt:    entity.CreatedAt,
-		UpdatedAt:    entity.UpdatedAt,
-		Status:       entity.Status,
-		IDNumber:     sqlx.NullStringToPtr(entity.IDNumber),
-		Source:       sqlx.NullStringToPtr(entity.Source),
-		DeviceID:     sqlx.NullStringToPtr(entity.DeviceID),
-	}
-}
-
-// toEntity 将领域模型转换为 DAO 实体
-func toEntity(u *domain.User) *Entity {
+		ID:            entity.ID,
+		UserID:        entity.UserID,
+		GroupID:       sqlx.NullStringToPtr(entity.GroupID),
+		UserName:      entity.UserName,
+		RealName:      sqlx.NullStringToPtr(realName),
+		PasswordHash:  entity.PasswordHash,
+		Email:         sqlx.NullStringToPtr(email),
+		EmailVerified: entity.EmailVerified,
+		PhoneNumber:   phoneNumber,
+		AvatarURL:     sqlx.NullStringToPtr(entity.AvatarURL),
+		Gender:        entity.Gender,
+		CreatedAt:     entity.CreatedAt,
+		UpdatedAt:     entity.UpdatedAt,
+		Status:        entity.Status,
+		IDNumber:      sqlx.NullStringToPtr(idNumber),
+		Source:        sqlx.NullStringToPtr(entity.Source),
+		DeviceID:      sqlx.NullStringToPtr(entity.DeviceID),
+
+		DeletionScheduledAt: sqlx.NullTimeToPtr(entity.DeletionScheduledAt),
+		OnboardedAt:         sqlx.NullTimeToPtr(entity.OnboardedAt),
+		LastActiveAt:        sqlx.NullTimeToPtr(entity.LastActiveAt),
+		Version:             entity.Version,
+	}, nil
+}
+
+// toEntity 将领域模型转换为 DAO 实体，加密敏感字段并计算盲索引
+func toEntity(u *domain.User, k *fieldcrypt.Keyring) (*Entity, error) {
+	phoneNumber, err := k.Encrypt(u.PhoneNumber, aadPhoneNumber+u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	email, err := encryptNull(k, sqlx.PtrToNullString(u.Email), aadEmail+u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	realName, err := encryptNull(k, sqlx.PtrToNullString(u.RealName), aadRealName+u.UserID)
+	if err != nil {
+		return nil, err
+	}
+	idNumber, err := encryptNull(k, sqlx.PtrToNullString(u.IDNumber), aadIDNumber+u.UserID)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Entity{
-		ID:           u.ID,
-		UserID:       u.UserID,
-		GroupID:      sqlx.PtrToNullString(u.GroupID),
-		UserName:     u.UserName,
-		RealName:     sqlx.PtrToNullString(u.RealName),
-		PasswordHash: u.PasswordHash,
-		Email:        sqlx.PtrToNullString(u.Email),
-		PhoneNumber:  u.PhoneNumber,
-		AvatarURL:    sqlx.PtrToNullString(u.AvatarURL),
-		Gender:       u.Gender,
-		CreatedAt:    u.CreatedAt,
-		UpdatedAt:    u.UpdatedAt,
-		Status:       u.Status,
-		IDNumber:     sqlx.PtrToNullString(u.IDNumber),
-		Source:       sqlx.PtrToNullString(u.Source),
-		DeviceID:     sqlx.PtrToNullString(u.DeviceID),
+		ID:            u.ID,
+		UserID:        u.UserID,
+		GroupID:       sqlx.PtrToNullString(u.GroupID),
+		UserName:      u.UserName,
+		RealName:      realName,
+		PasswordHash:  u.PasswordHash,
+		Email:         email,
+		EmailHash:     blindIndex(k, indexEmail, sqlx.PtrToNullString(u.Email)),
+		EmailVerified: u.EmailVerified,
+		PhoneNumber:   phoneNumber,
+		PhoneHash:     emptyToNull(k.BlindIndex(indexPhoneNumber, u.PhoneNumber)),
+		PhoneTailHash: emptyToNull(phoneTailIndex(k, u.PhoneNumber)),
+		AvatarURL:     sqlx.PtrToNullString(u.AvatarURL),
+		Gender:        u.Gender,
+		CreatedAt:     u.CreatedAt,
+		UpdatedAt:     u.UpdatedAt,
+		Status:        u.Status,
+		IDNumber:      idNumber,
+		IDNumberHash:  blindIndex(k, indexIDNumber, sqlx.PtrToNullString(u.IDNumber)),
+		Source:        sqlx.PtrToNullString(u.Source),
+		DeviceID:      sqlx.PtrToNullString(u.DeviceID),
+
+		DeletionScheduledAt: sqlx.PtrToNullTime(u.DeletionScheduledAt),
+		OnboardedAt:         sqlx.PtrToNullTime(u.OnboardedAt),
+		LastActiveAt:        sqlx.PtrToNullTime(u.LastActiveAt),
+		Version:             u.Version,
+	}, nil
+}
+
+// fieldColumns 把可更新字段对应的列与值写入 columns，加密字段连同盲索引一起写入
+func fieldColumns(e *Entity, f domain.Field, columns map[string]any) error {
+	switch f {
+	case domain.FieldUserName:
+		columns["user_name"] = e.UserName
+	case domain.FieldRealName:
+		columns["real_name"] = e.RealName
+	case domain.FieldPasswordHash:
+		columns["password_hash"] = e.PasswordHash
+	case domain.FieldEmail:
+		columns["email"] = e.Email
+		columns["email_hash"] = e.EmailHash
+	case domain.FieldEmailVerified:
+		columns["email_verified"] = e.EmailVerified
+	case domain.FieldPhoneNumber:
+		columns["phone_number"] = e.PhoneNumber
+		columns["phone_hash"] = e.PhoneHash
+		columns["phone_tail_hash"] = e.PhoneTailHash
+	case domain.FieldAvatarURL:
+		columns["avatar_url"] = e.AvatarURL
+	case domain.FieldGender:
+		columns["gender"] = e.Gender
+	case domain.FieldStatus:
+		columns["status"] = e.Status
+	case domain.FieldIDNumber:
+		columns["id_number"] = e.IDNumber
+		columns["id_number_hash"] = e.IDNumberHash
+	case domain.FieldSource:
+		columns["source"] = e.Source
+	case domain.FieldDeviceID:
+		columns["device_id"] = e.DeviceID
+	case domain.FieldDeletionScheduledAt:
+		columns["deletion_scheduled_at"] = e.DeletionScheduledAt
+	default:
+		return fmt.Errorf("user: unknown field %q", f)
+	}
+	return nil
+}
+
+// phoneTailIndex 计算手机号后 4 位的盲索引，不足 4 位返回空字符串
+func phoneTailIndex(k *fieldcrypt.Keyring, phone string) string {
+	if len(phone) < 4 {
+		return ""
+	}
+	return k.BlindIndex(indexPhoneTail, phone[len(phone)-4:])
+}
+
+// statusChangeToDomain 将状态变更实体转换为领域模型
+func statusChangeToDomain(e *StatusChangeEntity) *domain.StatusChange {
+	return &domain.StatusChange{
+		UserID:     e.UserID,
+		FromStatus: e.FromStatus,
+		ToStatus:   e.ToStatus,
+		Reason:     e.Reason,
+		OperatorID: e.OperatorID,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// encryptNull 加密可空字段，NULL 与空字符串保持原样
+func encryptNull(k *fieldcrypt.Keyring, v sql.NullString, aad string) (sql.NullString, error) {
+	if !v.Valid || v.String == "" {
+		return v, nil
 	}
+	enc, err := k.Encrypt(v.String, aad)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: enc, Valid: true}, nil
+}
+
+// decryptNull 解密可空字段
+func decryptNull(k *fieldcrypt.Keyring, v sql.NullString, aad string) (sql.NullString, error) {
+	if !v.Valid {
+		return v, nil
+	}
+	dec, err := k.Decrypt(v.String, aad)
+	if err != nil {
+		return sql.NullString{}, err
+	}
+	return sql.NullString{String: dec, Valid: true}, nil
+}
+
+// blindIndex 计算可空字段的盲索引，NULL 与空字符串得到 NULL（不参与唯一约束）
+func blindIndex(k *fieldcrypt.Keyring, column string, v sql.NullString) sql.NullString {
+	if !v.Valid {
+		return sql.NullString{}
+	}
+	return emptyToNull(k.BlindIndex(column, v.String))
+}
+
+// emptyToNull 空字符串转换为 NULL
+func emptyToNull(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
 }
@@ -0,0 +1,28 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	userservice "arch3/internal/service/user"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key 格式: user:cooldown:{key}
+const cooldownKeyPrefix = "user:cooldown:"
+
+// CooldownCache Redis 实现的发送冷却
+type CooldownCache struct {
+	rdb *redis.Client
+}
+
+// NewCooldownCache 创建发送冷却
+func NewCooldownCache(rdb *redis.Client) userservice.Cooldown {
+	return &CooldownCache{rdb: rdb}
+}
+
+// Acquire 占用冷却期（SET NX）
+func (c *CooldownCache) Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.rdb.SetNX(ctx, cooldownKeyPrefix+key, 1, ttl).Result()
+}
@@ -3,6 +3,10 @@ package user
 import (
 	"context"
 	"errors"
+	"strings"
+	"time"
+
+	"arch3/pkg/dbtx"
 
 	"gorm.io/gorm"
 )
@@ -20,10 +24,15 @@ func NewDAO(db *gorm.DB) *DAO {
 	return &DAO{db: db}
 }
 
+// conn 返回 ctx 对应的连接，ctx 在事务中时加入该事务
+func (d *DAO) conn(ctx context.Context) *gorm.DB {
+	return dbtx.Conn(ctx, d.db)
+}
+
 // FindByUserID 根据业务 ID 查询用户
 func (d *DAO) FindByUserID(ctx context.Context, userID string) (*Entity, error) {
 	var entity Entity
-	err := d.db.WithContext(ctx).Where("user_id = ?", userID).First(&entity).Error
+	err := d.conn(ctx).Where("user_id = ?", userID).First(&entity).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
@@ -33,10 +42,17 @@ func (d *DAO) FindByUserID(ctx context.Context, userID string) (*Entity, error)
 	return &entity, nil
 }
 
-// FindByPhoneNumber 根据手机号查询用户
-func (d *DAO) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*Entity, error) {
+// phoneMatch 按盲索引匹配手机号
+// 盲索引回填完成前，未回填的行仍按明文匹配
+const phoneMatch = "(phone_hash = ? OR (phone_hash IS NULL AND phone_number = ?))"
+
+// emailMatch 按盲索引匹配邮箱，规则同 phoneMatch
+const emailMatch = "(email_hash = ? OR (email_hash IS NULL AND email = ?))"
+
+// FindByPhoneNumber 根据手机号盲索引查询用户
+func (d *DAO) FindByPhoneNumber(ctx context.Context, phoneHash, phoneNumber string) (*Entity, error) {
 	var entity Entity
-	err := d.db.WithContext(ctx).Where("phone_number = ?", phoneNumber).First(&entity).Error
+	err := d.conn(ctx).Where(phoneMatch, phoneHash, phoneNumber).First(&entity).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
@@ -48,10 +64,270 @@ func (d *DAO) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*Entit
 
 // Create 创建用户
 func (d *DAO) Create(ctx context.Context, entity *Entity) error {
-	return d.db.WithContext(ctx).Create(entity).Error
+	return d.conn(ctx).Create(entity).Error
+}
+
+// nextVersion 版本号递增表达式，DAO 对 users 的每次更新都同时写入
+var nextVersion = gorm.Expr("version + 1")
+
+// bumpVersion 在待更新列中加入版本号递增
+func bumpVersion(columns map[string]any) map[string]any {
+	columns["version"] = nextVersion
+	return columns
+}
+
+// UpdateVersioned 以版本号为前置条件更新指定列
+// 仅当 version 等于 expected 时写入并递增版本号，返回受影响行数
+func (d *DAO) UpdateVersioned(ctx context.Context, userID string, expected int64, columns map[string]any) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND version = ?", userID, expected).
+		Updates(bumpVersion(columns))
+	return result.RowsAffected, result.Error
+}
+
+// UpdatePhoneNumber 条件更新手机号
+// 仅当当前手机号为 oldPhone 时写入，返回受影响行数；新手机号已被占用时返回 gorm.ErrDuplicatedKey
+func (d *DAO) UpdatePhoneNumber(ctx context.Context, userID, oldPhoneHash, oldPhone string, columns map[string]any) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ?", userID).
+		Where(phoneMatch, oldPhoneHash, oldPhone).
+		Updates(bumpVersion(columns))
+	return result.RowsAffected, result.Error
+}
+
+// MarkEmailVerified 条件标记邮箱已验证
+// 仅当当前邮箱为 email 时写入，返回受影响行数
+func (d *DAO) MarkEmailVerified(ctx context.Context, userID, emailHash, email string, updatedAt time.Time) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ?", userID).
+		Where(emailMatch, emailHash, email).
+		Updates(bumpVersion(map[string]any{
+			"email_verified": true,
+			"updated_at":     updatedAt,
+		}))
+	return result.RowsAffected, result.Error
+}
+
+// UpdateRealName 条件更新实名信息与状态
+// 仅当当前状态为 fromStatus 时写入，返回受影响行数
+func (d *DAO) UpdateRealName(ctx context.Context, userID, fromStatus string, columns map[string]any) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND status = ?", userID, fromStatus).
+		Updates(bumpVersion(columns))
+	return result.RowsAffected, result.Error
+}
+
+// ListByStatus 按 ID 升序分页查询指定状态的用户
+func (d *DAO) ListByStatus(ctx context.Context, status string, afterID uint, limit int) ([]*Entity, error) {
+	var entities []*Entity
+	err := d.conn(ctx).
+		Where("status = ? AND id > ?", status, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// ScheduleDeletion 条件设置注销时间
+// 仅当用户未申请注销时写入，返回受影响行数
+func (d *DAO) ScheduleDeletion(ctx context.Context, userID string, scheduledAt, updatedAt time.Time) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND deletion_scheduled_at IS NULL", userID).
+		Updates(bumpVersion(map[string]any{
+			"deletion_scheduled_at": scheduledAt,
+			"updated_at":            updatedAt,
+		}))
+	return result.RowsAffected, result.Error
+}
+
+// CancelDeletion 条件撤销注销
+// 仅当冷静期尚未结束（注销时间晚于 now）时写入，返回受影响行数
+func (d *DAO) CancelDeletion(ctx context.Context, userID string, now time.Time) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND deletion_scheduled_at > ?", userID, now).
+		Updates(bumpVersion(map[string]any{
+			"deletion_scheduled_at": nil,
+			"updated_at":            now,
+		}))
+	return result.RowsAffected, result.Error
+}
+
+// ListDueDeletions 查询冷静期已结束的用户，按注销时间升序
+func (d *DAO) ListDueDeletions(ctx context.Context, before time.Time, limit int) ([]*Entity, error) {
+	var entities []*Entity
+	err := d.conn(ctx).
+		Where("deletion_scheduled_at <= ?", before).
+		Order("deletion_scheduled_at, id").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// TouchLastActive 更新最近活跃时间
+// 仅当未记录或早于 staleBefore 时写入，不递增版本号、不更新 updated_at，避免干扰资料更新的乐观锁
+func (d *DAO) TouchLastActive(ctx context.Context, userID string, at, staleBefore time.Time) error {
+	return d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND (last_active_at IS NULL OR last_active_at < ?)", userID, staleBefore).
+		UpdateColumn("last_active_at", at).Error
+}
+
+// MarkOnboarded 条件写入完成引导时间，已完成引导时不写入，不递增版本号
+func (d *DAO) MarkOnboarded(ctx context.Context, userID string, at time.Time) error {
+	return d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND onboarded_at IS NULL", userID).
+		UpdateColumn("onboarded_at", at).Error
+}
+
+// inactiveCondition 未完成引导、未申请注销且最近活跃早于 before 的用户
+const inactiveCondition = "onboarded_at IS NULL AND deletion_scheduled_at IS NULL AND last_active_at < ?"
+
+// ListInactive 查询未完成引导且最近活跃早于 before 的用户，按 ID 升序
+func (d *DAO) ListInactive(ctx context.Context, before time.Time, limit int) ([]*Entity, error) {
+	var entities []*Entity
+	err := d.conn(ctx).
+		Where(inactiveCondition, before).
+		Order("id").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// ScheduleInactiveDeletion 条件设置注销时间
+// 仅当用户仍未完成引导、未申请注销且最近活跃早于 before 时写入，返回受影响行数
+func (d *DAO) ScheduleInactiveDeletion(ctx context.Context, userID string, before, scheduledAt, updatedAt time.Time) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND "+inactiveCondition, userID, before).
+		Updates(bumpVersion(map[string]any{
+			"deletion_scheduled_at": scheduledAt,
+			"updated_at":            updatedAt,
+		}))
+	return result.RowsAffected, result.Error
+}
+
+// Anonymize 条件匿名化并软删除用户
+// 仅当冷静期已结束（注销时间不晚于 now）时写入，返回受影响行数
+func (d *DAO) Anonymize(ctx context.Context, userID string, now time.Time, columns map[string]any) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("user_id = ? AND deletion_scheduled_at <= ?", userID, now).
+		Updates(bumpVersion(columns))
+	return result.RowsAffected, result.Error
+}
+
+// ListAfter 按 ID 升序分页查询全部用户
+func (d *DAO) ListAfter(ctx context.Context, afterID uint, limit int) ([]*Entity, error) {
+	var entities []*Entity
+	err := d.conn(ctx).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
+}
+
+// RewriteColumns 按主键条件改写指定列，不更新 updated_at
+// 仅当 updated_at 仍为 unmodifiedSince 时写入，返回受影响行数；用于后台数据迁移
+func (d *DAO) RewriteColumns(ctx context.Context, id uint, unmodifiedSince time.Time, columns map[string]any) (int64, error) {
+	result := d.conn(ctx).Model(&Entity{}).
+		Where("id = ? AND updated_at = ?", id, unmodifiedSince).
+		UpdateColumns(columns)
+	return result.RowsAffected, result.Error
+}
+
+// SearchCondition 用户搜索条件，零值字段不参与过滤
+type SearchCondition struct {
+	PhoneHash      string // 完整手机号盲索引，与 PhoneNumber 一同用于兼容未回填的旧数据
+	PhoneNumber    string
+	PhoneTailHash  string
+	UserNamePrefix string
+	Status         string
+	Source         string
+	GroupID        string
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+	BeforeUserID   string
+	Limit          int
+}
+
+// Search 按条件查询用户，按 user_id 倒序
+func (d *DAO) Search(ctx context.Context, cond *SearchCondition) ([]*Entity, error) {
+	q := d.conn(ctx).Model(&Entity{})
+	if cond.PhoneHash != "" {
+		q = q.Where(phoneMatch, cond.PhoneHash, cond.PhoneNumber)
+	}
+	if cond.PhoneTailHash != "" {
+		q = q.Where("phone_tail_hash = ?", cond.PhoneTailHash)
+	}
+	if cond.UserNamePrefix != "" {
+		q = q.Where("user_name LIKE ? ESCAPE '!'", escapeLike(cond.UserNamePrefix)+"%")
+	}
+	if cond.Status != "" {
+		q = q.Where("status = ?", cond.Status)
+	}
+	if cond.Source != "" {
+		q = q.Where("source = ?", cond.Source)
+	}
+	if cond.GroupID != "" {
+		q = q.Where("group_id = ?", cond.GroupID)
+	}
+	if cond.CreatedFrom != nil {
+		q = q.Where("created_at >= ?", *cond.CreatedFrom)
+	}
+	if cond.CreatedTo != nil {
+		q = q.Where("created_at < ?", *cond.CreatedTo)
+	}
+	if cond.BeforeUserID != "" {
+		q = q.Where("user_id < ?", cond.BeforeUserID)
+	}
+	var entities []*Entity
+	err := q.Order("user_id DESC").Limit(cond.Limit).Find(&entities).Error
+	return entities, err
+}
+
+// escapeLike 转义 LIKE 通配符，配合 ESCAPE '!' 使用
+// 不用反斜杠作转义符: MySQL 字符串字面量会解释反斜杠，SQLite 的 LIKE 也没有默认转义符
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+var likeEscaper = strings.NewReplacer(`!`, `!!`, `%`, `!%`, `_`, `!_`)
+
+// ChangeStatus 条件更新用户状态并写入变更记录
+// 仅当当前状态为 change.FromStatus 时写入，返回受影响行数；未命中时不写入记录
+func (d *DAO) ChangeStatus(ctx context.Context, change *StatusChangeEntity, updatedAt time.Time) (int64, error) {
+	var affected int64
+	err := d.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Entity{}).
+			Where("user_id = ? AND status = ?", change.UserID, change.FromStatus).
+			Updates(bumpVersion(map[string]any{
+				"status":     change.ToStatus,
+				"updated_at": updatedAt,
+			}))
+		if result.Error != nil {
+			return result.Error
+		}
+		affected = result.RowsAffected
+		if affected == 0 {
+			return nil
+		}
+		return tx.Create(change).Error
+	})
+	return affected, err
+}
+
+// ListStatusChanges 查询用户最近的状态变更记录，按时间倒序
+func (d *DAO) ListStatusChanges(ctx context.Context, userID string, limit int) ([]*StatusChangeEntity, error) {
+	var entities []*StatusChangeEntity
+	err := d.conn(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entities).Error
+	return entities, err
 }
 
-// Update 更新用户
-func (d *DAO) Update(ctx context.Context, entity *Entity) error {
-	return d.db.WithContext(ctx).Save(entity).Error
+// ExistsByUserID 用户是否存在
+func (d *DAO) ExistsByUserID(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	err := d.conn(ctx).Model(&Entity{}).Where("user_id = ?", userID).Count(&count).Error
+	return count > 0, err
 }
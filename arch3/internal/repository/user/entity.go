@@ -3,29 +3,64 @@ package user
 import (
 	"database/sql"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Entity 用户数据库实体
+//
+// 手机号、邮箱、姓名、身份证号以 fieldcrypt 信封加密存储，
+// 需要查询或唯一约束的列另存盲索引（*_hash）。
+// 盲索引可空，便于在已有数据上新增列后由 cmd/reencrypt 回填。
+// 表结构以 internal/migration 中的迁移脚本为准，修改字段需同时新增迁移。
+// gender、status 的取值由迁移中的 CHECK 约束限定，不使用 MySQL 专有的 enum 类型。
 type Entity struct {
-	ID           uint           `gorm:"column:id;primaryKey;autoIncrement"`
-	UserID       string         `gorm:"column:user_id;type:varchar(32);uniqueIndex;not null"`
-	GroupID      sql.NullString `gorm:"column:group_id;type:varchar(32)"`
-	UserName     string         `gorm:"column:user_name;type:varchar(50);not null;index"`
-	RealName     sql.NullString `gorm:"column:real_name;type:varchar(100);index"`
-	PasswordHash string         `gorm:"column:password_hash;type:char(64);not null"`
-	Email        sql.NullString `gorm:"column:email;type:varchar(254)"`
-	PhoneNumber  string         `gorm:"column:phone_number;type:varchar(15);uniqueIndex;not null"`
-	AvatarURL    sql.NullString `gorm:"column:avatar_url;type:varchar(255)"`
-	Gender       string         `gorm:"column:gender;type:enum('male','female','other');default:other"`
-	CreatedAt    time.Time      `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt    time.Time      `gorm:"column:updated_at;autoUpdateTime"`
-	Status       string         `gorm:"column:status;type:enum('real_name_verified','real_name_unverified','banned','under_review');default:real_name_unverified;not null"`
-	IDNumber     sql.NullString `gorm:"column:id_number;type:varchar(30);uniqueIndex"`
-	Source       sql.NullString `gorm:"column:source;type:varchar(50);index"`
-	DeviceID     sql.NullString `gorm:"column:device_id;type:varchar(128)"`
+	ID            uint           `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID        string         `gorm:"column:user_id;type:varchar(32);uniqueIndex;not null"`
+	GroupID       sql.NullString `gorm:"column:group_id;type:varchar(32)"`
+	UserName      string         `gorm:"column:user_name;type:varchar(50);not null;index"`
+	RealName      sql.NullString `gorm:"column:real_name;type:varchar(512)"` // 加密存储
+	PasswordHash  string         `gorm:"column:password_hash;type:char(64);not null"`
+	Email         sql.NullString `gorm:"column:email;type:varchar(512)"`        // 加密存储
+	EmailHash     sql.NullString `gorm:"column:email_hash;type:char(64);index"` // 唯一索引只作用于已验证的邮箱，见迁移
+	EmailVerified bool           `gorm:"column:email_verified;not null;default:false"`
+	PhoneNumber   string         `gorm:"column:phone_number;type:varchar(255);not null"` // 加密存储
+	PhoneHash     sql.NullString `gorm:"column:phone_hash;type:char(64);uniqueIndex"`
+	PhoneTailHash sql.NullString `gorm:"column:phone_tail_hash;type:char(64);index"` // 手机号后 4 位的盲索引，供管理后台模糊查询
+	AvatarURL     sql.NullString `gorm:"column:avatar_url;type:varchar(255)"`
+	Gender        string         `gorm:"column:gender;type:varchar(16);not null;default:other"`
+	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time      `gorm:"column:updated_at;autoUpdateTime"`
+	Status        string         `gorm:"column:status;type:varchar(32);default:real_name_unverified;not null"`
+	IDNumber      sql.NullString `gorm:"column:id_number;type:varchar(255)"` // 加密存储
+	IDNumberHash  sql.NullString `gorm:"column:id_number_hash;type:char(64);uniqueIndex"`
+	Source        sql.NullString `gorm:"column:source;type:varchar(50);index"`
+	DeviceID      sql.NullString `gorm:"column:device_id;type:varchar(128)"`
+
+	DeletionScheduledAt sql.NullTime   `gorm:"column:deletion_scheduled_at;index"`
+	OnboardedAt         sql.NullTime   `gorm:"column:onboarded_at"`
+	LastActiveAt        sql.NullTime   `gorm:"column:last_active_at;index"`
+	DeletedAt           gorm.DeletedAt `gorm:"column:deleted_at;index"`           // 注销匿名化的时间，软删除后常规查询不可见
+	Version             int64          `gorm:"column:version;not null;default:1"` // 乐观锁版本号，DAO 的每次更新递增
 }
 
 // TableName 返回表名
 func (Entity) TableName() string {
 	return "users"
 }
+
+// StatusChangeEntity 用户状态变更记录
+type StatusChangeEntity struct {
+	ID         uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID     string    `gorm:"column:user_id;type:varchar(32);not null;index:idx_user_status_changes_user_created,priority:1"`
+	FromStatus string    `gorm:"column:from_status;type:varchar(32);not null"`
+	ToStatus   string    `gorm:"column:to_status;type:varchar(32);not null"`
+	Reason     string    `gorm:"column:reason;type:varchar(200);not null"`
+	OperatorID string    `gorm:"column:operator_id;type:varchar(32);not null"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime;index:idx_user_status_changes_user_created,priority:2"`
+}
+
+// TableName 返回表名
+func (StatusChangeEntity) TableName() string {
+	return "user_status_changes"
+}
@@ -0,0 +1,105 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/dbtx"
+
+	"gorm.io/gorm"
+)
+
+// LoginHistoryEntity 登录记录数据库实体
+type LoginHistoryEntity struct {
+	ID          uint      `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID      string    `gorm:"column:user_id;type:varchar(32);not null;index:idx_user_login_history_user_created,priority:1"`
+	Event       string    `gorm:"column:event;type:varchar(16);not null"`
+	DeviceID    string    `gorm:"column:device_id;type:varchar(128);not null"`
+	Platform    string    `gorm:"column:platform;type:varchar(16);not null"`
+	AppVersion  string    `gorm:"column:app_version;type:varchar(64);not null"`
+	Channel     string    `gorm:"column:channel;type:varchar(64);not null;index"`
+	UTMSource   string    `gorm:"column:utm_source;type:varchar(64);not null"`
+	UTMMedium   string    `gorm:"column:utm_medium;type:varchar(64);not null"`
+	UTMCampaign string    `gorm:"column:utm_campaign;type:varchar(64);not null"`
+	IP          string    `gorm:"column:ip;type:varchar(45);not null"`
+	UserAgent   string    `gorm:"column:user_agent;type:varchar(255);not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime;index:idx_user_login_history_user_created,priority:2"`
+}
+
+// TableName 返回表名
+func (LoginHistoryEntity) TableName() string {
+	return "user_login_history"
+}
+
+// LoginHistoryRepository 登录记录仓储实现
+type LoginHistoryRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginHistoryRepository 创建登录记录仓储
+func NewLoginHistoryRepository(db *gorm.DB) userservice.LoginHistoryRepository {
+	return &LoginHistoryRepository{db: db}
+}
+
+// Create 写入登录记录
+func (r *LoginHistoryRepository) Create(ctx context.Context, rec *domain.LoginRecord) error {
+	entity := &LoginHistoryEntity{
+		UserID:      rec.UserID,
+		Event:       rec.Event,
+		DeviceID:    rec.DeviceID,
+		Platform:    rec.Platform,
+		AppVersion:  rec.AppVersion,
+		Channel:     rec.Channel,
+		UTMSource:   rec.UTMSource,
+		UTMMedium:   rec.UTMMedium,
+		UTMCampaign: rec.UTMCampaign,
+		IP:          rec.IP,
+		UserAgent:   rec.UserAgent,
+		CreatedAt:   rec.CreatedAt,
+	}
+	if err := dbtx.Conn(ctx, r.db).Create(entity).Error; err != nil {
+		return err
+	}
+	rec.ID = entity.ID
+	rec.CreatedAt = entity.CreatedAt
+	return nil
+}
+
+// ListByUser 查询用户最近的登录记录，按时间倒序
+func (r *LoginHistoryRepository) ListByUser(ctx context.Context, userID string, limit int) ([]*domain.LoginRecord, error) {
+	var entities []*LoginHistoryEntity
+	err := dbtx.Conn(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*domain.LoginRecord, 0, len(entities))
+	for _, e := range entities {
+		records = append(records, &domain.LoginRecord{
+			ID:          e.ID,
+			UserID:      e.UserID,
+			Event:       e.Event,
+			DeviceID:    e.DeviceID,
+			Platform:    e.Platform,
+			AppVersion:  e.AppVersion,
+			Channel:     e.Channel,
+			UTMSource:   e.UTMSource,
+			UTMMedium:   e.UTMMedium,
+			UTMCampaign: e.UTMCampaign,
+			IP:          e.IP,
+			UserAgent:   e.UserAgent,
+			CreatedAt:   e.CreatedAt,
+		})
+	}
+	return records, nil
+}
+
+// DeleteByUser 删除用户的全部登录记录
+func (r *LoginHistoryRepository) DeleteByUser(ctx context.Context, userID string) error {
+	return dbtx.Conn(ctx, r.db).Where("user_id = ?", userID).Delete(&LoginHistoryEntity{}).Error
+}
@@ -3,19 +3,29 @@ package user
 import (
 	"context"
 	"errors"
+	"time"
 
 	domain "arch3/internal/domain/user"
 	userservice "arch3/internal/service/user"
+	"arch3/pkg/fieldcrypt"
+
+	"gorm.io/gorm"
 )
 
+// lastActiveGranularity 最近活跃时间的更新粒度，距上次记录不足该时长时不写库
+const lastActiveGranularity = time.Hour
+
 // Repository 用户仓储实现
+//
+// 手机号、邮箱、姓名与身份证号在此层透明加解密并维护盲索引，Service 层只接触明文。
 type Repository struct {
-	dao *DAO
+	dao  *DAO
+	keys *fieldcrypt.Keyring
 }
 
 // NewRepository 创建用户仓储实例
-func NewRepository(dao *DAO) userservice.Repository {
-	return &Repository{dao: dao}
+func NewRepository(dao *DAO, keys *fieldcrypt.Keyring) userservice.Repository {
+	return &Repository{dao: dao, keys: keys}
 }
 
 // FindByUserID 根据业务 ID 查询用户
@@ -27,39 +37,452 @@ func (r *Repository) FindByUserID(ctx context.Context, userID string) (*domain.U
 		}
 		return nil, err
 	}
-	return toDomain(entity), nil
+	return toDomain(entity, r.keys)
 }
 
 // FindByPhoneNumber 根据手机号查询用户
 func (r *Repository) FindByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
-	entity, err := r.dao.FindByPhoneNumber(ctx, phoneNumber)
+	entity, err := r.dao.FindByPhoneNumber(ctx, r.keys.BlindIndex(indexPhoneNumber, phoneNumber), phoneNumber)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
 			return nil, domain.ErrUserNotFound
 		}
 		return nil, err
 	}
-	return toDomain(entity), nil
+	return toDomain(entity, r.keys)
 }
 
 // Create 创建用户
 func (r *Repository) Create(ctx context.Context, u *domain.User) error {
-	entity := toEntity(u)
+	entity, err := toEntity(u, r.keys)
+	if err != nil {
+		return err
+	}
+	entity.Version = 1
 	if err := r.dao.Create(ctx, entity); err != nil {
 		return err
 	}
 	// 回填生成的字段
 	u.CreatedAt = entity.CreatedAt
 	u.UpdatedAt = entity.UpdatedAt
+	u.Version = entity.Version
 	return nil
 }
 
-// Update 更新用户
+// Update 以 u.Version 为前置条件写入全部可更新字段
 func (r *Repository) Update(ctx context.Context, u *domain.User) error {
-	entity := toEntity(u)
-	if err := r.dao.Update(ctx, entity); err != nil {
+	return r.UpdateFields(ctx, u, domain.UpdatableFields...)
+}
+
+// UpdateFields 以 u.Version 为前置条件只写入 fields 指定的字段
+//
+// 未列出的字段即使在 u 中被修改也不会写入；加密字段同时更新盲索引。
+// 成功后 u.Version 递增、u.UpdatedAt 更新为写入时间。
+func (r *Repository) UpdateFields(ctx context.Context, u *domain.User, fields ...domain.Field) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	entity, err := toEntity(u, r.keys)
+	if err != nil {
 		return err
 	}
-	u.UpdatedAt = entity.UpdatedAt
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	columns := map[string]any{"updated_at": now}
+	for _, f := range fields {
+		if err := fieldColumns(entity, f, columns); err != nil {
+			return err
+		}
+	}
+
+	affected, err := r.dao.UpdateVersioned(ctx, u.UserID, u.Version, columns)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return duplicateError(fields, err)
+		}
+		return err
+	}
+	if err := r.checkAffected(ctx, u.UserID, affected); err != nil {
+		return err
+	}
+	u.Version++
+	u.UpdatedAt = now
 	return nil
 }
+
+// UpdateProfile 部分更新用户资料
+//
+// 以 upd.Version 为前置条件做条件更新，并只写入修改的列，
+// 不会覆盖其他请求同时修改的字段。
+func (r *Repository) UpdateProfile(ctx context.Context, userID string, upd *domain.ProfileUpdate) error {
+	columns := map[string]any{"updated_at": time.Now().UTC().Truncate(time.Millisecond)}
+	if upd.UserName != nil {
+		columns["user_name"] = *upd.UserName
+	}
+	if upd.Gender != nil {
+		columns["gender"] = *upd.Gender
+	}
+	if upd.Email != nil {
+		email, err := r.keys.Encrypt(*upd.Email, aadEmail+userID)
+		if err != nil {
+			return err
+		}
+		columns["email"] = emptyToNull(email)
+		columns["email_hash"] = emptyToNull(r.keys.BlindIndex(indexEmail, *upd.Email))
+	}
+	if upd.EmailVerified != nil {
+		columns["email_verified"] = *upd.EmailVerified
+	}
+	if upd.AvatarURL != nil {
+		columns["avatar_url"] = emptyToNull(*upd.AvatarURL)
+	}
+
+	affected, err := r.dao.UpdateVersioned(ctx, userID, upd.Version, columns)
+	if err != nil {
+		// 资料字段中只有已验证邮箱有唯一索引，未验证的邮箱不会冲突
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrEmailTaken
+		}
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	// 未更新: 区分用户不存在与版本冲突
+	exists, err := r.dao.ExistsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return domain.ErrUserConflict
+}
+
+// UpdatePhoneNumber 更换手机号
+//
+// 单条条件更新完成变更: 盲索引上的唯一索引保证新手机号不会被两个账号同时绑定，
+// 当前手机号作为前置条件，并发的其他变更不会被覆盖。
+func (r *Repository) UpdatePhoneNumber(ctx context.Context, userID, oldPhone, newPhone string) error {
+	encrypted, err := r.keys.Encrypt(newPhone, aadPhoneNumber+userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := r.dao.UpdatePhoneNumber(ctx, userID, r.keys.BlindIndex(indexPhoneNumber, oldPhone), oldPhone, map[string]any{
+		"phone_number":    encrypted,
+		"phone_hash":      emptyToNull(r.keys.BlindIndex(indexPhoneNumber, newPhone)),
+		"phone_tail_hash": emptyToNull(phoneTailIndex(r.keys, newPhone)),
+		"updated_at":      time.Now().UTC().Truncate(time.Millisecond),
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrPhoneTaken
+		}
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := r.dao.ExistsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return domain.ErrUserConflict
+}
+
+// MarkEmailVerified 标记邮箱已验证
+// 以当前邮箱作为前置条件，验证期间邮箱被更换时不会误标记新邮箱；
+// 邮箱唯一约束只作用于已验证的邮箱，已被其他账号验证时返回 ErrEmailTaken
+func (r *Repository) MarkEmailVerified(ctx context.Context, userID, email string) error {
+	affected, err := r.dao.MarkEmailVerified(ctx, userID, r.keys.BlindIndex(indexEmail, email), email, time.Now().UTC().Truncate(time.Millisecond))
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrEmailTaken
+		}
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := r.dao.ExistsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return domain.ErrUserConflict
+}
+
+// UpdateRealName 条件更新实名信息与状态
+// 以当前状态作为前置条件，保证提交与审核等状态流转不会相互覆盖
+func (r *Repository) UpdateRealName(ctx context.Context, userID, fromStatus string, upd *domain.RealNameUpdate) error {
+	realName, err := r.keys.Encrypt(upd.RealName, aadRealName+userID)
+	if err != nil {
+		return err
+	}
+	idNumber, err := r.keys.Encrypt(upd.IDNumber, aadIDNumber+userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := r.dao.UpdateRealName(ctx, userID, fromStatus, map[string]any{
+		"real_name":      emptyToNull(realName),
+		"id_number":      emptyToNull(idNumber),
+		"id_number_hash": emptyToNull(r.keys.BlindIndex(indexIDNumber, upd.IDNumber)),
+		"status":         upd.Status,
+		"updated_at":     time.Now().UTC().Truncate(time.Millisecond),
+	})
+	if err != nil {
+		// 同一身份证号只能认证一个账号
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return domain.ErrIDNumberTaken
+		}
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	exists, err := r.dao.ExistsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return domain.ErrUserConflict
+}
+
+// ListByStatus 按 ID 升序分页查询指定状态的用户
+func (r *Repository) ListByStatus(ctx context.Context, status string, afterID uint, limit int) ([]*domain.User, error) {
+	entities, err := r.dao.ListByStatus(ctx, status, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*domain.User, 0, len(entities))
+	for _, e := range entities {
+		u, err := toDomain(e, r.keys)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// ScheduleDeletion 申请注销，冷静期至 scheduledAt 结束
+// 以未申请注销作为前置条件，重复申请不会延长或缩短冷静期
+func (r *Repository) ScheduleDeletion(ctx context.Context, userID string, scheduledAt time.Time) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	affected, err := r.dao.ScheduleDeletion(ctx, userID, scheduledAt.UTC().Truncate(time.Millisecond), now)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(ctx, userID, affected)
+}
+
+// ScheduleInactiveDeletion 未完成引导且最近活跃早于 before 的用户转入注销流程
+func (r *Repository) ScheduleInactiveDeletion(ctx context.Context, userID string, before, scheduledAt time.Time) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	affected, err := r.dao.ScheduleInactiveDeletion(ctx, userID, before.UTC(), scheduledAt.UTC().Truncate(time.Millisecond), now)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(ctx, userID, affected)
+}
+
+// ListInactive 查询未完成引导、未申请注销且最近活跃早于 before 的用户
+func (r *Repository) ListInactive(ctx context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	entities, err := r.dao.ListInactive(ctx, before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*domain.User, 0, len(entities))
+	for _, e := range entities {
+		u, err := toDomain(e, r.keys)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// TouchLastActive 记录最近活跃时间，距上次记录不足 lastActiveGranularity 时不写入
+func (r *Repository) TouchLastActive(ctx context.Context, userID string, at time.Time) error {
+	at = at.UTC().Truncate(time.Millisecond)
+	return r.dao.TouchLastActive(ctx, userID, at, at.Add(-lastActiveGranularity))
+}
+
+// MarkOnboarded 记录完成引导时间，已完成引导时不修改
+func (r *Repository) MarkOnboarded(ctx context.Context, userID string, at time.Time) error {
+	return r.dao.MarkOnboarded(ctx, userID, at.UTC().Truncate(time.Millisecond))
+}
+
+// CancelDeletion 撤销注销申请，冷静期已结束时不可撤销
+func (r *Repository) CancelDeletion(ctx context.Context, userID string) error {
+	affected, err := r.dao.CancelDeletion(ctx, userID, time.Now().UTC().Truncate(time.Millisecond))
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(ctx, userID, affected)
+}
+
+// ListDueDeletions 查询冷静期在 before 之前结束的用户
+func (r *Repository) ListDueDeletions(ctx context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	entities, err := r.dao.ListDueDeletions(ctx, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*domain.User, 0, len(entities))
+	for _, e := range entities {
+		u, err := toDomain(e, r.keys)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// Anonymize 匿名化并软删除冷静期已结束的用户
+//
+// 清除全部个人信息与盲索引（手机号、邮箱、身份证号可被重新注册或认证），
+// 仅保留主键、业务 ID、状态、来源与创建时间用于统计。
+// 以冷静期已结束作为前置条件，与撤销注销互斥。
+func (r *Repository) Anonymize(ctx context.Context, userID string) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	affected, err := r.dao.Anonymize(ctx, userID, now, map[string]any{
+		"user_name":             domain.DeletedUserName,
+		"real_name":             nil,
+		"password_hash":         "",
+		"email":                 nil,
+		"email_hash":            nil,
+		"email_verified":        false,
+		"phone_number":          "",
+		"phone_hash":            nil,
+		"phone_tail_hash":       nil,
+		"avatar_url":            nil,
+		"gender":                domain.GenderOther,
+		"id_number":             nil,
+		"id_number_hash":        nil,
+		"group_id":              nil,
+		"device_id":             nil,
+		"deletion_scheduled_at": nil,
+		"onboarded_at":          nil,
+		"last_active_at":        nil,
+		"deleted_at":            now,
+		"updated_at":            now,
+	})
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(ctx, userID, affected)
+}
+
+// Search 按条件分页查询用户
+// 完整手机号按盲索引精确匹配，4 位数字按手机号后 4 位的盲索引匹配
+func (r *Repository) Search(ctx context.Context, f *domain.UserFilter) ([]*domain.User, error) {
+	cond := &SearchCondition{
+		UserNamePrefix: f.UserName,
+		Status:         f.Status,
+		Source:         f.Source,
+		GroupID:        f.GroupID,
+		CreatedFrom:    f.CreatedFrom,
+		CreatedTo:      f.CreatedTo,
+		BeforeUserID:   f.Cursor,
+		Limit:          f.Limit,
+	}
+	switch {
+	case len(f.Phone) == 4:
+		cond.PhoneTailHash = r.keys.BlindIndex(indexPhoneTail, f.Phone)
+	case f.Phone != "":
+		cond.PhoneHash = r.keys.BlindIndex(indexPhoneNumber, f.Phone)
+		cond.PhoneNumber = f.Phone
+	}
+	entities, err := r.dao.Search(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*domain.User, 0, len(entities))
+	for _, e := range entities {
+		u, err := toDomain(e, r.keys)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
+
+// ChangeStatus 变更用户状态并记录变更原因与操作人
+// 以当前状态为 change.FromStatus 作为前置条件，状态更新与记录写入在同一事务中
+func (r *Repository) ChangeStatus(ctx context.Context, change *domain.StatusChange) error {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	affected, err := r.dao.ChangeStatus(ctx, &StatusChangeEntity{
+		UserID:     change.UserID,
+		FromStatus: change.FromStatus,
+		ToStatus:   change.ToStatus,
+		Reason:     change.Reason,
+		OperatorID: change.OperatorID,
+		CreatedAt:  now,
+	}, now)
+	if err != nil {
+		return err
+	}
+	return r.checkAffected(ctx, change.UserID, affected)
+}
+
+// ListStatusChanges 查询用户最近的状态变更记录，按时间倒序
+func (r *Repository) ListStatusChanges(ctx context.Context, userID string, limit int) ([]*domain.StatusChange, error) {
+	entities, err := r.dao.ListStatusChanges(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*domain.StatusChange, 0, len(entities))
+	for _, e := range entities {
+		changes = append(changes, statusChangeToDomain(e))
+	}
+	return changes, nil
+}
+
+// duplicateError 根据写入的唯一字段确定冲突原因，写入多个唯一字段时无法区分，返回原错误
+func duplicateError(fields []domain.Field, err error) error {
+	var unique []error
+	for _, f := range fields {
+		switch f {
+		case domain.FieldEmail:
+			unique = append(unique, domain.ErrEmailTaken)
+		case domain.FieldPhoneNumber:
+			unique = append(unique, domain.ErrPhoneTaken)
+		case domain.FieldIDNumber:
+			unique = append(unique, domain.ErrIDNumberTaken)
+		}
+	}
+	if len(unique) != 1 {
+		return err
+	}
+	return unique[0]
+}
+
+// checkAffected 条件更新未命中时区分用户不存在与前置条件不满足
+func (r *Repository) checkAffected(ctx context.Context, userID string, affected int64) error {
+	if affected > 0 {
+		return nil
+	}
+	exists, err := r.dao.ExistsByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	return domain.ErrUserConflict
+}
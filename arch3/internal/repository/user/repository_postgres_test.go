@@ -0,0 +1,18 @@
+//go:build integration
+
+package user
+
+import (
+	"testing"
+
+	"arch3/internal/repository/dbtest"
+	"arch3/internal/repository/repotest"
+	userservice "arch3/internal/service/user"
+)
+
+// TestRepository_Postgres 需要 PostgreSQL: TEST_POSTGRES_DSN="host=... dbname=..." go test -tags integration ./internal/repository/...
+func TestRepository_Postgres(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) userservice.Repository {
+		return newTestRepository(t, dbtest.Postgres(t))
+	})
+}
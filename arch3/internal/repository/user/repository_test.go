@@ -0,0 +1,60 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/repository/dbtest"
+	"arch3/internal/repository/repotest"
+	userservice "arch3/internal/service/user"
+	"arch3/pkg/fieldcrypt"
+
+	"gorm.io/gorm"
+)
+
+func TestRepository_SQLite(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) userservice.Repository {
+		return newTestRepository(t, dbtest.SQLite(t))
+	})
+}
+
+func TestLoginHistoryRepository_SQLite(t *testing.T) {
+	repotest.TestLoginHistory(t, func(t *testing.T) userservice.LoginHistoryRepository {
+		return NewLoginHistoryRepository(dbtest.SQLite(t))
+	})
+}
+
+func newTestKeyring(t *testing.T) *fieldcrypt.Keyring {
+	t.Helper()
+	keys, err := fieldcrypt.NewKeyring(
+		map[int][]byte{1: bytes.Repeat([]byte{1}, fieldcrypt.KeySize)},
+		bytes.Repeat([]byte{0xff}, fieldcrypt.KeySize),
+	)
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return keys
+}
+
+func newTestRepository(t *testing.T, db *gorm.DB) userservice.Repository {
+	t.Helper()
+	return NewRepository(NewDAO(db), newTestKeyring(t))
+}
+
+// 驱动错误转换为 gorm.ErrDuplicatedKey，服务层据此区分唯一冲突
+func TestRepository_DuplicatedKey(t *testing.T) {
+	repo := newTestRepository(t, dbtest.SQLite(t))
+	ctx := context.Background()
+	newUser := func(userID, phone string) *domain.User {
+		return &domain.User{UserID: userID, UserName: userID, PhoneNumber: phone, Gender: domain.GenderOther, Status: domain.StatusRealNameUnverified}
+	}
+	if err := repo.Create(ctx, newUser("u1", "13800000001")); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if err := repo.Create(ctx, newUser("u2", "13800000001")); !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Errorf("Expected gorm.ErrDuplicatedKey, got %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package user
+
+import (
+	"time"
+
+	"arch3/internal/job"
+)
+
+// LoginHistoryRetention 登录记录保留策略: 删除超过 maxAge 的登录记录
+func LoginHistoryRetention(maxAge time.Duration) job.Policy {
+	return job.Policy{
+		Name:      "user_login_history",
+		Table:     LoginHistoryEntity{}.TableName(),
+		AgeColumn: "created_at",
+		MaxAge:    maxAge,
+		Action:    job.ActionDelete,
+	}
+}
+
+// DeletedUserRetention 已注销用户保留策略: 匿名化超过 maxAge 的用户记录被物理删除
+// 删除后业务 ID 不再保留，状态变更记录仍按业务 ID 保留用于审计
+func DeletedUserRetention(maxAge time.Duration) job.Policy {
+	return job.Policy{
+		Name:      "user_deleted",
+		Table:     Entity{}.TableName(),
+		AgeColumn: "deleted_at",
+		MaxAge:    maxAge,
+		Action:    job.ActionDelete,
+	}
+}
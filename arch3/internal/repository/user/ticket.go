@@ -0,0 +1,67 @@
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	userservice "arch3/internal/service/user"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis key 格式: user:phone_change:{sha256(ticket)}
+// 只保存凭证摘要，Redis 数据泄露不会暴露可用凭证
+const phoneChangeKeyPrefix = "user:phone_change:"
+
+// PhoneChangeTicketCache Redis 实现的更换手机号凭证存储
+type PhoneChangeTicketCache struct {
+	rdb *redis.Client
+}
+
+// NewPhoneChangeTicketCache 创建凭证存储
+func NewPhoneChangeTicketCache(rdb *redis.Client) userservice.PhoneChangeTicketStore {
+	return &PhoneChangeTicketCache{rdb: rdb}
+}
+
+// Save 保存凭证
+func (c *PhoneChangeTicketCache) Save(ctx context.Context, ticket string, t *domain.PhoneChangeTicket, ttl time.Duration) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return c.rdb.Set(ctx, c.key(ticket), data, ttl).Err()
+}
+
+// Get 查询凭证
+func (c *PhoneChangeTicketCache) Get(ctx context.Context, ticket string) (*domain.PhoneChangeTicket, error) {
+	return c.decode(c.rdb.Get(ctx, c.key(ticket)).Bytes())
+}
+
+// Take 取出并删除凭证（GETDEL）
+func (c *PhoneChangeTicketCache) Take(ctx context.Context, ticket string) (*domain.PhoneChangeTicket, error) {
+	return c.decode(c.rdb.GetDel(ctx, c.key(ticket)).Bytes())
+}
+
+func (c *PhoneChangeTicketCache) decode(data []byte, err error) (*domain.PhoneChangeTicket, error) {
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrTicketInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var t domain.PhoneChangeTicket
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (c *PhoneChangeTicketCache) key(ticket string) string {
+	sum := sha256.Sum256([]byte(ticket))
+	return phoneChangeKeyPrefix + hex.EncodeToString(sum[:])
+}
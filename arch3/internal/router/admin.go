@@ -0,0 +1,25 @@
+package router
+
+import (
+	"arch3/internal/handler/middleware"
+	userhandler "arch3/internal/handler/user"
+	"arch3/pkg/response"
+
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// RegisterAdminRoutes 注册管理后台路由（需登录且在管理员白名单中）
+func RegisterAdminRoutes(r *route.RouterGroup, adminIDs []string, userHandler *userhandler.Handler) {
+	adminGroup := r.Group("/admin", middleware.RequireAdmin(adminIDs))
+	{
+		// 实名认证人工审核
+		adminGroup.GET("/real-name/reviews", response.Wrap(userHandler.ListRealNameReviews))
+		adminGroup.POST("/real-name/reviews/:user_id", response.Wrap(userHandler.ReviewRealName))
+
+		// 用户管理
+		adminGroup.GET("/users", response.Wrap(userHandler.SearchUsers))
+		adminGroup.GET("/users/:user_id", response.Wrap(userHandler.GetUserDetail))
+		adminGroup.POST("/users/:user_id/ban", response.Wrap(userHandler.BanUser))
+		adminGroup.POST("/users/:user_id/unban", response.Wrap(userHandler.UnbanUser))
+	}
+}
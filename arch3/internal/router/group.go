@@ -0,0 +1,47 @@
+package router
+
+import (
+	domain "arch3/internal/domain/group"
+	grouphandler "arch3/internal/handler/group"
+	"arch3/internal/handler/middleware"
+	"arch3/pkg/response"
+
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// RegisterGroupRoutes 注册组织相关路由（均需登录）
+//
+// /groups/:group_id 下的路由要求当前用户是该组织成员，
+// 管理类操作再按角色限制；服务层会再次校验操作人与目标成员的角色关系。
+func RegisterGroupRoutes(r *route.RouterGroup, handler *grouphandler.Handler) {
+	member := middleware.RequireGroupRole(handler.RoleResolver())
+	manager := middleware.RequireGroupRole(handler.RoleResolver(), domain.RoleOwner, domain.RoleAdmin)
+	owner := middleware.RequireGroupRole(handler.RoleResolver(), domain.RoleOwner)
+
+	groupGroup := r.Group("/groups")
+	{
+		groupGroup.POST("", response.Wrap(handler.CreateGroup))
+		groupGroup.GET("", response.Wrap(handler.GetMyGroup))
+
+		// 成员可见
+		groupGroup.GET("/:group_id", member, response.Wrap(handler.GetGroup))
+		groupGroup.GET("/:group_id/members", member, response.Wrap(handler.ListMembers))
+		groupGroup.POST("/:group_id/leave", member, response.Wrap(handler.LeaveGroup))
+
+		// 管理员及以上
+		groupGroup.POST("/:group_id/invitations", manager, response.Wrap(handler.InviteMember))
+		groupGroup.DELETE("/:group_id/members/:user_id", manager, response.Wrap(handler.RemoveMember))
+
+		// 仅所有者
+		groupGroup.PUT("/:group_id/members/:user_id/role", owner, response.Wrap(handler.SetMemberRole))
+		groupGroup.POST("/:group_id/transfer", owner, response.Wrap(handler.TransferOwnership))
+	}
+
+	// 收到的邀请
+	invitationGroup := r.Group("/group-invitations")
+	{
+		invitationGroup.GET("", response.Wrap(handler.ListMyInvitations))
+		invitationGroup.POST("/:invitation_id/accept", response.Wrap(handler.AcceptInvitation))
+		invitationGroup.POST("/:invitation_id/decline", response.Wrap(handler.DeclineInvitation))
+	}
+}
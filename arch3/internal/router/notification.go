@@ -0,0 +1,22 @@
+package router
+
+import (
+	notificationhandler "arch3/internal/handler/notification"
+	"arch3/pkg/response"
+
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// RegisterNotificationRoutes 注册通知相关路由（均需登录）
+func RegisterNotificationRoutes(r *route.RouterGroup, handler *notificationhandler.Handler) {
+	notificationGroup := r.Group("/notification")
+	{
+		// 站内信
+		notificationGroup.GET("/messages", response.Wrap(handler.ListMessages))
+		notificationGroup.POST("/messages/read", response.Wrap(handler.MarkRead))
+
+		// 接收偏好
+		notificationGroup.GET("/preferences", response.Wrap(handler.ListPreferences))
+		notificationGroup.PUT("/preferences", response.Wrap(handler.SetPreference))
+	}
+}
@@ -2,6 +2,8 @@ package router
 
 import (
 	"arch3/internal/config"
+	grouphandler "arch3/internal/handler/group"
+	notificationhandler "arch3/internal/handler/notification"
 	userhandler "arch3/internal/handler/user"
 
 	"github.com/cloudwego/hertz/pkg/app/server"
@@ -19,9 +21,11 @@ import (
 //  2. 在 NewRouter 中接收并赋值
 //  3. 在 registerBusinessRoutes 中调用对应的 Register*Routes
 type Router struct {
-	cfg            *config.Config
-	userHandler    *userhandler.Handler
-	isShuttingDown ShutdownChecker // 检查服务是否正在关闭
+	cfg                 *config.Config
+	userHandler         *userhandler.Handler
+	notificationHandler *notificationhandler.Handler
+	groupHandler        *grouphandler.Handler
+	isShuttingDown      ShutdownChecker // 检查服务是否正在关闭
 	// 扩展点: 添加新的 handler
 	// orderHandler   *orderhandler.OrderHandler
 	// productHandler *producthandler.ProductHandler
@@ -31,11 +35,19 @@ type Router struct {
 //
 // 参数:
 //   - isShuttingDown: 检查服务是否正在关闭的函数，用于就绪探针
-func NewRouter(cfg *config.Config, userHandler *userhandler.Handler, isShuttingDown ShutdownChecker) *Router {
+func NewRouter(
+	cfg *config.Config,
+	userHandler *userhandler.Handler,
+	notificationHandler *notificationhandler.Handler,
+	groupHandler *grouphandler.Handler,
+	isShuttingDown ShutdownChecker,
+) *Router {
 	return &Router{
-		cfg:            cfg,
-		userHandler:    userHandler,
-		isShuttingDown: isShuttingDown,
+		cfg:                 cfg,
+		userHandler:         userHandler,
+		notificationHandler: notificationHandler,
+		groupHandler:        groupHandler,
+		isShuttingDown:      isShuttingDown,
 	}
 }
 
@@ -45,12 +57,16 @@ func NewRouter(cfg *config.Config, userHandler *userhandler.Handler, isShuttingD
 //
 // 路由分类:
 //   - 运维路由: /health, /ready, /swagger - 不需要认证
+//   - 上传文件: /uploads/* - 仅本地存储，不需要认证
 //   - 业务路由: /api/{version}/* - 按业务模块组织
 func (r *Router) Register(h *server.Hertz) {
 	// 1. 注册运维路由 (健康检查、就绪检查、文档)
 	RegisterOpsRoutes(h, r.cfg, r.isShuttingDown)
 
-	// 2. 注册业务路由
+	// 2. 注册上传文件访问路由
+	RegisterUploadRoutes(h, r.cfg)
+
+	// 3. 注册业务路由
 	r.registerBusinessRoutes(h)
 }
 
@@ -64,6 +80,15 @@ func (r *Router) registerBusinessRoutes(h *server.Hertz) {
 		// 用户模块路由
 		RegisterUserRoutes(api, r.userHandler)
 
+		// 通知模块路由
+		RegisterNotificationRoutes(api, r.notificationHandler)
+
+		// 组织模块路由
+		RegisterGroupRoutes(api, r.groupHandler)
+
+		// 管理后台路由
+		RegisterAdminRoutes(api, r.cfg.Admin.UserIDs, r.userHandler)
+
 		// 扩展点: 添加其他业务模块路由
 		// RegisterOrderRoutes(api, r.orderHandler)
 		// RegisterProductRoutes(api, r.productHandler)
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"strings"
+
+	"arch3/internal/config"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+)
+
+// RegisterUploadRoutes 注册本地存储的上传文件访问路由
+//
+// 仅在使用本地存储且配置了 oss.local.serve_path 时注册。
+// 不生成目录索引；该前缀在认证中间件中为公开路径。
+func RegisterUploadRoutes(h *server.Hertz, cfg *config.Config) {
+	if !cfg.OSS.IsLocal() || cfg.OSS.Local.ServePath == "" {
+		return
+	}
+
+	servePath := "/" + strings.Trim(cfg.OSS.Local.ServePath, "/")
+	h.StaticFS(servePath, &app.FS{
+		Root: cfg.OSS.Local.Dir,
+		// 去掉访问路径前缀，映射到 Dir 下
+		PathRewrite: app.NewPathSlashesStripper(strings.Count(servePath, "/")),
+	})
+}
@@ -13,10 +13,32 @@ func RegisterUserRoutes(r *route.RouterGroup, handler *userhandler.Handler) {
 	{
 		// 短信验证码
 		userGroup.POST("/sms", response.Wrap(handler.SendSMS))
+		userGroup.GET("/sms/status", response.Wrap(handler.GetSMSStatus)) // 查询投递状态
 
 		// 认证路由（无需登录）
 		userGroup.POST("/sms-login", response.Wrap(handler.SMSLogin))   // 验证码登录/注册
 		userGroup.POST("/refresh", response.Wrap(handler.RefreshToken)) // 刷新 token
 		userGroup.POST("/logout", response.Wrap(handler.Logout))        // 登出
+
+		// 当前用户资料（需登录）
+		userGroup.GET("/me", response.Wrap(handler.GetMe))
+		userGroup.PATCH("/me", response.Wrap(handler.UpdateMe))
+		userGroup.GET("/me/login-history", response.Wrap(handler.ListLoginHistory))
+		userGroup.POST("/me/avatar", response.Wrap(handler.UploadAvatar))
+		userGroup.POST("/me/phone/old-code", response.Wrap(handler.SendChangePhoneOldCode))
+		userGroup.POST("/me/phone/verify-old", response.Wrap(handler.VerifyChangePhoneOld))
+		userGroup.POST("/me/phone/new-code", response.Wrap(handler.SendChangePhoneNewCode))
+		userGroup.PUT("/me/phone", response.Wrap(handler.ChangePhone))
+		userGroup.POST("/me/email", response.Wrap(handler.BindEmail))
+		userGroup.POST("/me/email/verification", response.Wrap(handler.ResendEmailVerification))
+		userGroup.GET("/me/real-name", response.Wrap(handler.GetRealName))
+		userGroup.POST("/me/real-name", response.Wrap(handler.SubmitRealName))
+		userGroup.POST("/me/deletion/code", response.Wrap(handler.SendDeleteAccountCode))
+		userGroup.POST("/me/deletion", response.Wrap(handler.RequestAccountDeletion))
+		userGroup.DELETE("/me/deletion", response.Wrap(handler.CancelAccountDeletion))
+		userGroup.GET("/me/export", response.Wrap(handler.ExportAccountData))
+
+		// 邮箱验证（无需登录，凭邮件中的签名链接）
+		userGroup.POST("/email/verify", response.Wrap(handler.VerifyEmail))
 	}
 }
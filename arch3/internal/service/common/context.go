@@ -1 +1,49 @@
 package common
+
+import "context"
+
+type ctxKey int
+
+const clientInfoKey ctxKey = iota
+
+// ClientIP 从 context 获取客户端 IP，即 ClientInfo.IP，未设置时返回空字符串
+// 客户端 IP 只由中间件随 ClientInfo 写入，供下游按来源做限制或审计
+func ClientIP(ctx context.Context) string {
+	return ClientInfoFrom(ctx).IP
+}
+
+// ClientInfo 客户端信息，由中间件从标准请求头解析并校验格式
+type ClientInfo struct {
+	AppVersion  string // 应用版本，如 3.2.1
+	Platform    string // 平台: ios/android/harmony/web/h5/miniprogram/windows/macos/linux
+	Channel     string // 分发渠道，如应用商店或推广渠道编码
+	UTMSource   string
+	UTMMedium   string
+	UTMCampaign string
+	DeviceID    string
+	IP          string
+	UserAgent   string
+}
+
+// Source 注册来源: 优先分发渠道，其次 utm_source，最后平台
+func (c ClientInfo) Source() string {
+	switch {
+	case c.Channel != "":
+		return c.Channel
+	case c.UTMSource != "":
+		return c.UTMSource
+	default:
+		return c.Platform
+	}
+}
+
+// WithClientInfo 将客户端信息写入 context
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey, info)
+}
+
+// ClientInfoFrom 从 context 获取客户端信息，未设置时返回零值
+func ClientInfoFrom(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return info
+}
@@ -1 +1,48 @@
 package common
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"arch3/pkg/ulid"
+)
+
+// Event 领域事件
+//
+// 投递语义为至少一次，同一事件可能被重复投递，消费方应以 ID 作为幂等键去重；
+// 不保证投递顺序，需要按时间处理的消费方使用 OccurredAt。
+type Event struct {
+	ID          string          // 事件 ID（ULID），幂等键
+	Type        string          // 事件类型，如 user.registered
+	AggregateID string          // 事件主体 ID，如用户 ID
+	Payload     json.RawMessage // 事件内容 (JSON)
+	OccurredAt  time.Time
+}
+
+// NewEvent 创建领域事件，payload 序列化为 JSON
+func NewEvent(eventType, aggregateID string, payload any) (*Event, error) {
+	id, err := ulid.New()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		ID:          id,
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+		OccurredAt:  time.Now().UTC(),
+	}, nil
+}
+
+// EventPublisher 领域事件发布接口（由基础设施实现）
+//
+// Publish 把事件写入 outbox 而不是直接发送，应在业务写入所在的事务中调用（ctx 来自 Transactor.Do），
+// 事件与业务数据一同提交或回滚，由后台任务发送到消息中间件。
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...*Event) error
+}
@@ -1 +1,22 @@
 package common
+
+import (
+	"context"
+
+	"arch3/pkg/dbtx"
+)
+
+// Transactor 事务执行接口（由基础设施实现）
+//
+// Do 在事务中执行 fn，仓储使用 fn 收到的 ctx 时自动加入事务；
+// fn 返回错误时回滚，已在事务中时以保存点嵌套。
+// 遇到死锁等冲突时 fn 可能被重复执行，发通知等不能回滚的操作应通过 AfterCommit 注册。
+type Transactor interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// AfterCommit 注册事务提交后执行的操作，ctx 不在事务中时立即执行
+// 事务回滚时注册的操作被丢弃
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	dbtx.AfterCommit(ctx, fn)
+}
@@ -0,0 +1,34 @@
+package group
+
+import (
+	"errors"
+
+	domain "arch3/internal/domain/group"
+	userdomain "arch3/internal/domain/user"
+	"arch3/pkg/response"
+)
+
+// errPermissionDenied 操作人角色不足
+var errPermissionDenied = response.Err(response.CodeForbidden, "无权限执行该操作")
+
+// toResponse 将组织错误转换为业务响应
+func toResponse(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrGroupNotFound):
+		return response.Err(response.CodeNotFound, "组织不存在")
+	case errors.Is(err, domain.ErrMemberNotFound):
+		return response.Err(response.CodeNotFound, "该用户不是组织成员")
+	case errors.Is(err, domain.ErrAlreadyMember):
+		return response.Err(response.CodeConflict, "已加入其他组织，请先退出")
+	case errors.Is(err, domain.ErrInvitationNotFound):
+		return response.Err(response.CodeNotFound, "邀请不存在")
+	case errors.Is(err, domain.ErrInvitationClosed):
+		return response.Err(response.CodeConflict, "邀请已处理或已过期")
+	case errors.Is(err, domain.ErrGroupConflict):
+		return response.Err(response.CodeConflict, "成员信息已变化，请刷新后重试")
+	case errors.Is(err, userdomain.ErrUserNotFound):
+		return response.Err(response.CodeUserNotFound, "用户不存在")
+	default:
+		return response.Err(response.CodeDatabaseError, "组织服务异常")
+	}
+}
@@ -0,0 +1,149 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/group"
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+// UserData 用户在组织模块中的数据（账号数据导出）
+type UserData struct {
+	Membership  *ExportedMembership  `json:"membership,omitempty"`
+	Invitations []ExportedInvitation `json:"invitations"`
+}
+
+// ExportedMembership 导出的组织成员身份
+type ExportedMembership struct {
+	GroupID   string    `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
+
+// ExportedInvitation 导出的收到的邀请
+type ExportedInvitation struct {
+	InvitationID string     `json:"invitation_id"`
+	GroupID      string     `json:"group_id"`
+	InviterID    string     `json:"inviter_id"`
+	Role         string     `json:"role"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+}
+
+// ExportUserData 导出用户的组织成员身份与收到的邀请
+func (s *service) ExportUserData(ctx context.Context, userID string) (*UserData, error) {
+	ctx, span := tracer.Start(ctx, "service.group.ExportUserData")
+	defer span.End()
+
+	data := &UserData{Invitations: []ExportedInvitation{}}
+
+	m, err := s.repo.FindMembership(ctx, userID)
+	switch {
+	case err == nil:
+		g, err := s.repo.FindGroup(ctx, m.GroupID)
+		if err != nil {
+			tracer.RecordError(span, err)
+			return nil, err
+		}
+		data.Membership = &ExportedMembership{
+			GroupID:   g.GroupID,
+			GroupName: g.Name,
+			Role:      m.Role,
+			JoinedAt:  m.JoinedAt,
+		}
+	case !errors.Is(err, domain.ErrMemberNotFound):
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	invitations, err := s.repo.ListInvitationsByInvitee(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	for _, inv := range invitations {
+		data.Invitations = append(data.Invitations, ExportedInvitation{
+			InvitationID: inv.InvitationID,
+			GroupID:      inv.GroupID,
+			InviterID:    inv.InviterID,
+			Role:         inv.Role,
+			Status:       inv.Status,
+			CreatedAt:    inv.CreatedAt,
+			RespondedAt:  inv.RespondedAt,
+		})
+	}
+	return data, nil
+}
+
+// DeleteUserData 删除用户的组织数据
+func (s *service) DeleteUserData(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "service.group.DeleteUserData")
+	defer span.End()
+
+	if err := s.repo.DeleteInvitationsByInvitee(ctx, userID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	m, err := s.repo.FindMembership(ctx, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return nil
+	}
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	if m.Role == domain.RoleOwner {
+		if err := s.handOver(ctx, m); err != nil {
+			tracer.RecordError(span, err)
+			return err
+		}
+		if _, err := s.repo.FindGroup(ctx, m.GroupID); errors.Is(err, domain.ErrGroupNotFound) {
+			return nil
+		}
+	}
+
+	if err := s.repo.RemoveMember(ctx, m.GroupID, userID); err != nil && !errors.Is(err, domain.ErrMemberNotFound) {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// handOver 所有者注销前转让组织，无其他成员时解散
+func (s *service) handOver(ctx context.Context, owner *domain.Member) error {
+	members, err := s.repo.ListMembers(ctx, owner.GroupID)
+	if err != nil {
+		return err
+	}
+
+	var successor *domain.Member
+	for _, m := range members {
+		if m.UserID == owner.UserID {
+			continue
+		}
+		if successor == nil || m.Role == domain.RoleAdmin && successor.Role != domain.RoleAdmin {
+			successor = m
+		}
+	}
+
+	if successor == nil {
+		logger.Ctx(ctx).Info("group dissolved on owner deletion", zap.String("group_id", owner.GroupID))
+		return s.repo.DeleteGroup(ctx, owner.GroupID)
+	}
+
+	logger.Ctx(ctx).Info("group ownership transferred on owner deletion",
+		zap.String("group_id", owner.GroupID),
+		zap.String("from", owner.UserID),
+		zap.String("to", successor.UserID),
+	)
+	return s.repo.TransferOwnership(ctx, owner.GroupID, owner.UserID, successor.UserID)
+}
@@ -0,0 +1,54 @@
+package group
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/group"
+)
+
+// Service 组织服务接口
+//
+// 一个用户同时只能属于一个组织。角色权限:
+//   - owner: 全部操作，设置成员角色，转让所有权
+//   - admin: 邀请和移除普通成员
+//   - member: 查看组织与成员，退出组织
+type Service interface {
+	// CreateGroup 创建组织，创建者成为所有者
+	CreateGroup(ctx context.Context, userID, name string) (*domain.Group, error)
+	// GetGroup 查询组织
+	GetGroup(ctx context.Context, groupID string) (*domain.Group, error)
+	// GetMyMembership 查询用户所在组织的成员记录，未加入组织返回 nil
+	GetMyMembership(ctx context.Context, userID string) (*domain.Member, error)
+	// MemberRole 查询用户在组织中的角色，不是成员返回空字符串
+	// 供其他服务与中间件做组织级资源的权限判断
+	MemberRole(ctx context.Context, groupID, userID string) (string, error)
+	// ListMembers 查询组织全部成员
+	ListMembers(ctx context.Context, groupID string) ([]*domain.Member, error)
+
+	// InviteMember 按手机号邀请用户加入组织，返回邀请
+	// 手机号是否注册返回相同结果，未注册时邀请在该手机号注册后绑定到新用户（见 BindInvitations）
+	InviteMember(ctx context.Context, inviterID, groupID, phoneNumber, role string) (*domain.Invitation, error)
+	// BindInvitations 把发给手机号的未处理邀请绑定到刚注册的用户并通知，由用户注册钩子调用
+	BindInvitations(ctx context.Context, userID, phoneNumber string) error
+	// ListMyInvitations 查询用户未处理且未过期的邀请
+	ListMyInvitations(ctx context.Context, userID string) ([]*domain.Invitation, error)
+	// AcceptInvitation 接受邀请，返回成员记录
+	AcceptInvitation(ctx context.Context, userID, invitationID string) (*domain.Member, error)
+	// DeclineInvitation 拒绝邀请
+	DeclineInvitation(ctx context.Context, userID, invitationID string) error
+
+	// SetMemberRole 设置成员角色（admin/member），仅所有者可操作
+	SetMemberRole(ctx context.Context, operatorID, groupID, userID, role string) (*domain.Member, error)
+	// RemoveMember 移除权限低于操作人的成员
+	RemoveMember(ctx context.Context, operatorID, groupID, userID string) error
+	// TransferOwnership 转让所有权，原所有者降为管理员
+	TransferOwnership(ctx context.Context, ownerID, groupID, newOwnerID string) error
+	// LeaveGroup 退出组织；所有者需先转让，唯一成员退出时解散组织
+	LeaveGroup(ctx context.Context, userID, groupID string) error
+
+	// ExportUserData 导出用户的组织成员身份与收到的邀请（账号数据导出）
+	ExportUserData(ctx context.Context, userID string) (*UserData, error)
+	// DeleteUserData 删除用户的组织数据（账号注销）
+	// 所有者注销时转让给最早加入的管理员（无管理员时为最早加入的成员），无其他成员时解散组织
+	DeleteUserData(ctx context.Context, userID string) error
+}
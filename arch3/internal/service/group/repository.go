@@ -0,0 +1,67 @@
+package group
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/group"
+	userdomain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+)
+
+// Repository 组织仓储接口（由使用方定义）
+type Repository interface {
+	// CreateGroup 创建组织并以 owner 角色加入创建者，所有者已属于其他组织返回 domain.ErrAlreadyMember
+	CreateGroup(ctx context.Context, g *domain.Group) error
+	// FindGroup 查询组织，不存在返回 domain.ErrGroupNotFound
+	FindGroup(ctx context.Context, groupID string) (*domain.Group, error)
+	// FindMember 查询组织成员，不是成员返回 domain.ErrMemberNotFound
+	FindMember(ctx context.Context, groupID, userID string) (*domain.Member, error)
+	// FindMembership 查询用户所在组织的成员记录，未加入组织返回 domain.ErrMemberNotFound
+	FindMembership(ctx context.Context, userID string) (*domain.Member, error)
+	// ListMembers 查询组织全部成员，按加入时间升序
+	ListMembers(ctx context.Context, groupID string) ([]*domain.Member, error)
+
+	// CreateInvitation 创建邀请
+	CreateInvitation(ctx context.Context, inv *domain.Invitation) error
+	// FindInvitation 查询邀请，不存在返回 domain.ErrInvitationNotFound
+	FindInvitation(ctx context.Context, invitationID string) (*domain.Invitation, error)
+	// ListOpenInvitations 查询用户未处理且未过期的邀请
+	ListOpenInvitations(ctx context.Context, inviteeID string) ([]*domain.Invitation, error)
+	// ListOpenInvitationsByPhone 查询发给手机号未处理且未过期的邀请，包括已绑定用户的邀请
+	ListOpenInvitationsByPhone(ctx context.Context, phoneNumber string) ([]*domain.Invitation, error)
+	// BindPhoneInvitations 把发给手机号且尚未绑定用户的未处理邀请绑定到 userID，返回绑定的邀请
+	BindPhoneInvitations(ctx context.Context, phoneNumber, userID string) ([]*domain.Invitation, error)
+	// ListInvitationsByInvitee 查询用户收到的全部邀请
+	ListInvitationsByInvitee(ctx context.Context, inviteeID string) ([]*domain.Invitation, error)
+	// AcceptInvitation 接受邀请并加入组织
+	// 邀请已处理或过期返回 domain.ErrInvitationClosed，组织已解散返回 domain.ErrGroupNotFound，
+	// 用户已属于其他组织返回 domain.ErrAlreadyMember
+	AcceptInvitation(ctx context.Context, inv *domain.Invitation) error
+	// DeclineInvitation 拒绝邀请，邀请已处理或过期返回 domain.ErrInvitationClosed
+	DeclineInvitation(ctx context.Context, invitationID string) error
+	// DeleteInvitationsByInvitee 删除用户收到的全部邀请
+	DeleteInvitationsByInvitee(ctx context.Context, inviteeID string) error
+
+	// UpdateMemberRole 更新成员角色，当前角色不是 fromRole 返回 domain.ErrGroupConflict
+	UpdateMemberRole(ctx context.Context, groupID, userID, fromRole, toRole string) error
+	// TransferOwnership 转让所有权，原所有者降为管理员
+	// 原所有者已不是所有者或新所有者不是成员返回 domain.ErrGroupConflict
+	TransferOwnership(ctx context.Context, groupID, fromUserID, toUserID string) error
+	// RemoveMember 移除非所有者成员，不是成员或是所有者返回 domain.ErrMemberNotFound
+	RemoveMember(ctx context.Context, groupID, userID string) error
+	// DeleteGroup 解散组织，删除全部成员与邀请
+	DeleteGroup(ctx context.Context, groupID string) error
+}
+
+// UserLookup 用户查询接口（由使用方定义）
+type UserLookup interface {
+	// FindByPhoneNumber 按手机号查询用户，不存在返回 userdomain.ErrUserNotFound
+	FindByPhoneNumber(ctx context.Context, phoneNumber string) (*userdomain.User, error)
+	// FindByUserID 按 ID 查询用户，不存在返回 userdomain.ErrUserNotFound
+	FindByUserID(ctx context.Context, userID string) (*userdomain.User, error)
+}
+
+// Notifier 通知发送接口（由使用方定义）
+type Notifier interface {
+	Notify(ctx context.Context, req *notification.Request) error
+}
@@ -0,0 +1,504 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/group"
+	userdomain "arch3/internal/domain/user"
+	"arch3/internal/service/common"
+	"arch3/internal/service/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+	"arch3/pkg/ulid"
+
+	"go.uber.org/zap"
+)
+
+// invitationTTL 邀请有效期
+const invitationTTL = 7 * 24 * time.Hour
+
+// service 组织服务实现
+type service struct {
+	tx       common.Transactor
+	repo     Repository
+	users    UserLookup
+	notifier Notifier
+}
+
+// NewService 创建组织服务
+func NewService(tx common.Transactor, repo Repository, users UserLookup, notifier Notifier) Service {
+	return &service{tx: tx, repo: repo, users: users, notifier: notifier}
+}
+
+// CreateGroup 创建组织
+func (s *service) CreateGroup(ctx context.Context, userID, name string) (*domain.Group, error) {
+	ctx, span := tracer.Start(ctx, "service.group.CreateGroup")
+	defer span.End()
+
+	groupID, err := ulid.New()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeInternal, "生成组织 ID 失败")
+	}
+
+	g := &domain.Group{GroupID: groupID, Name: name, OwnerID: userID}
+	if err := s.repo.CreateGroup(ctx, g); err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("group created",
+		zap.String("group_id", groupID),
+		zap.String("owner_id", userID),
+	)
+	return g, nil
+}
+
+// GetGroup 查询组织
+func (s *service) GetGroup(ctx context.Context, groupID string) (*domain.Group, error) {
+	ctx, span := tracer.Start(ctx, "service.group.GetGroup")
+	defer span.End()
+
+	g, err := s.repo.FindGroup(ctx, groupID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+	return g, nil
+}
+
+// GetMyMembership 查询用户所在组织的成员记录
+func (s *service) GetMyMembership(ctx context.Context, userID string) (*domain.Member, error) {
+	ctx, span := tracer.Start(ctx, "service.group.GetMyMembership")
+	defer span.End()
+
+	m, err := s.repo.FindMembership(ctx, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+	return m, nil
+}
+
+// MemberRole 查询用户在组织中的角色
+func (s *service) MemberRole(ctx context.Context, groupID, userID string) (string, error) {
+	m, err := s.repo.FindMember(ctx, groupID, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", toResponse(err)
+	}
+	return m.Role, nil
+}
+
+// ListMembers 查询组织全部成员
+func (s *service) ListMembers(ctx context.Context, groupID string) ([]*domain.Member, error) {
+	ctx, span := tracer.Start(ctx, "service.group.ListMembers")
+	defer span.End()
+
+	members, err := s.repo.ListMembers(ctx, groupID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+	return members, nil
+}
+
+// InviteMember 按手机号邀请用户加入组织
+// 管理员只能邀请普通成员，所有者可邀请管理员。
+// 为避免借邀请判断手机号是否注册，未注册、已加入其他组织的手机号与普通用户返回相同结果，
+// 只有已是本组织成员或已被本组织邀请时返回冲突（邀请人本就能看到这些信息）
+func (s *service) InviteMember(ctx context.Context, inviterID, groupID, phoneNumber, role string) (*domain.Invitation, error) {
+	ctx, span := tracer.Start(ctx, "service.group.InviteMember")
+	defer span.End()
+
+	inviter, err := s.requireRole(ctx, groupID, inviterID, domain.RoleAdmin)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if role != domain.RoleMember && role != domain.RoleAdmin {
+		return nil, response.Err(response.CodeInvalidParam, "角色必须是 admin 或 member")
+	}
+	if role == domain.RoleAdmin && inviter.Role != domain.RoleOwner {
+		return nil, errPermissionDenied
+	}
+
+	g, err := s.repo.FindGroup(ctx, groupID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+
+	// 未注册时 inviteeID 为空，邀请在注册后绑定
+	var inviteeID string
+	invitee, err := s.users.FindByPhoneNumber(ctx, phoneNumber)
+	switch {
+	case err == nil:
+		inviteeID = invitee.UserID
+	case !errors.Is(err, userdomain.ErrUserNotFound):
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+
+	invitationID, err := ulid.New()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeInternal, "生成邀请 ID 失败")
+	}
+	now := time.Now().UTC()
+	inv := &domain.Invitation{
+		InvitationID: invitationID,
+		GroupID:      groupID,
+		InviteeID:    inviteeID,
+		InviteePhone: phoneNumber,
+		InviterID:    inviterID,
+		Role:         role,
+		Status:       domain.InvitationPending,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(invitationTTL),
+	}
+
+	// 检查与创建在同一事务中，通知在提交后发送
+	err = s.tx.Do(ctx, func(ctx context.Context) error {
+		if err := s.checkInvitable(ctx, groupID, inviteeID, phoneNumber); err != nil {
+			return err
+		}
+		if err := s.repo.CreateInvitation(ctx, inv); err != nil {
+			return toResponse(err)
+		}
+		if inviteeID != "" {
+			common.AfterCommit(ctx, func(ctx context.Context) {
+				s.notifyInvitation(ctx, inv, g)
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	logger.Ctx(ctx).Info("group invitation created",
+		zap.String("group_id", groupID),
+		zap.String("invitation_id", invitationID),
+		zap.String("inviter_id", inviterID),
+		zap.String("invitee_id", inviteeID),
+	)
+	return inv, nil
+}
+
+// BindInvitations 把发给手机号的未处理邀请绑定到刚注册的用户，提交后通知
+func (s *service) BindInvitations(ctx context.Context, userID, phoneNumber string) error {
+	ctx, span := tracer.Start(ctx, "service.group.BindInvitations")
+	defer span.End()
+
+	bound, err := s.repo.BindPhoneInvitations(ctx, phoneNumber, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	for _, inv := range bound {
+		common.AfterCommit(ctx, func(ctx context.Context) {
+			g, err := s.repo.FindGroup(ctx, inv.GroupID)
+			if err != nil {
+				logger.Ctx(ctx).Warn("find invitation group failed", zap.String("invitation_id", inv.InvitationID), zap.Error(err))
+				return
+			}
+			s.notifyInvitation(ctx, inv, g)
+		})
+	}
+	return nil
+}
+
+// notifyInvitation 通知被邀请人，失败只记录日志
+func (s *service) notifyInvitation(ctx context.Context, inv *domain.Invitation, g *domain.Group) {
+	err := s.notifier.Notify(ctx, &notification.Request{
+		Template:  notification.TemplateGroupInvitation,
+		Recipient: notification.Recipient{UserID: inv.InviteeID},
+		Vars:      map[string]string{"group_name": g.Name},
+	})
+	if err != nil {
+		logger.Ctx(ctx).Warn("send group invitation notification failed",
+			zap.String("invitation_id", inv.InvitationID),
+			zap.Error(err),
+		)
+	}
+}
+
+// checkInvitable 被邀请人不能已是本组织成员，该手机号也不能有本组织未处理的邀请
+// 已加入其他组织的用户仍可邀请，退出原组织后才能接受，避免暴露其注册与组织信息
+func (s *service) checkInvitable(ctx context.Context, groupID, inviteeID, phoneNumber string) error {
+	if inviteeID != "" {
+		_, err := s.repo.FindMember(ctx, groupID, inviteeID)
+		switch {
+		case err == nil:
+			return response.Err(response.CodeConflict, "该用户已是组织成员")
+		case !errors.Is(err, domain.ErrMemberNotFound):
+			return toResponse(err)
+		}
+	}
+
+	open, err := s.repo.ListOpenInvitationsByPhone(ctx, phoneNumber)
+	if err != nil {
+		return toResponse(err)
+	}
+	if inviteeID != "" {
+		// 按手机号记录之前创建的邀请只有被邀请人 ID
+		byUser, err := s.repo.ListOpenInvitations(ctx, inviteeID)
+		if err != nil {
+			return toResponse(err)
+		}
+		open = append(open, byUser...)
+	}
+	for _, inv := range open {
+		if inv.GroupID == groupID {
+			return response.Err(response.CodeConflict, "已邀请该手机号，请等待对方处理")
+		}
+	}
+	return nil
+}
+
+// ListMyInvitations 查询用户未处理且未过期的邀请
+func (s *service) ListMyInvitations(ctx context.Context, userID string) ([]*domain.Invitation, error) {
+	ctx, span := tracer.Start(ctx, "service.group.ListMyInvitations")
+	defer span.End()
+
+	invitations, err := s.repo.ListOpenInvitations(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+	return invitations, nil
+}
+
+// AcceptInvitation 接受邀请
+func (s *service) AcceptInvitation(ctx context.Context, userID, invitationID string) (*domain.Member, error) {
+	ctx, span := tracer.Start(ctx, "service.group.AcceptInvitation")
+	defer span.End()
+
+	inv, err := s.findOwnInvitation(ctx, userID, invitationID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	// 接受与读取成员在同一事务中，读到的一定是本次加入后的数据
+	var m *domain.Member
+	err = s.tx.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.AcceptInvitation(ctx, inv); err != nil {
+			return err
+		}
+		var err error
+		m, err = s.repo.FindMember(ctx, inv.GroupID, userID)
+		return err
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("group invitation accepted",
+		zap.String("group_id", inv.GroupID),
+		zap.String("invitation_id", invitationID),
+		zap.String("user_id", userID),
+	)
+	return m, nil
+}
+
+// DeclineInvitation 拒绝邀请
+func (s *service) DeclineInvitation(ctx context.Context, userID, invitationID string) error {
+	ctx, span := tracer.Start(ctx, "service.group.DeclineInvitation")
+	defer span.End()
+
+	if _, err := s.findOwnInvitation(ctx, userID, invitationID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if err := s.repo.DeclineInvitation(ctx, invitationID); err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+	return nil
+}
+
+// findOwnInvitation 查询发给 userID 且仍可处理的邀请
+// 发给其他用户的邀请按不存在处理，不暴露邀请是否存在
+func (s *service) findOwnInvitation(ctx context.Context, userID, invitationID string) (*domain.Invitation, error) {
+	inv, err := s.repo.FindInvitation(ctx, invitationID)
+	if err != nil {
+		return nil, toResponse(err)
+	}
+	if inv.InviteeID != userID {
+		return nil, toResponse(domain.ErrInvitationNotFound)
+	}
+	if !inv.Open(time.Now()) {
+		return nil, toResponse(domain.ErrInvitationClosed)
+	}
+	return inv, nil
+}
+
+// SetMemberRole 设置成员角色
+func (s *service) SetMemberRole(ctx context.Context, operatorID, groupID, userID, role string) (*domain.Member, error) {
+	ctx, span := tracer.Start(ctx, "service.group.SetMemberRole")
+	defer span.End()
+
+	if _, err := s.requireRole(ctx, groupID, operatorID, domain.RoleOwner); err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if role != domain.RoleMember && role != domain.RoleAdmin {
+		return nil, response.Err(response.CodeInvalidParam, "角色必须是 admin 或 member，转让所有权请使用转让接口")
+	}
+
+	target, err := s.repo.FindMember(ctx, groupID, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+	if target.Role == domain.RoleOwner {
+		return nil, response.Err(response.CodeConflict, "不能修改所有者的角色")
+	}
+	if target.Role == role {
+		return target, nil
+	}
+
+	if err := s.repo.UpdateMemberRole(ctx, groupID, userID, target.Role, role); err != nil {
+		tracer.RecordError(span, err)
+		return nil, toResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("group member role changed",
+		zap.String("group_id", groupID),
+		zap.String("user_id", userID),
+		zap.String("operator_id", operatorID),
+		zap.String("from", target.Role),
+		zap.String("to", role),
+	)
+	target.Role = role
+	return target, nil
+}
+
+// RemoveMember 移除成员
+// 操作人的角色必须高于被移除的成员: 所有者可移除管理员与普通成员，管理员只能移除普通成员
+func (s *service) RemoveMember(ctx context.Context, operatorID, groupID, userID string) error {
+	ctx, span := tracer.Start(ctx, "service.group.RemoveMember")
+	defer span.End()
+
+	if operatorID == userID {
+		return response.Err(response.CodeInvalidParam, "不能移除自己，请使用退出组织")
+	}
+	operator, err := s.requireRole(ctx, groupID, operatorID, domain.RoleAdmin)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	target, err := s.repo.FindMember(ctx, groupID, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+	if domain.RoleAtLeast(target.Role, operator.Role) {
+		return errPermissionDenied
+	}
+
+	if err := s.repo.RemoveMember(ctx, groupID, userID); err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("group member removed",
+		zap.String("group_id", groupID),
+		zap.String("user_id", userID),
+		zap.String("operator_id", operatorID),
+	)
+	return nil
+}
+
+// TransferOwnership 转让所有权
+func (s *service) TransferOwnership(ctx context.Context, ownerID, groupID, newOwnerID string) error {
+	ctx, span := tracer.Start(ctx, "service.group.TransferOwnership")
+	defer span.End()
+
+	if ownerID == newOwnerID {
+		return response.Err(response.CodeInvalidParam, "不能转让给自己")
+	}
+	if _, err := s.requireRole(ctx, groupID, ownerID, domain.RoleOwner); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if _, err := s.repo.FindMember(ctx, groupID, newOwnerID); err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+
+	if err := s.repo.TransferOwnership(ctx, groupID, ownerID, newOwnerID); err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("group ownership transferred",
+		zap.String("group_id", groupID),
+		zap.String("from", ownerID),
+		zap.String("to", newOwnerID),
+	)
+	return nil
+}
+
+// LeaveGroup 退出组织
+func (s *service) LeaveGroup(ctx context.Context, userID, groupID string) error {
+	ctx, span := tracer.Start(ctx, "service.group.LeaveGroup")
+	defer span.End()
+
+	m, err := s.repo.FindMember(ctx, groupID, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+
+	if m.Role == domain.RoleOwner {
+		members, err := s.repo.ListMembers(ctx, groupID)
+		if err != nil {
+			tracer.RecordError(span, err)
+			return toResponse(err)
+		}
+		if len(members) > 1 {
+			return response.Err(response.CodeConflict, "请先将组织转让给其他成员")
+		}
+		if err := s.repo.DeleteGroup(ctx, groupID); err != nil {
+			tracer.RecordError(span, err)
+			return toResponse(err)
+		}
+		logger.Ctx(ctx).Info("group dissolved", zap.String("group_id", groupID), zap.String("owner_id", userID))
+		return nil
+	}
+
+	if err := s.repo.RemoveMember(ctx, groupID, userID); err != nil {
+		tracer.RecordError(span, err)
+		return toResponse(err)
+	}
+	logger.Ctx(ctx).Info("group member left", zap.String("group_id", groupID), zap.String("user_id", userID))
+	return nil
+}
+
+// requireRole 校验操作人是组织成员且角色不低于 min
+func (s *service) requireRole(ctx context.Context, groupID, userID, min string) (*domain.Member, error) {
+	m, err := s.repo.FindMember(ctx, groupID, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return nil, errPermissionDenied
+	}
+	if err != nil {
+		return nil, toResponse(err)
+	}
+	if !domain.RoleAtLeast(m.Role, min) {
+		return nil, errPermissionDenied
+	}
+	return m, nil
+}
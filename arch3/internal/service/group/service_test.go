@@ -0,0 +1,474 @@
+package group
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/group"
+	userdomain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/pkg/response"
+)
+
+// fakeRepository 测试用内存仓储
+type fakeRepository struct {
+	mu          sync.Mutex
+	groups      map[string]*domain.Group
+	members     []*domain.Member
+	invitations []*domain.Invitation
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{groups: make(map[string]*domain.Group)}
+}
+
+func (r *fakeRepository) memberIndex(groupID, userID string) int {
+	for i, m := range r.members {
+		if m.UserID == userID && (groupID == "" || m.GroupID == groupID) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *fakeRepository) CreateGroup(_ context.Context, g *domain.Group) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.memberIndex("", g.OwnerID) >= 0 {
+		return domain.ErrAlreadyMember
+	}
+	r.groups[g.GroupID] = g
+	r.members = append(r.members, &domain.Member{GroupID: g.GroupID, UserID: g.OwnerID, Role: domain.RoleOwner, JoinedAt: time.Now()})
+	return nil
+}
+
+func (r *fakeRepository) FindGroup(_ context.Context, groupID string) (*domain.Group, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g, ok := r.groups[groupID]
+	if !ok {
+		return nil, domain.ErrGroupNotFound
+	}
+	cp := *g
+	return &cp, nil
+}
+
+func (r *fakeRepository) FindMember(_ context.Context, groupID, userID string) (*domain.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.memberIndex(groupID, userID)
+	if i < 0 {
+		return nil, domain.ErrMemberNotFound
+	}
+	cp := *r.members[i]
+	return &cp, nil
+}
+
+func (r *fakeRepository) FindMembership(ctx context.Context, userID string) (*domain.Member, error) {
+	return r.FindMember(ctx, "", userID)
+}
+
+func (r *fakeRepository) ListMembers(_ context.Context, groupID string) ([]*domain.Member, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var members []*domain.Member
+	for _, m := range r.members {
+		if m.GroupID == groupID {
+			cp := *m
+			members = append(members, &cp)
+		}
+	}
+	return members, nil
+}
+
+func (r *fakeRepository) CreateInvitation(_ context.Context, inv *domain.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.invitations = append(r.invitations, inv)
+	return nil
+}
+
+func (r *fakeRepository) FindInvitation(_ context.Context, invitationID string) (*domain.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, inv := range r.invitations {
+		if inv.InvitationID == invitationID {
+			cp := *inv
+			return &cp, nil
+		}
+	}
+	return nil, domain.ErrInvitationNotFound
+}
+
+func (r *fakeRepository) ListOpenInvitations(_ context.Context, inviteeID string) ([]*domain.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var invitations []*domain.Invitation
+	for _, inv := range r.invitations {
+		if inv.InviteeID == inviteeID && inv.Open(time.Now()) {
+			invitations = append(invitations, inv)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *fakeRepository) ListOpenInvitationsByPhone(_ context.Context, phoneNumber string) ([]*domain.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var invitations []*domain.Invitation
+	for _, inv := range r.invitations {
+		if inv.InviteePhone == phoneNumber && inv.Open(time.Now()) {
+			invitations = append(invitations, inv)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *fakeRepository) BindPhoneInvitations(_ context.Context, phoneNumber, userID string) ([]*domain.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var bound []*domain.Invitation
+	for _, inv := range r.invitations {
+		if inv.InviteePhone == phoneNumber && inv.InviteeID == "" && inv.Open(time.Now()) {
+			inv.InviteeID = userID
+			bound = append(bound, inv)
+		}
+	}
+	return bound, nil
+}
+
+func (r *fakeRepository) ListInvitationsByInvitee(_ context.Context, inviteeID string) ([]*domain.Invitation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var invitations []*domain.Invitation
+	for _, inv := range r.invitations {
+		if inv.InviteeID == inviteeID {
+			invitations = append(invitations, inv)
+		}
+	}
+	return invitations, nil
+}
+
+func (r *fakeRepository) AcceptInvitation(_ context.Context, inv *domain.Invitation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, stored := range r.invitations {
+		if stored.InvitationID != inv.InvitationID {
+			continue
+		}
+		if !stored.Open(time.Now()) {
+			return domain.ErrInvitationClosed
+		}
+		if _, ok := r.groups[inv.GroupID]; !ok {
+			return domain.ErrGroupNotFound
+		}
+		if r.memberIndex("", inv.InviteeID) >= 0 {
+			return domain.ErrAlreadyMember
+		}
+		stored.Status = domain.InvitationAccepted
+		r.members = append(r.members, &domain.Member{GroupID: inv.GroupID, UserID: inv.InviteeID, Role: inv.Role, JoinedAt: time.Now()})
+		return nil
+	}
+	return domain.ErrInvitationClosed
+}
+
+func (r *fakeRepository) DeclineInvitation(_ context.Context, invitationID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, inv := range r.invitations {
+		if inv.InvitationID == invitationID && inv.Open(time.Now()) {
+			inv.Status = domain.InvitationDeclined
+			return nil
+		}
+	}
+	return domain.ErrInvitationClosed
+}
+
+func (r *fakeRepository) DeleteInvitationsByInvitee(_ context.Context, inviteeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.invitations[:0]
+	for _, inv := range r.invitations {
+		if inv.InviteeID != inviteeID {
+			kept = append(kept, inv)
+		}
+	}
+	r.invitations = kept
+	return nil
+}
+
+func (r *fakeRepository) UpdateMemberRole(_ context.Context, groupID, userID, fromRole, toRole string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.memberIndex(groupID, userID)
+	if i < 0 || r.members[i].Role != fromRole {
+		return domain.ErrGroupConflict
+	}
+	r.members[i].Role = toRole
+	return nil
+}
+
+func (r *fakeRepository) TransferOwnership(_ context.Context, groupID, fromUserID, toUserID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	from, to := r.memberIndex(groupID, fromUserID), r.memberIndex(groupID, toUserID)
+	if from < 0 || to < 0 || r.members[from].Role != domain.RoleOwner || r.members[to].Role == domain.RoleOwner {
+		return domain.ErrGroupConflict
+	}
+	r.members[from].Role = domain.RoleAdmin
+	r.members[to].Role = domain.RoleOwner
+	r.groups[groupID].OwnerID = toUserID
+	return nil
+}
+
+func (r *fakeRepository) RemoveMember(_ context.Context, groupID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	i := r.memberIndex(groupID, userID)
+	if i < 0 || r.members[i].Role == domain.RoleOwner {
+		return domain.ErrMemberNotFound
+	}
+	r.members = append(r.members[:i], r.members[i+1:]...)
+	return nil
+}
+
+func (r *fakeRepository) DeleteGroup(_ context.Context, groupID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.groups, groupID)
+	kept := r.members[:0]
+	for _, m := range r.members {
+		if m.GroupID != groupID {
+			kept = append(kept, m)
+		}
+	}
+	r.members = kept
+	return nil
+}
+
+// fakeUsers 测试用用户查询，手机号为 "phone-" + 用户 ID，其他手机号视为未注册
+type fakeUsers struct{}
+
+func (fakeUsers) FindByPhoneNumber(_ context.Context, phone string) (*userdomain.User, error) {
+	userID, ok := strings.CutPrefix(phone, "phone-")
+	if !ok || userID == "" {
+		return nil, userdomain.ErrUserNotFound
+	}
+	return &userdomain.User{UserID: userID, PhoneNumber: phone}, nil
+}
+
+func (fakeUsers) FindByUserID(_ context.Context, userID string) (*userdomain.User, error) {
+	return &userdomain.User{UserID: userID, PhoneNumber: "phone-" + userID}, nil
+}
+
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, *notification.Request) error { return nil }
+
+// setupGroup 创建 owner 为所有者、admin 与 member 为成员的组织
+func setupGroup(t *testing.T) (*service, *fakeRepository, string) {
+	t.Helper()
+	repo := newFakeRepository()
+	s := &service{tx: directTx{}, repo: repo, users: fakeUsers{}, notifier: nopNotifier{}}
+	ctx := context.Background()
+
+	g, err := s.CreateGroup(ctx, "owner", "研发部")
+	if err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+	for _, invitee := range []struct{ userID, role string }{{"admin", domain.RoleAdmin}, {"member", domain.RoleMember}} {
+		inv, err := s.InviteMember(ctx, "owner", g.GroupID, "phone-"+invitee.userID, invitee.role)
+		if err != nil {
+			t.Fatalf("InviteMember(%s) error = %v", invitee.userID, err)
+		}
+		if _, err := s.AcceptInvitation(ctx, invitee.userID, inv.InvitationID); err != nil {
+			t.Fatalf("AcceptInvitation(%s) error = %v", invitee.userID, err)
+		}
+	}
+	return s, repo, g.GroupID
+}
+
+func codeOf(err error) int {
+	if err == nil {
+		return response.CodeSuccess
+	}
+	return response.CodeFromError(err)
+}
+
+func TestInviteMember(t *testing.T) {
+	tests := []struct {
+		name      string
+		inviterID string
+		phone     string
+		role      string
+		wantCode  int
+	}{
+		{"所有者邀请管理员", "owner", "phone-u1", domain.RoleAdmin, response.CodeSuccess},
+		{"管理员邀请成员", "admin", "phone-u1", domain.RoleMember, response.CodeSuccess},
+		{"管理员不能邀请管理员", "admin", "phone-u1", domain.RoleAdmin, response.CodeForbidden},
+		{"普通成员不能邀请", "member", "phone-u1", domain.RoleMember, response.CodeForbidden},
+		{"非成员不能邀请", "stranger", "phone-u1", domain.RoleMember, response.CodeForbidden},
+		{"已是成员", "owner", "phone-member", domain.RoleMember, response.CodeConflict},
+		{"已加入其他组织", "owner", "phone-other", domain.RoleMember, response.CodeSuccess},
+		{"手机号未注册", "owner", "13800000009", domain.RoleMember, response.CodeSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, groupID := setupGroup(t)
+			if _, err := s.CreateGroup(context.Background(), "other", "市场部"); err != nil {
+				t.Fatalf("CreateGroup() error = %v", err)
+			}
+			_, err := s.InviteMember(context.Background(), tt.inviterID, groupID, tt.phone, tt.role)
+			if got := codeOf(err); got != tt.wantCode {
+				t.Errorf("Expected code %d, got %d (%v)", tt.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestInviteMember_Unregistered(t *testing.T) {
+	s, _, groupID := setupGroup(t)
+	ctx := context.Background()
+	const phone = "13800000009"
+
+	inv, err := s.InviteMember(ctx, "owner", groupID, phone, domain.RoleMember)
+	if err != nil {
+		t.Fatalf("InviteMember() error = %v", err)
+	}
+	if inv.InviteeID != "" {
+		t.Errorf("Expected unbound invitation, got invitee %q", inv.InviteeID)
+	}
+	if _, err := s.InviteMember(ctx, "admin", groupID, phone, domain.RoleMember); codeOf(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict for duplicate invitation, got %v", err)
+	}
+
+	// 注册后绑定，新用户可以接受
+	if err := s.BindInvitations(ctx, "u9", phone); err != nil {
+		t.Fatalf("BindInvitations() error = %v", err)
+	}
+	invitations, err := s.ListMyInvitations(ctx, "u9")
+	if err != nil {
+		t.Fatalf("ListMyInvitations() error = %v", err)
+	}
+	if len(invitations) != 1 || invitations[0].InvitationID != inv.InvitationID {
+		t.Fatalf("Expected bound invitation %s, got %+v", inv.InvitationID, invitations)
+	}
+	if _, err := s.AcceptInvitation(ctx, "u9", inv.InvitationID); err != nil {
+		t.Fatalf("AcceptInvitation() error = %v", err)
+	}
+	if role, _ := s.MemberRole(ctx, groupID, "u9"); role != domain.RoleMember {
+		t.Errorf("Expected u9 member, got role %q", role)
+	}
+}
+
+func TestInvitation_AcceptOnce(t *testing.T) {
+	s, _, groupID := setupGroup(t)
+	ctx := context.Background()
+
+	inv, err := s.InviteMember(ctx, "owner", groupID, "phone-u1", domain.RoleMember)
+	if err != nil {
+		t.Fatalf("InviteMember() error = %v", err)
+	}
+	if _, err := s.InviteMember(ctx, "owner", groupID, "phone-u1", domain.RoleMember); codeOf(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict for duplicate invitation, got %v", err)
+	}
+	if _, err := s.AcceptInvitation(ctx, "u2", inv.InvitationID); codeOf(err) != response.CodeNotFound {
+		t.Errorf("Expected CodeNotFound for other user's invitation, got %v", err)
+	}
+	if err := s.DeclineInvitation(ctx, "u1", inv.InvitationID); err != nil {
+		t.Fatalf("DeclineInvitation() error = %v", err)
+	}
+	if _, err := s.AcceptInvitation(ctx, "u1", inv.InvitationID); codeOf(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict for declined invitation, got %v", err)
+	}
+	if role, _ := s.MemberRole(ctx, groupID, "u1"); role != "" {
+		t.Errorf("Expected u1 not a member, got role %q", role)
+	}
+}
+
+func TestRemoveMember(t *testing.T) {
+	tests := []struct {
+		name       string
+		operatorID string
+		userID     string
+		wantCode   int
+	}{
+		{"所有者移除管理员", "owner", "admin", response.CodeSuccess},
+		{"管理员移除成员", "admin", "member", response.CodeSuccess},
+		{"管理员不能移除所有者", "admin", "owner", response.CodeForbidden},
+		{"成员不能移除成员", "member", "admin", response.CodeForbidden},
+		{"不能移除自己", "owner", "owner", response.CodeInvalidParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, groupID := setupGroup(t)
+			err := s.RemoveMember(context.Background(), tt.operatorID, groupID, tt.userID)
+			if got := codeOf(err); got != tt.wantCode {
+				t.Errorf("Expected code %d, got %d (%v)", tt.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestTransferOwnershipAndLeave(t *testing.T) {
+	s, repo, groupID := setupGroup(t)
+	ctx := context.Background()
+
+	if err := s.LeaveGroup(ctx, "owner", groupID); codeOf(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict when owner leaves, got %v", err)
+	}
+	if err := s.TransferOwnership(ctx, "admin", groupID, "member"); codeOf(err) != response.CodeForbidden {
+		t.Errorf("Expected CodeForbidden for non-owner transfer, got %v", err)
+	}
+	if err := s.TransferOwnership(ctx, "owner", groupID, "member"); err != nil {
+		t.Fatalf("TransferOwnership() error = %v", err)
+	}
+	if role, _ := s.MemberRole(ctx, groupID, "member"); role != domain.RoleOwner {
+		t.Errorf("Expected new owner, got %q", role)
+	}
+	if err := s.LeaveGroup(ctx, "owner", groupID); err != nil {
+		t.Fatalf("LeaveGroup() error = %v", err)
+	}
+	if role, _ := s.MemberRole(ctx, groupID, "owner"); role != "" {
+		t.Errorf("Expected former owner left, got role %q", role)
+	}
+	if repo.groups[groupID].OwnerID != "member" {
+		t.Errorf("Expected group owner member, got %s", repo.groups[groupID].OwnerID)
+	}
+}
+
+func TestDeleteUserData_OwnerHandOver(t *testing.T) {
+	s, repo, groupID := setupGroup(t)
+	ctx := context.Background()
+
+	// 所有者注销: 优先转让给管理员
+	if err := s.DeleteUserData(ctx, "owner"); err != nil {
+		t.Fatalf("DeleteUserData(owner) error = %v", err)
+	}
+	if role, _ := s.MemberRole(ctx, groupID, "admin"); role != domain.RoleOwner {
+		t.Errorf("Expected admin promoted to owner, got %q", role)
+	}
+	if role, _ := s.MemberRole(ctx, groupID, "owner"); role != "" {
+		t.Errorf("Expected deleted owner removed, got role %q", role)
+	}
+
+	// 剩余成员依次注销，最后一人注销时解散组织
+	for _, userID := range []string{"member", "admin"} {
+		if err := s.DeleteUserData(ctx, userID); err != nil {
+			t.Fatalf("DeleteUserData(%s) error = %v", userID, err)
+		}
+	}
+	if _, ok := repo.groups[groupID]; ok {
+		t.Error("Expected group dissolved after last member deleted")
+	}
+}
+
+// directTx 直接执行 fn 的事务替身，内存仓储不需要真正的事务
+type directTx struct{}
+
+func (directTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"errors"
+
+	domain "arch3/internal/domain/notification"
+	"arch3/pkg/response"
+)
+
+// 通知服务错误定义
+var (
+	ErrTemplateNotFound  = errors.New("通知模板不存在")
+	ErrChannelNotFound   = errors.New("通知渠道不存在")
+	ErrMandatoryTemplate = errors.New("该通知涉及账号安全，不可关闭")
+)
+
+// ToResponse 将通知错误转换为业务响应
+func ToResponse(err error) *response.Result {
+	switch {
+	case errors.Is(err, ErrTemplateNotFound), errors.Is(err, ErrChannelNotFound):
+		return response.Err(response.CodeInvalidParam, err.Error())
+	case errors.Is(err, ErrMandatoryTemplate):
+		return response.Err(response.CodeForbidden, err.Error())
+	case errors.Is(err, domain.ErrMessageNotFound):
+		return response.Err(response.CodeNotFound, "消息不存在")
+	default:
+		return response.Err(response.CodeDatabaseError, "通知服务异常")
+	}
+}
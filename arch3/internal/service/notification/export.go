@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"context"
+	"time"
+
+	"arch3/pkg/tracer"
+)
+
+// exportPageSize 导出时分页读取站内信的页大小
+const exportPageSize = 100
+
+// UserData 用户在通知模块中的数据（账号数据导出）
+type UserData struct {
+	Messages    []ExportedMessage    `json:"messages"`
+	Preferences []ExportedPreference `json:"preferences"`
+}
+
+// ExportedMessage 导出的站内信
+type ExportedMessage struct {
+	MessageID string     `json:"message_id"`
+	Template  string     `json:"template"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ExportedPreference 导出的通知偏好（仅用户显式设置的项）
+type ExportedPreference struct {
+	Template  string    `json:"template"`
+	Channel   string    `json:"channel"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ExportUserData 导出用户的全部站内信与偏好
+func (s *service) ExportUserData(ctx context.Context, userID string) (*UserData, error) {
+	ctx, span := tracer.Start(ctx, "service.notification.ExportUserData")
+	defer span.End()
+
+	data := &UserData{Messages: []ExportedMessage{}, Preferences: []ExportedPreference{}}
+	for offset := 0; ; offset += exportPageSize {
+		msgs, _, err := s.repo.ListMessages(ctx, userID, false, exportPageSize, offset)
+		if err != nil {
+			tracer.RecordError(span, err)
+			return nil, err
+		}
+		for _, m := range msgs {
+			data.Messages = append(data.Messages, ExportedMessage{
+				MessageID: m.MessageID,
+				Template:  m.Template,
+				Title:     m.Title,
+				Body:      m.Body,
+				ReadAt:    m.ReadAt,
+				CreatedAt: m.CreatedAt,
+			})
+		}
+		if len(msgs) < exportPageSize {
+			break
+		}
+	}
+
+	prefs, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	for _, p := range prefs {
+		data.Preferences = append(data.Preferences, ExportedPreference{
+			Template:  p.Template,
+			Channel:   string(p.Channel),
+			Enabled:   p.Enabled,
+			UpdatedAt: p.UpdatedAt,
+		})
+	}
+	return data, nil
+}
+
+// DeleteUserData 删除用户的全部站内信与偏好
+func (s *service) DeleteUserData(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "service.notification.DeleteUserData")
+	defer span.End()
+
+	if err := s.repo.DeleteUserData(ctx, userID); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
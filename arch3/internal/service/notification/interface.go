@@ -0,0 +1,53 @@
+package notification
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/notification"
+)
+
+// Service 通知服务接口
+type Service interface {
+	// Notify 按模板向用户发送通知
+	// 单个渠道失败不影响其他渠道，返回所有渠道错误的合并
+	Notify(ctx context.Context, req *Request) error
+
+	// ListMessages 分页查询站内信，按时间倒序
+	ListMessages(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Message, int64, error)
+	// MarkRead 标记站内信已读
+	MarkRead(ctx context.Context, userID, messageID string) error
+
+	// ListPreferences 查询用户对所有模板、渠道的接收偏好
+	ListPreferences(ctx context.Context, userID string) ([]*PreferenceItem, error)
+	// SetPreference 设置接收偏好，强制通知不允许关闭
+	SetPreference(ctx context.Context, userID, template string, channel domain.Channel, enabled bool) error
+
+	// ExportUserData 导出用户的全部站内信与偏好（账号数据导出）
+	ExportUserData(ctx context.Context, userID string) (*UserData, error)
+	// DeleteUserData 删除用户的全部站内信与偏好（账号注销）
+	DeleteUserData(ctx context.Context, userID string) error
+}
+
+// Request 通知请求
+type Request struct {
+	Template  string
+	Recipient Recipient
+	Vars      map[string]string // 模板变量
+	Channels  []domain.Channel  // 指定渠道，为空时使用模板默认渠道
+}
+
+// Recipient 通知接收方
+type Recipient struct {
+	UserID string
+	Phone  string // 短信渠道地址，为空时跳过短信
+	Email  string // 邮件渠道地址，为空时跳过邮件
+	Locale string // 语言，如 zh-CN、en-US，为空时使用 DefaultLocale
+}
+
+// PreferenceItem 偏好项（含默认值）
+type PreferenceItem struct {
+	Template  string
+	Channel   domain.Channel
+	Enabled   bool
+	Mandatory bool // 强制通知，不可关闭
+}
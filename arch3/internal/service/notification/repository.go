@@ -0,0 +1,25 @@
+package notification
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/notification"
+)
+
+// Repository 通知仓储接口（由使用方定义）
+type Repository interface {
+	// CreateMessage 创建站内信
+	CreateMessage(ctx context.Context, msg *domain.Message) error
+	// ListMessages 分页查询站内信
+	ListMessages(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Message, int64, error)
+	// MarkRead 标记已读，消息不存在或不属于该用户时返回 domain.ErrMessageNotFound
+	MarkRead(ctx context.Context, userID, messageID string) error
+
+	// ListPreferences 查询用户显式设置的偏好
+	ListPreferences(ctx context.Context, userID string) ([]*domain.Preference, error)
+	// UpsertPreference 保存偏好
+	UpsertPreference(ctx context.Context, pref *domain.Preference) error
+
+	// DeleteUserData 删除用户的全部站内信与偏好
+	DeleteUserData(ctx context.Context, userID string) error
+}
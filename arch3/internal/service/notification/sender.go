@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/notification"
+)
+
+// Sender 外部渠道发送器接口
+// 由 Service 层定义，Integration 层实现（短信、邮件）
+type Sender interface {
+	// Channel 渠道名称
+	Channel() domain.Channel
+	// Send 发送已渲染的通知
+	Send(ctx context.Context, d *Delivery) error
+}
+
+// Delivery 渲染后的单渠道投递内容
+type Delivery struct {
+	Template string            // 模板名称
+	To       string            // 渠道地址（手机号/邮箱）
+	Subject  string            // 标题
+	Body     string            // 正文
+	Vars     map[string]string // 原始变量，供需要服务商模板的渠道透传
+
+	// ProviderTemplate 短信服务商模板 ID
+	ProviderTemplate string
+}
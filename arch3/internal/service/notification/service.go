@@ -0,0 +1,246 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "arch3/internal/domain/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/tracer"
+	"arch3/pkg/ulid"
+
+	"go.uber.org/zap"
+)
+
+// allChannels 偏好列表展示的渠道顺序
+var allChannels = []domain.Channel{domain.ChannelInApp, domain.ChannelSMS, domain.ChannelEmail}
+
+// service 通知服务实现
+type service struct {
+	registry *Registry
+	repo     Repository
+	senders  map[domain.Channel]Sender
+}
+
+// NewService 创建通知服务
+// 站内信由服务自身通过 Repository 投递，短信、邮件通过 Sender 投递，未注册的渠道跳过
+func NewService(registry *Registry, repo Repository, senders ...Sender) Service {
+	s := &service{
+		registry: registry,
+		repo:     repo,
+		senders:  make(map[domain.Channel]Sender, len(senders)),
+	}
+	for _, sender := range senders {
+		s.senders[sender.Channel()] = sender
+	}
+	return s
+}
+
+// Notify 按模板向用户发送通知
+func (s *service) Notify(ctx context.Context, req *Request) error {
+	ctx, span := tracer.Start(ctx, "service.notification.Notify")
+	defer span.End()
+
+	span.SetAttributes(tracer.String("notification.template", req.Template))
+
+	tpl, ok := s.registry.get(req.Template)
+	if !ok {
+		tracer.RecordError(span, ErrTemplateNotFound)
+		return ErrTemplateNotFound
+	}
+
+	channels := req.Channels
+	if len(channels) == 0 {
+		channels = tpl.Channels
+	}
+
+	disabled, err := s.disabledChannels(ctx, tpl, req.Recipient.UserID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	var errs []error
+	for _, ch := range channels {
+		if disabled[ch] {
+			tracer.AddEvent(span, "notification.opted_out", tracer.String("channel", string(ch)))
+			continue
+		}
+		if err := s.deliver(ctx, tpl, req, ch); err != nil {
+			tracer.RecordError(span, err)
+			logger.Ctx(ctx).Error("notification delivery failed",
+				zap.String("template", req.Template),
+				zap.String("channel", string(ch)),
+				zap.String("user_id", req.Recipient.UserID),
+				zap.Error(err),
+			)
+			errs = append(errs, fmt.Errorf("%s: %w", ch, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver 渲染并投递单个渠道
+func (s *service) deliver(ctx context.Context, tpl *compiledTemplate, req *Request, ch domain.Channel) error {
+	rendered, ok, err := tpl.render(req.Recipient.Locale, ch, req.Vars)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		// 模板未提供该渠道文案
+		return nil
+	}
+
+	if ch == domain.ChannelInApp {
+		messageID, err := ulid.New()
+		if err != nil {
+			return err
+		}
+		return s.repo.CreateMessage(ctx, &domain.Message{
+			MessageID: messageID,
+			UserID:    req.Recipient.UserID,
+			Template:  req.Template,
+			Title:     rendered.Subject,
+			Body:      rendered.Body,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+
+	sender, ok := s.senders[ch]
+	if !ok {
+		return nil
+	}
+	to := req.Recipient.address(ch)
+	if to == "" {
+		return nil
+	}
+	return sender.Send(ctx, &Delivery{
+		Template:         req.Template,
+		To:               to,
+		Subject:          rendered.Subject,
+		Body:             rendered.Body,
+		Vars:             req.Vars,
+		ProviderTemplate: rendered.ProviderTemplate,
+	})
+}
+
+// disabledChannels 查询用户关闭的渠道，强制通知忽略偏好
+func (s *service) disabledChannels(ctx context.Context, tpl *compiledTemplate, userID string) (map[domain.Channel]bool, error) {
+	if tpl.Mandatory || userID == "" {
+		return nil, nil
+	}
+	prefs, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	disabled := make(map[domain.Channel]bool)
+	for _, p := range prefs {
+		if p.Template == tpl.Name && !p.Enabled {
+			disabled[p.Channel] = true
+		}
+	}
+	return disabled, nil
+}
+
+// ListMessages 分页查询站内信
+func (s *service) ListMessages(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*domain.Message, int64, error) {
+	ctx, span := tracer.Start(ctx, "service.notification.ListMessages")
+	defer span.End()
+
+	msgs, total, err := s.repo.ListMessages(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, 0, ToResponse(err)
+	}
+	return msgs, total, nil
+}
+
+// MarkRead 标记站内信已读
+func (s *service) MarkRead(ctx context.Context, userID, messageID string) error {
+	ctx, span := tracer.Start(ctx, "service.notification.MarkRead")
+	defer span.End()
+
+	if err := s.repo.MarkRead(ctx, userID, messageID); err != nil {
+		tracer.RecordError(span, err)
+		return ToResponse(err)
+	}
+	return nil
+}
+
+// ListPreferences 查询用户对所有模板、渠道的接收偏好
+func (s *service) ListPreferences(ctx context.Context, userID string) ([]*PreferenceItem, error) {
+	ctx, span := tracer.Start(ctx, "service.notification.ListPreferences")
+	defer span.End()
+
+	prefs, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, ToResponse(err)
+	}
+	explicit := make(map[string]bool, len(prefs))
+	for _, p := range prefs {
+		explicit[p.Template+"/"+string(p.Channel)] = p.Enabled
+	}
+
+	var items []*PreferenceItem
+	for _, name := range s.registry.order {
+		tpl := s.registry.templates[name]
+		for _, ch := range allChannels {
+			if !tpl.hasChannel(ch) {
+				continue
+			}
+			enabled, ok := explicit[name+"/"+string(ch)]
+			items = append(items, &PreferenceItem{
+				Template:  name,
+				Channel:   ch,
+				Enabled:   tpl.Mandatory || !ok || enabled,
+				Mandatory: tpl.Mandatory,
+			})
+		}
+	}
+	return items, nil
+}
+
+// SetPreference 设置接收偏好
+func (s *service) SetPreference(ctx context.Context, userID, template string, channel domain.Channel, enabled bool) error {
+	ctx, span := tracer.Start(ctx, "service.notification.SetPreference")
+	defer span.End()
+
+	tpl, ok := s.registry.get(template)
+	if !ok {
+		return ToResponse(ErrTemplateNotFound)
+	}
+	if !tpl.hasChannel(channel) {
+		return ToResponse(ErrChannelNotFound)
+	}
+	if tpl.Mandatory && !enabled {
+		return ToResponse(ErrMandatoryTemplate)
+	}
+
+	err := s.repo.UpsertPreference(ctx, &domain.Preference{
+		UserID:    userID,
+		Template:  template,
+		Channel:   channel,
+		Enabled:   enabled,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return ToResponse(err)
+	}
+	return nil
+}
+
+// address 获取渠道地址
+func (r *Recipient) address(ch domain.Channel) string {
+	switch ch {
+	case domain.ChannelSMS:
+		return r.Phone
+	case domain.ChannelEmail:
+		return r.Email
+	default:
+		return ""
+	}
+}
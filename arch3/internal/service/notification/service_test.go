@@ -0,0 +1,232 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	domain "arch3/internal/domain/notification"
+)
+
+// fakeRepository 测试用内存仓储
+type fakeRepository struct {
+	mu       sync.Mutex
+	messages []*domain.Message
+	prefs    []*domain.Preference
+}
+
+func (r *fakeRepository) CreateMessage(_ context.Context, msg *domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+func (r *fakeRepository) ListMessages(_ context.Context, userID string, _ bool, _, _ int) ([]*domain.Message, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var msgs []*domain.Message
+	for _, m := range r.messages {
+		if m.UserID == userID {
+			msgs = append(msgs, m)
+		}
+	}
+	return msgs, int64(len(msgs)), nil
+}
+
+func (r *fakeRepository) MarkRead(context.Context, string, string) error { return nil }
+
+func (r *fakeRepository) ListPreferences(_ context.Context, userID string) ([]*domain.Preference, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var prefs []*domain.Preference
+	for _, p := range r.prefs {
+		if p.UserID == userID {
+			prefs = append(prefs, p)
+		}
+	}
+	return prefs, nil
+}
+
+func (r *fakeRepository) UpsertPreference(_ context.Context, pref *domain.Preference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, p := range r.prefs {
+		if p.UserID == pref.UserID && p.Template == pref.Template && p.Channel == pref.Channel {
+			r.prefs[i] = pref
+			return nil
+		}
+	}
+	r.prefs = append(r.prefs, pref)
+	return nil
+}
+
+func (r *fakeRepository) DeleteUserData(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = slices.DeleteFunc(r.messages, func(m *domain.Message) bool { return m.UserID == userID })
+	r.prefs = slices.DeleteFunc(r.prefs, func(p *domain.Preference) bool { return p.UserID == userID })
+	return nil
+}
+
+// fakeSender 测试用发送器
+type fakeSender struct {
+	channel    domain.Channel
+	deliveries []*Delivery
+	err        error
+}
+
+func (s *fakeSender) Channel() domain.Channel { return s.channel }
+
+func (s *fakeSender) Send(_ context.Context, d *Delivery) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.deliveries = append(s.deliveries, d)
+	return nil
+}
+
+func newTestService(t *testing.T) (Service, *fakeRepository, *fakeSender, *fakeSender) {
+	t.Helper()
+	registry, err := NewRegistry(BuiltinTemplates()...)
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+	registry.SetProviderTemplate(TemplateAccountBanned, "ST_BANNED")
+
+	repo := &fakeRepository{}
+	sms := &fakeSender{channel: domain.ChannelSMS}
+	mail := &fakeSender{channel: domain.ChannelEmail}
+	return NewService(registry, repo, sms, mail), repo, sms, mail
+}
+
+var testRecipient = Recipient{UserID: "u1", Phone: "13800138000", Email: "u1@example.com"}
+
+func TestService_Notify_Locale(t *testing.T) {
+	tests := []struct {
+		name      string
+		locale    string
+		wantTitle string
+	}{
+		{name: "默认语言", locale: "", wantTitle: "新设备登录提醒"},
+		{name: "主语言回退", locale: "en-US", wantTitle: "New device sign-in"},
+		{name: "未知语言回退默认", locale: "fr-FR", wantTitle: "新设备登录提醒"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _, mail := newTestService(t)
+			recipient := testRecipient
+			recipient.Locale = tt.locale
+
+			err := svc.Notify(context.Background(), &Request{
+				Template:  TemplateLoginNewDevice,
+				Recipient: recipient,
+				Vars:      map[string]string{"device": "iPhone", "time": "2024-01-01 10:00", "user_name": "张三"},
+			})
+			if err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+
+			if len(repo.messages) != 1 || repo.messages[0].Title != tt.wantTitle {
+				t.Errorf("Expected in-app title %q, got %+v", tt.wantTitle, repo.messages)
+			}
+			if len(mail.deliveries) != 1 || mail.deliveries[0].To != "u1@example.com" {
+				t.Errorf("Expected 1 email to u1@example.com, got %+v", mail.deliveries)
+			}
+		})
+	}
+}
+
+func TestService_Notify_OptOut(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, _, mail := newTestService(t)
+
+	if err := svc.SetPreference(ctx, "u1", TemplateLoginNewDevice, domain.ChannelEmail, false); err != nil {
+		t.Fatalf("SetPreference() error = %v", err)
+	}
+
+	vars := map[string]string{"device": "iPhone", "time": "10:00", "user_name": "张三"}
+	if err := svc.Notify(ctx, &Request{Template: TemplateLoginNewDevice, Recipient: testRecipient, Vars: vars}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if len(mail.deliveries) != 0 {
+		t.Errorf("Expected email skipped after opt-out, got %d", len(mail.deliveries))
+	}
+	if len(repo.messages) != 1 {
+		t.Errorf("Expected in-app message still delivered, got %d", len(repo.messages))
+	}
+}
+
+func TestService_Notify_Mandatory(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, sms, mail := newTestService(t)
+
+	// 强制通知不允许关闭
+	err := svc.SetPreference(ctx, "u1", TemplateAccountBanned, domain.ChannelSMS, false)
+	if err == nil {
+		t.Fatal("Expected error disabling mandatory notification")
+	}
+
+	vars := map[string]string{"reason": "违规", "user_name": "张三"}
+	if err := svc.Notify(ctx, &Request{Template: TemplateAccountBanned, Recipient: testRecipient, Vars: vars}); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if len(sms.deliveries) != 1 || sms.deliveries[0].ProviderTemplate != "ST_BANNED" || sms.deliveries[0].Vars["reason"] != "违规" {
+		t.Errorf("Expected sms with provider template and vars, got %+v", sms.deliveries)
+	}
+	if len(mail.deliveries) != 1 || len(repo.messages) != 1 {
+		t.Errorf("Expected email and in-app delivered, got %d, %d", len(mail.deliveries), len(repo.messages))
+	}
+}
+
+func TestService_Notify_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		req     *Request
+		setup   func(sms, mail *fakeSender)
+		wantErr error
+	}{
+		{
+			name:    "模板不存在",
+			req:     &Request{Template: "unknown", Recipient: testRecipient},
+			wantErr: ErrTemplateNotFound,
+		},
+		{
+			name: "缺少模板变量",
+			req:  &Request{Template: TemplateLoginNewDevice, Recipient: testRecipient, Vars: map[string]string{}},
+		},
+		{
+			name: "渠道发送失败",
+			req: &Request{
+				Template:  TemplateLoginNewDevice,
+				Recipient: testRecipient,
+				Vars:      map[string]string{"device": "iPhone", "time": "10:00", "user_name": "张三"},
+				Channels:  []domain.Channel{domain.ChannelEmail},
+			},
+			setup: func(_, mail *fakeSender) { mail.err = errors.New("smtp down") },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, sms, mail := newTestService(t)
+			if tt.setup != nil {
+				tt.setup(sms, mail)
+			}
+			err := svc.Notify(ctx, tt.req)
+			if err == nil {
+				t.Fatal("Expected error but got nil")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Notify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
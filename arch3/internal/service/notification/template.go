@@ -0,0 +1,171 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	domain "arch3/internal/domain/notification"
+)
+
+// DefaultLocale 默认语言，找不到用户语言对应的文案时回退到该语言
+const DefaultLocale = "zh-CN"
+
+// Template 通知模板
+//
+// 同一模板按 语言 × 渠道 维护文案，Channels 为默认下发渠道。
+// Mandatory 的模板（如账号安全类通知）不受用户偏好影响。
+type Template struct {
+	Name      string
+	Channels  []domain.Channel
+	Mandatory bool
+	Variants  map[string]map[domain.Channel]Content // locale -> channel -> 文案
+}
+
+// Content 单个渠道的文案
+type Content struct {
+	Subject string // 标题（邮件主题/站内信标题），支持变量
+	Body    string // 正文，支持变量，如 {{.device}}
+
+	// ProviderTemplate 短信服务商模板 ID
+	// 国内短信须使用服务商审核过的模板，正文由服务商渲染，变量以参数形式透传
+	ProviderTemplate string
+}
+
+// Rendered 渲染后的文案
+type Rendered struct {
+	Subject          string
+	Body             string
+	ProviderTemplate string
+}
+
+// compiledContent 预编译的文案
+type compiledContent struct {
+	subject          *template.Template
+	body             *template.Template
+	providerTemplate string
+}
+
+// compiledTemplate 预编译的模板
+type compiledTemplate struct {
+	*Template
+	variants map[string]map[domain.Channel]*compiledContent
+}
+
+// Registry 模板注册表
+type Registry struct {
+	templates map[string]*compiledTemplate
+	order     []string // 注册顺序，用于偏好列表展示
+}
+
+// NewRegistry 创建模板注册表，文案语法错误时返回错误
+func NewRegistry(templates ...*Template) (*Registry, error) {
+	r := &Registry{templates: make(map[string]*compiledTemplate, len(templates))}
+	for _, t := range templates {
+		ct := &compiledTemplate{
+			Template: t,
+			variants: make(map[string]map[domain.Channel]*compiledContent, len(t.Variants)),
+		}
+		for locale, contents := range t.Variants {
+			ct.variants[locale] = make(map[domain.Channel]*compiledContent, len(contents))
+			for ch, c := range contents {
+				cc, err := compileContent(t.Name, locale, ch, c)
+				if err != nil {
+					return nil, err
+				}
+				ct.variants[locale][ch] = cc
+			}
+		}
+		r.templates[t.Name] = ct
+		r.order = append(r.order, t.Name)
+	}
+	return r, nil
+}
+
+// SetProviderTemplate 设置短信服务商模板 ID（来自配置）
+// 对模板的所有语言生效，模板不存在时忽略
+func (r *Registry) SetProviderTemplate(name, providerTemplate string) {
+	t, ok := r.templates[name]
+	if !ok {
+		return
+	}
+	for _, contents := range t.variants {
+		if c, ok := contents[domain.ChannelSMS]; ok {
+			c.providerTemplate = providerTemplate
+		}
+	}
+}
+
+// get 获取模板
+func (r *Registry) get(name string) (*compiledTemplate, bool) {
+	t, ok := r.templates[name]
+	return t, ok
+}
+
+// render 按语言与渠道渲染文案
+// 语言回退顺序: 完整语言标签 → 主语言 → DefaultLocale
+func (t *compiledTemplate) render(locale string, ch domain.Channel, vars map[string]string) (*Rendered, bool, error) {
+	c := t.lookup(locale, ch)
+	if c == nil {
+		return nil, false, nil
+	}
+
+	subject, err := execute(c.subject, vars)
+	if err != nil {
+		return nil, true, err
+	}
+	body, err := execute(c.body, vars)
+	if err != nil {
+		return nil, true, err
+	}
+	return &Rendered{Subject: subject, Body: body, ProviderTemplate: c.providerTemplate}, true, nil
+}
+
+func (t *compiledTemplate) lookup(locale string, ch domain.Channel) *compiledContent {
+	candidates := []string{locale}
+	if lang, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, lang)
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	for _, l := range candidates {
+		if l == "" {
+			continue
+		}
+		if c, ok := t.variants[l][ch]; ok {
+			return c
+		}
+	}
+	return nil
+}
+
+func compileContent(name, locale string, ch domain.Channel, c Content) (*compiledContent, error) {
+	prefix := fmt.Sprintf("%s/%s/%s", name, locale, ch)
+	subject, err := template.New(prefix + "/subject").Option("missingkey=error").Parse(c.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s subject: %w", prefix, err)
+	}
+	body, err := template.New(prefix + "/body").Option("missingkey=error").Parse(c.Body)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %s body: %w", prefix, err)
+	}
+	return &compiledContent{subject: subject, body: body, providerTemplate: c.ProviderTemplate}, nil
+}
+
+func execute(t *template.Template, vars map[string]string) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, vars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// hasChannel 模板是否可通过该渠道下发
+func (t *compiledTemplate) hasChannel(ch domain.Channel) bool {
+	for _, c := range t.Channels {
+		if c == ch {
+			return true
+		}
+	}
+	return false
+}
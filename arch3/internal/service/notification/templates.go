@@ -0,0 +1,124 @@
+package notification
+
+import domain "arch3/internal/domain/notification"
+
+// 内置模板名称
+const (
+	TemplateLoginNewDevice  = "login_new_device" // 新设备登录提醒
+	TemplateAccountBanned   = "account_banned"   // 账号封禁通知
+	TemplatePhoneChanged    = "phone_changed"    // 手机号变更通知
+	TemplateRealNameReview  = "real_name_review" // 实名认证审核结果
+	TemplateAccountDeletion = "account_deletion" // 账号注销申请确认
+	TemplateAccountInactive = "account_inactive" // 长期未使用账号转入注销
+	TemplateGroupInvitation = "group_invitation" // 组织邀请
+)
+
+// BuiltinTemplates 内置通知模板
+// 短信服务商模板 ID 通过配置 notification.sms_templates 设置
+func BuiltinTemplates() []*Template {
+	return []*Template{
+		{
+			Name:     TemplateLoginNewDevice,
+			Channels: []domain.Channel{domain.ChannelInApp, domain.ChannelEmail},
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "新设备登录提醒", Body: "您的账号于 {{.time}} 在新设备 {{.device}} 上登录，如非本人操作请及时修改密码。"},
+					domain.ChannelEmail: {Subject: "新设备登录提醒", Body: "{{.user_name}}，您好：\n\n您的账号于 {{.time}} 在新设备 {{.device}} 上登录。\n如非本人操作，请立即修改密码并联系客服。"},
+					domain.ChannelSMS:   {Body: "您的账号于{{.time}}在新设备登录，如非本人操作请及时修改密码。"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "New device sign-in", Body: "Your account signed in on a new device ({{.device}}) at {{.time}}. If this wasn't you, change your password now."},
+					domain.ChannelEmail: {Subject: "New device sign-in", Body: "Hi {{.user_name}},\n\nYour account signed in on a new device ({{.device}}) at {{.time}}.\nIf this wasn't you, change your password and contact support immediately."},
+				},
+			},
+		},
+		{
+			Name:      TemplateAccountBanned,
+			Channels:  []domain.Channel{domain.ChannelInApp, domain.ChannelSMS, domain.ChannelEmail},
+			Mandatory: true,
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "账号已被封禁", Body: "您的账号因 {{.reason}} 已被封禁，如有疑问请联系客服。"},
+					domain.ChannelEmail: {Subject: "账号封禁通知", Body: "{{.user_name}}，您好：\n\n您的账号因 {{.reason}} 已被封禁。\n如有疑问请联系客服。"},
+					domain.ChannelSMS:   {Body: "您的账号因{{.reason}}已被封禁，如有疑问请联系客服。"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "Account suspended", Body: "Your account has been suspended: {{.reason}}. Contact support if you have questions."},
+					domain.ChannelEmail: {Subject: "Account suspended", Body: "Hi {{.user_name}},\n\nYour account has been suspended: {{.reason}}.\nContact support if you have questions."},
+				},
+			},
+		},
+		{
+			Name:      TemplatePhoneChanged,
+			Channels:  []domain.Channel{domain.ChannelInApp, domain.ChannelSMS, domain.ChannelEmail},
+			Mandatory: true,
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "手机号已变更", Body: "您的账号绑定手机号已变更为 {{.phone}}，如非本人操作请立即联系客服。"},
+					domain.ChannelEmail: {Subject: "手机号变更通知", Body: "{{.user_name}}，您好：\n\n您的账号绑定手机号已于 {{.time}} 变更为 {{.phone}}。\n如非本人操作，请立即联系客服。"},
+					domain.ChannelSMS:   {Body: "您的账号绑定手机号已变更为{{.phone}}，如非本人操作请立即联系客服。"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "Phone number changed", Body: "Your account phone number was changed to {{.phone}}. If this wasn't you, contact support now."},
+					domain.ChannelEmail: {Subject: "Phone number changed", Body: "Hi {{.user_name}},\n\nYour account phone number was changed to {{.phone}} at {{.time}}.\nIf this wasn't you, contact support immediately."},
+				},
+			},
+		},
+		{
+			Name:     TemplateRealNameReview,
+			Channels: []domain.Channel{domain.ChannelInApp},
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "实名认证{{.result}}", Body: "您提交的实名认证{{.result}}。{{.reason}}"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "Identity verification {{.result_en}}", Body: "Your identity verification was {{.result_en}}. {{.reason}}"},
+				},
+			},
+		},
+		{
+			Name:      TemplateAccountDeletion,
+			Channels:  []domain.Channel{domain.ChannelInApp, domain.ChannelSMS, domain.ChannelEmail},
+			Mandatory: true,
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "账号注销申请已提交", Body: "您的账号将于 {{.date}} 注销，届时所有数据将被删除且无法恢复。在此之前登录并撤销即可保留账号。"},
+					domain.ChannelEmail: {Subject: "账号注销申请确认", Body: "{{.user_name}}，您好：\n\n您已申请注销账号，账号将于 {{.date}} 注销，届时所有数据将被删除且无法恢复。\n如需保留账号，请在此之前登录并撤销注销申请；如非本人操作，请立即联系客服。"},
+					domain.ChannelSMS:   {Body: "您的账号将于{{.date}}注销，如需保留请在此之前登录撤销，如非本人操作请立即联系客服。"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "Account deletion requested", Body: "Your account will be deleted on {{.date}} and all data will be permanently removed. Sign in and cancel before then to keep your account."},
+					domain.ChannelEmail: {Subject: "Account deletion requested", Body: "Hi {{.user_name}},\n\nYou requested to delete your account. It will be deleted on {{.date}} and all data will be permanently removed.\nTo keep your account, sign in and cancel before then. If this wasn't you, contact support immediately."},
+				},
+			},
+		},
+		{
+			Name:      TemplateAccountInactive,
+			Channels:  []domain.Channel{domain.ChannelInApp, domain.ChannelSMS, domain.ChannelEmail},
+			Mandatory: true,
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "账号即将注销", Body: "您的账号注册后长期未使用，将于 {{.date}} 注销，届时所有数据将被删除且无法恢复。在此之前登录并撤销即可保留账号。"},
+					domain.ChannelEmail: {Subject: "长期未使用账号注销提醒", Body: "{{.user_name}}，您好：\n\n您的账号注册后长期未使用，将于 {{.date}} 注销，届时所有数据将被删除且无法恢复。\n如需保留账号，请在此之前登录并撤销注销。"},
+					domain.ChannelSMS:   {Body: "您的账号长期未使用，将于{{.date}}注销，如需保留请在此之前登录撤销。"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "Account scheduled for deletion", Body: "Your account has not been used since sign-up and will be deleted on {{.date}}. All data will be permanently removed. Sign in and cancel before then to keep your account."},
+					domain.ChannelEmail: {Subject: "Inactive account scheduled for deletion", Body: "Hi {{.user_name}},\n\nYour account has not been used since sign-up and will be deleted on {{.date}}. All data will be permanently removed.\nTo keep your account, sign in and cancel before then."},
+				},
+			},
+		},
+		{
+			Name:     TemplateGroupInvitation,
+			Channels: []domain.Channel{domain.ChannelInApp},
+			Variants: map[string]map[domain.Channel]Content{
+				"zh-CN": {
+					domain.ChannelInApp: {Subject: "组织邀请", Body: "您收到加入组织「{{.group_name}}」的邀请，7 天内有效。"},
+				},
+				"en": {
+					domain.ChannelInApp: {Subject: "Group invitation", Body: "You have been invited to join {{.group_name}}. The invitation expires in 7 days."},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,249 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// deletionPurgeBatch 每轮匿名化的最大账号数
+	deletionPurgeBatch = 100
+	// loginHistoryExportLimit 导出的登录记录条数上限
+	loginHistoryExportLimit = 1000
+)
+
+// SendDeleteAccountCode 向绑定手机号发送注销验证码
+func (s *service) SendDeleteAccountCode(ctx context.Context, userID, channel string) (string, error) {
+	ctx, span := tracer.Start(ctx, "service.user.SendDeleteAccountCode")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	dispatchID, err := s.SendSMS(ctx, u.PhoneNumber, string(SMSTypeDeleteAccount), channel)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	return dispatchID, nil
+}
+
+// RequestAccountDeletion 校验验证码并申请注销
+//
+// 账号进入冷静期，期间仍可正常登录并撤销；冷静期结束后由 PurgeDeletedAccounts 匿名化。
+func (s *service) RequestAccountDeletion(ctx context.Context, userID, code string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.RequestAccountDeletion")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if u.DeletionScheduledAt != nil {
+		return nil, response.Err(response.CodeConflict, "已申请注销，请勿重复提交")
+	}
+
+	if err := s.otpClient.Verify(ctx, SMSTypeDeleteAccount, u.PhoneNumber, code); err != nil {
+		tracer.RecordError(span, err)
+		return nil, SMSToResponse(err)
+	}
+
+	scheduledAt := time.Now().Add(s.account.CoolingOff)
+	if err := s.userRepo.ScheduleDeletion(ctx, userID, scheduledAt); err != nil {
+		tracer.RecordError(span, err)
+		return nil, accountErrorToResponse(err, "已申请注销，请勿重复提交")
+	}
+
+	s.notify(ctx, u, notification.TemplateAccountDeletion, map[string]string{
+		"date": scheduledAt.Format("2006-01-02 15:04"),
+	})
+
+	logger.Ctx(ctx).Info("account deletion requested",
+		zap.String("user_id", userID),
+		zap.Time("scheduled_at", scheduledAt),
+	)
+	return s.GetUserByID(ctx, userID)
+}
+
+// CancelAccountDeletion 冷静期内撤销注销
+func (s *service) CancelAccountDeletion(ctx context.Context, userID string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.CancelAccountDeletion")
+	defer span.End()
+
+	if err := s.userRepo.CancelDeletion(ctx, userID); err != nil {
+		tracer.RecordError(span, err)
+		return nil, accountErrorToResponse(err, "未申请注销或冷静期已结束")
+	}
+
+	logger.Ctx(ctx).Info("account deletion cancelled", zap.String("user_id", userID))
+	return s.GetUserByID(ctx, userID)
+}
+
+// ExportAccountData 导出账号的全部数据: 用户记录与各模块通过导出钩子提供的数据
+func (s *service) ExportAccountData(ctx context.Context, userID string) (*domain.AccountExport, error) {
+	ctx, span := tracer.Start(ctx, "service.user.ExportAccountData")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	logins, err := s.logins.ListByUser(ctx, userID, loginHistoryExportLimit)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeDatabaseError, "导出数据失败，请稍后重试")
+	}
+
+	export := &domain.AccountExport{
+		ExportedAt:   time.Now().UTC(),
+		User:         u,
+		LoginHistory: logins,
+		Modules:      make(map[string]any),
+	}
+	for _, hook := range s.account.Hooks.export {
+		data, err := hook.fn(ctx, userID)
+		if err != nil {
+			tracer.RecordError(span, err)
+			logger.Ctx(ctx).Error("account export hook failed",
+				zap.String("user_id", userID),
+				zap.String("hook", hook.name),
+				zap.Error(err),
+			)
+			return nil, response.Err(response.CodeInternal, "导出数据失败，请稍后重试")
+		}
+		if data != nil {
+			export.Modules[hook.name] = data
+		}
+	}
+
+	logger.Ctx(ctx).Info("account data exported", zap.String("user_id", userID))
+	return export, nil
+}
+
+// ScheduleInactiveDeletions 未完成引导且长期不活跃的账号转入注销流程
+//
+// 与用户申请注销相同: 进入冷静期并通知用户，冷静期内登录并撤销即可保留账号，
+// 冷静期结束后由 PurgeDeletedAccounts 匿名化。列出后、写入前重新校验条件，期间活跃的账号不受影响。
+func (s *service) ScheduleInactiveDeletions(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "service.user.ScheduleInactiveDeletions")
+	defer span.End()
+
+	if s.account.InactiveAfter <= 0 {
+		return nil
+	}
+	now := time.Now()
+	before := now.Add(-s.account.InactiveAfter)
+	users, err := s.userRepo.ListInactive(ctx, before, deletionPurgeBatch)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	scheduledAt := now.Add(s.account.CoolingOff)
+	var errs []error
+	for _, u := range users {
+		err := s.userRepo.ScheduleInactiveDeletion(ctx, u.UserID, before, scheduledAt)
+		if errors.Is(err, domain.ErrUserConflict) || errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			tracer.RecordError(span, err)
+			errs = append(errs, fmt.Errorf("schedule %s: %w", u.UserID, err))
+			continue
+		}
+
+		s.notify(ctx, u, notification.TemplateAccountInactive, map[string]string{
+			"date": scheduledAt.Format("2006-01-02 15:04"),
+		})
+		logger.Ctx(ctx).Info("inactive account deletion scheduled",
+			zap.String("user_id", u.UserID),
+			zap.Time("scheduled_at", scheduledAt),
+		)
+	}
+	return errors.Join(errs...)
+}
+
+// PurgeDeletedAccounts 匿名化冷静期已结束的账号
+//
+// 单个账号失败不影响其他账号，失败的账号在下一轮重试。
+func (s *service) PurgeDeletedAccounts(ctx context.Context) error {
+	ctx, span := tracer.Start(ctx, "service.user.PurgeDeletedAccounts")
+	defer span.End()
+
+	users, err := s.userRepo.ListDueDeletions(ctx, time.Now(), deletionPurgeBatch)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+
+	var errs []error
+	for _, u := range users {
+		if err := s.purgeAccount(ctx, u); err != nil {
+			tracer.RecordError(span, err)
+			errs = append(errs, fmt.Errorf("purge %s: %w", u.UserID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// purgeAccount 注销单个账号
+//
+// 顺序: 各模块注销钩子（含撤销 token）→ 清除验证码缓存 → 删除头像与登录记录 → 匿名化用户记录。
+// 匿名化放在最后: 之前任一步失败时账号仍可在下一轮被查到并重试。
+func (s *service) purgeAccount(ctx context.Context, u *domain.User) error {
+	for _, hook := range s.account.Hooks.deletion {
+		if err := hook.fn(ctx, u.UserID); err != nil {
+			return fmt.Errorf("deletion hook %s: %w", hook.name, err)
+		}
+	}
+
+	if err := s.otpClient.Purge(ctx, u.PhoneNumber); err != nil {
+		return fmt.Errorf("purge otp: %w", err)
+	}
+
+	s.deleteObjects(ctx, s.ownedAvatarKeys(u.UserID, ptr.Value(u.AvatarURL)))
+
+	if err := s.logins.DeleteByUser(ctx, u.UserID); err != nil {
+		return fmt.Errorf("delete login history: %w", err)
+	}
+
+	if err := s.userRepo.Anonymize(ctx, u.UserID); err != nil {
+		if errors.Is(err, domain.ErrUserConflict) || errors.Is(err, domain.ErrUserNotFound) {
+			// 已被其他实例处理
+			return nil
+		}
+		return err
+	}
+
+	logger.Ctx(ctx).Info("account anonymized", zap.String("user_id", u.UserID))
+	return nil
+}
+
+// accountErrorToResponse 将注销相关的仓储错误转换为业务响应
+func accountErrorToResponse(err error, conflictMsg string) *response.Result {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return response.Err(response.CodeUserNotFound, "用户不存在")
+	case errors.Is(err, domain.ErrUserConflict):
+		return response.Err(response.CodeConflict, conflictMsg)
+	default:
+		return response.Err(response.CodeDatabaseError, "更新注销状态失败")
+	}
+}
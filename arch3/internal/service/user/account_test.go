@@ -0,0 +1,222 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+)
+
+// stubOTPClient 测试用验证码客户端，验证码固定为 code
+type stubOTPClient struct {
+	code   string
+	purged []string
+}
+
+func (c *stubOTPClient) Send(context.Context, *OTPSendRequest) (string, error) { return "d1", nil }
+
+func (c *stubOTPClient) DispatchStatus(context.Context, string) (OTPDispatchStatus, error) {
+	return OTPDispatchSent, nil
+}
+
+func (c *stubOTPClient) Verify(_ context.Context, _ SMSType, _, code string) error {
+	if code != c.code {
+		return ErrSMSCodeInvalid
+	}
+	return nil
+}
+
+func (c *stubOTPClient) Purge(_ context.Context, target string) error {
+	c.purged = append(c.purged, target)
+	return nil
+}
+
+func newAccountTestService(hooks *AccountHooks) (*service, *usertest.Repository, *stubOTPClient, *memStorage) {
+	past := time.Now().Add(-time.Minute)
+	repo := usertest.NewRepository(
+		&domain.User{UserID: "u1", PhoneNumber: "13800000001"},
+		&domain.User{UserID: "u2", PhoneNumber: "13800000002", DeletionScheduledAt: &past,
+			AvatarURL: ptr.Of("https://cdn.example.com/avatars/u2/a1/512.jpg")},
+	)
+	otp := &stubOTPClient{code: "123456"}
+	storage := newMemStorage()
+	_ = storage.Put(context.Background(), "avatars/u2/a1/512.jpg", []byte("x"), "image/jpeg")
+	s := &service{
+		tx:        directTx{},
+		otpClient: otp,
+		userRepo:  repo,
+		logins:    usertest.NewLoginHistory(),
+		notifier:  nopNotifier{},
+		storage:   storage,
+		account:   AccountDeletion{CoolingOff: 15 * 24 * time.Hour, Hooks: hooks},
+	}
+	return s, repo, otp, storage
+}
+
+func TestAccountDeletion_RequestAndCancel(t *testing.T) {
+	s, repo, _, _ := newAccountTestService(NewAccountHooks())
+	ctx := context.Background()
+
+	if _, err := s.RequestAccountDeletion(ctx, "u1", "000000"); response.CodeFromError(err) != response.CodeSMSCodeInvalid {
+		t.Errorf("Expected CodeSMSCodeInvalid for wrong code, got %v", err)
+	}
+
+	u, err := s.RequestAccountDeletion(ctx, "u1", "123456")
+	if err != nil {
+		t.Fatalf("RequestAccountDeletion() error = %v", err)
+	}
+	if u.DeletionScheduledAt == nil || time.Until(*u.DeletionScheduledAt) < 14*24*time.Hour {
+		t.Errorf("Expected deletion scheduled after cooling-off, got %v", u.DeletionScheduledAt)
+	}
+
+	if _, err := s.RequestAccountDeletion(ctx, "u1", "123456"); response.CodeFromError(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict for repeated request, got %v", err)
+	}
+
+	if _, err := s.CancelAccountDeletion(ctx, "u1"); err != nil {
+		t.Fatalf("CancelAccountDeletion() error = %v", err)
+	}
+	if findUser(repo, "u1").DeletionScheduledAt != nil {
+		t.Error("Expected deletion cancelled")
+	}
+
+	// 冷静期已结束不可撤销
+	if _, err := s.CancelAccountDeletion(ctx, "u2"); response.CodeFromError(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict after cooling-off, got %v", err)
+	}
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	hooks := NewAccountHooks()
+	var deleted []string
+	failing := true
+	hooks.OnDelete("orders", func(_ context.Context, userID string) error {
+		if failing {
+			return errors.New("orders unavailable")
+		}
+		deleted = append(deleted, userID)
+		return nil
+	})
+	s, repo, otp, storage := newAccountTestService(hooks)
+	ctx := context.Background()
+
+	// 钩子失败: 账号保留，下一轮重试
+	if err := s.PurgeDeletedAccounts(ctx); err == nil {
+		t.Error("Expected error when deletion hook fails")
+	}
+	if findUser(repo, "u2") == nil {
+		t.Fatal("Expected account kept when deletion hook fails")
+	}
+
+	failing = false
+	if err := s.PurgeDeletedAccounts(ctx); err != nil {
+		t.Fatalf("PurgeDeletedAccounts() error = %v", err)
+	}
+	if findUser(repo, "u2") != nil {
+		t.Error("Expected account anonymized")
+	}
+	if findUser(repo, "u1") == nil {
+		t.Error("Expected account without deletion request kept")
+	}
+	if len(deleted) != 1 || deleted[0] != "u2" {
+		t.Errorf("Expected deletion hook called for u2, got %v", deleted)
+	}
+	if len(otp.purged) != 1 || otp.purged[0] != "13800000002" {
+		t.Errorf("Expected otp purged for u2 phone, got %v", otp.purged)
+	}
+	if keys := storage.keys(); len(keys) != 0 {
+		t.Errorf("Expected avatar objects deleted, got %v", keys)
+	}
+}
+
+func TestScheduleInactiveDeletions(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-60 * 24 * time.Hour)
+	repo := usertest.NewRepository(
+		&domain.User{UserID: "abandoned", PhoneNumber: "13800000001", LastActiveAt: &stale},
+		&domain.User{UserID: "onboarded", PhoneNumber: "13800000002", LastActiveAt: &stale, OnboardedAt: &stale},
+		&domain.User{UserID: "refreshed", PhoneNumber: "13800000003", LastActiveAt: &stale},
+		&domain.User{UserID: "recent", PhoneNumber: "13800000004", LastActiveAt: &now},
+	)
+	notifier := &memNotifier{}
+	s := &service{
+		userRepo: repo,
+		notifier: notifier,
+		account:  AccountDeletion{CoolingOff: 15 * 24 * time.Hour, InactiveAfter: 30 * 24 * time.Hour},
+	}
+	ctx := context.Background()
+
+	// 刷新令牌即视为活跃
+	s.touchLastActive(ctx, "refreshed")
+
+	if err := s.ScheduleInactiveDeletions(ctx); err != nil {
+		t.Fatalf("ScheduleInactiveDeletions() error = %v", err)
+	}
+
+	tests := []struct {
+		userID        string
+		wantScheduled bool
+	}{
+		{userID: "abandoned", wantScheduled: true},
+		{userID: "onboarded", wantScheduled: false},
+		{userID: "refreshed", wantScheduled: false},
+		{userID: "recent", wantScheduled: false},
+	}
+	for _, tt := range tests {
+		got := findUser(repo, tt.userID).DeletionScheduledAt
+		if (got != nil) != tt.wantScheduled {
+			t.Errorf("Expected %s scheduled = %v, got %v", tt.userID, tt.wantScheduled, got)
+		}
+	}
+	// 与用户申请注销相同，经过冷静期后才匿名化
+	if got := findUser(repo, "abandoned").DeletionScheduledAt; got != nil && time.Until(*got) < 14*24*time.Hour {
+		t.Errorf("Expected deletion scheduled after cooling-off, got %v", got)
+	}
+	if len(notifier.requests) != 1 || notifier.requests[0].Template != notification.TemplateAccountInactive ||
+		notifier.requests[0].Recipient.UserID != "abandoned" || notifier.requests[0].Vars["date"] == "" {
+		t.Errorf("Expected inactive notice to abandoned, got %+v", notifier.requests)
+	}
+
+	// 已转入注销流程的账号不重复处理
+	notifier.requests = nil
+	if err := s.ScheduleInactiveDeletions(ctx); err != nil {
+		t.Fatalf("ScheduleInactiveDeletions() error = %v", err)
+	}
+	if len(notifier.requests) != 0 {
+		t.Errorf("Expected no repeated notice, got %d", len(notifier.requests))
+	}
+}
+
+func TestExportAccountData(t *testing.T) {
+	hooks := NewAccountHooks()
+	hooks.OnExport("orders", func(context.Context, string) (any, error) {
+		return []string{"o1"}, nil
+	})
+	hooks.OnExport("empty", func(context.Context, string) (any, error) { return nil, nil })
+	s, _, _, _ := newAccountTestService(hooks)
+
+	export, err := s.ExportAccountData(context.Background(), "u1")
+	if err != nil {
+		t.Fatalf("ExportAccountData() error = %v", err)
+	}
+	if export.User.UserID != "u1" {
+		t.Errorf("Expected user u1, got %s", export.User.UserID)
+	}
+	if _, ok := export.Modules["orders"]; !ok {
+		t.Error("Expected orders module exported")
+	}
+	if _, ok := export.Modules["empty"]; ok {
+		t.Error("Expected module without data omitted")
+	}
+
+	hooks.OnExport("broken", func(context.Context, string) (any, error) { return nil, errors.New("down") })
+	if _, err := s.ExportAccountData(context.Background(), "u1"); response.CodeFromError(err) != response.CodeInternal {
+		t.Errorf("Expected CodeInternal when export hook fails, got %v", err)
+	}
+}
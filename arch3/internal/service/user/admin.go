@@ -0,0 +1,208 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+
+	// statusHistoryLimit 用户详情中返回的状态变更记录条数
+	statusHistoryLimit = 20
+)
+
+// errAccountBanned 封禁用户登录或刷新令牌时返回
+var errAccountBanned = response.Err(response.CodeUserDisabled, "账号已被封禁")
+
+// SearchUsers 按条件分页查询用户
+// 多查询一条用于判断是否存在下一页
+func (s *service) SearchUsers(ctx context.Context, f *domain.UserFilter) ([]*domain.User, string, error) {
+	ctx, span := tracer.Start(ctx, "service.user.SearchUsers")
+	defer span.End()
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	limit = min(limit, maxUserPageSize)
+
+	query := *f
+	query.Limit = limit + 1
+	users, err := s.userRepo.Search(ctx, &query)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, "", response.Err(response.CodeDatabaseError, "查询用户列表失败")
+	}
+
+	var nextCursor string
+	if len(users) > limit {
+		users = users[:limit]
+		nextCursor = users[limit-1].UserID
+	}
+	return users, nextCursor, nil
+}
+
+// GetUserDetail 查询用户详情与最近的状态变更记录
+func (s *service) GetUserDetail(ctx context.Context, userID string) (*domain.UserDetail, error) {
+	ctx, span := tracer.Start(ctx, "service.user.GetUserDetail")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	changes, err := s.userRepo.ListStatusChanges(ctx, userID, statusHistoryLimit)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeDatabaseError, "查询状态变更记录失败")
+	}
+	return &domain.UserDetail{User: u, StatusChanges: changes}, nil
+}
+
+// BanUser 封禁用户
+//
+// 状态变更后撤销用户全部令牌；撤销失败仅记录日志，登录与刷新令牌时会再次校验状态，
+// 已签发的访问令牌在过期后失效。
+func (s *service) BanUser(ctx context.Context, operatorID, userID, reason string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.BanUser")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if u.Status == domain.StatusBanned {
+		return nil, response.Err(response.CodeConflict, "该用户已被封禁")
+	}
+
+	err = s.changeStatus(ctx, domain.EventUserBanned, &domain.StatusChange{
+		UserID:     userID,
+		FromStatus: u.Status,
+		ToStatus:   domain.StatusBanned,
+		Reason:     reason,
+		OperatorID: operatorID,
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, statusErrorToResponse(err)
+	}
+
+	if err := s.jwtManager.RevokeUserTokens(ctx, userID); err != nil {
+		tracer.RecordError(span, err)
+		logger.Ctx(ctx).Error("revoke banned user tokens failed",
+			zap.String("user_id", userID),
+			zap.Error(err),
+		)
+	}
+
+	logger.Ctx(ctx).Info("user banned",
+		zap.String("user_id", userID),
+		zap.String("operator_id", operatorID),
+		zap.String("reason", reason),
+	)
+
+	s.notify(ctx, u, notification.TemplateAccountBanned, map[string]string{"reason": reason})
+	return s.GetUserByID(ctx, userID)
+}
+
+// UnbanUser 解封用户
+// 恢复到最近一次封禁前的状态，没有封禁记录时（如直接修改数据库）按是否已提交实名信息推断
+func (s *service) UnbanUser(ctx context.Context, operatorID, userID, reason string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.UnbanUser")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if u.Status != domain.StatusBanned {
+		return nil, response.Err(response.CodeConflict, "该用户未被封禁")
+	}
+
+	restore, err := s.statusBeforeBan(ctx, u)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeDatabaseError, "查询状态变更记录失败")
+	}
+
+	err = s.changeStatus(ctx, domain.EventUserUnbanned, &domain.StatusChange{
+		UserID:     userID,
+		FromStatus: domain.StatusBanned,
+		ToStatus:   restore,
+		Reason:     reason,
+		OperatorID: operatorID,
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, statusErrorToResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("user unbanned",
+		zap.String("user_id", userID),
+		zap.String("operator_id", operatorID),
+		zap.String("status", restore),
+		zap.String("reason", reason),
+	)
+	return s.GetUserByID(ctx, userID)
+}
+
+// changeStatus 变更用户状态，并在同一事务中写入状态变更事件
+func (s *service) changeStatus(ctx context.Context, eventType string, change *domain.StatusChange) error {
+	return s.tx.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.ChangeStatus(ctx, change); err != nil {
+			return err
+		}
+		return s.publishEvent(ctx, eventType, change.UserID, &domain.StatusChangedEvent{
+			UserID:     change.UserID,
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			OperatorID: change.OperatorID,
+			ChangedAt:  time.Now().UTC(),
+		})
+	})
+}
+
+// statusBeforeBan 返回用户最近一次被封禁前的状态
+func (s *service) statusBeforeBan(ctx context.Context, u *domain.User) (string, error) {
+	changes, err := s.userRepo.ListStatusChanges(ctx, u.UserID, statusHistoryLimit)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range changes {
+		if c.ToStatus == domain.StatusBanned && c.FromStatus != domain.StatusBanned {
+			return c.FromStatus, nil
+		}
+	}
+	if u.IDNumber != nil {
+		return domain.StatusRealNameVerified, nil
+	}
+	return domain.StatusRealNameUnverified, nil
+}
+
+// statusErrorToResponse 将状态变更错误转换为响应错误
+func statusErrorToResponse(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return response.Err(response.CodeUserNotFound, "用户不存在")
+	case errors.Is(err, domain.ErrUserConflict):
+		return response.Err(response.CodeConflict, "用户状态已变化，请刷新后重试")
+	default:
+		return response.Err(response.CodeDatabaseError, "更新用户状态失败")
+	}
+}
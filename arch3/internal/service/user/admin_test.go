@@ -0,0 +1,132 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+)
+
+func newAdminTestService() (*service, *usertest.Repository, *memEvents) {
+	repo := usertest.NewRepository(
+		&domain.User{UserID: "u1", UserName: "alice", PhoneNumber: "13800001234", Status: domain.StatusRealNameUnverified},
+		&domain.User{UserID: "u2", UserName: "bob", PhoneNumber: "13800005678", Status: domain.StatusRealNameVerified,
+			IDNumber: ptr.Of(validTestIDNumber)},
+		&domain.User{UserID: "u3", UserName: "alan", PhoneNumber: "13900001234", Status: domain.StatusBanned},
+		&domain.User{UserID: "u4", UserName: "amy", PhoneNumber: "13900004321", Status: domain.StatusBanned},
+	)
+	events := &memEvents{}
+	s := &service{
+		tx:        directTx{},
+		events:    events,
+		otpClient: &stubOTPClient{code: "123456"},
+		userRepo:  repo,
+		notifier:  nopNotifier{},
+	}
+	return s, repo, events
+}
+
+func TestSearchUsers_Pagination(t *testing.T) {
+	s, _, _ := newAdminTestService()
+	ctx := context.Background()
+
+	var got []string
+	cursor := ""
+	for range 3 {
+		users, next, err := s.SearchUsers(ctx, &domain.UserFilter{UserName: "a", Cursor: cursor, Limit: 2})
+		if err != nil {
+			t.Fatalf("SearchUsers() error = %v", err)
+		}
+		for _, u := range users {
+			got = append(got, u.UserID)
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	want := []string{"u4", "u3", "u1"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestUnbanUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		banFrom    string // 非空时预置一条从该状态封禁的记录
+		wantCode   int
+		wantStatus string
+	}{
+		{"恢复封禁前状态", "u3", domain.StatusUnderReview, response.CodeSuccess, domain.StatusUnderReview},
+		{"无封禁记录时按实名信息推断", "u4", "", response.CodeSuccess, domain.StatusRealNameUnverified},
+		{"未被封禁", "u1", "", response.CodeConflict, domain.StatusRealNameUnverified},
+		{"用户不存在", "u9", "", response.CodeUserNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo, events := newAdminTestService()
+			ctx := context.Background()
+			if tt.banFrom != "" {
+				// 构造封禁记录: 先切到封禁前状态，再从该状态封禁
+				for _, c := range []*domain.StatusChange{
+					{UserID: tt.userID, FromStatus: domain.StatusBanned, ToStatus: tt.banFrom},
+					{UserID: tt.userID, FromStatus: tt.banFrom, ToStatus: domain.StatusBanned},
+				} {
+					if err := repo.ChangeStatus(ctx, c); err != nil {
+						t.Fatalf("ChangeStatus() error = %v", err)
+					}
+				}
+			}
+
+			_, err := s.UnbanUser(ctx, "admin", tt.userID, "申诉通过")
+			code := response.CodeSuccess
+			if err != nil {
+				code = response.CodeFromError(err)
+			}
+			if code != tt.wantCode {
+				t.Fatalf("Expected code %d, got %d (%v)", tt.wantCode, code, err)
+			}
+			wantEvents := "[]"
+			if tt.wantCode == response.CodeSuccess {
+				wantEvents = "[user.unbanned]"
+			}
+			if got := events.types(); got != wantEvents {
+				t.Errorf("Expected events %s, got %s", wantEvents, got)
+			}
+			if tt.wantStatus == "" {
+				return
+			}
+			if got := findUser(repo, tt.userID).Status; got != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, got)
+			}
+		})
+	}
+}
+
+func TestBanUser_AlreadyBanned(t *testing.T) {
+	s, _, _ := newAdminTestService()
+	_, err := s.BanUser(context.Background(), "admin", "u3", "spam")
+	if response.CodeFromError(err) != response.CodeConflict {
+		t.Errorf("Expected CodeConflict, got %v", err)
+	}
+}
+
+func TestSMSLogin_Banned(t *testing.T) {
+	s, _, _ := newAdminTestService()
+	_, err := s.SMSLogin(context.Background(), "13900001234", "123456", "")
+	if response.CodeFromError(err) != response.CodeUserDisabled {
+		t.Errorf("Expected CodeUserDisabled, got %v", err)
+	}
+}
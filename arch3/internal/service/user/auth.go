@@ -2,43 +2,87 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
 	domain "arch3/internal/domain/user"
+	"arch3/internal/service/common"
+	"arch3/internal/service/notification"
 	"arch3/pkg/jwt"
+	"arch3/pkg/logger"
+	"arch3/pkg/ptr"
 	"arch3/pkg/response"
 	"arch3/pkg/tracer"
 	"arch3/pkg/ulid"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultLoginHistorySize = 20
+	maxLoginHistorySize     = 100
 )
 
 // SMSLogin 短信验证码登录（用户不存在则自动注册）
-func (s *service) SMSLogin(ctx context.Context, phoneNumber, smsCode string) (*domain.LoginResult, error) {
+func (s *service) SMSLogin(ctx context.Context, phoneNumber, smsCode, deviceID string) (*domain.LoginResult, error) {
 	ctx, span := tracer.Start(ctx, "service.user.SMSLogin")
 	defer span.End()
 
 	// 验证短信验证码
-	if err := s.smsClient.Verify(ctx, SMSTypeLogin, phoneNumber, smsCode); err != nil {
+	if err := s.otpClient.Verify(ctx, SMSTypeLogin, phoneNumber, smsCode); err != nil {
 		tracer.RecordError(span, err)
 		return nil, SMSToResponse(err)
 	}
 
-	// 查询或创建用户
-	isNew := false
-	u, err := s.userRepo.FindByPhoneNumber(ctx, phoneNumber)
-	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
+	client := common.ClientInfoFrom(ctx)
+	if deviceID == "" {
+		deviceID = client.DeviceID
+	}
+
+	// 查询或注册用户、记录登录设备与登录记录在同一事务中完成，
+	// 注册失败时不会留下登录记录，记录写入失败也不会回滚注册
+	var (
+		u     *domain.User
+		isNew bool
+	)
+	var failMsg string
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		var err error
+		isNew, failMsg = false, "查询用户失败"
+		u, err = s.userRepo.FindByPhoneNumber(ctx, phoneNumber)
+		switch {
+		case errors.Is(err, domain.ErrUserNotFound):
 			// 用户不存在，自动注册
-			u, err = s.registerUserByPhone(ctx, phoneNumber)
-			if err != nil {
-				tracer.RecordError(span, err)
-				return nil, response.Err(response.CodeDatabaseError, "注册失败")
+			failMsg = "注册失败"
+			if u, err = s.registerUserByPhone(ctx, phoneNumber, deviceID, client.Source()); err != nil {
+				return err
 			}
 			isNew = true
-		} else {
-			tracer.RecordError(span, err)
-			return nil, response.Err(response.CodeDatabaseError, "查询用户失败")
+		case err != nil:
+			return err
+		case u.Status == domain.StatusBanned:
+			return errAccountBanned
+		default:
+			s.checkLoginDevice(ctx, u, deviceID)
 		}
+
+		event := domain.LoginEventLogin
+		if isNew {
+			event = domain.LoginEventRegister
+		}
+		s.recordLogin(ctx, u.UserID, event, deviceID, client)
+		return nil
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		if errors.Is(err, errAccountBanned) {
+			return nil, errAccountBanned
+		}
+		return nil, response.Err(response.CodeDatabaseError, failMsg)
+	}
+	if !isNew {
+		s.touchLastActive(ctx, u.UserID)
 	}
 
 	// 生成 token 对
@@ -55,8 +99,113 @@ func (s *service) SMSLogin(ctx context.Context, phoneNumber, smsCode string) (*d
 	}, nil
 }
 
-// registerUserByPhone 通过手机号注册新用户
-func (s *service) registerUserByPhone(ctx context.Context, phoneNumber string) (*domain.User, error) {
+// recordLogin 写入登录记录，失败不影响登录
+// 在调用方事务中以保存点执行，写入失败只回滚记录本身
+func (s *service) recordLogin(ctx context.Context, userID, event, deviceID string, client common.ClientInfo) {
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		return s.logins.Create(ctx, &domain.LoginRecord{
+			UserID:      userID,
+			Event:       event,
+			DeviceID:    deviceID,
+			Platform:    client.Platform,
+			AppVersion:  client.AppVersion,
+			Channel:     client.Channel,
+			UTMSource:   client.UTMSource,
+			UTMMedium:   client.UTMMedium,
+			UTMCampaign: client.UTMCampaign,
+			IP:          common.ClientIP(ctx),
+			UserAgent:   client.UserAgent,
+			CreatedAt:   time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		logger.Ctx(ctx).Warn("record login history failed",
+			zap.String("user_id", userID),
+			zap.String("event", event),
+			zap.Error(err),
+		)
+	}
+}
+
+// ListLoginHistory 查询最近的登录记录
+func (s *service) ListLoginHistory(ctx context.Context, userID string, limit int) ([]*domain.LoginRecord, error) {
+	ctx, span := tracer.Start(ctx, "service.user.ListLoginHistory")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultLoginHistorySize
+	}
+	limit = min(limit, maxLoginHistorySize)
+
+	records, err := s.logins.ListByUser(ctx, userID, limit)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeDatabaseError, "查询登录记录失败")
+	}
+	return records, nil
+}
+
+// checkLoginDevice 记录登录设备，已有设备记录且本次设备不同时写入新设备登录事件
+// 失败不影响登录；提醒由事件消费者异步发送（见 NotifyLoginNewDevice），不阻塞登录请求
+func (s *service) checkLoginDevice(ctx context.Context, u *domain.User, deviceID string) {
+	if deviceID == "" || ptr.Value(u.DeviceID) == deviceID {
+		return
+	}
+
+	newDevice := u.DeviceID != nil
+	u.DeviceID = &deviceID
+	err := s.tx.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdateFields(ctx, u, domain.FieldDeviceID); err != nil {
+			return err
+		}
+		if !newDevice {
+			return nil
+		}
+		return s.publishEvent(ctx, domain.EventUserLoginNewDevice, u.UserID, &domain.LoginNewDeviceEvent{
+			UserID:     u.UserID,
+			DeviceID:   deviceID,
+			LoggedInAt: time.Now().UTC(),
+		})
+	})
+	if err != nil {
+		logger.Ctx(ctx).Warn("update login device failed", zap.String("user_id", u.UserID), zap.Error(err))
+	}
+}
+
+// NotifyLoginNewDevice 处理新设备登录事件，发送新设备登录提醒
+// 用户已注销或不存在时跳过；查询失败返回错误，由消费者重新投递
+func (s *service) NotifyLoginNewDevice(ctx context.Context, e *common.Event) error {
+	ctx, span := tracer.Start(ctx, "service.user.NotifyLoginNewDevice")
+	defer span.End()
+
+	var payload domain.LoginNewDeviceEvent
+	if err := json.Unmarshal(e.Payload, &payload); err != nil {
+		// 内容无法解析时重试也无法处理
+		logger.Ctx(ctx).Error("decode login new device event failed", zap.String("event_id", e.ID), zap.Error(err))
+		return nil
+	}
+
+	u, err := s.userRepo.FindByUserID(ctx, payload.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	}
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if u.DeletionScheduledAt != nil {
+		return nil
+	}
+
+	s.notify(ctx, u, notification.TemplateLoginNewDevice, map[string]string{
+		"device": payload.DeviceID,
+		"time":   payload.LoggedInAt.Local().Format("2006-01-02 15:04"),
+	})
+	return nil
+}
+
+// registerUserByPhone 通过手机号注册新用户并写入注册事件，source 为空时不记录注册来源
+func (s *service) registerUserByPhone(ctx context.Context, phoneNumber, deviceID, source string) (*domain.User, error) {
 	now := time.Now().UTC()
 
 	// 生成用户 ID
@@ -73,18 +222,56 @@ func (s *service) registerUserByPhone(ctx context.Context, phoneNumber string) (
 		UserName:    defaultUserName,
 		PhoneNumber: phoneNumber,
 		Gender:      "other",
-		Status:      "real_name_unverified",
+		Status:      domain.StatusRealNameUnverified,
 		CreatedAt:   now,
 		UpdatedAt:   now,
+
+		LastActiveAt: &now,
+	}
+	if deviceID != "" {
+		u.DeviceID = &deviceID
+	}
+	if source != "" {
+		u.Source = &source
 	}
 
 	if err := s.userRepo.Create(ctx, u); err != nil {
 		return nil, err
 	}
 
+	// 注册事件与用户在同一事务中写入
+	err = s.publishEvent(ctx, domain.EventUserRegistered, userID, &domain.RegisteredEvent{
+		UserID:       userID,
+		Source:       source,
+		RegisteredAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.runRegistrationHooks(ctx, u)
 	return u, nil
 }
 
+// runRegistrationHooks 执行各模块的注册钩子，每个钩子以保存点执行，失败只回滚钩子本身
+func (s *service) runRegistrationHooks(ctx context.Context, u *domain.User) {
+	if s.account.Hooks == nil {
+		return
+	}
+	for _, hook := range s.account.Hooks.register {
+		err := s.tx.Do(ctx, func(ctx context.Context) error {
+			return hook.fn(ctx, u.UserID, u.PhoneNumber)
+		})
+		if err != nil {
+			logger.Ctx(ctx).Warn("registration hook failed",
+				zap.String("hook", hook.name),
+				zap.String("user_id", u.UserID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
 // RefreshToken 刷新 token
 func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*jwt.TokenPair, error) {
 	ctx, span := tracer.Start(ctx, "service.user.RefreshToken")
@@ -97,8 +284,8 @@ func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*jwt.T
 		return nil, response.Err(response.CodeTokenInvalid, "刷新令牌无效")
 	}
 
-	// 检查 token 是否在黑名单中
-	blacklisted, err := s.jwtManager.IsTokenBlacklisted(ctx, claims.ID)
+	// 检查 token 是否已撤销（单个 token 黑名单或用户级撤销）
+	blacklisted, err := s.jwtManager.IsTokenRevoked(ctx, claims)
 	if err != nil {
 		tracer.RecordError(span, err)
 		return nil, response.Err(response.CodeCacheError, "检查令牌状态失败")
@@ -107,12 +294,15 @@ func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*jwt.T
 		return nil, response.Err(response.CodeTokenInvalid, "刷新令牌已失效")
 	}
 
-	// 验证用户是否存在
-	_, err = s.userRepo.FindByUserID(ctx, claims.UserID)
+	// 验证用户是否存在且未被封禁
+	u, err := s.userRepo.FindByUserID(ctx, claims.UserID)
 	if err != nil {
 		tracer.RecordError(span, err)
 		return nil, response.Err(response.CodeUserNotFound, "用户不存在")
 	}
+	if u.Status == domain.StatusBanned {
+		return nil, errAccountBanned
+	}
 
 	// 先将旧的 refresh token 加入黑名单（token 轮转）
 	// 必须在生成新 token 之前完成，防止旧 token 继续使用
@@ -128,9 +318,17 @@ func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*jwt.T
 		return nil, response.Err(response.CodeInternal, "生成令牌失败")
 	}
 
+	s.touchLastActive(ctx, claims.UserID)
 	return newTokenPair, nil
 }
 
+// touchLastActive 记录用户最近活跃时间，失败不影响主流程
+func (s *service) touchLastActive(ctx context.Context, userID string) {
+	if err := s.userRepo.TouchLastActive(ctx, userID, time.Now()); err != nil {
+		logger.Ctx(ctx).Warn("update last active failed", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
 // Logout 登出
 func (s *service) Logout(ctx context.Context, accessJTI, refreshJTI string) error {
 	ctx, span := tracer.Start(ctx, "service.user.Logout")
@@ -0,0 +1,227 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/common"
+	"arch3/internal/service/notification"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/jwt"
+	"arch3/pkg/ptr"
+)
+
+func TestSMSLogin_ClientAttribution(t *testing.T) {
+	repo := usertest.NewRepository()
+	logins := usertest.NewLoginHistory()
+	events := &memEvents{}
+	s := &service{
+		tx:         directTx{},
+		events:     events,
+		otpClient:  &stubOTPClient{code: "123456"},
+		userRepo:   repo,
+		logins:     logins,
+		jwtManager: jwt.NewManager(&jwt.Config{Secret: "test-secret"}, nil),
+		notifier:   nopNotifier{},
+	}
+
+	ctx := common.WithClientInfo(context.Background(), common.ClientInfo{
+		AppVersion: "3.2.1",
+		Platform:   "ios",
+		UTMSource:  "wechat",
+		DeviceID:   "dev-1",
+		IP:         "203.0.113.7",
+	})
+
+	// 首次登录即注册: 记录注册来源与设备
+	result, err := s.SMSLogin(ctx, "13800000001", "123456", "")
+	if err != nil {
+		t.Fatalf("SMSLogin() error = %v", err)
+	}
+	if !result.IsNew {
+		t.Fatal("Expected new user")
+	}
+	stored := findUser(repo, result.User.UserID)
+	if got := ptr.Value(stored.Source); got != "wechat" {
+		t.Errorf("Expected source wechat, got %q", got)
+	}
+	if got := ptr.Value(stored.DeviceID); got != "dev-1" {
+		t.Errorf("Expected device dev-1, got %q", got)
+	}
+	if got := events.types(); got != "[user.registered]" {
+		t.Errorf("Expected registered event, got %s", got)
+	}
+
+	// 再次登录: 请求体中的设备标识优先
+	if _, err := s.SMSLogin(ctx, "13800000001", "123456", "dev-2"); err != nil {
+		t.Fatalf("SMSLogin() error = %v", err)
+	}
+
+	if got := events.types(); got != "[user.registered user.login_new_device]" {
+		t.Errorf("Expected login new device event, got %s", got)
+	}
+
+	records, _ := logins.ListByUser(ctx, result.User.UserID, 10)
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 login records, got %d", len(records))
+	}
+	tests := []struct {
+		name     string
+		record   *domain.LoginRecord
+		event    string
+		deviceID string
+	}{
+		{"登录", records[0], domain.LoginEventLogin, "dev-2"},
+		{"注册", records[1], domain.LoginEventRegister, "dev-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.record.Event != tt.event || tt.record.DeviceID != tt.deviceID {
+				t.Errorf("Expected %s on %s, got %s on %s", tt.event, tt.deviceID, tt.record.Event, tt.record.DeviceID)
+			}
+			if tt.record.Platform != "ios" || tt.record.AppVersion != "3.2.1" || tt.record.IP != "203.0.113.7" {
+				t.Errorf("Expected client info recorded, got %+v", tt.record)
+			}
+		})
+	}
+}
+
+func TestSMSLogin_RegistrationHooks(t *testing.T) {
+	var calls []string
+	hooks := NewAccountHooks()
+	hooks.OnRegister("failing", func(context.Context, string, string) error {
+		calls = append(calls, "failing")
+		return errors.New("hook failed")
+	})
+	hooks.OnRegister("group", func(_ context.Context, userID, phoneNumber string) error {
+		calls = append(calls, "group:"+phoneNumber)
+		return nil
+	})
+	s := &service{
+		tx:         directTx{},
+		events:     &memEvents{},
+		otpClient:  &stubOTPClient{code: "123456"},
+		userRepo:   usertest.NewRepository(),
+		logins:     usertest.NewLoginHistory(),
+		jwtManager: jwt.NewManager(&jwt.Config{Secret: "test-secret"}, nil),
+		notifier:   nopNotifier{},
+		account:    AccountDeletion{Hooks: hooks},
+	}
+	ctx := context.Background()
+
+	// 钩子失败不影响注册，只在注册时调用
+	for range 2 {
+		if _, err := s.SMSLogin(ctx, "13800000001", "123456", ""); err != nil {
+			t.Fatalf("SMSLogin() error = %v", err)
+		}
+	}
+	if got := fmt.Sprint(calls); got != "[failing group:13800000001]" {
+		t.Errorf("Expected hooks called once in order, got %s", got)
+	}
+}
+
+func TestNotifyLoginNewDevice(t *testing.T) {
+	deleting := time.Now().Add(time.Hour)
+	repo := usertest.NewRepository(
+		&domain.User{ID: 1, UserID: "u1", UserName: "张三", PhoneNumber: "13800000001", DeviceID: ptr.Of("dev-1")},
+		&domain.User{ID: 2, UserID: "u2", PhoneNumber: "13800000002", DeviceID: ptr.Of("dev-1"), DeletionScheduledAt: &deleting},
+	)
+	events := &memEvents{}
+	notifier := &memNotifier{}
+	s := &service{
+		tx:         directTx{},
+		events:     events,
+		otpClient:  &stubOTPClient{code: "123456"},
+		userRepo:   repo,
+		logins:     usertest.NewLoginHistory(),
+		jwtManager: jwt.NewManager(&jwt.Config{Secret: "test-secret"}, nil),
+		notifier:   notifier,
+	}
+	ctx := context.Background()
+
+	// 登录只写入事件，不同步发送通知
+	if _, err := s.SMSLogin(ctx, "13800000001", "123456", "dev-2"); err != nil {
+		t.Fatalf("SMSLogin() error = %v", err)
+	}
+	if _, err := s.SMSLogin(ctx, "13800000001", "123456", "dev-2"); err != nil {
+		t.Fatalf("SMSLogin() error = %v", err)
+	}
+	if got := events.types(); got != "[user.login_new_device]" {
+		t.Fatalf("Expected one login new device event, got %s", got)
+	}
+	if len(notifier.requests) != 0 {
+		t.Fatalf("Expected no notification during login, got %d", len(notifier.requests))
+	}
+
+	unknown, _ := common.NewEvent(domain.EventUserLoginNewDevice, "u404", &domain.LoginNewDeviceEvent{UserID: "u404", DeviceID: "dev-2"})
+	deletingEvent, _ := common.NewEvent(domain.EventUserLoginNewDevice, "u2", &domain.LoginNewDeviceEvent{UserID: "u2", DeviceID: "dev-2"})
+
+	tests := []struct {
+		name      string
+		event     *common.Event
+		wantNotes int
+	}{
+		{"发送提醒", events.events[0], 1},
+		{"用户不存在时跳过", unknown, 0},
+		{"注销冷静期内跳过", deletingEvent, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier.requests = nil
+			if err := s.NotifyLoginNewDevice(ctx, tt.event); err != nil {
+				t.Fatalf("NotifyLoginNewDevice() error = %v", err)
+			}
+			if len(notifier.requests) != tt.wantNotes {
+				t.Fatalf("Expected %d notifications, got %d", tt.wantNotes, len(notifier.requests))
+			}
+		})
+	}
+
+	notifier.requests = nil
+	if err := s.NotifyLoginNewDevice(ctx, events.events[0]); err != nil {
+		t.Fatalf("NotifyLoginNewDevice() error = %v", err)
+	}
+	req := notifier.requests
+	if req[0].Template != notification.TemplateLoginNewDevice || req[0].Recipient.Phone != "13800000001" || req[0].Vars["device"] != "dev-2" {
+		t.Errorf("Expected new device notice to 13800000001 for dev-2, got %+v", req[0])
+	}
+}
+
+// directTx 直接执行 fn 的事务替身，内存仓储不需要真正的事务
+type directTx struct{}
+
+func (directTx) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memEvents 记录已发布事件的事件发布替身
+type memEvents struct {
+	events []*common.Event
+}
+
+func (m *memEvents) Publish(_ context.Context, events ...*common.Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *memEvents) types() string {
+	types := make([]string, 0, len(m.events))
+	for _, e := range m.events {
+		types = append(types, e.Type)
+	}
+	return fmt.Sprint(types)
+}
+
+// memNotifier 记录通知请求的通知替身
+type memNotifier struct {
+	requests []*notification.Request
+}
+
+func (m *memNotifier) Notify(_ context.Context, req *notification.Request) error {
+	m.requests = append(m.requests, req)
+	return nil
+}
@@ -0,0 +1,153 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	domain "arch3/internal/domain/user"
+	"arch3/pkg/imaging"
+	"arch3/pkg/logger"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+	"arch3/pkg/ulid"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxAvatarBytes 头像文件大小上限
+	MaxAvatarBytes = 5 << 20
+	// maxAvatarPixels 头像像素数上限，超过的图片不解码（防止解压炸弹）
+	maxAvatarPixels = 4096 * 4096
+)
+
+// avatarSizes 头像尺寸（边长，像素），第一个为写入 AvatarURL 的主尺寸
+var avatarSizes = []int{512, 128, 48}
+
+// UploadAvatar 上传头像
+//
+// 流程: 校验格式与尺寸 → 解码（应用 EXIF 方向）→ 生成各尺寸缩略图并重新编码（去除 EXIF）
+// → 写入对象存储 → 条件更新 AvatarURL → 删除旧头像。
+func (s *service) UploadAvatar(ctx context.Context, userID string, data []byte) (*domain.Avatar, error) {
+	ctx, span := tracer.Start(ctx, "service.user.UploadAvatar")
+	defer span.End()
+
+	span.SetAttributes(tracer.Int("avatar.size", len(data)))
+
+	if len(data) > MaxAvatarBytes {
+		return nil, response.Err(response.CodeFileTooLarge, "头像文件不能超过 5MB")
+	}
+
+	img, format, err := imaging.Decode(data, maxAvatarPixels)
+	if err != nil {
+		tracer.RecordError(span, err)
+		switch {
+		case errors.Is(err, imaging.ErrTooManyPixels):
+			return nil, response.Err(response.CodeFileTooLarge, "图片尺寸过大")
+		case errors.Is(err, imaging.ErrUnsupportedFormat):
+			return nil, response.Err(response.CodeFileTypeError, "仅支持 JPEG、PNG 格式的图片")
+		default:
+			return nil, response.Err(response.CodeFileTypeError, "图片文件已损坏")
+		}
+	}
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	id, err := ulid.New()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeInternal, "生成文件名失败")
+	}
+
+	// 生成并上传各尺寸
+	avatar := &domain.Avatar{Thumbnails: make(map[int]string, len(avatarSizes))}
+	keys := make([]string, 0, len(avatarSizes))
+	for _, size := range avatarSizes {
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, imaging.Thumbnail(img, size), format); err != nil {
+			tracer.RecordError(span, err)
+			s.deleteObjects(ctx, keys)
+			return nil, response.Err(response.CodeUploadFailed, "头像处理失败")
+		}
+
+		key := avatarKey(userID, id, size, format)
+		if err := s.storage.Put(ctx, key, buf.Bytes(), imaging.ContentType(format)); err != nil {
+			tracer.RecordError(span, err)
+			s.deleteObjects(ctx, keys)
+			return nil, response.Err(response.CodeOSSError, "头像上传失败")
+		}
+		keys = append(keys, key)
+		avatar.Thumbnails[size] = s.storage.URL(key)
+	}
+	avatar.URL = avatar.Thumbnails[avatarSizes[0]]
+
+	// 以读取时的版本做条件更新，并发修改资料时不覆盖
+	err = s.userRepo.UpdateProfile(ctx, userID, &domain.ProfileUpdate{
+		AvatarURL: &avatar.URL,
+		Version:   u.Version,
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		s.deleteObjects(ctx, keys)
+		if errors.Is(err, domain.ErrUserConflict) {
+			return nil, response.Err(response.CodeConflict, "资料已被修改，请重试")
+		}
+		return nil, response.Err(response.CodeDatabaseError, "更新头像失败")
+	}
+
+	// 删除旧头像（仅限本服务上传的）
+	if oldKeys := s.ownedAvatarKeys(userID, ptr.Value(u.AvatarURL)); len(oldKeys) > 0 {
+		s.deleteObjects(ctx, oldKeys)
+	}
+
+	return avatar, nil
+}
+
+// avatarKey 头像对象 key: avatars/{user_id}/{id}/{size}.{ext}
+func avatarKey(userID, id string, size int, format string) string {
+	return fmt.Sprintf("avatars/%s/%s/%d.%s", userID, id, size, imaging.Ext(format))
+}
+
+// ownedAvatarKeys 解析由本服务上传的头像地址，返回其各尺寸对象 key
+// 外部地址（如用户通过资料接口填写的链接）返回 nil
+func (s *service) ownedAvatarKeys(userID, avatarURL string) []string {
+	base := s.storage.URL("")
+	if avatarURL == "" || !strings.HasPrefix(avatarURL, base) {
+		return nil
+	}
+
+	key := strings.TrimPrefix(avatarURL, base)
+	dir, file := path.Split(key)
+	if !strings.HasPrefix(dir, "avatars/"+userID+"/") {
+		return nil
+	}
+	ext := path.Ext(file)
+	if _, err := strconv.Atoi(strings.TrimSuffix(file, ext)); err != nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(avatarSizes))
+	for _, size := range avatarSizes {
+		keys = append(keys, dir+strconv.Itoa(size)+ext)
+	}
+	return keys
+}
+
+// deleteObjects 尽力删除对象，失败仅记录日志
+func (s *service) deleteObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logger.Ctx(ctx).Warn("delete object failed", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+)
+
+// memStorage 测试用内存对象存储
+type memStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemStorage() *memStorage {
+	return &memStorage{objects: make(map[string][]byte)}
+}
+
+func (s *memStorage) Put(_ context.Context, key string, data []byte, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	return nil
+}
+
+func (s *memStorage) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStorage) URL(key string) string { return "https://cdn.example.com/" + key }
+
+func (s *memStorage) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for k := range s.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// findUser 查询测试仓储中的用户，不存在（含已匿名化）时返回 nil
+func findUser(repo *usertest.Repository, userID string) *domain.User {
+	u, err := repo.FindByUserID(context.Background(), userID)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+func newAvatarTestService(avatarURL string) (*service, *usertest.Repository, *memStorage) {
+	repo := usertest.NewRepository(
+		&domain.User{UserID: "u1", AvatarURL: ptr.Of(avatarURL), UpdatedAt: time.Unix(1700000000, 0)},
+	)
+	storage := newMemStorage()
+	return &service{tx: directTx{}, userRepo: repo, storage: storage}, repo, storage
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadAvatar(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, storage := newAvatarTestService("")
+
+	first, err := svc.UploadAvatar(ctx, "u1", encodePNG(t, 800, 600))
+	if err != nil {
+		t.Fatalf("UploadAvatar() error = %v", err)
+	}
+	if len(storage.keys()) != len(avatarSizes) {
+		t.Fatalf("Expected %d objects, got %v", len(avatarSizes), storage.keys())
+	}
+	if !strings.HasSuffix(first.URL, "/512.png") || first.Thumbnails[48] == "" {
+		t.Errorf("Expected 512px avatar and thumbnails, got %+v", first)
+	}
+	if ptr.Value(findUser(repo, "u1").AvatarURL) != first.URL {
+		t.Errorf("Expected AvatarURL updated to %s", first.URL)
+	}
+
+	// 再次上传后旧头像的各尺寸被删除
+	second, err := svc.UploadAvatar(ctx, "u1", encodePNG(t, 100, 100))
+	if err != nil {
+		t.Fatalf("UploadAvatar() error = %v", err)
+	}
+	for _, key := range storage.keys() {
+		if !strings.HasPrefix(storage.URL(key), strings.TrimSuffix(second.URL, "512.png")) {
+			t.Errorf("Expected old avatar object deleted, found %s", key)
+		}
+	}
+}
+
+func TestUploadAvatar_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantCode int
+	}{
+		{name: "文件过大", data: make([]byte, MaxAvatarBytes+1), wantCode: response.CodeFileTooLarge},
+		{name: "非图片", data: []byte("<svg></svg>"), wantCode: response.CodeFileTypeError},
+		{name: "像素超限", data: encodePNG(t, 5000, 4000), wantCode: response.CodeFileTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, storage := newAvatarTestService("")
+			_, err := svc.UploadAvatar(context.Background(), "u1", tt.data)
+			if err == nil || response.CodeFromError(err) != tt.wantCode {
+				t.Errorf("Expected code %d, got %v", tt.wantCode, err)
+			}
+			if len(storage.keys()) != 0 {
+				t.Errorf("Expected nothing stored, got %v", storage.keys())
+			}
+		})
+	}
+}
+
+func TestOwnedAvatarKeys(t *testing.T) {
+	svc, _, _ := newAvatarTestService("")
+
+	tests := []struct {
+		name string
+		url  string
+		want int
+	}{
+		{name: "本服务上传", url: "https://cdn.example.com/avatars/u1/01HX/512.jpg", want: len(avatarSizes)},
+		{name: "外部地址", url: "https://other.example.com/avatars/u1/01HX/512.jpg", want: 0},
+		{name: "其他用户", url: "https://cdn.example.com/avatars/u2/01HX/512.jpg", want: 0},
+		{name: "非头像文件", url: "https://cdn.example.com/avatars/u1/01HX/a.jpg", want: 0},
+		{name: "空地址", url: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := svc.ownedAvatarKeys("u1", tt.url); len(got) != tt.want {
+				t.Errorf("Expected %d keys, got %v", tt.want, got)
+			}
+		})
+	}
+}
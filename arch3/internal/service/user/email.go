@@ -0,0 +1,239 @@
+package user
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/pkg/logger"
+	"arch3/pkg/mailer"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+// errEmailTokenInvalid 验证链接签名错误、已过期或邮箱已更换
+var errEmailTokenInvalid = errors.New("email verification token invalid")
+
+// BindEmail 绑定或更换邮箱
+//
+// 新邮箱写入后状态为未验证，随后发送验证邮件。
+// 唯一索引只作用于已验证的邮箱，未验证的邮箱不占用地址，唯一性在验证时检查。
+func (s *service) BindEmail(ctx context.Context, userID, email string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.BindEmail")
+	defer span.End()
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if !validEmail(email) {
+		return nil, response.Err(response.CodeInvalidParam, "邮箱格式无效")
+	}
+	if s.email.Mailer == nil {
+		return nil, response.Err(response.CodeUnavailable, "邮件服务未启用")
+	}
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	if ptr.Value(u.Email) == email {
+		if u.EmailVerified {
+			return nil, response.Err(response.CodeInvalidParam, "该邮箱已绑定并验证")
+		}
+		// 重复绑定同一未验证邮箱等同于重发
+		if err := s.sendEmailVerification(ctx, u); err != nil {
+			tracer.RecordError(span, err)
+			return nil, err
+		}
+		return u, nil
+	}
+
+	// 先占用冷却期再写库，避免频繁更换邮箱绕过发送间隔
+	if err := s.acquireEmailCooldown(ctx, u.UserID); err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	upd := &domain.ProfileUpdate{
+		Email:         &email,
+		EmailVerified: ptr.Of(false),
+		Version:       u.Version,
+	}
+	if err := s.userRepo.UpdateProfile(ctx, u.UserID, upd); err != nil {
+		tracer.RecordError(span, err)
+		return nil, profileErrorToResponse(err)
+	}
+
+	u, err = s.GetUserByID(ctx, u.UserID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if err := s.deliverEmailVerification(ctx, u); err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	logger.Ctx(ctx).Info("email bound, verification sent",
+		zap.String("user_id", u.UserID),
+	)
+	return u, nil
+}
+
+// ResendEmailVerification 重新发送验证邮件
+func (s *service) ResendEmailVerification(ctx context.Context, userID string) error {
+	ctx, span := tracer.Start(ctx, "service.user.ResendEmailVerification")
+	defer span.End()
+
+	if s.email.Mailer == nil {
+		return response.Err(response.CodeUnavailable, "邮件服务未启用")
+	}
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	if ptr.Value(u.Email) == "" {
+		return response.Err(response.CodeInvalidParam, "尚未绑定邮箱")
+	}
+	if u.EmailVerified {
+		return response.Err(response.CodeInvalidParam, "邮箱已验证")
+	}
+
+	if err := s.sendEmailVerification(ctx, u); err != nil {
+		tracer.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// VerifyEmail 校验验证链接并标记邮箱已验证
+//
+// token 绑定用户与邮箱: 邮箱在验证前被更换时，旧链接失效。重复验证是幂等的。
+func (s *service) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.VerifyEmail")
+	defer span.End()
+
+	userID, email, err := s.parseEmailToken(token, time.Now())
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeInvalidParam, "验证链接无效或已过期")
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, userID, email); err != nil {
+		tracer.RecordError(span, err)
+		switch {
+		case errors.Is(err, domain.ErrUserConflict) || errors.Is(err, domain.ErrUserNotFound):
+			return nil, response.Err(response.CodeInvalidParam, "验证链接无效或已过期")
+		case errors.Is(err, domain.ErrEmailTaken):
+			return nil, response.Err(response.CodeEmailRegistered, "该邮箱已被其他账号绑定")
+		}
+		return nil, response.Err(response.CodeDatabaseError, "更新用户失败")
+	}
+	s.markOnboarded(ctx, userID)
+
+	logger.Ctx(ctx).Info("email verified", zap.String("user_id", userID))
+	return s.GetUserByID(ctx, userID)
+}
+
+// sendEmailVerification 检查发送间隔并发送验证邮件
+func (s *service) sendEmailVerification(ctx context.Context, u *domain.User) error {
+	if err := s.acquireEmailCooldown(ctx, u.UserID); err != nil {
+		return err
+	}
+	return s.deliverEmailVerification(ctx, u)
+}
+
+// acquireEmailCooldown 占用验证邮件发送冷却期
+func (s *service) acquireEmailCooldown(ctx context.Context, userID string) error {
+	if s.email.Cooldown == nil || s.email.ResendInterval <= 0 {
+		return nil
+	}
+	ok, err := s.email.Cooldown.Acquire(ctx, "email_verify:"+userID, s.email.ResendInterval)
+	if err != nil {
+		return response.Err(response.CodeCacheError, "发送验证邮件失败")
+	}
+	if !ok {
+		return response.Err(response.CodeTooManyRequests, "发送太频繁，请稍后再试")
+	}
+	return nil
+}
+
+// deliverEmailVerification 发送验证邮件
+func (s *service) deliverEmailVerification(ctx context.Context, u *domain.User) error {
+	email := ptr.Value(u.Email)
+	token := s.signEmailToken(u.UserID, email, time.Now().Add(s.email.TTL))
+
+	err := s.email.Mailer.Send(ctx, &mailer.Message{
+		To:      email,
+		Subject: "验证您的邮箱",
+		Body: fmt.Sprintf("您好 %s：\n\n请在 %d 小时内点击以下链接完成邮箱验证：\n%s\n\n如非本人操作，请忽略本邮件。",
+			u.UserName, int(s.email.TTL.Hours()), emailVerifyLink(s.email.LinkURL, token)),
+	})
+	if err != nil {
+		logger.Ctx(ctx).Error("send verification email failed", zap.String("user_id", u.UserID), zap.Error(err))
+		return response.Err(response.CodeThirdPartyError, "验证邮件发送失败，请稍后重试")
+	}
+	return nil
+}
+
+// signEmailToken 签发验证 token: base64url(user_id \n email \n exp) . base64url(HMAC-SHA256)
+func (s *service) signEmailToken(userID, email string, exp time.Time) string {
+	payload := userID + "\n" + email + "\n" + strconv.FormatInt(exp.Unix(), 10)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(s.emailTokenMAC(payload))
+}
+
+// parseEmailToken 校验签名与有效期，返回 token 绑定的用户与邮箱
+func (s *service) parseEmailToken(token string, now time.Time) (userID, email string, err error) {
+	enc := base64.RawURLEncoding
+	payloadPart, macPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", "", errEmailTokenInvalid
+	}
+	payload, err := enc.DecodeString(payloadPart)
+	if err != nil {
+		return "", "", errEmailTokenInvalid
+	}
+	mac, err := enc.DecodeString(macPart)
+	if err != nil || !hmac.Equal(mac, s.emailTokenMAC(string(payload))) {
+		return "", "", errEmailTokenInvalid
+	}
+
+	fields := strings.Split(string(payload), "\n")
+	if len(fields) != 3 {
+		return "", "", errEmailTokenInvalid
+	}
+	exp, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || now.Unix() > exp {
+		return "", "", errEmailTokenInvalid
+	}
+	return fields[0], fields[1], nil
+}
+
+func (s *service) emailTokenMAC(payload string) []byte {
+	h := hmac.New(sha256.New, s.email.Secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
+
+// emailVerifyLink 拼接验证链接
+func emailVerifyLink(base, token string) string {
+	sep := "?"
+	if strings.Contains(base, "?") {
+		sep = "&"
+	}
+	return base + sep + "token=" + url.QueryEscape(token)
+}
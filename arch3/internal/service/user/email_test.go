@@ -0,0 +1,160 @@
+package user
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/mailer"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+)
+
+func newEmailTestService() (*service, *usertest.Repository, *mailer.Memory) {
+	repo := usertest.NewRepository(
+		&domain.User{UserID: "u1", UserName: "alice", UpdatedAt: time.Unix(1700000000, 0)},
+		&domain.User{UserID: "u2", Email: ptr.Of("taken@example.com"), EmailVerified: true, UpdatedAt: time.Unix(1700000000, 0)},
+	)
+	mail := mailer.NewMemory()
+	s := &service{tx: directTx{}, userRepo: repo, email: EmailVerification{
+		Mailer:  mail,
+		Secret:  []byte("test-secret"),
+		LinkURL: "https://example.com/verify-email",
+		TTL:     time.Hour,
+	}}
+	return s, repo, mail
+}
+
+// tokenFromMail 从验证邮件中提取 token
+func tokenFromMail(t *testing.T, mail *mailer.Memory, to string) string {
+	t.Helper()
+	msg, ok := mail.Last(to)
+	if !ok {
+		t.Fatalf("Expected verification mail to %s", to)
+	}
+	i := strings.Index(msg.Body, "https://")
+	if i < 0 {
+		t.Fatalf("Expected link in mail body, got %q", msg.Body)
+	}
+	link, err := url.Parse(strings.Fields(msg.Body[i:])[0])
+	if err != nil {
+		t.Fatalf("url.Parse() error = %v", err)
+	}
+	return link.Query().Get("token")
+}
+
+func TestBindAndVerifyEmail(t *testing.T) {
+	s, repo, mail := newEmailTestService()
+	ctx := context.Background()
+
+	u, err := s.BindEmail(ctx, "u1", " Alice@Example.com ")
+	if err != nil {
+		t.Fatalf("BindEmail() error = %v", err)
+	}
+	if ptr.Value(u.Email) != "alice@example.com" || u.EmailVerified {
+		t.Fatalf("Expected unverified alice@example.com, got %q verified=%v", ptr.Value(u.Email), u.EmailVerified)
+	}
+
+	token := tokenFromMail(t, mail, "alice@example.com")
+	u, err = s.VerifyEmail(ctx, token)
+	if err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	if !u.EmailVerified {
+		t.Error("Expected email verified")
+	}
+
+	// 重复验证幂等
+	if _, err := s.VerifyEmail(ctx, token); err != nil {
+		t.Errorf("Expected repeated verification to succeed, got %v", err)
+	}
+
+	// 更换邮箱后需重新验证，旧链接失效
+	if _, err := s.BindEmail(ctx, "u1", "bob@example.com"); err != nil {
+		t.Fatalf("BindEmail() error = %v", err)
+	}
+	if findUser(repo, "u1").EmailVerified {
+		t.Error("Expected verification reset after email change")
+	}
+	if _, err := s.VerifyEmail(ctx, token); response.CodeFromError(err) != response.CodeInvalidParam {
+		t.Errorf("Expected old token rejected, got %v", err)
+	}
+}
+
+func TestBindEmailErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		wantCode int
+	}{
+		{"格式无效", "not-an-email", response.CodeInvalidParam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, mail := newEmailTestService()
+			_, err := s.BindEmail(context.Background(), "u1", tt.email)
+			if got := response.CodeFromError(err); err == nil || got != tt.wantCode {
+				t.Errorf("Expected code %d, got %v", tt.wantCode, err)
+			}
+			if n := len(mail.Messages()); n != 0 {
+				t.Errorf("Expected no mail sent, got %d", n)
+			}
+		})
+	}
+}
+
+func TestVerifyEmailTaken(t *testing.T) {
+	s, repo, mail := newEmailTestService()
+	ctx := context.Background()
+
+	// 未验证的邮箱不占用地址，绑定他人已验证的邮箱在验证时才被拒绝
+	if _, err := s.BindEmail(ctx, "u1", "taken@example.com"); err != nil {
+		t.Fatalf("BindEmail() error = %v", err)
+	}
+	token := tokenFromMail(t, mail, "taken@example.com")
+	if _, err := s.VerifyEmail(ctx, token); response.CodeFromError(err) != response.CodeEmailRegistered {
+		t.Errorf("Expected code %d, got %v", response.CodeEmailRegistered, err)
+	}
+	if findUser(repo, "u1").EmailVerified {
+		t.Error("Expected u1 email unverified")
+	}
+}
+
+func TestParseEmailToken(t *testing.T) {
+	s, _, _ := newEmailTestService()
+	now := time.Unix(1700000000, 0)
+	valid := s.signEmailToken("u1", "a@example.com", now.Add(time.Hour))
+
+	other := &service{email: EmailVerification{Secret: []byte("other-secret")}}
+	forged := other.signEmailToken("u1", "a@example.com", now.Add(time.Hour))
+
+	tests := []struct {
+		name    string
+		token   string
+		now     time.Time
+		wantErr bool
+	}{
+		{"有效", valid, now, false},
+		{"已过期", valid, now.Add(2 * time.Hour), true},
+		{"密钥不符", forged, now, true},
+		{"篡改签名", valid[:len(valid)-2] + "AA", now, true},
+		{"格式错误", "garbage", now, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, email, err := s.parseEmailToken(tt.token, tt.now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && (userID != "u1" || email != "a@example.com") {
+				t.Errorf("Expected u1/a@example.com, got %s/%s", userID, email)
+			}
+		})
+	}
+}
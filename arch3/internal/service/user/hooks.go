@@ -0,0 +1,71 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+// DeletionHook 账号注销时删除或匿名化其他模块保存的用户数据
+// 冷静期结束、匿名化用户记录之前调用；失败时该账号下一轮重试，因此须幂等
+type DeletionHook func(ctx context.Context, userID string) error
+
+// RegistrationHook 新用户注册时关联其他模块按手机号保存的数据（如未注册时收到的组织邀请）
+// 在注册事务中以保存点执行，失败只记录日志，不影响注册
+type RegistrationHook func(ctx context.Context, userID, phoneNumber string) error
+
+// ExportHook 导出其他模块保存的用户数据，返回值须可 JSON 序列化，无数据时返回 nil
+type ExportHook func(ctx context.Context, userID string) (any, error)
+
+// AccountHooks 账号注册、注销与数据导出钩子注册表
+//
+// 保存用户数据的模块在启动时注册钩子，用户服务注册、注销或导出账号时按注册顺序调用。
+// 注册须在服务处理请求之前完成，注册表本身不做并发保护。
+type AccountHooks struct {
+	register []namedRegistrationHook
+	deletion []namedDeletionHook
+	export   []namedExportHook
+}
+
+type namedRegistrationHook struct {
+	name string
+	fn   RegistrationHook
+}
+
+type namedDeletionHook struct {
+	name string
+	fn   DeletionHook
+}
+
+type namedExportHook struct {
+	name string
+	fn   ExportHook
+}
+
+// NewAccountHooks 创建钩子注册表
+func NewAccountHooks() *AccountHooks {
+	return &AccountHooks{}
+}
+
+// OnRegister 注册新用户注册钩子，name 用于日志
+func (h *AccountHooks) OnRegister(name string, fn RegistrationHook) {
+	h.register = append(h.register, namedRegistrationHook{name: name, fn: fn})
+}
+
+// OnDelete 注册注销钩子，name 用于日志
+func (h *AccountHooks) OnDelete(name string, fn DeletionHook) {
+	h.deletion = append(h.deletion, namedDeletionHook{name: name, fn: fn})
+}
+
+// OnExport 注册导出钩子，name 为导出归档中的模块名
+func (h *AccountHooks) OnExport(name string, fn ExportHook) {
+	h.export = append(h.export, namedExportHook{name: name, fn: fn})
+}
+
+// AccountDeletion 账号注销依赖
+type AccountDeletion struct {
+	CoolingOff time.Duration // 冷静期，期间可撤销注销
+	Hooks      *AccountHooks
+
+	// InactiveAfter 未完成引导的账号超过该时长不活跃后转入注销流程，0 表示不清理
+	InactiveAfter time.Duration
+}
@@ -0,0 +1,21 @@
+package user
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/user"
+)
+
+// RealNameVerification 实名认证依赖
+type RealNameVerification struct {
+	Verifier IdentityVerifier // 身份核验服务商
+	Cooldown Cooldown         // 提交冷却，限制服务商调用频率
+}
+
+// IdentityVerifier 实名身份核验接口（由使用方定义）
+// 对接公安身份核验等服务商，校验姓名与身份证号是否一致
+type IdentityVerifier interface {
+	// Verify 核验姓名与身份证号
+	// 返回 error 表示服务不可用，调用方应转人工审核而不是拒绝
+	Verify(ctx context.Context, realName, idNumber string) (domain.IdentityResult, error)
+}
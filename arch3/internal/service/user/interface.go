@@ -4,6 +4,7 @@ import (
 	"context"
 
 	domain "arch3/internal/domain/user"
+	"arch3/internal/service/common"
 	"arch3/pkg/jwt"
 )
 
@@ -11,23 +12,119 @@ import (
 type Service interface {
 	SMSService
 	AuthService
+	ProfileService
+	PhoneService
+	EmailService
+	RealNameService
+	AccountService
+	AdminService
 }
 
-// SMSService 短信服务接口
+// SMSService 验证码服务接口
 type SMSService interface {
-	// SendSMS 发送短信验证码
+	// SendSMS 发送验证码
 	// smsType: login/register/forget
-	SendSMS(ctx context.Context, phoneNumber, smsType string) error
+	// channel: sms/voice/email，为空时使用 sms
+	// 返回投递 ID，实际投递异步进行
+	SendSMS(ctx context.Context, phoneNumber, smsType, channel string) (dispatchID string, err error)
+	// GetSMSStatus 查询投递状态: pending/sent/failed
+	GetSMSStatus(ctx context.Context, dispatchID string) (string, error)
 }
 
 // AuthService 认证服务接口
 type AuthService interface {
 	// SMSLogin 短信验证码登录（用户不存在则自动注册）
-	SMSLogin(ctx context.Context, phoneNumber, smsCode string) (*domain.LoginResult, error)
+	// deviceID 可选，为空时取 common.ClientInfo 中的设备标识，已有用户在新设备登录时发送提醒；
+	// 注册时按 common.ClientInfo 记录注册来源，每次登录写入登录记录
+	SMSLogin(ctx context.Context, phoneNumber, smsCode, deviceID string) (*domain.LoginResult, error)
 	// RefreshToken 刷新 token
 	RefreshToken(ctx context.Context, refreshToken string) (*jwt.TokenPair, error)
 	// Logout 登出
 	Logout(ctx context.Context, accessJTI, refreshJTI string) error
 	// GetUserByID 根据 ID 获取用户
 	GetUserByID(ctx context.Context, userID string) (*domain.User, error)
+	// ListLoginHistory 查询最近的登录记录，按时间倒序
+	ListLoginHistory(ctx context.Context, userID string, limit int) ([]*domain.LoginRecord, error)
+	// NotifyLoginNewDevice 发送新设备登录提醒，由 user.login_new_device 事件消费者调用
+	NotifyLoginNewDevice(ctx context.Context, e *common.Event) error
+}
+
+// ProfileService 当前用户资料服务接口
+type ProfileService interface {
+	// UpdateProfile 部分更新用户资料，返回更新后的用户
+	// upd.Version 为零时以当前版本为前置条件
+	UpdateProfile(ctx context.Context, userID string, upd *domain.ProfileUpdate) (*domain.User, error)
+	// UploadAvatar 上传头像图片，生成缩略图并更新 AvatarURL
+	UploadAvatar(ctx context.Context, userID string, data []byte) (*domain.Avatar, error)
+}
+
+// PhoneService 更换手机号服务接口
+//
+// 流程: 验证原手机号（或备用邮箱）→ 获得凭证 → 验证新手机号 → 提交变更
+type PhoneService interface {
+	// SendChangePhoneOldCode 发送原手机号验证码，channel 为 email 时投递到绑定邮箱
+	SendChangePhoneOldCode(ctx context.Context, userID, channel string) (dispatchID string, err error)
+	// VerifyChangePhoneOld 校验原手机号验证码，返回更换手机号凭证
+	VerifyChangePhoneOld(ctx context.Context, userID, code string) (ticket string, err error)
+	// SendChangePhoneNewCode 凭证有效时向新手机号发送验证码
+	SendChangePhoneNewCode(ctx context.Context, userID, ticket, newPhone, channel string) (dispatchID string, err error)
+	// ChangePhone 校验新手机号验证码并更换，返回更新后的用户与当前会话的新 token
+	ChangePhone(ctx context.Context, userID, ticket, newPhone, code string) (*domain.LoginResult, error)
+}
+
+// EmailService 邮箱绑定与验证服务接口
+//
+// 绑定或更换邮箱后状态为未验证，用户点击邮件中的签名链接完成验证；
+// 只有已验证的邮箱用于验证码登录与通知。
+type EmailService interface {
+	// BindEmail 绑定或更换邮箱并发送验证邮件
+	BindEmail(ctx context.Context, userID, email string) (*domain.User, error)
+	// ResendEmailVerification 重新发送当前邮箱的验证邮件
+	ResendEmailVerification(ctx context.Context, userID string) error
+	// VerifyEmail 校验验证链接中的 token，标记邮箱已验证
+	VerifyEmail(ctx context.Context, token string) (*domain.User, error)
+}
+
+// RealNameService 实名认证服务接口
+//
+// 状态流转: real_name_unverified → (核验一致) real_name_verified
+//
+//	→ (无法判定/服务商不可用) under_review → (人工审核) real_name_verified / real_name_unverified
+type RealNameService interface {
+	// SubmitRealName 提交实名信息，返回更新后的用户
+	SubmitRealName(ctx context.Context, userID, realName, idNumber string) (*domain.User, error)
+	// ListRealNameReviews 分页查询待人工审核的用户，afterID 为上一页最后一条的 ID
+	ListRealNameReviews(ctx context.Context, afterID uint, limit int) ([]*domain.User, error)
+	// ReviewRealName 人工审核实名信息，驳回时清除已提交的信息
+	ReviewRealName(ctx context.Context, reviewerID, userID string, approve bool, reason string) (*domain.User, error)
+}
+
+// AccountService 账号注销与数据导出服务接口
+//
+// 注销流程: 验证手机号 → 进入冷静期（可撤销）→ 冷静期结束后由后台任务匿名化账号
+type AccountService interface {
+	// SendDeleteAccountCode 向绑定手机号发送注销验证码
+	SendDeleteAccountCode(ctx context.Context, userID, channel string) (dispatchID string, err error)
+	// RequestAccountDeletion 校验验证码并申请注销，返回更新后的用户
+	RequestAccountDeletion(ctx context.Context, userID, code string) (*domain.User, error)
+	// CancelAccountDeletion 冷静期内撤销注销，返回更新后的用户
+	CancelAccountDeletion(ctx context.Context, userID string) (*domain.User, error)
+	// ExportAccountData 导出账号的全部数据
+	ExportAccountData(ctx context.Context, userID string) (*domain.AccountExport, error)
+	// ScheduleInactiveDeletions 未完成引导且长期不活跃的账号转入注销流程并通知，由后台任务周期调用
+	ScheduleInactiveDeletions(ctx context.Context) error
+	// PurgeDeletedAccounts 匿名化冷静期已结束的账号，由后台任务周期调用
+	PurgeDeletedAccounts(ctx context.Context) error
+}
+
+// AdminService 管理后台用户管理服务接口
+type AdminService interface {
+	// SearchUsers 按条件分页查询用户，返回下一页游标，没有更多数据时为空
+	SearchUsers(ctx context.Context, f *domain.UserFilter) (users []*domain.User, nextCursor string, err error)
+	// GetUserDetail 查询用户详情与最近的状态变更记录
+	GetUserDetail(ctx context.Context, userID string) (*domain.UserDetail, error)
+	// BanUser 封禁用户并使其全部会话失效
+	BanUser(ctx context.Context, operatorID, userID, reason string) (*domain.User, error)
+	// UnbanUser 解封用户，恢复到封禁前的状态
+	UnbanUser(ctx context.Context, operatorID, userID, reason string) (*domain.User, error)
 }
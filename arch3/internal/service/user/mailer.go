@@ -0,0 +1,29 @@
+package user
+
+import (
+	"context"
+	"time"
+
+	"arch3/pkg/mailer"
+)
+
+// Mailer 邮件发送接口（由使用方定义）
+type Mailer interface {
+	Send(ctx context.Context, msg *mailer.Message) error
+}
+
+// Cooldown 发送冷却（由使用方定义）
+type Cooldown interface {
+	// Acquire 占用 key 的冷却期，冷却期内再次占用返回 false
+	Acquire(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// EmailVerification 邮箱验证依赖与参数
+type EmailVerification struct {
+	Mailer         Mailer        // 为 nil 时不支持绑定邮箱
+	Cooldown       Cooldown      // 发送冷却
+	Secret         []byte        // 验证链接签名密钥
+	LinkURL        string        // 验证页面地址
+	TTL            time.Duration // 链接有效期
+	ResendInterval time.Duration // 同一用户两次发送的最小间隔
+}
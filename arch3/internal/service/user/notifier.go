@@ -0,0 +1,50 @@
+package user
+
+import (
+	"context"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/ptr"
+
+	"go.uber.org/zap"
+)
+
+// Notifier 通知发送接口（由使用方定义）
+// 用户服务只按模板发送事务通知，不直接调用短信、邮件服务商
+type Notifier interface {
+	Notify(ctx context.Context, req *notification.Request) error
+}
+
+// notify 向用户发送通知
+// 通知失败不影响主流程，仅记录日志
+func (s *service) notify(ctx context.Context, u *domain.User, template string, vars map[string]string) {
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	vars["user_name"] = u.UserName
+
+	// 未验证的邮箱可能不属于用户本人，不发送通知
+	var email string
+	if u.EmailVerified {
+		email = ptr.Value(u.Email)
+	}
+
+	err := s.notifier.Notify(ctx, &notification.Request{
+		Template: template,
+		Recipient: notification.Recipient{
+			UserID: u.UserID,
+			Phone:  u.PhoneNumber,
+			Email:  email,
+		},
+		Vars: vars,
+	})
+	if err != nil {
+		logger.Ctx(ctx).Warn("send user notification failed",
+			zap.String("user_id", u.UserID),
+			zap.String("template", template),
+			zap.Error(err),
+		)
+	}
+}
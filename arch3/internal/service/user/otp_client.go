@@ -0,0 +1,123 @@
+package user
+
+import (
+	"context"
+	"errors"
+
+	"arch3/pkg/response"
+)
+
+// SMSType 验证码用途（沿用短信类型命名，与下发渠道无关）
+type SMSType string
+
+const (
+	SMSTypeRegister SMSType = "register" // 注册
+	SMSTypeLogin    SMSType = "login"    // 登录
+	SMSTypeForget   SMSType = "forget"   // 忘记密码
+
+	SMSTypeChangePhoneOld SMSType = "change_phone_old" // 更换手机号: 验证原手机号（或备用邮箱）
+	SMSTypeChangePhoneNew SMSType = "change_phone_new" // 更换手机号: 验证新手机号
+
+	SMSTypeDeleteAccount SMSType = "delete_account" // 注销账号
+)
+
+// OTPChannel 验证码下发渠道
+type OTPChannel string
+
+const (
+	OTPChannelSMS   OTPChannel = "sms"   // 短信
+	OTPChannelEmail OTPChannel = "email" // 邮件
+	OTPChannelVoice OTPChannel = "voice" // 语音电话
+)
+
+// OTPSendRequest 验证码下发请求
+type OTPSendRequest struct {
+	Channel OTPChannel // 下发渠道
+	Type    SMSType    // 验证码用途
+	Target  string     // 验证主体（如手机号），验证码与其绑定
+	Address string     // 投递地址（手机号/邮箱），为空时使用 Target
+}
+
+// OTPDispatchStatus 验证码投递状态
+type OTPDispatchStatus string
+
+const (
+	OTPDispatchPending OTPDispatchStatus = "pending" // 投递中（含重试）
+	OTPDispatchSent    OTPDispatchStatus = "sent"    // 已投递到服务商
+	OTPDispatchFailed  OTPDispatchStatus = "failed"  // 投递失败，需重新获取
+)
+
+// OTPClient 一次性验证码客户端接口
+// 由 Service 层定义，Integration 层实现
+//
+// 验证码的签发与校验与渠道无关：同一 Target 无论通过短信、邮件还是语音下发，
+// 都使用相同的验证码存储、发送限流和验证失败计数。
+//
+// 投递是异步的：Send 在存储验证码并写入投递队列后即返回投递 ID，
+// 客户端可通过 DispatchStatus 查询投递结果。
+type OTPClient interface {
+	// Send 生成验证码并提交异步投递，返回投递 ID
+	Send(ctx context.Context, req *OTPSendRequest) (dispatchID string, err error)
+	// DispatchStatus 查询投递状态
+	DispatchStatus(ctx context.Context, dispatchID string) (OTPDispatchStatus, error)
+	// Verify 验证验证码
+	Verify(ctx context.Context, smsType SMSType, target, code string) error
+	// Purge 清除主体的全部验证码、发送计数与验证失败计数（账号注销）
+	Purge(ctx context.Context, target string) error
+}
+
+// 验证码服务错误定义
+var (
+	// 发送限制
+	ErrSMSTooFrequent = errors.New("一分钟内最多发送1次")
+	ErrSMSDailyLimit  = errors.New("今日发送次数已达上限")
+
+	// 发送失败
+	ErrSMSSendFailed = errors.New("发送短信失败")
+
+	// 配置错误
+	ErrSMSTemplateNotFound = errors.New("短信模板未配置")
+
+	// 投递记录不存在或已过期
+	ErrOTPDispatchNotFound = errors.New("投递记录不存在或已过期")
+
+	// 渠道相关
+	// 用户未绑定邮箱等情况同样返回该错误，避免探测账号信息
+	ErrOTPChannelUnavailable = errors.New("该验证方式暂不可用，请选择其他方式")
+
+	// 验证码相关
+	// 统一错误信息，避免攻击者通过不同错误信息探测验证码状态
+	ErrSMSCodeInvalid   = errors.New("验证码错误或已过期")
+	ErrSMSVerifyTooMany = errors.New("验证失败次数过多，请重新获取验证码")
+)
+
+// SMSToResponse 将验证码错误转换为业务响应
+func SMSToResponse(err error) *response.Result {
+	switch {
+	// 短信发送限制
+	case errors.Is(err, ErrSMSTooFrequent):
+		return response.Err(response.CodeSMSTooFrequent, err.Error())
+	case errors.Is(err, ErrSMSDailyLimit):
+		return response.Err(response.CodeSMSDailyLimit, err.Error())
+
+	// 短信验证码（统一使用 CodeSMSCodeInvalid，不区分过期和错误）
+	case errors.Is(err, ErrSMSCodeInvalid):
+		return response.Err(response.CodeSMSCodeInvalid, err.Error())
+	case errors.Is(err, ErrSMSVerifyTooMany):
+		return response.Err(response.CodeSMSVerifyTooMany, err.Error())
+
+	case errors.Is(err, ErrOTPDispatchNotFound):
+		return response.Err(response.CodeNotFound, err.Error())
+
+	// 下发渠道不可用
+	case errors.Is(err, ErrOTPChannelUnavailable):
+		return response.Err(response.CodeInvalidParam, err.Error())
+
+	// 短信服务配置/发送
+	case errors.Is(err, ErrSMSTemplateNotFound), errors.Is(err, ErrSMSSendFailed):
+		return response.Err(response.CodeSMSSendFailed, err.Error())
+
+	default:
+		return response.Err(response.CodeSMSSendFailed, err.Error())
+	}
+}
@@ -0,0 +1,229 @@
+package user
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+// PhoneChangeTicketTTL 原手机号验证通过后，完成新手机号验证的时限
+const PhoneChangeTicketTTL = 10 * time.Minute
+
+// SendChangePhoneOldCode 发送原手机号验证码
+//
+// 验证码绑定原手机号；原手机号无法接收时可选 email 渠道，投递到账号绑定的邮箱。
+func (s *service) SendChangePhoneOldCode(ctx context.Context, userID, channel string) (string, error) {
+	ctx, span := tracer.Start(ctx, "service.user.SendChangePhoneOldCode")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	dispatchID, err := s.SendSMS(ctx, u.PhoneNumber, string(SMSTypeChangePhoneOld), channel)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	return dispatchID, nil
+}
+
+// VerifyChangePhoneOld 校验原手机号验证码，签发更换手机号凭证
+func (s *service) VerifyChangePhoneOld(ctx context.Context, userID, code string) (string, error) {
+	ctx, span := tracer.Start(ctx, "service.user.VerifyChangePhoneOld")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	if err := s.otpClient.Verify(ctx, SMSTypeChangePhoneOld, u.PhoneNumber, code); err != nil {
+		tracer.RecordError(span, err)
+		return "", SMSToResponse(err)
+	}
+
+	ticket, err := newTicket()
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", response.Err(response.CodeInternal, "生成凭证失败")
+	}
+	t := &domain.PhoneChangeTicket{UserID: u.UserID, OldPhone: u.PhoneNumber}
+	if err := s.phoneTickets.Save(ctx, ticket, t, PhoneChangeTicketTTL); err != nil {
+		tracer.RecordError(span, err)
+		return "", response.Err(response.CodeCacheError, "保存凭证失败")
+	}
+
+	return ticket, nil
+}
+
+// SendChangePhoneNewCode 凭证有效时向新手机号发送验证码
+func (s *service) SendChangePhoneNewCode(ctx context.Context, userID, ticket, newPhone, channel string) (string, error) {
+	ctx, span := tracer.Start(ctx, "service.user.SendChangePhoneNewCode")
+	defer span.End()
+
+	// 新手机号只能通过短信或语音验证，证明持有该号码
+	if channel == string(OTPChannelEmail) {
+		return "", SMSToResponse(ErrOTPChannelUnavailable)
+	}
+
+	t, err := s.checkPhoneChangeTicket(ctx, userID, ticket, newPhone)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+	if err := s.checkPhoneAvailable(ctx, newPhone); err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	dispatchID, err := s.SendSMS(ctx, newPhone, string(SMSTypeChangePhoneNew), channel)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", err
+	}
+
+	logger.Ctx(ctx).Info("change phone code sent to new number",
+		zap.String("user_id", t.UserID),
+		zap.String("new_phone", tracer.MaskPhone(newPhone)),
+	)
+	return dispatchID, nil
+}
+
+// ChangePhone 校验新手机号验证码并完成更换
+//
+// 更换成功后撤销该用户所有已签发的 token，并为当前会话签发新 token；
+// 通知发送到原手机号与绑定邮箱，便于原号码持有人发现异常变更。
+func (s *service) ChangePhone(ctx context.Context, userID, ticket, newPhone, code string) (*domain.LoginResult, error) {
+	ctx, span := tracer.Start(ctx, "service.user.ChangePhone")
+	defer span.End()
+
+	t, err := s.checkPhoneChangeTicket(ctx, userID, ticket, newPhone)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	if err := s.otpClient.Verify(ctx, SMSTypeChangePhoneNew, newPhone, code); err != nil {
+		tracer.RecordError(span, err)
+		return nil, SMSToResponse(err)
+	}
+
+	// 凭证一次性使用: 并发提交只有一个能取到
+	if _, err := s.phoneTickets.Take(ctx, ticket); err != nil {
+		tracer.RecordError(span, err)
+		return nil, ticketToResponse(err)
+	}
+
+	// 更换与重新读取在同一事务中，读到的一定是本次更换后的数据
+	var u *domain.User
+	err = s.tx.Do(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.UpdatePhoneNumber(ctx, t.UserID, t.OldPhone, newPhone); err != nil {
+			return err
+		}
+		var err error
+		u, err = s.userRepo.FindByUserID(ctx, t.UserID)
+		return err
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		switch {
+		case errors.Is(err, domain.ErrPhoneTaken):
+			return nil, response.Err(response.CodePhoneRegistered, "该手机号已被其他账号绑定")
+		case errors.Is(err, domain.ErrUserConflict):
+			return nil, response.Err(response.CodeConflict, "手机号已变更，请重新验证")
+		case errors.Is(err, domain.ErrUserNotFound):
+			return nil, response.Err(response.CodeUserNotFound, "用户不存在")
+		default:
+			return nil, response.Err(response.CodeDatabaseError, "更换手机号失败")
+		}
+	}
+
+	// 通知发往原手机号
+	old := *u
+	old.PhoneNumber = t.OldPhone
+	s.notify(ctx, &old, notification.TemplatePhoneChanged, map[string]string{
+		"phone": tracer.MaskPhone(newPhone),
+		"time":  time.Now().Format("2006-01-02 15:04"),
+	})
+
+	logger.Ctx(ctx).Info("phone number changed",
+		zap.String("user_id", u.UserID),
+		zap.String("old_phone", tracer.MaskPhone(t.OldPhone)),
+		zap.String("new_phone", tracer.MaskPhone(newPhone)),
+	)
+
+	// 撤销所有已签发的 token，其他设备需重新登录
+	// 撤销失败时旧 token 仍然有效，不签发新 token，由客户端重新登录后再次撤销
+	if err := s.jwtManager.RevokeUserTokens(ctx, u.UserID); err != nil {
+		tracer.RecordError(span, err)
+		logger.Ctx(ctx).Error("revoke user tokens after phone change failed", zap.String("user_id", u.UserID), zap.Error(err))
+		return nil, response.Err(response.CodeCacheError, "手机号已更换，请重新登录")
+	}
+	tokenPair, err := s.jwtManager.GenerateTokenPair(u.UserID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeInternal, "生成令牌失败")
+	}
+
+	return &domain.LoginResult{User: u, TokenPair: tokenPair}, nil
+}
+
+// checkPhoneChangeTicket 校验凭证属于当前用户且新手机号与原手机号不同
+func (s *service) checkPhoneChangeTicket(ctx context.Context, userID, ticket, newPhone string) (*domain.PhoneChangeTicket, error) {
+	t, err := s.phoneTickets.Get(ctx, ticket)
+	if err != nil {
+		return nil, ticketToResponse(err)
+	}
+	if t.UserID != userID {
+		return nil, ticketToResponse(domain.ErrTicketInvalid)
+	}
+	if t.OldPhone == newPhone {
+		return nil, response.Err(response.CodeInvalidParam, "新手机号不能与原手机号相同")
+	}
+	return t, nil
+}
+
+// checkPhoneAvailable 新手机号未被其他账号绑定
+// 最终以唯一索引为准，此处仅提前拦截，避免向已注册号码发送验证码
+func (s *service) checkPhoneAvailable(ctx context.Context, phone string) error {
+	_, err := s.userRepo.FindByPhoneNumber(ctx, phone)
+	switch {
+	case err == nil:
+		return response.Err(response.CodePhoneRegistered, "该手机号已被其他账号绑定")
+	case errors.Is(err, domain.ErrUserNotFound):
+		return nil
+	default:
+		return response.Err(response.CodeDatabaseError, "查询用户失败")
+	}
+}
+
+// ticketToResponse 将凭证错误转换为业务响应
+func ticketToResponse(err error) *response.Result {
+	if errors.Is(err, domain.ErrTicketInvalid) {
+		return response.Err(response.CodeSessionExpired, "验证已过期，请重新验证原手机号")
+	}
+	return response.Err(response.CodeCacheError, "查询凭证失败")
+}
+
+// newTicket 生成 256 位随机凭证
+func newTicket() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
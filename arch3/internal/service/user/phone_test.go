@@ -0,0 +1,162 @@
+package user
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/jwt"
+	"arch3/pkg/response"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// memTicketStore 测试用内存凭证存储
+type memTicketStore struct {
+	mu      sync.Mutex
+	tickets map[string]domain.PhoneChangeTicket
+}
+
+func (m *memTicketStore) Save(_ context.Context, ticket string, t *domain.PhoneChangeTicket, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tickets[ticket] = *t
+	return nil
+}
+
+func (m *memTicketStore) Get(_ context.Context, ticket string) (*domain.PhoneChangeTicket, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tickets[ticket]
+	if !ok {
+		return nil, domain.ErrTicketInvalid
+	}
+	return &t, nil
+}
+
+func (m *memTicketStore) Take(ctx context.Context, ticket string) (*domain.PhoneChangeTicket, error) {
+	t, err := m.Get(ctx, ticket)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	delete(m.tickets, ticket)
+	m.mu.Unlock()
+	return t, nil
+}
+
+func TestCheckPhoneChangeTicket(t *testing.T) {
+	repo := usertest.NewRepository(
+		&domain.User{UserID: "u1", PhoneNumber: "13800000001"},
+		&domain.User{UserID: "u2", PhoneNumber: "13800000002"},
+	)
+	tickets := &memTicketStore{tickets: map[string]domain.PhoneChangeTicket{
+		"t1": {UserID: "u1", OldPhone: "13800000001"},
+	}}
+	s := &service{tx: directTx{}, userRepo: repo, phoneTickets: tickets}
+
+	tests := []struct {
+		name     string
+		userID   string
+		ticket   string
+		newPhone string
+		wantCode int
+	}{
+		{"凭证有效", "u1", "t1", "13800000009", response.CodeSuccess},
+		{"凭证不存在", "u1", "t9", "13800000009", response.CodeSessionExpired},
+		{"凭证属于其他用户", "u2", "t1", "13800000009", response.CodeSessionExpired},
+		{"新旧手机号相同", "u1", "t1", "13800000001", response.CodeInvalidParam},
+		{"新手机号已被绑定", "u1", "t1", "13800000002", response.CodePhoneRegistered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			_, err := s.checkPhoneChangeTicket(ctx, tt.userID, tt.ticket, tt.newPhone)
+			if err == nil {
+				err = s.checkPhoneAvailable(ctx, tt.newPhone)
+			}
+			got := response.CodeSuccess
+			if err != nil {
+				got = response.CodeFromError(err)
+			}
+			if got != tt.wantCode {
+				t.Errorf("Expected code %d, got %d (err=%v)", tt.wantCode, got, err)
+			}
+		})
+	}
+}
+
+func TestTicketTakeOnce(t *testing.T) {
+	tickets := &memTicketStore{tickets: map[string]domain.PhoneChangeTicket{}}
+	s := &service{phoneTickets: tickets}
+	ctx := context.Background()
+
+	ticket, err := newTicket()
+	if err != nil {
+		t.Fatalf("newTicket() error = %v", err)
+	}
+	if err := s.phoneTickets.Save(ctx, ticket, &domain.PhoneChangeTicket{UserID: "u1"}, time.Minute); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := s.phoneTickets.Take(ctx, ticket); err != nil {
+		t.Fatalf("Expected first Take to succeed, got %v", err)
+	}
+	if _, err := s.phoneTickets.Take(ctx, ticket); err != domain.ErrTicketInvalid {
+		t.Errorf("Expected ErrTicketInvalid on second Take, got %v", err)
+	}
+}
+
+func TestChangePhone_RevokeTokens(t *testing.T) {
+	tests := []struct {
+		name       string
+		redisDown  bool
+		wantCode   int
+		wantTokens bool
+	}{
+		{name: "撤销成功后签发新 token", wantCode: response.CodeSuccess, wantTokens: true},
+		{name: "撤销失败不签发新 token", redisDown: true, wantCode: response.CodeCacheError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			repo := usertest.NewRepository(&domain.User{UserID: "u1", PhoneNumber: "13800000001"})
+			tickets := &memTicketStore{tickets: map[string]domain.PhoneChangeTicket{
+				"t1": {UserID: "u1", OldPhone: "13800000001"},
+			}}
+			s := &service{
+				tx:           directTx{},
+				otpClient:    &stubOTPClient{code: "123456"},
+				userRepo:     repo,
+				jwtManager:   jwt.NewManager(&jwt.Config{Secret: "test-secret"}, rdb),
+				notifier:     nopNotifier{},
+				phoneTickets: tickets,
+			}
+			if tt.redisDown {
+				mr.Close()
+			}
+
+			result, err := s.ChangePhone(context.Background(), "u1", "t1", "13800000009", "123456")
+			got := response.CodeSuccess
+			if err != nil {
+				got = response.CodeFromError(err)
+			}
+			if got != tt.wantCode {
+				t.Errorf("Expected code %d, got %d (err=%v)", tt.wantCode, got, err)
+			}
+			if (result != nil && result.TokenPair != nil) != tt.wantTokens {
+				t.Errorf("Expected tokens issued = %v, got %+v", tt.wantTokens, result)
+			}
+			// 手机号已在事务中更换，撤销失败不回滚
+			if phone := findUser(repo, "u1").PhoneNumber; phone != "13800000009" {
+				t.Errorf("Expected phone changed, got %s", phone)
+			}
+		})
+	}
+}
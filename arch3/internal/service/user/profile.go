@@ -0,0 +1,169 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	domain "arch3/internal/domain/user"
+	"arch3/pkg/logger"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+const (
+	maxUserNameLen  = 50  // 与 users.user_name 列宽一致
+	maxEmailLen     = 254 // 与 users.email 列宽一致
+	maxAvatarURLLen = 255 // 与 users.avatar_url 列宽一致
+)
+
+// UpdateProfile 部分更新用户资料
+func (s *service) UpdateProfile(ctx context.Context, userID string, upd *domain.ProfileUpdate) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.UpdateProfile")
+	defer span.End()
+
+	if upd.Empty() {
+		return nil, response.Err(response.CodeMissingParam, "没有需要修改的字段")
+	}
+	if err := normalizeProfileUpdate(upd); err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	// 邮箱变更需要与当前邮箱比较；客户端未携带版本时以当前版本为前置条件
+	var current *domain.User
+	if upd.Email != nil || upd.Version == 0 {
+		u, err := s.GetUserByID(ctx, userID)
+		if err != nil {
+			tracer.RecordError(span, err)
+			return nil, err
+		}
+		current = u
+		if upd.Version == 0 {
+			upd.Version = u.Version
+		}
+	}
+
+	// 更换邮箱后需重新验证；若 current 已不是 upd.Version 版本，条件更新会返回冲突
+	emailChanged := upd.Email != nil && *upd.Email != ptr.Value(current.Email)
+	if emailChanged {
+		upd.EmailVerified = ptr.Of(false)
+	} else {
+		upd.EmailVerified = nil
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, userID, upd); err != nil {
+		tracer.RecordError(span, err)
+		return nil, profileErrorToResponse(err)
+	}
+	s.markOnboarded(ctx, userID)
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+
+	// 验证邮件发送失败不影响资料修改，用户可稍后重发
+	if emailChanged && ptr.Value(u.Email) != "" && s.email.Mailer != nil {
+		if err := s.sendEmailVerification(ctx, u); err != nil {
+			logger.Ctx(ctx).Warn("send verification email after profile update failed",
+				zap.String("user_id", u.UserID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return u, nil
+}
+
+// markOnboarded 记录用户完成引导（首次修改资料、验证邮箱或提交实名认证），失败不影响主流程
+// 完成引导的账号不再因长期不活跃被清理
+func (s *service) markOnboarded(ctx context.Context, userID string) {
+	if err := s.userRepo.MarkOnboarded(ctx, userID, time.Now()); err != nil {
+		logger.Ctx(ctx).Warn("mark onboarded failed", zap.String("user_id", userID), zap.Error(err))
+	}
+}
+
+// profileErrorToResponse 将资料更新错误转换为业务响应
+func profileErrorToResponse(err error) *response.Result {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return response.Err(response.CodeUserNotFound, "用户不存在")
+	case errors.Is(err, domain.ErrUserConflict):
+		return response.Err(response.CodeConflict, "资料已被修改，请刷新后重试")
+	case errors.Is(err, domain.ErrEmailTaken):
+		return response.Err(response.CodeEmailRegistered, "该邮箱已被其他账号绑定")
+	default:
+		return response.Err(response.CodeDatabaseError, "更新用户失败")
+	}
+}
+
+// normalizeProfileUpdate 校验并规范化资料字段（去除首尾空白、邮箱小写）
+func normalizeProfileUpdate(upd *domain.ProfileUpdate) error {
+	if upd.UserName != nil {
+		name := strings.TrimSpace(*upd.UserName)
+		if name == "" || utf8.RuneCountInString(name) > maxUserNameLen {
+			return response.Err(response.CodeInvalidParam, "用户名长度需为 1-50 个字符")
+		}
+		if strings.ContainsFunc(name, unicode.IsControl) {
+			return response.Err(response.CodeInvalidParam, "用户名包含非法字符")
+		}
+		upd.UserName = &name
+	}
+
+	if upd.Gender != nil {
+		switch *upd.Gender {
+		case domain.GenderMale, domain.GenderFemale, domain.GenderOther:
+		default:
+			return response.Err(response.CodeInvalidParam, "性别必须是 male、female 或 other")
+		}
+	}
+
+	if upd.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*upd.Email))
+		if email != "" && !validEmail(email) {
+			return response.Err(response.CodeInvalidParam, "邮箱格式无效")
+		}
+		upd.Email = &email
+	}
+
+	if upd.AvatarURL != nil {
+		avatar := strings.TrimSpace(*upd.AvatarURL)
+		if avatar != "" && !validAvatarURL(avatar) {
+			return response.Err(response.CodeInvalidParam, "头像地址需为 http(s) 链接")
+		}
+		upd.AvatarURL = &avatar
+	}
+
+	return nil
+}
+
+// validEmail 仅接受裸地址（不含显示名）
+func validEmail(email string) bool {
+	if len(email) > maxEmailLen {
+		return false
+	}
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
+
+// validAvatarURL 校验头像地址为绝对 http(s) 链接
+func validAvatarURL(raw string) bool {
+	if len(raw) > maxAvatarURLLen {
+		return false
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" && u.User == nil
+}
@@ -0,0 +1,51 @@
+package user
+
+import (
+	"strings"
+	"testing"
+
+	domain "arch3/internal/domain/user"
+	"arch3/pkg/ptr"
+)
+
+func TestNormalizeProfileUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		upd       domain.ProfileUpdate
+		wantErr   bool
+		wantName  string
+		wantEmail string
+	}{
+		{name: "用户名去除空白", upd: domain.ProfileUpdate{UserName: ptr.Of("  张三 ")}, wantName: "张三"},
+		{name: "用户名为空", upd: domain.ProfileUpdate{UserName: ptr.Of("   ")}, wantErr: true},
+		{name: "用户名过长", upd: domain.ProfileUpdate{UserName: ptr.Of(strings.Repeat("名", 51))}, wantErr: true},
+		{name: "用户名含控制字符", upd: domain.ProfileUpdate{UserName: ptr.Of("a\nb")}, wantErr: true},
+		{name: "性别有效", upd: domain.ProfileUpdate{Gender: ptr.Of("female")}},
+		{name: "性别无效", upd: domain.ProfileUpdate{Gender: ptr.Of("unknown")}, wantErr: true},
+		{name: "邮箱转小写", upd: domain.ProfileUpdate{Email: ptr.Of("Foo@Example.com")}, wantEmail: "foo@example.com"},
+		{name: "邮箱清空", upd: domain.ProfileUpdate{Email: ptr.Of("")}, wantEmail: ""},
+		{name: "邮箱含显示名", upd: domain.ProfileUpdate{Email: ptr.Of("Foo <foo@example.com>")}, wantErr: true},
+		{name: "头像 https", upd: domain.ProfileUpdate{AvatarURL: ptr.Of("https://cdn.example.com/a.png")}},
+		{name: "头像非 http 协议", upd: domain.ProfileUpdate{AvatarURL: ptr.Of("javascript:alert(1)")}, wantErr: true},
+		{name: "头像含用户信息", upd: domain.ProfileUpdate{AvatarURL: ptr.Of("https://u:p@example.com/a.png")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upd := tt.upd
+			err := normalizeProfileUpdate(&upd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeProfileUpdate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantName != "" && ptr.Value(upd.UserName) != tt.wantName {
+				t.Errorf("Expected user name %q, got %q", tt.wantName, ptr.Value(upd.UserName))
+			}
+			if upd.Email != nil && *upd.Email != tt.wantEmail {
+				t.Errorf("Expected email %q, got %q", tt.wantEmail, *upd.Email)
+			}
+		})
+	}
+}
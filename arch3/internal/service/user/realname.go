@@ -0,0 +1,225 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/pkg/logger"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+	"arch3/pkg/tracer"
+
+	"go.uber.org/zap"
+)
+
+const (
+	realNameSubmitInterval = time.Minute // 同一用户两次提交的最小间隔
+	defaultReviewPageSize  = 20
+	maxReviewPageSize      = 100
+)
+
+// SubmitRealName 提交实名信息
+//
+// 服务商核验一致直接通过；无法判定或服务商不可用时转人工审核；不一致时拒绝且不保存。
+func (s *service) SubmitRealName(ctx context.Context, userID, realName, idNumber string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.SubmitRealName")
+	defer span.End()
+
+	realName = strings.TrimSpace(realName)
+	idNumber = strings.ToUpper(strings.TrimSpace(idNumber))
+	if !validRealName(realName) {
+		return nil, response.Err(response.CodeInvalidParam, "姓名格式无效")
+	}
+	if !validIDNumber(idNumber) {
+		return nil, response.Err(response.CodeInvalidParam, "身份证号格式无效")
+	}
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	switch u.Status {
+	case domain.StatusRealNameUnverified:
+	case domain.StatusRealNameVerified:
+		return nil, response.Err(response.CodeInvalidParam, "已完成实名认证")
+	case domain.StatusUnderReview:
+		return nil, response.Err(response.CodeConflict, "实名信息审核中，请耐心等待")
+	default:
+		return nil, response.Err(response.CodeUserDisabled, "账号状态异常，无法实名认证")
+	}
+
+	if s.realName.Cooldown != nil {
+		ok, err := s.realName.Cooldown.Acquire(ctx, "real_name:"+u.UserID, realNameSubmitInterval)
+		if err != nil {
+			tracer.RecordError(span, err)
+			return nil, response.Err(response.CodeCacheError, "提交实名信息失败")
+		}
+		if !ok {
+			return nil, response.Err(response.CodeTooManyRequests, "提交太频繁，请稍后再试")
+		}
+	}
+
+	result, err := s.realName.Verifier.Verify(ctx, realName, idNumber)
+	if err != nil {
+		// 服务商不可用不应阻断用户，转人工审核
+		tracer.RecordError(span, err)
+		logger.Ctx(ctx).Warn("identity verification failed, fallback to manual review",
+			zap.String("user_id", u.UserID),
+			zap.Error(err),
+		)
+		result = domain.IdentityUncertain
+	}
+	span.SetAttributes(tracer.String("identity.result", string(result)))
+
+	var status string
+	switch result {
+	case domain.IdentityMatch:
+		status = domain.StatusRealNameVerified
+	case domain.IdentityUncertain:
+		status = domain.StatusUnderReview
+	default:
+		return nil, response.Err(response.CodeInvalidParam, "姓名与身份证号不一致")
+	}
+
+	err = s.userRepo.UpdateRealName(ctx, u.UserID, domain.StatusRealNameUnverified, &domain.RealNameUpdate{
+		RealName: realName,
+		IDNumber: idNumber,
+		Status:   status,
+	})
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, realNameErrorToResponse(err)
+	}
+	s.markOnboarded(ctx, u.UserID)
+
+	logger.Ctx(ctx).Info("real name submitted",
+		zap.String("user_id", u.UserID),
+		zap.String("status", status),
+	)
+	return s.GetUserByID(ctx, u.UserID)
+}
+
+// ListRealNameReviews 分页查询待人工审核的用户
+func (s *service) ListRealNameReviews(ctx context.Context, afterID uint, limit int) ([]*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.ListRealNameReviews")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = defaultReviewPageSize
+	}
+	limit = min(limit, maxReviewPageSize)
+
+	users, err := s.userRepo.ListByStatus(ctx, domain.StatusUnderReview, afterID, limit)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, response.Err(response.CodeDatabaseError, "查询待审核列表失败")
+	}
+	return users, nil
+}
+
+// ReviewRealName 人工审核实名信息
+func (s *service) ReviewRealName(ctx context.Context, reviewerID, userID string, approve bool, reason string) (*domain.User, error) {
+	ctx, span := tracer.Start(ctx, "service.user.ReviewRealName")
+	defer span.End()
+
+	u, err := s.GetUserByID(ctx, userID)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return nil, err
+	}
+	if u.Status != domain.StatusUnderReview {
+		return nil, response.Err(response.CodeConflict, "该用户不在待审核状态")
+	}
+
+	// 驳回时清除已提交的信息，用户可重新提交
+	upd := &domain.RealNameUpdate{Status: domain.StatusRealNameUnverified}
+	if approve {
+		upd = &domain.RealNameUpdate{
+			RealName: ptr.Value(u.RealName),
+			IDNumber: ptr.Value(u.IDNumber),
+			Status:   domain.StatusRealNameVerified,
+		}
+	}
+
+	if err := s.userRepo.UpdateRealName(ctx, u.UserID, domain.StatusUnderReview, upd); err != nil {
+		tracer.RecordError(span, err)
+		if errors.Is(err, domain.ErrUserConflict) {
+			return nil, response.Err(response.CodeConflict, "该用户不在待审核状态")
+		}
+		return nil, realNameErrorToResponse(err)
+	}
+
+	logger.Ctx(ctx).Info("real name reviewed",
+		zap.String("user_id", u.UserID),
+		zap.String("reviewer_id", reviewerID),
+		zap.Bool("approved", approve),
+		zap.String("reason", reason),
+	)
+
+	vars := map[string]string{"result": "未通过", "result_en": "rejected", "reason": reason}
+	if approve {
+		vars = map[string]string{"result": "已通过", "result_en": "approved", "reason": ""}
+	}
+	s.notify(ctx, u, notification.TemplateRealNameReview, vars)
+
+	return s.GetUserByID(ctx, u.UserID)
+}
+
+// realNameErrorToResponse 将实名信息更新错误转换为业务响应
+func realNameErrorToResponse(err error) *response.Result {
+	switch {
+	case errors.Is(err, domain.ErrUserNotFound):
+		return response.Err(response.CodeUserNotFound, "用户不存在")
+	case errors.Is(err, domain.ErrUserConflict):
+		return response.Err(response.CodeConflict, "实名状态已变更，请刷新后重试")
+	case errors.Is(err, domain.ErrIDNumberTaken):
+		return response.Err(response.CodeAlreadyExists, "该身份证号已被其他账号认证")
+	default:
+		return response.Err(response.CodeDatabaseError, "更新实名信息失败")
+	}
+}
+
+// validRealName 姓名 2-50 个字符，不含数字与控制字符（允许少数民族姓名中的间隔号）
+func validRealName(name string) bool {
+	n := utf8.RuneCountInString(name)
+	if n < 2 || n > 50 {
+		return false
+	}
+	return !strings.ContainsFunc(name, func(r rune) bool {
+		return unicode.IsControl(r) || unicode.IsDigit(r)
+	})
+}
+
+// idNumberWeights 身份证号前 17 位加权因子（GB 11643-1999）
+var idNumberWeights = [17]int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+
+// idNumberCheckCodes 加权和模 11 对应的校验码
+const idNumberCheckCodes = "10X98765432"
+
+// validIDNumber 校验 18 位居民身份证号: 出生日期与校验码
+func validIDNumber(id string) bool {
+	if len(id) != 18 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 17; i++ {
+		c := id[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		sum += int(c-'0') * idNumberWeights[i]
+	}
+	if id[17] != idNumberCheckCodes[sum%11] {
+		return false
+	}
+
+	birth, err := time.Parse("20060102", id[6:14])
+	return err == nil && birth.Year() >= 1900 && !birth.After(time.Now())
+}
@@ -0,0 +1,118 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	domain "arch3/internal/domain/user"
+	"arch3/internal/service/notification"
+	"arch3/internal/service/user/usertest"
+	"arch3/pkg/ptr"
+	"arch3/pkg/response"
+)
+
+// stubVerifier 固定返回结果的核验器
+type stubVerifier struct {
+	result domain.IdentityResult
+	err    error
+}
+
+func (v *stubVerifier) Verify(context.Context, string, string) (domain.IdentityResult, error) {
+	return v.result, v.err
+}
+
+// nopNotifier 忽略所有通知
+type nopNotifier struct{}
+
+func (nopNotifier) Notify(context.Context, *notification.Request) error { return nil }
+
+// validTestIDNumber 校验码正确的测试身份证号
+const validTestIDNumber = "11010519491231002X"
+
+// pendingTestIDNumber 待审核用户 u2 提交的身份证号
+const pendingTestIDNumber = "110101199003070011"
+
+func newRealNameTestService(v IdentityVerifier) (*service, *usertest.Repository) {
+	repo := usertest.NewRepository(
+		&domain.User{ID: 1, UserID: "u1", Status: domain.StatusRealNameUnverified},
+		&domain.User{ID: 2, UserID: "u2", Status: domain.StatusUnderReview, RealName: ptr.Of("李四"), IDNumber: ptr.Of(pendingTestIDNumber)},
+		&domain.User{ID: 3, UserID: "u3", Status: domain.StatusBanned},
+	)
+	return &service{tx: directTx{}, userRepo: repo, notifier: nopNotifier{}, realName: RealNameVerification{Verifier: v}}, repo
+}
+
+func TestSubmitRealName(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		realName   string
+		idNumber   string
+		verifier   *stubVerifier
+		wantCode   int
+		wantStatus string
+	}{
+		{"核验一致直接通过", "u1", "张三", validTestIDNumber, &stubVerifier{result: domain.IdentityMatch}, response.CodeSuccess, domain.StatusRealNameVerified},
+		{"小写 x 规范化", "u1", "张三", "11010519491231002x", &stubVerifier{result: domain.IdentityMatch}, response.CodeSuccess, domain.StatusRealNameVerified},
+		{"无法判定转人工", "u1", "张三", validTestIDNumber, &stubVerifier{result: domain.IdentityUncertain}, response.CodeSuccess, domain.StatusUnderReview},
+		{"服务商错误转人工", "u1", "张三", validTestIDNumber, &stubVerifier{err: errors.New("timeout")}, response.CodeSuccess, domain.StatusUnderReview},
+		{"核验不一致", "u1", "张三", validTestIDNumber, &stubVerifier{result: domain.IdentityMismatch}, response.CodeInvalidParam, domain.StatusRealNameUnverified},
+		{"校验码错误", "u1", "张三", "110105194912310021", &stubVerifier{result: domain.IdentityMatch}, response.CodeInvalidParam, domain.StatusRealNameUnverified},
+		{"姓名含数字", "u1", "张3", validTestIDNumber, &stubVerifier{result: domain.IdentityMatch}, response.CodeInvalidParam, domain.StatusRealNameUnverified},
+		{"身份证号已被其他账号使用", "u1", "张三", pendingTestIDNumber, &stubVerifier{result: domain.IdentityMatch}, response.CodeAlreadyExists, domain.StatusRealNameUnverified},
+		{"审核中不可重复提交", "u2", "张三", validTestIDNumber, &stubVerifier{result: domain.IdentityMatch}, response.CodeConflict, domain.StatusUnderReview},
+		{"封禁用户", "u3", "张三", validTestIDNumber, &stubVerifier{result: domain.IdentityMatch}, response.CodeUserDisabled, domain.StatusBanned},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, repo := newRealNameTestService(tt.verifier)
+			_, err := s.SubmitRealName(context.Background(), tt.userID, tt.realName, tt.idNumber)
+
+			got := response.CodeSuccess
+			if err != nil {
+				got = response.CodeFromError(err)
+			}
+			if got != tt.wantCode {
+				t.Errorf("Expected code %d, got %d (err=%v)", tt.wantCode, got, err)
+			}
+			u := findUser(repo, tt.userID)
+			if u.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, u.Status)
+			}
+			if tt.wantCode == response.CodeSuccess && ptr.Value(u.IDNumber) != validTestIDNumber {
+				t.Errorf("Expected normalized ID number stored, got %q", ptr.Value(u.IDNumber))
+			}
+		})
+	}
+}
+
+func TestReviewRealName(t *testing.T) {
+	ctx := context.Background()
+
+	s, repo := newRealNameTestService(&stubVerifier{})
+	pending, err := s.ListRealNameReviews(ctx, 0, 0)
+	if err != nil || len(pending) != 1 || pending[0].UserID != "u2" {
+		t.Fatalf("Expected u2 pending, got %v, %v", pending, err)
+	}
+
+	if _, err := s.ReviewRealName(ctx, "admin", "u2", true, ""); err != nil {
+		t.Fatalf("ReviewRealName() error = %v", err)
+	}
+	if u := findUser(repo, "u2"); u.Status != domain.StatusRealNameVerified || ptr.Value(u.RealName) != "李四" {
+		t.Errorf("Expected u2 verified with name kept, got %s %q", u.Status, ptr.Value(u.RealName))
+	}
+
+	// 已审核的用户不可再次审核
+	if _, err := s.ReviewRealName(ctx, "admin", "u2", false, ""); response.CodeFromError(err) != response.CodeConflict {
+		t.Errorf("Expected conflict on second review, got %v", err)
+	}
+
+	s, repo = newRealNameTestService(&stubVerifier{})
+	if _, err := s.ReviewRealName(ctx, "admin", "u2", false, "证件信息不清晰"); err != nil {
+		t.Fatalf("ReviewRealName() error = %v", err)
+	}
+	if u := findUser(repo, "u2"); u.Status != domain.StatusRealNameUnverified || u.RealName != nil || u.IDNumber != nil {
+		t.Errorf("Expected rejected submission cleared, got %s %v %v", u.Status, u.RealName, u.IDNumber)
+	}
+}
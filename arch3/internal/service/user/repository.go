@@ -2,6 +2,7 @@ package user
 
 import (
 	"context"
+	"time"
 
 	domain "arch3/internal/domain/user"
 )
@@ -14,6 +15,75 @@ type Repository interface {
 	FindByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error)
 	// Create 创建用户
 	Create(ctx context.Context, user *domain.User) error
-	// Update 更新用户
+	// Update 写入全部可更新字段，仅当数据库中的版本号等于 user.Version 时写入
+	// 版本不符返回 domain.ErrUserConflict；成功后 user.Version 递增
 	Update(ctx context.Context, user *domain.User) error
+	// UpdateFields 只写入 fields 指定的字段，版本号前置条件与 Update 相同
+	// 手机号、已验证邮箱、身份证号已被占用分别返回 domain.ErrPhoneTaken、ErrEmailTaken、ErrIDNumberTaken
+	UpdateFields(ctx context.Context, user *domain.User, fields ...domain.Field) error
+	// UpdateProfile 部分更新用户资料，仅写入 upd 中非 nil 的字段
+	// 版本号与 upd.Version 不一致时返回 domain.ErrUserConflict，成功后版本号递增
+	// 写入已验证的邮箱且已被其他账号验证时返回 domain.ErrEmailTaken，未验证的邮箱不检查唯一性
+	UpdateProfile(ctx context.Context, userID string, upd *domain.ProfileUpdate) error
+	// UpdatePhoneNumber 条件更新手机号，仅当当前手机号为 oldPhone 时写入
+	// 新手机号已被占用返回 domain.ErrPhoneTaken，当前手机号不符返回 domain.ErrUserConflict
+	UpdatePhoneNumber(ctx context.Context, userID, oldPhone, newPhone string) error
+	// MarkEmailVerified 标记邮箱已验证，仅当当前邮箱为 email 时写入
+	// 当前邮箱不符返回 domain.ErrUserConflict，邮箱已被其他账号验证返回 domain.ErrEmailTaken
+	MarkEmailVerified(ctx context.Context, userID, email string) error
+	// UpdateRealName 更新实名信息与状态，仅当当前状态为 fromStatus 时写入
+	// 当前状态不符返回 domain.ErrUserConflict
+	UpdateRealName(ctx context.Context, userID, fromStatus string, upd *domain.RealNameUpdate) error
+	// ListByStatus 按 ID 升序分页查询指定状态的用户，afterID 为上一页最后一条的 ID
+	ListByStatus(ctx context.Context, status string, afterID uint, limit int) ([]*domain.User, error)
+
+	// ScheduleDeletion 申请注销，冷静期至 scheduledAt 结束
+	// 已申请注销返回 domain.ErrUserConflict
+	ScheduleDeletion(ctx context.Context, userID string, scheduledAt time.Time) error
+	// ScheduleInactiveDeletion 未完成引导的不活跃用户转入注销流程，冷静期至 scheduledAt 结束
+	// 仅当用户仍未完成引导、未申请注销且最近活跃早于 before 时写入，否则返回 domain.ErrUserConflict
+	ScheduleInactiveDeletion(ctx context.Context, userID string, before, scheduledAt time.Time) error
+	// ListInactive 按 ID 升序查询未完成引导、未申请注销且最近活跃早于 before 的用户
+	// 未记录最近活跃时间的用户不在结果中
+	ListInactive(ctx context.Context, before time.Time, limit int) ([]*domain.User, error)
+	// TouchLastActive 记录最近活跃时间，距上次记录不足一小时时不写入
+	// 不递增版本号、不修改 UpdatedAt，用户不存在时不报错
+	TouchLastActive(ctx context.Context, userID string, at time.Time) error
+	// MarkOnboarded 记录完成引导时间，已完成引导时不修改
+	// 不递增版本号、不修改 UpdatedAt，用户不存在时不报错
+	MarkOnboarded(ctx context.Context, userID string, at time.Time) error
+	// CancelDeletion 撤销注销申请，未申请或冷静期已结束返回 domain.ErrUserConflict
+	CancelDeletion(ctx context.Context, userID string) error
+	// ListDueDeletions 查询冷静期在 before 之前结束的用户
+	ListDueDeletions(ctx context.Context, before time.Time, limit int) ([]*domain.User, error)
+	// Anonymize 匿名化并软删除冷静期已结束的用户，之后按 ID 或手机号均查询不到
+	// 冷静期未结束（含已撤销）返回 domain.ErrUserConflict
+	Anonymize(ctx context.Context, userID string) error
+	// Search 按条件分页查询用户，按 UserID 倒序
+	Search(ctx context.Context, f *domain.UserFilter) ([]*domain.User, error)
+	// ChangeStatus 变更用户状态并写入变更记录
+	// 当前状态不是 change.FromStatus 返回 domain.ErrUserConflict
+	ChangeStatus(ctx context.Context, change *domain.StatusChange) error
+	// ListStatusChanges 查询用户最近的状态变更记录，按时间倒序
+	ListStatusChanges(ctx context.Context, userID string, limit int) ([]*domain.StatusChange, error)
+}
+
+// LoginHistoryRepository 登录记录仓储接口（由使用方定义）
+type LoginHistoryRepository interface {
+	// Create 写入登录记录
+	Create(ctx context.Context, rec *domain.LoginRecord) error
+	// ListByUser 查询用户最近的登录记录，按时间倒序
+	ListByUser(ctx context.Context, userID string, limit int) ([]*domain.LoginRecord, error)
+	// DeleteByUser 删除用户的全部登录记录
+	DeleteByUser(ctx context.Context, userID string) error
+}
+
+// PhoneChangeTicketStore 更换手机号凭证存储（由使用方定义）
+type PhoneChangeTicketStore interface {
+	// Save 保存凭证，ttl 后过期
+	Save(ctx context.Context, ticket string, t *domain.PhoneChangeTicket, ttl time.Duration) error
+	// Get 查询凭证，不存在或已过期返回 domain.ErrTicketInvalid
+	Get(ctx context.Context, ticket string) (*domain.PhoneChangeTicket, error)
+	// Take 原子取出并删除凭证，保证凭证只能使用一次
+	Take(ctx context.Context, ticket string) (*domain.PhoneChangeTicket, error)
 }
@@ -1,21 +1,53 @@
 package user
 
 import (
+	"context"
+
+	"arch3/internal/service/common"
 	"arch3/pkg/jwt"
 )
 
 // service 用户服务实现
 type service struct {
-	smsClient  SMSClient
+	otpClient  OTPClient
+	tx         common.Transactor
+	events     common.EventPublisher
 	userRepo   Repository
+	logins     LoginHistoryRepository
 	jwtManager *jwt.Manager
+	notifier   Notifier
+	storage    ObjectStorage
+
+	phoneTickets PhoneChangeTicketStore
+	email        EmailVerification
+	realName     RealNameVerification
+	account      AccountDeletion
 }
 
 // NewService 创建用户服务实例
-func NewService(smsClient SMSClient, userRepo Repository, jwtManager *jwt.Manager) Service {
+func NewService(otpClient OTPClient, tx common.Transactor, events common.EventPublisher, userRepo Repository, logins LoginHistoryRepository, jwtManager *jwt.Manager, notifier Notifier, storage ObjectStorage, phoneTickets PhoneChangeTicketStore, email EmailVerification, realName RealNameVerification, account AccountDeletion) Service {
 	return &service{
-		smsClient:  smsClient,
+		otpClient:  otpClient,
+		tx:         tx,
+		events:     events,
 		userRepo:   userRepo,
+		logins:     logins,
 		jwtManager: jwtManager,
+		notifier:   notifier,
+		storage:    storage,
+
+		phoneTickets: phoneTickets,
+		email:        email,
+		realName:     realName,
+		account:      account,
+	}
+}
+
+// publishEvent 写入用户领域事件，应在业务写入所在的事务中调用
+func (s *service) publishEvent(ctx context.Context, eventType, userID string, payload any) error {
+	event, err := common.NewEvent(eventType, userID, payload)
+	if err != nil {
+		return err
 	}
+	return s.events.Publish(ctx, event)
 }
@@ -2,19 +2,77 @@ package user
 
 import (
 	"context"
+	"errors"
 
+	domain "arch3/internal/domain/user"
+	"arch3/pkg/ptr"
 	"arch3/pkg/tracer"
 )
 
-// SendSMS 发送短信验证码
-func (s *service) SendSMS(ctx context.Context, phoneNumber, smsType string) error {
+// SendSMS 发送验证码
+//
+// 验证码始终与手机号绑定，channel 仅决定投递方式:
+//   - sms/voice: 直接投递到该手机号
+//   - email: 投递到该手机号对应账号绑定的邮箱
+//
+// 投递为异步，返回的投递 ID 可用于 GetSMSStatus 查询结果。
+func (s *service) SendSMS(ctx context.Context, phoneNumber, smsType, channel string) (string, error) {
 	ctx, span := tracer.Start(ctx, "service.user.SendSMS")
 	defer span.End()
 
-	if err := s.smsClient.Send(ctx, SMSType(smsType), phoneNumber); err != nil {
+	req := &OTPSendRequest{
+		Channel: OTPChannel(channel),
+		Type:    SMSType(smsType),
+		Target:  phoneNumber,
+	}
+	if req.Channel == "" {
+		req.Channel = OTPChannelSMS
+	}
+
+	if req.Channel == OTPChannelEmail {
+		address, err := s.boundEmail(ctx, phoneNumber)
+		if err != nil {
+			tracer.RecordError(span, err)
+			return "", SMSToResponse(err)
+		}
+		req.Address = address
+	}
+
+	dispatchID, err := s.otpClient.Send(ctx, req)
+	if err != nil {
+		tracer.RecordError(span, err)
+		return "", SMSToResponse(err)
+	}
+
+	return dispatchID, nil
+}
+
+// GetSMSStatus 查询验证码投递状态
+func (s *service) GetSMSStatus(ctx context.Context, dispatchID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "service.user.GetSMSStatus")
+	defer span.End()
+
+	status, err := s.otpClient.DispatchStatus(ctx, dispatchID)
+	if err != nil {
 		tracer.RecordError(span, err)
-		return SMSToResponse(err)
+		return "", SMSToResponse(err)
 	}
 
-	return nil
+	return string(status), nil
+}
+
+// boundEmail 查询手机号对应账号绑定且已验证的邮箱
+// 用户不存在、未绑定或未验证邮箱统一返回 ErrOTPChannelUnavailable
+func (s *service) boundEmail(ctx context.Context, phoneNumber string) (string, error) {
+	u, err := s.userRepo.FindByPhoneNumber(ctx, phoneNumber)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return "", ErrOTPChannelUnavailable
+		}
+		return "", err
+	}
+	if ptr.Value(u.Email) == "" || !u.EmailVerified {
+		return "", ErrOTPChannelUnavailable
+	}
+	return *u.Email, nil
 }
@@ -0,0 +1,13 @@
+package user
+
+import "context"
+
+// ObjectStorage 对象存储接口（由使用方定义）
+type ObjectStorage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// URL 返回对象的公开访问地址
+	URL(key string) string
+}
@@ -0,0 +1,70 @@
+package usertest
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	domain "arch3/internal/domain/user"
+)
+
+// LoginHistory 内存登录记录仓储，实现 user.LoginHistoryRepository
+type LoginHistory struct {
+	mu      sync.Mutex
+	nextID  uint
+	records []*domain.LoginRecord
+}
+
+// NewLoginHistory 创建内存登录记录仓储
+func NewLoginHistory() *LoginHistory {
+	return &LoginHistory{}
+}
+
+// Create 写入登录记录，未设置 CreatedAt 时使用当前时间
+func (h *LoginHistory) Create(_ context.Context, rec *domain.LoginRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	rec.ID = h.nextID
+	if rec.CreatedAt.IsZero() {
+		rec.CreatedAt = time.Now()
+	}
+	cp := *rec
+	h.records = append(h.records, &cp)
+	return nil
+}
+
+// ListByUser 查询用户最近的登录记录，按时间倒序
+func (h *LoginHistory) ListByUser(_ context.Context, userID string, limit int) ([]*domain.LoginRecord, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	records := make([]*domain.LoginRecord, 0)
+	for _, r := range h.records {
+		if r.UserID == userID {
+			cp := *r
+			records = append(records, &cp)
+		}
+	}
+	slices.SortFunc(records, func(a, b *domain.LoginRecord) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// DeleteByUser 删除用户的全部登录记录
+func (h *LoginHistory) DeleteByUser(_ context.Context, userID string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = slices.DeleteFunc(h.records, func(r *domain.LoginRecord) bool {
+		return r.UserID == userID
+	})
+	return nil
+}
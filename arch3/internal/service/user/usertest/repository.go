@@ -0,0 +1,612 @@
+// Package usertest 用户模块仓储接口的内存实现，供测试使用
+//
+// 行为与数据库实现一致（版本号、条件更新、唯一约束、软删除等），
+// 由 internal/repository/repotest 中的契约测试保证，测试中可直接替代数据库实现。
+// 本包只依赖领域模型，用户服务自身的测试也可以使用。
+package usertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	domain "arch3/internal/domain/user"
+)
+
+// ErrDuplicate 违反唯一约束（用户 ID、手机号、邮箱、身份证号）
+var ErrDuplicate = errors.New("usertest: duplicate key")
+
+// ErrInvalidValue 状态或性别取值非法，对应数据库的 CHECK 约束
+var ErrInvalidValue = errors.New("usertest: invalid value")
+
+var (
+	validStatuses = []string{domain.StatusRealNameUnverified, domain.StatusUnderReview, domain.StatusRealNameVerified, domain.StatusBanned}
+	validGenders  = []string{domain.GenderMale, domain.GenderFemale, domain.GenderOther}
+)
+
+// record 存储的用户，匿名化后软删除，保留用户 ID 的唯一约束
+type record struct {
+	user    domain.User
+	deleted bool
+}
+
+// Repository 内存用户仓储，实现 user.Repository
+type Repository struct {
+	mu      sync.Mutex
+	nextID  uint
+	users   map[string]*record
+	changes []*domain.StatusChange
+}
+
+// NewRepository 创建内存用户仓储，users 为预置数据
+// 预置用户不做约束检查；未设置的 ID 按顺序分配，性别、状态、版本号按数据库默认值补齐，其余字段原样保存
+func NewRepository(users ...*domain.User) *Repository {
+	r := &Repository{users: make(map[string]*record)}
+	for _, u := range users {
+		cp := *u
+		if cp.ID == 0 {
+			r.nextID++
+			cp.ID = r.nextID
+		}
+		r.nextID = max(r.nextID, cp.ID)
+		setDefaults(&cp)
+		if cp.Version == 0 {
+			cp.Version = 1
+		}
+		r.users[cp.UserID] = &record{user: cp}
+	}
+	return r
+}
+
+// now 写入时间，与数据库实现一致截断到毫秒
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
+
+// find 查询未删除的用户，调用方须持有锁
+func (r *Repository) find(userID string) (*domain.User, bool) {
+	rec, ok := r.users[userID]
+	if !ok || rec.deleted {
+		return nil, false
+	}
+	return &rec.user, true
+}
+
+// taken 其他未删除用户是否已使用该值，调用方须持有锁
+func (r *Repository) taken(userID string, match func(u *domain.User) bool) bool {
+	for id, rec := range r.users {
+		if id != userID && !rec.deleted && match(&rec.user) {
+			return true
+		}
+	}
+	return false
+}
+
+// emailTaken 其他用户是否已验证该邮箱，对应只作用于已验证邮箱的唯一索引，调用方须持有锁
+func (r *Repository) emailTaken(userID string, email *string) bool {
+	return r.taken(userID, func(o *domain.User) bool { return o.EmailVerified && samePtr(o.Email, email) })
+}
+
+func samePtr(a, b *string) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// checkUnique 检查手机号、邮箱、身份证号的唯一约束
+func (r *Repository) checkUnique(u *domain.User) error {
+	switch {
+	case r.taken(u.UserID, func(o *domain.User) bool { return o.PhoneNumber != "" && o.PhoneNumber == u.PhoneNumber }):
+		return domain.ErrPhoneTaken
+	case u.EmailVerified && r.emailTaken(u.UserID, u.Email):
+		return domain.ErrEmailTaken
+	case r.taken(u.UserID, func(o *domain.User) bool { return samePtr(o.IDNumber, u.IDNumber) }):
+		return domain.ErrIDNumberTaken
+	}
+	return nil
+}
+
+// setDefaults 对应数据库的列默认值
+func setDefaults(u *domain.User) {
+	if u.Gender == "" {
+		u.Gender = domain.GenderOther
+	}
+	if u.Status == "" {
+		u.Status = domain.StatusRealNameUnverified
+	}
+}
+
+// checkValues 对应数据库的 CHECK 约束
+func checkValues(u *domain.User) error {
+	if !slices.Contains(validStatuses, u.Status) || !slices.Contains(validGenders, u.Gender) {
+		return fmt.Errorf("%w: status %q, gender %q", ErrInvalidValue, u.Status, u.Gender)
+	}
+	return nil
+}
+
+// FindByUserID 根据业务 ID 查询用户
+func (r *Repository) FindByUserID(_ context.Context, userID string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.find(userID)
+	if !ok {
+		return nil, domain.ErrUserNotFound
+	}
+	return clone(u), nil
+}
+
+// FindByPhoneNumber 根据手机号查询用户
+func (r *Repository) FindByPhoneNumber(_ context.Context, phoneNumber string) (*domain.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rec := range r.users {
+		if !rec.deleted && rec.user.PhoneNumber == phoneNumber {
+			return clone(&rec.user), nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+// Create 创建用户，违反唯一约束时返回错误
+func (r *Repository) Create(_ context.Context, u *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[u.UserID]; ok {
+		return fmt.Errorf("%w: user_id %s", ErrDuplicate, u.UserID)
+	}
+	cp := *u
+	setDefaults(&cp)
+	if err := checkValues(&cp); err != nil {
+		return err
+	}
+	if err := r.checkUnique(&cp); err != nil {
+		return fmt.Errorf("%w: %w", ErrDuplicate, err)
+	}
+
+	ts := now()
+	if cp.CreatedAt.IsZero() {
+		cp.CreatedAt = ts
+	}
+	if cp.UpdatedAt.IsZero() {
+		cp.UpdatedAt = ts
+	}
+	cp.Version = 1
+	r.nextID++
+	cp.ID = r.nextID
+	r.users[cp.UserID] = &record{user: cp}
+
+	u.CreatedAt, u.UpdatedAt, u.Version = cp.CreatedAt, cp.UpdatedAt, cp.Version
+	return nil
+}
+
+// Update 以 u.Version 为前置条件写入全部可更新字段
+func (r *Repository) Update(ctx context.Context, u *domain.User) error {
+	return r.UpdateFields(ctx, u, domain.UpdatableFields...)
+}
+
+// UpdateFields 以 u.Version 为前置条件只写入 fields 指定的字段
+func (r *Repository) UpdateFields(_ context.Context, u *domain.User, fields ...domain.Field) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(u.UserID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.Version != u.Version {
+		return domain.ErrUserConflict
+	}
+
+	next := *stored
+	for _, f := range fields {
+		if err := copyField(&next, u, f); err != nil {
+			return err
+		}
+	}
+	if err := checkValues(&next); err != nil {
+		return err
+	}
+	if err := r.checkUnique(&next); err != nil {
+		return err
+	}
+	r.write(stored, &next)
+	u.Version, u.UpdatedAt = stored.Version, stored.UpdatedAt
+	return nil
+}
+
+// copyField 复制单个字段，对应数据库实现的 fieldColumns
+func copyField(dst, src *domain.User, f domain.Field) error {
+	switch f {
+	case domain.FieldUserName:
+		dst.UserName = src.UserName
+	case domain.FieldRealName:
+		dst.RealName = nonEmpty(src.RealName)
+	case domain.FieldPasswordHash:
+		dst.PasswordHash = src.PasswordHash
+	case domain.FieldEmail:
+		dst.Email = nonEmpty(src.Email)
+	case domain.FieldEmailVerified:
+		dst.EmailVerified = src.EmailVerified
+	case domain.FieldPhoneNumber:
+		dst.PhoneNumber = src.PhoneNumber
+	case domain.FieldAvatarURL:
+		dst.AvatarURL = nonEmpty(src.AvatarURL)
+	case domain.FieldGender:
+		dst.Gender = src.Gender
+	case domain.FieldStatus:
+		dst.Status = src.Status
+	case domain.FieldIDNumber:
+		dst.IDNumber = nonEmpty(src.IDNumber)
+	case domain.FieldSource:
+		dst.Source = nonEmpty(src.Source)
+	case domain.FieldDeviceID:
+		dst.DeviceID = nonEmpty(src.DeviceID)
+	case domain.FieldDeletionScheduledAt:
+		if src.DeletionScheduledAt == nil {
+			dst.DeletionScheduledAt = nil
+		} else {
+			t := src.DeletionScheduledAt.UTC().Truncate(time.Millisecond)
+			dst.DeletionScheduledAt = &t
+		}
+	default:
+		return fmt.Errorf("unknown user field %q", f)
+	}
+	return nil
+}
+
+// write 保存修改后的用户，递增版本号并更新写入时间，调用方须持有锁
+func (r *Repository) write(stored, next *domain.User) {
+	next.Version = stored.Version + 1
+	next.UpdatedAt = now()
+	*stored = *next
+}
+
+// UpdateProfile 部分更新用户资料，以 upd.Version 为前置条件
+func (r *Repository) UpdateProfile(_ context.Context, userID string, upd *domain.ProfileUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.Version != upd.Version {
+		return domain.ErrUserConflict
+	}
+
+	next := *stored
+	if upd.UserName != nil {
+		next.UserName = *upd.UserName
+	}
+	if upd.Gender != nil {
+		next.Gender = *upd.Gender
+	}
+	if upd.Email != nil {
+		next.Email = nonEmpty(upd.Email)
+	}
+	if upd.EmailVerified != nil {
+		next.EmailVerified = *upd.EmailVerified
+	}
+	if upd.AvatarURL != nil {
+		next.AvatarURL = nonEmpty(upd.AvatarURL)
+	}
+	if err := checkValues(&next); err != nil {
+		return err
+	}
+	if next.EmailVerified && r.emailTaken(userID, next.Email) {
+		return domain.ErrEmailTaken
+	}
+	r.write(stored, &next)
+	return nil
+}
+
+// UpdatePhoneNumber 条件更新手机号，仅当当前手机号为 oldPhone 时写入
+func (r *Repository) UpdatePhoneNumber(_ context.Context, userID, oldPhone, newPhone string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.PhoneNumber != oldPhone {
+		return domain.ErrUserConflict
+	}
+	if r.taken(userID, func(o *domain.User) bool { return o.PhoneNumber == newPhone }) {
+		return domain.ErrPhoneTaken
+	}
+	next := *stored
+	next.PhoneNumber = newPhone
+	r.write(stored, &next)
+	return nil
+}
+
+// MarkEmailVerified 标记邮箱已验证，仅当当前邮箱为 email 时写入
+func (r *Repository) MarkEmailVerified(_ context.Context, userID, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.Email == nil || *stored.Email != email {
+		return domain.ErrUserConflict
+	}
+	if r.emailTaken(userID, stored.Email) {
+		return domain.ErrEmailTaken
+	}
+	next := *stored
+	next.EmailVerified = true
+	r.write(stored, &next)
+	return nil
+}
+
+// UpdateRealName 更新实名信息与状态，仅当当前状态为 fromStatus 时写入
+func (r *Repository) UpdateRealName(_ context.Context, userID, fromStatus string, upd *domain.RealNameUpdate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.Status != fromStatus {
+		return domain.ErrUserConflict
+	}
+	next := *stored
+	next.RealName = nonEmpty(&upd.RealName)
+	next.IDNumber = nonEmpty(&upd.IDNumber)
+	next.Status = upd.Status
+	if err := checkValues(&next); err != nil {
+		return err
+	}
+	if r.taken(userID, func(o *domain.User) bool { return samePtr(o.IDNumber, next.IDNumber) }) {
+		return domain.ErrIDNumberTaken
+	}
+	r.write(stored, &next)
+	return nil
+}
+
+// ListByStatus 按 ID 升序分页查询指定状态的用户
+func (r *Repository) ListByStatus(_ context.Context, status string, afterID uint, limit int) ([]*domain.User, error) {
+	return r.list(func(u *domain.User) bool {
+		return u.Status == status && u.ID > afterID
+	}, func(a, b *domain.User) int {
+		return compareID(a, b)
+	}, limit), nil
+}
+
+// ScheduleDeletion 申请注销，已申请注销返回 domain.ErrUserConflict
+func (r *Repository) ScheduleDeletion(_ context.Context, userID string, scheduledAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.DeletionScheduledAt != nil {
+		return domain.ErrUserConflict
+	}
+	next := *stored
+	t := scheduledAt.UTC().Truncate(time.Millisecond)
+	next.DeletionScheduledAt = &t
+	r.write(stored, &next)
+	return nil
+}
+
+// inactive 未完成引导、未申请注销且最近活跃早于 before，对应数据库实现的 inactiveCondition
+func inactive(u *domain.User, before time.Time) bool {
+	return u.OnboardedAt == nil && u.DeletionScheduledAt == nil && u.LastActiveAt != nil && u.LastActiveAt.Before(before)
+}
+
+// ScheduleInactiveDeletion 未完成引导的不活跃用户转入注销流程，条件不满足返回 domain.ErrUserConflict
+func (r *Repository) ScheduleInactiveDeletion(_ context.Context, userID string, before, scheduledAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if !inactive(stored, before) {
+		return domain.ErrUserConflict
+	}
+	next := *stored
+	t := scheduledAt.UTC().Truncate(time.Millisecond)
+	next.DeletionScheduledAt = &t
+	r.write(stored, &next)
+	return nil
+}
+
+// ListInactive 按 ID 升序查询未完成引导、未申请注销且最近活跃早于 before 的用户
+func (r *Repository) ListInactive(_ context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	return r.list(func(u *domain.User) bool {
+		return inactive(u, before)
+	}, compareID, limit), nil
+}
+
+// TouchLastActive 记录最近活跃时间，距上次记录不足一小时时不写入，不递增版本号
+func (r *Repository) TouchLastActive(_ context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return nil
+	}
+	at = at.UTC().Truncate(time.Millisecond)
+	if stored.LastActiveAt == nil || stored.LastActiveAt.Before(at.Add(-time.Hour)) {
+		stored.LastActiveAt = &at
+	}
+	return nil
+}
+
+// MarkOnboarded 记录完成引导时间，已完成引导时不修改，不递增版本号
+func (r *Repository) MarkOnboarded(_ context.Context, userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok || stored.OnboardedAt != nil {
+		return nil
+	}
+	at = at.UTC().Truncate(time.Millisecond)
+	stored.OnboardedAt = &at
+	return nil
+}
+
+// CancelDeletion 撤销注销申请，未申请或冷静期已结束返回 domain.ErrUserConflict
+func (r *Repository) CancelDeletion(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(userID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.DeletionScheduledAt == nil || !stored.DeletionScheduledAt.After(now()) {
+		return domain.ErrUserConflict
+	}
+	next := *stored
+	next.DeletionScheduledAt = nil
+	r.write(stored, &next)
+	return nil
+}
+
+// ListDueDeletions 查询冷静期在 before 之前结束的用户，按注销时间升序
+func (r *Repository) ListDueDeletions(_ context.Context, before time.Time, limit int) ([]*domain.User, error) {
+	return r.list(func(u *domain.User) bool {
+		return u.DeletionScheduledAt != nil && !u.DeletionScheduledAt.After(before)
+	}, func(a, b *domain.User) int {
+		if c := a.DeletionScheduledAt.Compare(*b.DeletionScheduledAt); c != 0 {
+			return c
+		}
+		return compareID(a, b)
+	}, limit), nil
+}
+
+// Anonymize 匿名化并软删除冷静期已结束的用户
+// 清除个人信息后手机号、邮箱、身份证号可被重新使用，用户 ID 仍保留
+func (r *Repository) Anonymize(_ context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.users[userID]
+	if !ok || rec.deleted {
+		return domain.ErrUserNotFound
+	}
+	u := rec.user
+	if u.DeletionScheduledAt == nil || u.DeletionScheduledAt.After(now()) {
+		return domain.ErrUserConflict
+	}
+	rec.user = domain.User{
+		ID:        u.ID,
+		UserID:    u.UserID,
+		UserName:  domain.DeletedUserName,
+		Gender:    domain.GenderOther,
+		Status:    u.Status,
+		Source:    u.Source,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: now(),
+		Version:   u.Version + 1,
+	}
+	rec.deleted = true
+	return nil
+}
+
+// Search 按条件分页查询用户，按 UserID 倒序
+func (r *Repository) Search(_ context.Context, f *domain.UserFilter) ([]*domain.User, error) {
+	return r.list(func(u *domain.User) bool {
+		switch {
+		case len(f.Phone) == 4 && !strings.HasSuffix(u.PhoneNumber, f.Phone),
+			f.Phone != "" && len(f.Phone) != 4 && u.PhoneNumber != f.Phone,
+			!strings.HasPrefix(u.UserName, f.UserName),
+			f.Status != "" && u.Status != f.Status,
+			f.Source != "" && (u.Source == nil || *u.Source != f.Source),
+			f.GroupID != "" && (u.GroupID == nil || *u.GroupID != f.GroupID),
+			f.CreatedFrom != nil && u.CreatedAt.Before(*f.CreatedFrom),
+			f.CreatedTo != nil && !u.CreatedAt.Before(*f.CreatedTo),
+			f.Cursor != "" && u.UserID >= f.Cursor:
+			return false
+		}
+		return true
+	}, func(a, b *domain.User) int {
+		return strings.Compare(b.UserID, a.UserID)
+	}, f.Limit), nil
+}
+
+// ChangeStatus 变更用户状态并写入变更记录
+func (r *Repository) ChangeStatus(_ context.Context, change *domain.StatusChange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.find(change.UserID)
+	if !ok {
+		return domain.ErrUserNotFound
+	}
+	if stored.Status != change.FromStatus {
+		return domain.ErrUserConflict
+	}
+	next := *stored
+	next.Status = change.ToStatus
+	if err := checkValues(&next); err != nil {
+		return err
+	}
+	r.write(stored, &next)
+
+	cp := *change
+	cp.CreatedAt = next.UpdatedAt
+	r.changes = append(r.changes, &cp)
+	return nil
+}
+
+// ListStatusChanges 查询用户最近的状态变更记录，按时间倒序
+func (r *Repository) ListStatusChanges(_ context.Context, userID string, limit int) ([]*domain.StatusChange, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	changes := make([]*domain.StatusChange, 0)
+	for i := len(r.changes) - 1; i >= 0 && len(changes) < limit; i-- {
+		if r.changes[i].UserID == userID {
+			cp := *r.changes[i]
+			changes = append(changes, &cp)
+		}
+	}
+	return changes, nil
+}
+
+// list 按条件过滤未删除的用户并排序，limit 不大于 0 时不限制条数
+func (r *Repository) list(match func(u *domain.User) bool, cmp func(a, b *domain.User) int, limit int) []*domain.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]*domain.User, 0)
+	for _, rec := range r.users {
+		if !rec.deleted && match(&rec.user) {
+			users = append(users, clone(&rec.user))
+		}
+	}
+	slices.SortFunc(users, cmp)
+	if limit > 0 && len(users) > limit {
+		users = users[:limit]
+	}
+	return users
+}
+
+func compareID(a, b *domain.User) int {
+	switch {
+	case a.ID < b.ID:
+		return -1
+	case a.ID > b.ID:
+		return 1
+	}
+	return 0
+}
+
+// nonEmpty 空字符串按数据库实现存为 NULL
+func nonEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	v := *s
+	return &v
+}
+
+// clone 返回副本，调用方修改不影响存储
+func clone(u *domain.User) *domain.User {
+	c := *u
+	return &c
+}
@@ -0,0 +1,21 @@
+package usertest_test
+
+import (
+	"testing"
+
+	"arch3/internal/repository/repotest"
+	userservice "arch3/internal/service/user"
+	"arch3/internal/service/user/usertest"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.TestUserRepository(t, func(t *testing.T) userservice.Repository {
+		return usertest.NewRepository()
+	})
+}
+
+func TestLoginHistory(t *testing.T) {
+	repotest.TestLoginHistory(t, func(t *testing.T) userservice.LoginHistoryRepository {
+		return usertest.NewLoginHistory()
+	})
+}
@@ -0,0 +1,249 @@
+// Package dbreplica 为 GORM 提供读写分离: 读请求路由到从库，写请求与事务使用主库
+//
+// 基于 gorm.io/plugin/dbresolver，在其之上增加:
+//   - 从库健康检查: 定期 Ping，不健康的从库不再接收读请求；全部不健康时读请求回退到主库
+//   - 请求内读己之写: 通过 WithSession 创建会话的 context 中发生过写入后，
+//     后续读请求改走主库，避免主从延迟读到旧数据
+//   - 强制主库: WithPrimary 标记的 context 中所有读请求走主库
+//
+// 事务由 dbresolver 固定在主库执行，事务内的读写不会被路由到从库。
+package dbreplica
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// Replica 从库
+type Replica struct {
+	Name      string         // 用于日志与指标的标识，如 host:port
+	DB        *sql.DB        // 从库连接池
+	Dialector gorm.Dialector // 基于 DB 创建的 Dialector，交给 dbresolver 使用
+	healthy   atomic.Bool
+}
+
+// Healthy 从库最近一次健康检查是否通过
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// Config 读写分离配置
+type Config struct {
+	// HealthCheckInterval 健康检查间隔，默认 5 秒
+	HealthCheckInterval time.Duration
+	// HealthCheckTimeout 单次 Ping 超时，默认 1 秒
+	HealthCheckTimeout time.Duration
+	// OnStateChange 从库健康状态变化时回调，可用于记录日志
+	OnStateChange func(replica *Replica, healthy bool, err error)
+}
+
+// Resolver 读写分离路由
+type Resolver struct {
+	primary  gorm.ConnPool
+	replicas []*Replica
+	byPool   map[gorm.ConnPool]*Replica
+	cfg      Config
+	next     atomic.Uint64
+
+	started  atomic.Bool
+	stopOnce sync.Once
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New 创建读写分离路由，初始时所有从库视为健康
+// primary 为主库的连接池，从库全部不健康时读请求回退到主库
+func New(primary gorm.ConnPool, replicas []*Replica, cfg Config) *Resolver {
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = 5 * time.Second
+	}
+	if cfg.HealthCheckTimeout <= 0 {
+		cfg.HealthCheckTimeout = time.Second
+	}
+	r := &Resolver{
+		primary:  primary,
+		replicas: replicas,
+		byPool:   make(map[gorm.ConnPool]*Replica, len(replicas)),
+		cfg:      cfg,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, rep := range replicas {
+		rep.healthy.Store(true)
+		r.byPool[rep.DB] = rep
+	}
+	return r
+}
+
+// Replicas 返回全部从库
+func (r *Resolver) Replicas() []*Replica {
+	return r.replicas
+}
+
+// Register 把读写分离注册到 db
+// 需在 db 上其他插件（如 tracing）之后调用，保证回调对主从库的请求都生效
+func (r *Resolver) Register(db *gorm.DB) error {
+	dialectors := make([]gorm.Dialector, 0, len(r.replicas))
+	for _, rep := range r.replicas {
+		dialectors = append(dialectors, rep.Dialector)
+	}
+	if err := db.Use(dbresolver.Register(dbresolver.Config{
+		Replicas: dialectors,
+		Policy:   dbresolver.PolicyFunc(r.resolve),
+	})); err != nil {
+		return err
+	}
+
+	// 写入成功后标记会话；读请求执行前按会话状态决定是否改走主库
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("dbreplica:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("dbreplica:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Delete().After("gorm:delete").Register("dbreplica:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Raw().After("gorm:raw").Register("dbreplica:mark_write", markWrite); err != nil {
+		return err
+	}
+	if err := cb.Query().Before("gorm:query").Register("dbreplica:stick_primary", r.stickPrimary); err != nil {
+		return err
+	}
+	return cb.Row().Before("gorm:row").Register("dbreplica:stick_primary", r.stickPrimary)
+}
+
+// Start 启动后台健康检查
+func (r *Resolver) Start() {
+	if r.started.CompareAndSwap(false, true) {
+		go r.healthLoop()
+	}
+}
+
+// Close 停止健康检查并关闭从库连接池
+func (r *Resolver) Close() error {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	if r.started.Load() {
+		<-r.done
+	}
+	var firstErr error
+	for _, rep := range r.replicas {
+		if err := rep.DB.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// resolve dbresolver 的读路由策略: 在健康的从库间轮询，全部不健康时回退到主库
+// 注意 dbresolver 只有一个从库时不调用策略，全部不健康的回退由 stickPrimary 处理
+func (r *Resolver) resolve(pools []gorm.ConnPool) gorm.ConnPool {
+	n := len(pools)
+	start := int(r.next.Add(1) % uint64(n))
+	for i := 0; i < n; i++ {
+		pool := pools[(start+i)%n]
+		if rep, ok := r.byPool[pool]; !ok || rep.Healthy() {
+			return pool
+		}
+	}
+	return r.primary
+}
+
+// healthLoop 定期检查全部从库
+func (r *Resolver) healthLoop() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.CheckHealth(context.Background())
+		}
+	}
+}
+
+// CheckHealth 立即检查全部从库并更新健康状态
+func (r *Resolver) CheckHealth(ctx context.Context) {
+	for _, rep := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, r.cfg.HealthCheckTimeout)
+		err := rep.DB.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if rep.healthy.Swap(healthy) != healthy && r.cfg.OnStateChange != nil {
+			r.cfg.OnStateChange(rep, healthy, err)
+		}
+	}
+}
+
+// ========== 请求会话 ==========
+
+type sessionKey struct{}
+
+type primaryKey struct{}
+
+// session 一次请求内的读写状态
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession 为 ctx 创建读写会话
+// 会话内任意写入成功后，使用该 ctx（及其派生 ctx）的后续读请求都走主库
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// WithPrimary 标记 ctx 中的读请求全部走主库，用于不能容忍复制延迟的读取
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// usePrimary ctx 中的读请求是否应走主库
+func usePrimary(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	if forced, _ := ctx.Value(primaryKey{}).(bool); forced {
+		return true
+	}
+	s, _ := ctx.Value(sessionKey{}).(*session)
+	return s != nil && s.wrote.Load()
+}
+
+// markWrite 写入成功后标记会话
+func markWrite(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if s, _ := db.Statement.Context.Value(sessionKey{}).(*session); s != nil {
+		s.wrote.Store(true)
+	}
+}
+
+// stickPrimary 会话中已写入、强制主库或从库全部不健康时把读请求改到主库
+func (r *Resolver) stickPrimary(db *gorm.DB) {
+	if usePrimary(db.Statement.Context) || !r.anyHealthy() {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// anyHealthy 是否至少有一个健康的从库
+func (r *Resolver) anyHealthy() bool {
+	for _, rep := range r.replicas {
+		if rep.Healthy() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package dbreplica
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID   uint
+	Name string
+}
+
+// openSQLite 打开文件库并建表，主从库各一个文件，通过读到的数据判断请求落在哪个库
+func openSQLite(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), name+".db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	if err := db.Create(&item{ID: 1, Name: name}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return db
+}
+
+func newTestResolver(t *testing.T) (*gorm.DB, *Resolver) {
+	t.Helper()
+	db := openSQLite(t, "primary")
+	replicaDB := openSQLite(t, "replica")
+	sqlReplica, _ := replicaDB.DB()
+	sqlPrimary, _ := db.DB()
+
+	r := New(sqlPrimary, []*Replica{{
+		Name:      "replica",
+		DB:        sqlReplica,
+		Dialector: sqlite.Dialector{Conn: sqlReplica},
+	}}, Config{})
+	if err := r.Register(db); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	t.Cleanup(func() { _ = r.Close() })
+	return db, r
+}
+
+// readFrom 读取 id=1 的记录名，即请求落在的库
+func readFrom(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var it item
+	if err := db.First(&it, 1).Error; err != nil {
+		t.Fatalf("First() error = %v", err)
+	}
+	return it.Name
+}
+
+func TestResolver_Routing(t *testing.T) {
+	db, _ := newTestResolver(t)
+	ctx := context.Background()
+
+	session := WithSession(ctx)
+	tests := []struct {
+		name string
+		run  func() string
+		want string
+	}{
+		{"读请求走从库", func() string { return readFrom(t, db.WithContext(ctx)) }, "replica"},
+		{"强制主库", func() string { return readFrom(t, db.WithContext(WithPrimary(ctx))) }, "primary"},
+		{"会话写入前读从库", func() string { return readFrom(t, db.WithContext(session)) }, "replica"},
+		{"会话写入后读主库", func() string {
+			if err := db.WithContext(session).Create(&item{ID: 2, Name: "new"}).Error; err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			return readFrom(t, db.WithContext(session))
+		}, "primary"},
+		{"其他请求不受会话影响", func() string { return readFrom(t, db.WithContext(WithSession(ctx))) }, "replica"},
+		{"事务内读主库", func() string {
+			var name string
+			_ = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				name = readFrom(t, tx)
+				return nil
+			})
+			return name
+		}, "primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.run(); got != tt.want {
+				t.Errorf("Expected read from %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolver_HealthFallback(t *testing.T) {
+	db, r := newTestResolver(t)
+	ctx := context.Background()
+
+	var changes []bool
+	r.cfg.OnStateChange = func(_ *Replica, healthy bool, _ error) { changes = append(changes, healthy) }
+
+	// 关闭从库连接池模拟从库不可用
+	_ = r.Replicas()[0].DB.Close()
+	r.CheckHealth(ctx)
+
+	if r.Replicas()[0].Healthy() {
+		t.Fatal("Expected replica unhealthy after ping failure")
+	}
+	if len(changes) != 1 || changes[0] {
+		t.Errorf("Expected one unhealthy transition, got %v", changes)
+	}
+	if got := readFrom(t, db.WithContext(ctx)); got != "primary" {
+		t.Errorf("Expected fallback to primary, got %s", got)
+	}
+}
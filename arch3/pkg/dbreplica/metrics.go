@@ -0,0 +1,96 @@
+package dbreplica
+
+import (
+	"context"
+	"database/sql"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// 连接池指标，主库与每个从库各一组，以 db.role（primary/replica）与 db.instance 区分:
+//   - arch3.db.pool.connections (gauge) - 连接数，state 为 idle 或 in_use
+//   - arch3.db.pool.max_open (gauge) - 最大连接数
+//   - arch3.db.pool.wait.total (counter) - 等待空闲连接的累计次数
+//   - arch3.db.pool.wait.duration (counter) - 等待空闲连接的累计时长（秒）
+//   - arch3.db.replica.healthy (gauge) - 从库健康状态，1 健康 0 不健康
+
+// RegisterMetrics 注册连接池与从库健康指标
+// r 为 nil 时只上报主库
+func RegisterMetrics(meter metric.Meter, primaryName string, primary *sql.DB, r *Resolver) error {
+	type pool struct {
+		attrs   metric.MeasurementOption
+		db      *sql.DB
+		replica *Replica
+	}
+	pools := []pool{{
+		attrs: metric.WithAttributes(attribute.String("db.role", "primary"), attribute.String("db.instance", primaryName)),
+		db:    primary,
+	}}
+	if r != nil {
+		for _, rep := range r.replicas {
+			pools = append(pools, pool{
+				attrs:   metric.WithAttributes(attribute.String("db.role", "replica"), attribute.String("db.instance", rep.Name)),
+				db:      rep.DB,
+				replica: rep,
+			})
+		}
+	}
+
+	connections, err := meter.Int64ObservableGauge("arch3.db.pool.connections",
+		metric.WithDescription("Number of connections in the pool by state"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+	maxOpen, err := meter.Int64ObservableGauge("arch3.db.pool.max_open",
+		metric.WithDescription("Maximum number of open connections"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return err
+	}
+	waitTotal, err := meter.Int64ObservableCounter("arch3.db.pool.wait.total",
+		metric.WithDescription("Total number of connections waited for"),
+		metric.WithUnit("{wait}"),
+	)
+	if err != nil {
+		return err
+	}
+	waitDuration, err := meter.Float64ObservableCounter("arch3.db.pool.wait.duration",
+		metric.WithDescription("Total time blocked waiting for a new connection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return err
+	}
+	healthy, err := meter.Int64ObservableGauge("arch3.db.replica.healthy",
+		metric.WithDescription("Whether the replica passed its last health check (1) or not (0)"),
+	)
+	if err != nil {
+		return err
+	}
+
+	idleState := attribute.String("state", "idle")
+	inUseState := attribute.String("state", "in_use")
+	_, err = meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, p := range pools {
+			stats := p.db.Stats()
+			o.ObserveInt64(connections, int64(stats.Idle), p.attrs, metric.WithAttributes(idleState))
+			o.ObserveInt64(connections, int64(stats.InUse), p.attrs, metric.WithAttributes(inUseState))
+			o.ObserveInt64(maxOpen, int64(stats.MaxOpenConnections), p.attrs)
+			o.ObserveInt64(waitTotal, stats.WaitCount, p.attrs)
+			o.ObserveFloat64(waitDuration, stats.WaitDuration.Seconds(), p.attrs)
+			if p.replica != nil {
+				var v int64
+				if p.replica.Healthy() {
+					v = 1
+				}
+				o.ObserveInt64(healthy, v, p.attrs)
+			}
+		}
+		return nil
+	}, connections, maxOpen, waitTotal, waitDuration, healthy)
+	return err
+}
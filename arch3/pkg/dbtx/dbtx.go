@@ -0,0 +1,204 @@
+// Package dbtx 基于 context 传递的 GORM 事务管理
+//
+// Manager.Do 开启事务并把事务放入 fn 收到的 ctx，仓储通过 Conn(ctx, db) 取连接，
+// 在事务内自动使用事务连接，事务外使用普通连接，无需在方法签名中传递 *gorm.DB。
+//
+//   - 嵌套: 已在事务中再次调用 Do 时创建保存点，fn 返回错误只回滚到保存点，外层事务可继续
+//   - 重试: 最外层事务因死锁或序列化冲突失败时整体重试，fn 须可重复执行（不在 fn 中做外部副作用）
+//   - 提交后操作: AfterCommit 注册的操作在最外层事务提交后执行，回滚（含重试前的回滚）时丢弃；
+//     适合缓存失效、发送通知等不能回滚的副作用
+package dbtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Config 事务配置
+type Config struct {
+	// MaxRetries 死锁或序列化冲突时最外层事务的最大重试次数，0 表示不重试
+	MaxRetries int
+	// RetryBackoff 首次重试前的等待时间，之后按次数线性增加并带随机抖动，默认 20ms
+	RetryBackoff time.Duration
+}
+
+// Manager 事务管理器
+type Manager struct {
+	db  *gorm.DB
+	cfg Config
+}
+
+// New 创建事务管理器，db 须为主库连接（启用读写分离时 dbresolver 会把事务固定在主库）
+func New(db *gorm.DB, cfg Config) *Manager {
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 20 * time.Millisecond
+	}
+	return &Manager{db: db, cfg: cfg}
+}
+
+type txKey struct{}
+
+// txState ctx 中的事务状态，每层 Do 一个
+type txState struct {
+	tx    *gorm.DB
+	depth int
+
+	mu    sync.Mutex
+	hooks []func(ctx context.Context)
+}
+
+func (s *txState) addHook(fn func(ctx context.Context)) {
+	s.mu.Lock()
+	s.hooks = append(s.hooks, fn)
+	s.mu.Unlock()
+}
+
+func (s *txState) takeHooks() []func(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hooks := s.hooks
+	s.hooks = nil
+	return hooks
+}
+
+// Do 在事务中执行 fn
+// fn 返回错误或 panic 时回滚；已在事务中时以保存点嵌套
+func (m *Manager) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if parent, ok := ctx.Value(txKey{}).(*txState); ok {
+		return m.nested(ctx, parent, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		hooks, err := m.run(ctx, fn)
+		if err == nil {
+			for _, h := range hooks {
+				h(ctx)
+			}
+			return nil
+		}
+		if attempt >= m.cfg.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+		if err := m.backoff(ctx, attempt); err != nil {
+			return err
+		}
+	}
+}
+
+// run 执行一次最外层事务，提交成功后返回待执行的提交后操作
+func (m *Manager) run(ctx context.Context, fn func(ctx context.Context) error) (hooks []func(ctx context.Context), err error) {
+	tx := m.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	state := &txState{tx: tx}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	committed = true
+	return state.takeHooks(), nil
+}
+
+// nested 以保存点执行嵌套事务，成功后把提交后操作交给外层
+func (m *Manager) nested(ctx context.Context, parent *txState, fn func(ctx context.Context) error) error {
+	state := &txState{tx: parent.tx, depth: parent.depth + 1}
+	name := fmt.Sprintf("dbtx_sp%d", state.depth)
+	if err := parent.tx.SavePoint(name).Error; err != nil {
+		return err
+	}
+
+	released := false
+	defer func() {
+		if !released {
+			parent.tx.RollbackTo(name)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		return err
+	}
+	released = true
+	for _, h := range state.takeHooks() {
+		parent.addHook(h)
+	}
+	return nil
+}
+
+// backoff 重试前等待，ctx 取消时返回 ctx 的错误
+func (m *Manager) backoff(ctx context.Context, attempt int) error {
+	d := m.cfg.RetryBackoff * time.Duration(attempt+1)
+	d += time.Duration(rand.Int64N(int64(m.cfg.RetryBackoff)))
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Conn 返回 ctx 对应的数据库连接: 在事务中返回事务连接，否则返回 db.WithContext(ctx)
+// 返回的连接都带有 ctx，链路追踪与超时对事务内的语句同样生效
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if s, ok := ctx.Value(txKey{}).(*txState); ok {
+		return s.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// InTx ctx 是否在事务中
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// AfterCommit 注册最外层事务提交后执行的操作，ctx 不在事务中时立即执行
+// 保存点回滚时，该保存点内注册的操作一并丢弃
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if s, ok := ctx.Value(txKey{}).(*txState); ok {
+		s.addHook(fn)
+		return
+	}
+	fn(ctx)
+}
+
+// IsRetryable 错误是否为可重试的事务冲突
+//   - MySQL: 1213 死锁、1205 锁等待超时
+//   - PostgreSQL: 40001 序列化失败、40P01 死锁
+//   - SQLite: 5 SQLITE_BUSY、6 SQLITE_LOCKED
+func IsRetryable(err error) bool {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		return myErr.Number == 1213 || myErr.Number == 1205
+	}
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		code := pgErr.SQLState()
+		return code == "40001" || code == "40P01"
+	}
+	var liteErr interface{ Code() int }
+	if errors.As(err, &liteErr) {
+		code := liteErr.Code() & 0xff // 扩展错误码的低 8 位为基础错误码
+		return code == 5 || code == 6
+	}
+	return false
+}
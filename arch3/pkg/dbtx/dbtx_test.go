@@ -0,0 +1,253 @@
+package dbtx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type item struct {
+	ID   uint
+	Name string
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	return db
+}
+
+// names 按 ID 顺序返回已提交的记录名
+func names(t *testing.T, db *gorm.DB) []string {
+	t.Helper()
+	var items []item
+	if err := db.Order("id").Find(&items).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.Name)
+	}
+	return out
+}
+
+func insert(ctx context.Context, db *gorm.DB, name string) error {
+	return Conn(ctx, db).Create(&item{Name: name}).Error
+}
+
+func TestManager_Do(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context, m *Manager, db *gorm.DB) error
+		wantErr   error
+		wantNames string
+		wantHooks string
+	}{
+		{
+			name: "提交后执行提交后操作",
+			fn: func(ctx context.Context, m *Manager, db *gorm.DB) error {
+				return insert(ctx, db, "a")
+			},
+			wantNames: "[a]",
+			wantHooks: "[a]",
+		},
+		{
+			name: "返回错误时回滚且丢弃提交后操作",
+			fn: func(ctx context.Context, m *Manager, db *gorm.DB) error {
+				if err := insert(ctx, db, "a"); err != nil {
+					return err
+				}
+				return errBoom
+			},
+			wantErr:   errBoom,
+			wantNames: "[]",
+			wantHooks: "[]",
+		},
+		{
+			name: "嵌套失败只回滚到保存点",
+			fn: func(ctx context.Context, m *Manager, db *gorm.DB) error {
+				if err := insert(ctx, db, "outer"); err != nil {
+					return err
+				}
+				err := m.Do(ctx, func(ctx context.Context) error {
+					if err := insert(ctx, db, "inner"); err != nil {
+						return err
+					}
+					return errBoom
+				})
+				if !errors.Is(err, errBoom) {
+					return fmt.Errorf("nested error = %v", err)
+				}
+				return insert(ctx, db, "after")
+			},
+			wantNames: "[outer after]",
+			wantHooks: "[outer after]",
+		},
+		{
+			name: "嵌套成功随外层提交",
+			fn: func(ctx context.Context, m *Manager, db *gorm.DB) error {
+				return m.Do(ctx, func(ctx context.Context) error {
+					return insert(ctx, db, "inner")
+				})
+			},
+			wantNames: "[inner]",
+			wantHooks: "[inner]",
+		},
+		{
+			name: "外层失败时嵌套的写入与提交后操作一并丢弃",
+			fn: func(ctx context.Context, m *Manager, db *gorm.DB) error {
+				if err := m.Do(ctx, func(ctx context.Context) error {
+					return insert(ctx, db, "inner")
+				}); err != nil {
+					return err
+				}
+				return errBoom
+			},
+			wantErr:   errBoom,
+			wantNames: "[]",
+			wantHooks: "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openSQLite(t)
+			m := New(db, Config{})
+
+			var hooks []string
+			// 每次写入后注册一个提交后操作，验证回滚时被丢弃
+			db.Callback().Create().After("gorm:create").Register("test:hook", func(tx *gorm.DB) {
+				name := tx.Statement.Dest.(*item).Name
+				AfterCommit(tx.Statement.Context, func(context.Context) {
+					hooks = append(hooks, name)
+				})
+			})
+
+			err := m.Do(context.Background(), func(ctx context.Context) error {
+				return tt.fn(ctx, m, db)
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got := fmt.Sprint(names(t, db)); got != tt.wantNames {
+				t.Errorf("Expected committed rows %s, got %s", tt.wantNames, got)
+			}
+			if got := fmt.Sprint(hooks); got != tt.wantHooks {
+				t.Errorf("Expected hooks %s, got %s", tt.wantHooks, got)
+			}
+		})
+	}
+}
+
+func TestManager_Retry(t *testing.T) {
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+
+	tests := []struct {
+		name         string
+		maxRetries   int
+		failures     int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "死锁后重试成功", maxRetries: 2, failures: 2, wantAttempts: 3},
+		{name: "超过重试次数返回错误", maxRetries: 1, failures: 2, wantAttempts: 2, wantErr: true},
+		{name: "不重试", maxRetries: 0, failures: 1, wantAttempts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openSQLite(t)
+			m := New(db, Config{MaxRetries: tt.maxRetries})
+
+			attempts := 0
+			err := m.Do(context.Background(), func(ctx context.Context) error {
+				attempts++
+				if err := insert(ctx, db, fmt.Sprint(attempts)); err != nil {
+					return err
+				}
+				if attempts <= tt.failures {
+					return fmt.Errorf("insert: %w", deadlock)
+				}
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+			// 失败的尝试已回滚，最多只有最后一次的写入
+			if rows := names(t, db); len(rows) > 1 {
+				t.Errorf("Expected failed attempts to be rolled back, got %v", rows)
+			}
+		})
+	}
+}
+
+func TestConn_OutsideTransaction(t *testing.T) {
+	db := openSQLite(t)
+	ctx := context.Background()
+	if InTx(ctx) {
+		t.Fatal("Expected background context not to be in a transaction")
+	}
+	if err := insert(ctx, db, "a"); err != nil {
+		t.Fatalf("insert() error = %v", err)
+	}
+
+	ran := false
+	AfterCommit(ctx, func(context.Context) { ran = true })
+	if !ran {
+		t.Error("Expected AfterCommit to run immediately outside a transaction")
+	}
+}
+
+type sqlStateErr string
+
+func (e sqlStateErr) Error() string    { return string(e) }
+func (e sqlStateErr) SQLState() string { return string(e) }
+
+type codeErr int
+
+func (e codeErr) Error() string { return fmt.Sprint(int(e)) }
+func (e codeErr) Code() int     { return int(e) }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "MySQL 死锁", err: &mysql.MySQLError{Number: 1213}, want: true},
+		{name: "MySQL 锁等待超时", err: &mysql.MySQLError{Number: 1205}, want: true},
+		{name: "MySQL 唯一键冲突", err: &mysql.MySQLError{Number: 1062}, want: false},
+		{name: "PostgreSQL 序列化失败", err: sqlStateErr("40001"), want: true},
+		{name: "PostgreSQL 死锁", err: fmt.Errorf("wrapped: %w", sqlStateErr("40P01")), want: true},
+		{name: "PostgreSQL 唯一键冲突", err: sqlStateErr("23505"), want: false},
+		{name: "SQLite BUSY 扩展错误码", err: codeErr(5 | 2<<8), want: true},
+		{name: "SQLite 约束失败", err: codeErr(19), want: false},
+		{name: "普通错误", err: errors.New("boom"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Package fieldcrypt 提供数据库字段级信封加密与盲索引
+//
+// 信封加密: 每个值使用随机生成的数据密钥（DEK）以 AES-256-GCM 加密，
+// DEK 再由带版本号的主密钥（KEK）加密后与密文一起保存。
+// 密文格式: "env:{KEK 版本}:{base64(加密的 DEK)}:{base64(nonce || 密文)}"。
+//
+// 密钥轮换: 向 Keyring 加入更高版本的 KEK 后，新数据使用新版本加密，
+// 旧数据仍可用旧版本解密；NeedsReencrypt 判断值是否需要重新加密。
+//
+// 兼容格式（只读）:
+//   - "enc:..." 为早期直接使用主密钥加密的格式，使用版本 1 的 KEK 解密
+//   - 不带前缀的值视为加密上线前写入的明文，原样返回
+//
+// 调用方应传入 associated data（如 "users.id_number:{user_id}"），
+// 把密文绑定到具体的行与列，防止密文在行间或列间被挪用。
+//
+// 盲索引: 随机 nonce 使密文无法用于等值查询与唯一约束，
+// BlindIndex 使用独立密钥计算 HMAC，供查询与唯一索引使用。
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// KeySize 密钥长度（AES-256）
+const KeySize = 32
+
+const (
+	envelopePrefix = "env:"
+	legacyPrefix   = "enc:"
+	legacyVersion  = 1 // 早期格式使用的 KEK 版本
+)
+
+var (
+	// ErrInvalidKey 密钥长度错误
+	ErrInvalidKey = errors.New("fieldcrypt: key must be 32 bytes")
+	// ErrNoKey 没有可用的主密钥
+	ErrNoKey = errors.New("fieldcrypt: no key encryption key")
+	// ErrUnknownVersion 密文使用的主密钥版本不在 Keyring 中
+	ErrUnknownVersion = errors.New("fieldcrypt: unknown key version")
+	// ErrDecrypt 密文损坏、被篡改或 associated data 不匹配
+	ErrDecrypt = errors.New("fieldcrypt: decrypt failed")
+)
+
+// Keyring 字段加密密钥环，并发安全
+type Keyring struct {
+	keks     map[int]cipher.AEAD
+	active   int // 加密使用的 KEK 版本（最大版本）
+	indexKey []byte
+}
+
+// NewKeyring 创建密钥环
+// keks 为版本号到主密钥的映射，加密使用最大版本；indexKey 为盲索引密钥
+func NewKeyring(keks map[int][]byte, indexKey []byte) (*Keyring, error) {
+	if len(keks) == 0 {
+		return nil, ErrNoKey
+	}
+	if len(indexKey) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	k := &Keyring{keks: make(map[int]cipher.AEAD, len(keks)), indexKey: indexKey}
+	for version, key := range keks {
+		if version <= 0 {
+			return nil, ErrUnknownVersion
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keks[version] = aead
+		k.active = max(k.active, version)
+	}
+	return k, nil
+}
+
+// ActiveVersion 当前加密使用的 KEK 版本
+func (k *Keyring) ActiveVersion() int {
+	return k.active
+}
+
+// Encrypt 信封加密明文，空字符串原样返回（保持 NULL/空值语义）
+func (k *Keyring) Encrypt(plaintext, aad string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	dek := make([]byte, KeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keks[k.active], dek, aad)
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(data, []byte(plaintext), aad)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.StdEncoding
+	return envelopePrefix + strconv.Itoa(k.active) + ":" + enc.EncodeToString(wrapped) + ":" + enc.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密任一支持的格式；不带密文前缀的值视为明文原样返回
+func (k *Keyring) Decrypt(value, aad string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envelopePrefix):
+		version, wrapped, sealed, err := parseEnvelope(value)
+		if err != nil {
+			return "", err
+		}
+		kek, ok := k.keks[version]
+		if !ok {
+			return "", ErrUnknownVersion
+		}
+		dek, err := open(kek, wrapped, aad)
+		if err != nil {
+			return "", err
+		}
+		data, err := newAEAD(dek)
+		if err != nil {
+			return "", ErrDecrypt
+		}
+		plaintext, err := open(data, sealed, aad)
+		return string(plaintext), err
+
+	case strings.HasPrefix(value, legacyPrefix):
+		kek, ok := k.keks[legacyVersion]
+		if !ok {
+			return "", ErrUnknownVersion
+		}
+		sealed, err := base64.StdEncoding.DecodeString(value[len(legacyPrefix):])
+		if err != nil {
+			return "", ErrDecrypt
+		}
+		plaintext, err := open(kek, sealed, aad)
+		return string(plaintext), err
+
+	default:
+		return value, nil
+	}
+}
+
+// NeedsReencrypt 值是否需要重新加密: 明文、早期格式或非当前版本的 KEK
+func (k *Keyring) NeedsReencrypt(value string) bool {
+	if value == "" {
+		return false
+	}
+	if !strings.HasPrefix(value, envelopePrefix) {
+		return true
+	}
+	version, _, _, err := parseEnvelope(value)
+	return err != nil || version != k.active
+}
+
+// BlindIndex 计算盲索引: hex(HMAC-SHA256(indexKey, column \0 value))
+// column 区分不同列，相同的值在不同列得到不同索引；空值返回空字符串
+func (k *Keyring) BlindIndex(column, value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(column))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseEnvelope 解析信封格式
+func parseEnvelope(value string) (version int, wrapped, sealed []byte, err error) {
+	parts := strings.Split(value[len(envelopePrefix):], ":")
+	if len(parts) != 3 {
+		return 0, nil, nil, ErrDecrypt
+	}
+	version, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, nil, nil, ErrDecrypt
+	}
+	enc := base64.StdEncoding
+	if wrapped, err = enc.DecodeString(parts[1]); err != nil {
+		return 0, nil, nil, ErrDecrypt
+	}
+	if sealed, err = enc.DecodeString(parts[2]); err != nil {
+		return 0, nil, nil, ErrDecrypt
+	}
+	return version, wrapped, sealed, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal 加密，输出 nonce || 密文
+func seal(aead cipher.AEAD, plaintext []byte, aad string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, []byte(aad)), nil
+}
+
+// open 解密 seal 的输出
+func open(aead cipher.AEAD, sealed []byte, aad string) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(aad))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// EncryptedLen 明文长度为 n 字节时信封密文的最大长度（KEK 版本不超过 4 位），用于确定列宽
+func EncryptedLen(n int) int {
+	const nonceSize, tagSize = 12, 16
+	enc := base64.StdEncoding
+	return len(envelopePrefix) + 4 + 1 + enc.EncodedLen(nonceSize+KeySize+tagSize) + 1 + enc.EncodedLen(nonceSize+n+tagSize)
+}
@@ -0,0 +1,176 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func newTestKeyring(t *testing.T, versions ...int) *Keyring {
+	t.Helper()
+	keks := make(map[int][]byte, len(versions))
+	for _, v := range versions {
+		keks[v] = testKey(byte(v))
+	}
+	k, err := NewKeyring(keks, testKey(0xff))
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return k
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	k := newTestKeyring(t, 1)
+
+	enc, err := k.Encrypt("110101199003077777", "users.id_number:u1")
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if !strings.HasPrefix(enc, "env:1:") {
+		t.Fatalf("Expected envelope with version 1, got %q", enc)
+	}
+	if len(enc) > EncryptedLen(18) {
+		t.Errorf("Expected length <= %d, got %d", EncryptedLen(18), len(enc))
+	}
+
+	again, _ := k.Encrypt("110101199003077777", "users.id_number:u1")
+	if again == enc {
+		t.Error("Expected random keys to produce different ciphertexts")
+	}
+
+	got, err := k.Decrypt(enc, "users.id_number:u1")
+	if err != nil || got != "110101199003077777" {
+		t.Errorf("Expected round trip, got %q, %v", got, err)
+	}
+}
+
+func TestDecryptRejects(t *testing.T) {
+	k := newTestKeyring(t, 1)
+	other := newTestKeyring(t, 2)
+	enc, _ := k.Encrypt("张三", "users.real_name:u1")
+	parts := strings.Split(enc, ":")
+
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		value   string
+		aad     string
+		wantErr error
+	}{
+		{"缺少密钥版本", other, enc, "users.real_name:u1", ErrUnknownVersion},
+		{"行不符", k, enc, "users.real_name:u2", ErrDecrypt},
+		{"列不符", k, enc, "users.id_number:u1", ErrDecrypt},
+		{"篡改密文", k, strings.Join(parts[:3], ":") + ":" + parts[3][:len(parts[3])-4] + "AAAA", "users.real_name:u1", ErrDecrypt},
+		{"格式错误", k, "env:1:xx", "users.real_name:u1", ErrDecrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.keyring.Decrypt(tt.value, tt.aad); err != tt.wantErr {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := newTestKeyring(t, 1)
+	rotated := newTestKeyring(t, 1, 2)
+
+	enc, _ := old.Encrypt("13800000000", "users.phone_number:u1")
+	if old.NeedsReencrypt(enc) {
+		t.Error("Expected value under active key not to need re-encryption")
+	}
+	if !rotated.NeedsReencrypt(enc) {
+		t.Error("Expected value under old key to need re-encryption")
+	}
+
+	// 旧版本密文在轮换后仍可解密
+	if got, err := rotated.Decrypt(enc, "users.phone_number:u1"); err != nil || got != "13800000000" {
+		t.Fatalf("Expected old ciphertext readable, got %q, %v", got, err)
+	}
+
+	reenc, _ := rotated.Encrypt("13800000000", "users.phone_number:u1")
+	if !strings.HasPrefix(reenc, "env:2:") || rotated.NeedsReencrypt(reenc) {
+		t.Errorf("Expected re-encryption under version 2, got %q", reenc)
+	}
+}
+
+func TestCompatFormats(t *testing.T) {
+	k := newTestKeyring(t, 1, 2)
+
+	// 早期格式: "enc:" + base64(nonce || 密文)，直接使用版本 1 的 KEK
+	sealed, err := seal(k.keks[1], []byte("张三"), "users.real_name:u1")
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+	legacy := "enc:" + base64.StdEncoding.EncodeToString(sealed)
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"早期格式", legacy, "张三"},
+		{"明文", "张三", "张三"},
+		{"空值", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := k.Decrypt(tt.value, "users.real_name:u1")
+			if err != nil || got != tt.want {
+				t.Errorf("Expected %q, got %q, %v", tt.want, got, err)
+			}
+			if tt.value != "" && !k.NeedsReencrypt(tt.value) {
+				t.Error("Expected compat format to need re-encryption")
+			}
+		})
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k := newTestKeyring(t, 1)
+	other, _ := NewKeyring(map[int][]byte{1: testKey(1)}, testKey(0xee))
+
+	a := k.BlindIndex("phone_number", "13800000000")
+	if len(a) != 64 || a != k.BlindIndex("phone_number", "13800000000") {
+		t.Fatalf("Expected stable 64-char index, got %q", a)
+	}
+	if a == k.BlindIndex("id_number", "13800000000") {
+		t.Error("Expected different index per column")
+	}
+	if a == other.BlindIndex("phone_number", "13800000000") {
+		t.Error("Expected different index per key")
+	}
+	if k.BlindIndex("phone_number", "") != "" {
+		t.Error("Expected empty index for empty value")
+	}
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		keks     map[int][]byte
+		indexKey []byte
+		wantErr  error
+	}{
+		{"无主密钥", nil, testKey(1), ErrNoKey},
+		{"主密钥长度错误", map[int][]byte{1: []byte("short")}, testKey(1), ErrInvalidKey},
+		{"索引密钥长度错误", map[int][]byte{1: testKey(1)}, []byte("short"), ErrInvalidKey},
+		{"版本非正数", map[int][]byte{0: testKey(1)}, testKey(1), ErrUnknownVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.keks, tt.indexKey); err != tt.wantErr {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package imaging 提供上传图片的解码、缩略图与重新编码
+//
+// 仅依赖标准库，支持 JPEG 与 PNG。重新编码会丢弃 EXIF 等全部元数据，
+// JPEG 的 EXIF 方向在解码时应用到像素上，去除元数据后显示方向不变。
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// 支持的图片格式
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+)
+
+const jpegQuality = 85
+
+var (
+	// ErrUnsupportedFormat 图片格式不支持
+	ErrUnsupportedFormat = errors.New("imaging: unsupported format")
+	// ErrTooManyPixels 图片像素数超过限制（防止解压炸弹）
+	ErrTooManyPixels = errors.New("imaging: too many pixels")
+)
+
+// Decode 解码图片并应用 JPEG EXIF 方向
+// 先读取图片头校验格式与尺寸，超过 maxPixels 的图片不会被完整解码
+func Decode(data []byte, maxPixels int) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, "", ErrUnsupportedFormat
+		}
+		return nil, "", err
+	}
+	if format != FormatJPEG && format != FormatPNG {
+		return nil, "", ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxPixels/cfg.Height {
+		return nil, "", ErrTooManyPixels
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if format == FormatJPEG {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Thumbnail 居中裁剪为正方形并缩放到 size×size
+// 原图短边小于 size 时不放大，返回短边尺寸的正方形
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		b.Min.X+(b.Dx()-side)/2,
+		b.Min.Y+(b.Dy()-side)/2,
+	))
+
+	src := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(src, src.Bounds(), img, crop.Min, draw.Src)
+
+	if side <= size {
+		return src
+	}
+	return boxResize(src, size)
+}
+
+// Encode 按格式编码图片，不写入任何元数据
+func Encode(w io.Writer, img image.Image, format string) error {
+	switch format {
+	case FormatJPEG:
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	case FormatPNG:
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		return enc.Encode(w, img)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// ContentType 返回格式对应的 MIME 类型
+func ContentType(format string) string {
+	switch format {
+	case FormatJPEG:
+		return "image/jpeg"
+	case FormatPNG:
+		return "image/png"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Ext 返回格式对应的文件扩展名（不含点）
+func Ext(format string) string {
+	if format == FormatJPEG {
+		return "jpg"
+	}
+	return format
+}
+
+// boxResize 将正方形图片缩小到 size×size
+// 每个目标像素取其覆盖的源像素区域平均值（预乘 alpha），缩小时无锯齿
+func boxResize(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	for dy := 0; dy < size; dy++ {
+		y0, y1 := dy*side/size, (dy+1)*side/size
+		for dx := 0; dx < size; dx++ {
+			x0, x1 := dx*side/size, (dx+1)*side/size
+
+			var r, g, bl, a, n uint64
+			for y := y0; y < y1; y++ {
+				row := src.Pix[y*src.Stride+x0*4 : y*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					bl += uint64(row[i+2])
+					a += uint64(row[i+3])
+					n++
+				}
+			}
+
+			off := dy*dst.Stride + dx*4
+			dst.Pix[off] = uint8(r / n)
+			dst.Pix[off+1] = uint8(g / n)
+			dst.Pix[off+2] = uint8(bl / n)
+			dst.Pix[off+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
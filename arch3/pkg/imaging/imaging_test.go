@@ -0,0 +1,180 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// newTwoColorImage 左半红色、右半蓝色
+func newTwoColorImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= w/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+// withExifOrientation 在 SOI 之后插入只含方向标签的 EXIF 段
+func withExifOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(8))      // IFD0 偏移
+	_ = binary.Write(&tiff, binary.BigEndian, uint16(1))      // 条目数
+	_ = binary.Write(&tiff, binary.BigEndian, uint16(0x0112)) // 方向标签
+	_ = binary.Write(&tiff, binary.BigEndian, uint16(3))      // SHORT
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(1))      // 数量
+	_ = binary.Write(&tiff, binary.BigEndian, orientation)    // 值
+	_ = binary.Write(&tiff, binary.BigEndian, uint16(0))      // 填充
+	_ = binary.Write(&tiff, binary.BigEndian, uint32(0))      // 下一个 IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var out bytes.Buffer
+	out.Write(jpg[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	_ = binary.Write(&out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+	out.Write(jpg[2:])
+	return out.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecode_Orientation(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation uint16
+		wantW       int
+		wantH       int
+		wantTopLeft string // 左上角颜色: red/blue
+	}{
+		{name: "无旋转", orientation: 1, wantW: 32, wantH: 16, wantTopLeft: "red"},
+		{name: "旋转 180°", orientation: 3, wantW: 32, wantH: 16, wantTopLeft: "blue"},
+		{name: "顺时针 90°", orientation: 6, wantW: 16, wantH: 32, wantTopLeft: "red"},
+		{name: "逆时针 90°", orientation: 8, wantW: 16, wantH: 32, wantTopLeft: "blue"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := withExifOrientation(t, encodeJPEG(t, newTwoColorImage(32, 16)), tt.orientation)
+
+			img, format, err := Decode(data, 1<<20)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if format != FormatJPEG {
+				t.Errorf("Expected format jpeg, got %s", format)
+			}
+			if b := img.Bounds(); b.Dx() != tt.wantW || b.Dy() != tt.wantH {
+				t.Errorf("Expected %dx%d, got %dx%d", tt.wantW, tt.wantH, b.Dx(), b.Dy())
+			}
+			r, _, bl, _ := img.At(img.Bounds().Min.X+1, img.Bounds().Min.Y+1).RGBA()
+			got := "blue"
+			if r > bl {
+				got = "red"
+			}
+			if got != tt.wantTopLeft {
+				t.Errorf("Expected top-left %s, got %s", tt.wantTopLeft, got)
+			}
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var gifBuf bytes.Buffer
+	_ = gif.Encode(&gifBuf, image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black}), nil)
+
+	tests := []struct {
+		name      string
+		data      []byte
+		maxPixels int
+		wantErr   error
+	}{
+		{name: "非图片", data: []byte("not an image"), maxPixels: 1 << 20, wantErr: ErrUnsupportedFormat},
+		{name: "GIF 不支持", data: gifBuf.Bytes(), maxPixels: 1 << 20, wantErr: ErrUnsupportedFormat},
+		{name: "像素超限", data: encodeJPEG(t, newTwoColorImage(32, 16)), maxPixels: 100, wantErr: ErrTooManyPixels},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(tt.data, tt.maxPixels)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestThumbnailAndEncode(t *testing.T) {
+	data := withExifOrientation(t, encodeJPEG(t, newTwoColorImage(300, 200)), 6)
+	img, format, err := Decode(data, 1<<20)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		size     int
+		wantSide int
+	}{
+		{name: "缩小", size: 64, wantSide: 64},
+		{name: "不放大", size: 512, wantSide: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb := Thumbnail(img, tt.size)
+			if b := thumb.Bounds(); b.Dx() != tt.wantSide || b.Dy() != tt.wantSide {
+				t.Errorf("Expected %dx%d, got %dx%d", tt.wantSide, tt.wantSide, b.Dx(), b.Dy())
+			}
+
+			var buf bytes.Buffer
+			if err := Encode(&buf, thumb, format); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if bytes.Contains(buf.Bytes(), []byte("Exif")) {
+				t.Error("Expected EXIF stripped from output")
+			}
+			if jpegOrientation(buf.Bytes()) != orientationNormal {
+				t.Error("Expected no orientation in output")
+			}
+		})
+	}
+}
+
+func TestThumbnail_PNGAlpha(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, src) // 全透明
+
+	img, format, err := Decode(buf.Bytes(), 1<<20)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if format != FormatPNG {
+		t.Fatalf("Expected format png, got %s", format)
+	}
+	if _, _, _, a := Thumbnail(img, 10).At(5, 5).RGBA(); a != 0 {
+		t.Errorf("Expected transparent pixel preserved, got alpha %d", a)
+	}
+}
@@ -42,9 +42,23 @@ type Config struct {
 type Claims struct {
 	UserID    string `json:"user_id"`
 	TokenType string `json:"token_type"` // access 或 refresh
+	// IssuedAtMs 毫秒精度的签发时间，用于判断是否早于用户级撤销时间
+	// （标准 iat 仅精确到秒，撤销后同一秒内重新签发的 token 无法区分）
+	IssuedAtMs int64 `json:"iat_ms,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// issuedAtMilli 返回签发时间（毫秒），兼容未携带 iat_ms 的旧 token
+func (c *Claims) issuedAtMilli() int64 {
+	if c.IssuedAtMs > 0 {
+		return c.IssuedAtMs
+	}
+	if c.IssuedAt != nil {
+		return c.IssuedAt.UnixMilli()
+	}
+	return 0
+}
+
 // TokenPair 访问令牌对
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
@@ -88,8 +102,9 @@ func (m *Manager) GenerateTokenPair(userID string) (*TokenPair, error) {
 	// 生成 Access Token (短 token)
 	accessJTI := uuid.New().String()
 	accessClaims := &Claims{
-		UserID:    userID,
-		TokenType: TokenTypeAccess,
+		UserID:     userID,
+		TokenType:  TokenTypeAccess,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        accessJTI,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -106,8 +121,9 @@ func (m *Manager) GenerateTokenPair(userID string) (*TokenPair, error) {
 	// 生成 Refresh Token (长 token)
 	refreshJTI := uuid.New().String()
 	refreshClaims := &Claims{
-		UserID:    userID,
-		TokenType: TokenTypeRefresh,
+		UserID:     userID,
+		TokenType:  TokenTypeRefresh,
+		IssuedAtMs: now.UnixMilli(),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        refreshJTI,
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -235,6 +251,31 @@ func (m *Manager) RevokeRefreshToken(ctx context.Context, jti string) error {
 	return m.AddTokenToBlacklist(ctx, jti, m.refreshExpire)
 }
 
+// RevokeUserTokens 撤销用户在此之前签发的所有 token（如更换手机号、封禁后强制下线）
+// 撤销记录保留一个 refresh token 有效期，之后旧 token 已自然过期
+func (m *Manager) RevokeUserTokens(ctx context.Context, userID string) error {
+	key := fmt.Sprintf("token:revoked_before:%s", userID)
+	return m.rdb.Set(ctx, key, time.Now().UnixMilli(), m.refreshExpire).Err()
+}
+
+// IsTokenRevoked 检查 token 是否已失效: 单个 token 在黑名单中，或签发时间早于用户级撤销时间
+func (m *Manager) IsTokenRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	blacklisted, err := m.IsTokenBlacklisted(ctx, claims.ID)
+	if err != nil || blacklisted {
+		return blacklisted, err
+	}
+
+	key := fmt.Sprintf("token:revoked_before:%s", claims.UserID)
+	revokedBefore, err := m.rdb.Get(ctx, key).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, err
+	}
+	return claims.issuedAtMilli() < revokedBefore, nil
+}
+
 // GetAccessExpire 获取 access token 过期时间
 func (m *Manager) GetAccessExpire() time.Duration {
 	return m.accessExpire
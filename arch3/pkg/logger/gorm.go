@@ -0,0 +1,104 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// GormConfig GORM 日志配置
+type GormConfig struct {
+	// Level 日志级别: Silent 不输出; Error 只输出失败的 SQL; Warn 另输出慢查询; Info 输出全部 SQL（Debug 级别）
+	Level gormlogger.LogLevel
+	// SlowThreshold 慢查询阈值，执行时间超过该值的 SQL 以 Warn 级别输出，0 表示不检测慢查询
+	SlowThreshold time.Duration
+	// LogParams 是否在 SQL 中输出参数值
+	// 默认输出带占位符的 SQL，避免手机号、密文等参数写入日志
+	LogParams bool
+}
+
+// GormLogger 通过 zap 输出 GORM 日志，实现 gorm logger.Interface
+//
+// 日志经 Ctx(ctx) 输出，带有 trace_id/span_id，可与请求链路关联。
+// gorm.ErrRecordNotFound 由 Repository 转换为业务错误，不作为错误输出。
+type GormLogger struct {
+	cfg GormConfig
+}
+
+// NewGormLogger 创建 GORM 日志适配器
+func NewGormLogger(cfg GormConfig) *GormLogger {
+	return &GormLogger{cfg: cfg}
+}
+
+var (
+	_ gormlogger.Interface = (*GormLogger)(nil)
+	_ gorm.ParamsFilter    = (*GormLogger)(nil)
+)
+
+// LogMode 返回指定级别的副本
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	cp := *l
+	cp.cfg.Level = level
+	return &cp
+}
+
+// Info 输出 GORM 内部信息
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...any) {
+	if l.cfg.Level >= gormlogger.Info {
+		Ctx(ctx).Info(fmt.Sprintf(msg, data...), zap.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Warn 输出 GORM 内部警告
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...any) {
+	if l.cfg.Level >= gormlogger.Warn {
+		Ctx(ctx).Warn(fmt.Sprintf(msg, data...), zap.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Error 输出 GORM 内部错误
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...any) {
+	if l.cfg.Level >= gormlogger.Error {
+		Ctx(ctx).Error(fmt.Sprintf(msg, data...), zap.String("source", utils.FileWithLineNum()))
+	}
+}
+
+// Trace 输出 SQL 执行结果: 失败、慢查询与（Info 级别时）全部 SQL
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.cfg.Level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	fields := func() []zap.Field {
+		sql, rows := fc()
+		return []zap.Field{
+			zap.String("sql", sql),
+			zap.Int64("rows", rows),
+			zap.Duration("elapsed", elapsed),
+			zap.String("source", utils.FileWithLineNum()),
+		}
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.cfg.Level >= gormlogger.Error:
+		Ctx(ctx).Error("sql failed", append(fields(), zap.Error(err))...)
+	case l.cfg.SlowThreshold > 0 && elapsed > l.cfg.SlowThreshold && l.cfg.Level >= gormlogger.Warn:
+		Ctx(ctx).Warn("slow sql", append(fields(), zap.Duration("threshold", l.cfg.SlowThreshold))...)
+	case l.cfg.Level >= gormlogger.Info:
+		Ctx(ctx).Debug("sql", fields()...)
+	}
+}
+
+// ParamsFilter 未开启 LogParams 时去掉参数值，日志中的 SQL 保留占位符
+func (l *GormLogger) ParamsFilter(_ context.Context, sql string, params ...any) (string, []any) {
+	if l.cfg.LogParams {
+		return sql, params
+	}
+	return sql, nil
+}
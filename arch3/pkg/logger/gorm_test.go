@@ -0,0 +1,202 @@
+package logger
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type gormUser struct {
+	ID    uint
+	Phone string
+}
+
+// observeLogs 将全局 logger 替换为记录日志的 observer，测试结束后恢复
+func observeLogs(t *testing.T) *observer.ObservedLogs {
+	t.Helper()
+	core, logs := observer.New(zapcore.DebugLevel)
+	prev := zapLogger
+	zapLogger = zap.New(core)
+	t.Cleanup(func() { zapLogger = prev })
+	return logs
+}
+
+func openGormDB(t *testing.T, cfg GormConfig) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "gorm.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	if err := db.AutoMigrate(&gormUser{}); err != nil {
+		t.Fatalf("AutoMigrate() error = %v", err)
+	}
+	if err := db.Create(&gormUser{Phone: "13800000000"}).Error; err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	return db.Session(&gorm.Session{Logger: NewGormLogger(cfg)})
+}
+
+func TestGormLogger_Trace(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       GormConfig
+		query     func(db *gorm.DB) error
+		wantLevel zapcore.Level
+		wantMsg   string // 为空表示不输出日志
+		wantSQL   string // 日志 sql 字段应包含的内容
+		hideSQL   string // 日志 sql 字段不应包含的内容
+	}{
+		{
+			name: "Info 级别输出全部 SQL，默认隐藏参数",
+			cfg:  GormConfig{Level: gormlogger.Info},
+			query: func(db *gorm.DB) error {
+				return db.Where("phone = ?", "13800000000").Find(&[]gormUser{}).Error
+			},
+			wantLevel: zapcore.DebugLevel,
+			wantMsg:   "sql",
+			wantSQL:   "phone = ?",
+			hideSQL:   "13800000000",
+		},
+		{
+			name: "开启 LogParams 输出参数",
+			cfg:  GormConfig{Level: gormlogger.Info, LogParams: true},
+			query: func(db *gorm.DB) error {
+				return db.Where("phone = ?", "13800000000").Find(&[]gormUser{}).Error
+			},
+			wantLevel: zapcore.DebugLevel,
+			wantMsg:   "sql",
+			wantSQL:   `phone = "13800000000"`,
+		},
+		{
+			name: "Warn 级别不输出普通 SQL",
+			cfg:  GormConfig{Level: gormlogger.Warn, SlowThreshold: time.Hour},
+			query: func(db *gorm.DB) error {
+				return db.Find(&[]gormUser{}).Error
+			},
+		},
+		{
+			name: "超过阈值输出慢查询",
+			cfg:  GormConfig{Level: gormlogger.Warn, SlowThreshold: time.Nanosecond},
+			query: func(db *gorm.DB) error {
+				return db.Where("phone = ?", "13800000000").Find(&[]gormUser{}).Error
+			},
+			wantLevel: zapcore.WarnLevel,
+			wantMsg:   "slow sql",
+			wantSQL:   "phone = ?",
+			hideSQL:   "13800000000",
+		},
+		{
+			name: "Error 级别不输出慢查询",
+			cfg:  GormConfig{Level: gormlogger.Error, SlowThreshold: time.Nanosecond},
+			query: func(db *gorm.DB) error {
+				return db.Find(&[]gormUser{}).Error
+			},
+		},
+		{
+			name: "SQL 失败输出错误",
+			cfg:  GormConfig{Level: gormlogger.Error},
+			query: func(db *gorm.DB) error {
+				db.Table("missing").Where("phone = ?", "13800000000").Find(&[]gormUser{})
+				return nil
+			},
+			wantLevel: zapcore.ErrorLevel,
+			wantMsg:   "sql failed",
+			wantSQL:   "missing",
+			hideSQL:   "13800000000",
+		},
+		{
+			name: "记录不存在不输出错误",
+			cfg:  GormConfig{Level: gormlogger.Error},
+			query: func(db *gorm.DB) error {
+				db.Where("id = ?", 100).First(&gormUser{})
+				return nil
+			},
+		},
+		{
+			name: "Silent 级别不输出",
+			cfg:  GormConfig{Level: gormlogger.Silent, SlowThreshold: time.Nanosecond},
+			query: func(db *gorm.DB) error {
+				db.Table("missing").Find(&[]gormUser{})
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openGormDB(t, tt.cfg)
+			logs := observeLogs(t)
+
+			if err := tt.query(db); err != nil {
+				t.Fatalf("query error = %v", err)
+			}
+
+			entries := logs.All()
+			if tt.wantMsg == "" {
+				if len(entries) != 0 {
+					t.Errorf("Expected no logs, got %d: %v", len(entries), entries[0].Message)
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("Expected 1 log, got %d", len(entries))
+			}
+			entry := entries[0]
+			if entry.Level != tt.wantLevel || entry.Message != tt.wantMsg {
+				t.Errorf("Expected %s %q, got %s %q", tt.wantLevel, tt.wantMsg, entry.Level, entry.Message)
+			}
+			sql, _ := entry.ContextMap()["sql"].(string)
+			if !strings.Contains(sql, tt.wantSQL) {
+				t.Errorf("Expected sql to contain %q, got %q", tt.wantSQL, sql)
+			}
+			if tt.hideSQL != "" && strings.Contains(sql, tt.hideSQL) {
+				t.Errorf("Expected sql not to contain %q, got %q", tt.hideSQL, sql)
+			}
+		})
+	}
+}
+
+func TestGormLogger_TraceID(t *testing.T) {
+	db := openGormDB(t, GormConfig{Level: gormlogger.Info})
+	logs := observeLogs(t)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	if err := db.WithContext(ctx).Find(&[]gormUser{}).Error; err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()[TraceIDKey]; got != sc.TraceID().String() {
+		t.Errorf("Expected trace_id %s, got %v", sc.TraceID(), got)
+	}
+}
+
+func TestGormLogger_LogMode(t *testing.T) {
+	l := NewGormLogger(GormConfig{Level: gormlogger.Info})
+	silent := l.LogMode(gormlogger.Silent)
+
+	if silent.(*GormLogger).cfg.Level != gormlogger.Silent {
+		t.Errorf("Expected Silent level, got %v", silent.(*GormLogger).cfg.Level)
+	}
+	if l.cfg.Level != gormlogger.Info {
+		t.Errorf("Expected original level unchanged, got %v", l.cfg.Level)
+	}
+}
@@ -0,0 +1,19 @@
+// Package mailer 定义邮件消息与发送接口
+//
+// SMTP 实现见 internal/integration/email；Memory 为内存实现，
+// 只记录消息不实际投递，用于测试与本地开发。
+package mailer
+
+import "context"
+
+// Message 邮件消息
+type Message struct {
+	To      string // 收件人地址
+	Subject string // 主题
+	Body    string // 正文（纯文本）
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory 内存邮件发送器
+// 并发安全，发送的消息按顺序保存
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+// NewMemory 创建内存邮件发送器
+func NewMemory() *Memory {
+	return &Memory{}
+}
+
+var _ Mailer = (*Memory)(nil)
+
+// Send 记录邮件；设置了 FailWith 时返回该错误且不记录
+func (m *Memory) Send(_ context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// FailWith 设置后续发送返回的错误，传 nil 恢复正常
+func (m *Memory) FailWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+// Messages 返回已发送的邮件副本
+func (m *Memory) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.messages...)
+}
+
+// Last 返回发往 to 的最后一封邮件
+func (m *Memory) Last(to string) (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// Reset 清空已发送的邮件
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
@@ -0,0 +1,66 @@
+package migrate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Create 在每个 dir 下创建下一个版本的空迁移文件，返回创建的文件路径
+//
+// 多个目录（如各驱动各一套迁移）使用同一版本号，取所有目录中已有最大版本加 1，格式化为 6 位数字。
+func Create(name string, dirs ...string) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, fmt.Errorf("migrate: name must match %s", namePattern)
+	}
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("migrate: no directory given")
+	}
+
+	var latest int64
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			match := fileNamePattern.FindStringSubmatch(entry.Name())
+			if match == nil {
+				continue
+			}
+			if v, err := strconv.ParseInt(match[1], 10, 64); err == nil && v > latest {
+				latest = v
+			}
+		}
+	}
+
+	base := Migration{Version: latest + 1, Name: name}.String()
+	var created []string
+	for _, dir := range dirs {
+		for _, direction := range []string{"up", "down"} {
+			path := filepath.Join(dir, base+"."+direction+".sql")
+			if err := writeNew(path, "-- "+base+" "+direction+"\n"); err != nil {
+				for _, p := range created {
+					_ = os.Remove(p)
+				}
+				return nil, err
+			}
+			created = append(created, path)
+		}
+	}
+	return created, nil
+}
+
+// writeNew 创建文件并写入内容，文件已存在时报错
+func writeNew(path, content string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("migrate: create %s: %w", path, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("migrate: write %s: %w", path, err)
+	}
+	return f.Close()
+}
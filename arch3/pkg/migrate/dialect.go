@@ -0,0 +1,151 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dialect 封装迁移记录表、占位符与咨询锁的数据库差异
+type dialect interface {
+	// createTableSQL 返回创建迁移记录表的语句（需幂等）
+	createTableSQL(table string) string
+	// rebind 把 "?" 占位符转换为数据库使用的格式
+	rebind(query string) string
+	// lock 在 conn 所在会话上获取咨询锁，超时返回 ErrLockTimeout
+	lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) error
+	// unlock 释放 lock 获取的咨询锁
+	unlock(ctx context.Context, conn *sql.Conn, name string) error
+}
+
+// dialectFor 按驱动名返回 dialect
+func dialectFor(driver string) (dialect, error) {
+	switch driver {
+	case "mysql":
+		return mysqlDialect{}, nil
+	case "postgres":
+		return postgresDialect{}, nil
+	case "sqlite":
+		return sqliteDialect{}, nil
+	default:
+		return nil, fmt.Errorf("migrate: unsupported driver %q", driver)
+	}
+}
+
+// mysqlDialect MySQL 实现
+// 锁名带上当前库名，同一 MySQL 实例上的不同库互不阻塞
+type mysqlDialect struct{}
+
+func (mysqlDialect) createTableSQL(table string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + ` (
+    version    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    dirty      BOOLEAN      NOT NULL DEFAULT FALSE,
+    applied_at DATETIME(3)  NOT NULL,
+    PRIMARY KEY (version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`
+}
+
+func (mysqlDialect) rebind(query string) string { return query }
+
+func (mysqlDialect) lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) error {
+	var got sql.NullInt64
+	err := conn.QueryRowContext(ctx,
+		"SELECT GET_LOCK(CONCAT(DATABASE(), '.', ?), ?)", name, int(timeout.Seconds()),
+	).Scan(&got)
+	if err != nil {
+		return fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+	if !got.Valid || got.Int64 != 1 {
+		return ErrLockTimeout
+	}
+	return nil
+}
+
+func (mysqlDialect) unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(CONCAT(DATABASE(), '.', ?))", name)
+	return err
+}
+
+// postgresDialect PostgreSQL 实现
+// 会话级咨询锁以 "库名.表名" 的哈希为键；pg_advisory_lock 不支持超时，改为轮询 pg_try_advisory_lock
+type postgresDialect struct{}
+
+// postgresLockPoll 轮询咨询锁的间隔
+const postgresLockPoll = 500 * time.Millisecond
+
+func (postgresDialect) createTableSQL(table string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + ` (
+    version    BIGINT       NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    dirty      BOOLEAN      NOT NULL DEFAULT FALSE,
+    applied_at TIMESTAMPTZ  NOT NULL
+)`
+}
+
+func (postgresDialect) rebind(query string) string {
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (postgresDialect) lock(ctx context.Context, conn *sql.Conn, name string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var got bool
+		err := conn.QueryRowContext(ctx,
+			"SELECT pg_try_advisory_lock(hashtext(current_database() || '.' || $1))", name,
+		).Scan(&got)
+		if err != nil {
+			return fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+		if got {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(postgresLockPoll):
+		}
+	}
+}
+
+func (postgresDialect) unlock(ctx context.Context, conn *sql.Conn, name string) error {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext(current_database() || '.' || $1))", name)
+	return err
+}
+
+// sqliteDialect SQLite 实现
+// SQLite 为单机文件或内存库，写入本身由数据库文件锁串行化，不需要咨询锁
+type sqliteDialect struct{}
+
+func (sqliteDialect) createTableSQL(table string) string {
+	return "CREATE TABLE IF NOT EXISTS " + table + ` (
+    version    INTEGER      NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    dirty      BOOLEAN      NOT NULL DEFAULT FALSE,
+    applied_at DATETIME     NOT NULL
+)`
+}
+
+func (sqliteDialect) rebind(query string) string { return query }
+
+func (sqliteDialect) lock(context.Context, *sql.Conn, string, time.Duration) error { return nil }
+
+func (sqliteDialect) unlock(context.Context, *sql.Conn, string) error { return nil }
@@ -0,0 +1,415 @@
+// Package migrate 提供版本化的 SQL 数据库迁移
+//
+// 迁移文件命名: "{版本}_{名称}.up.sql" 与 "{版本}_{名称}.down.sql"，
+// 版本为正整数（如 000001），名称只含小写字母、数字与下划线。
+// 每个版本必须同时提供 up 与 down 两个文件。
+//
+// 已执行的版本记录在迁移表（默认 schema_migrations）中。
+// 执行迁移前先写入 dirty=true 的记录，全部语句成功后再清除 dirty 标记；
+// MySQL 的 DDL 会隐式提交，迁移中途失败时无法回滚（为统一行为，PostgreSQL 与 SQLite 同样逐条执行），
+// 记录保持 dirty 状态，后续 Up/Down 拒绝执行，需人工修复后处理该记录。
+//
+// 支持的驱动: mysql、postgres、sqlite。迁移脚本本身按驱动分别编写。
+//
+// 并发: Up/Down 在执行前获取数据库级咨询锁（MySQL GET_LOCK、PostgreSQL pg_advisory_lock），
+// 多个实例同时启动时只有一个执行迁移，其余等待锁释放后发现已无待执行版本。
+//
+// 语句拆分: 迁移文件按行尾的分号拆分为多条语句逐条执行，
+// 因此不要在字符串字面量中出现行尾分号；以 "--" 开头的整行注释会被忽略。
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable 默认迁移记录表名
+const DefaultTable = "schema_migrations"
+
+// defaultLockTimeout 等待咨询锁的默认超时
+const defaultLockTimeout = time.Minute
+
+var (
+	// ErrDirty 存在执行失败的迁移，需人工修复
+	ErrDirty = errors.New("migrate: database is dirty")
+
+	// ErrLockTimeout 等待咨询锁超时
+	ErrLockTimeout = errors.New("migrate: lock timeout")
+
+	fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	namePattern     = regexp.MustCompile(`^[a-z0-9_]+$`)
+)
+
+// Migration 单个迁移版本
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// String 返回 "{版本}_{名称}"
+func (m Migration) String() string {
+	return fmt.Sprintf("%06d_%s", m.Version, m.Name)
+}
+
+// Status 迁移版本的执行状态
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	Missing   bool // 数据库中有记录但迁移文件不存在
+	AppliedAt time.Time
+}
+
+// record 迁移表中的一行
+type record struct {
+	version   int64
+	name      string
+	dirty     bool
+	appliedAt time.Time
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db          *sql.DB
+	migrations  []Migration
+	table       string
+	lockTimeout time.Duration
+	dialect     dialect
+}
+
+// Option 迁移执行器配置项
+type Option func(*Migrator)
+
+// WithTable 设置迁移记录表名
+func WithTable(table string) Option {
+	return func(m *Migrator) { m.table = table }
+}
+
+// WithLockTimeout 设置等待咨询锁的超时
+func WithLockTimeout(d time.Duration) Option {
+	return func(m *Migrator) { m.lockTimeout = d }
+}
+
+// New 创建迁移执行器
+// driver 为 mysql、postgres 或 sqlite；fsys 的根目录下直接存放该驱动的迁移文件
+func New(db *sql.DB, driver string, fsys fs.FS, opts ...Option) (*Migrator, error) {
+	d, err := dialectFor(driver)
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	m := &Migrator{
+		db:          db,
+		migrations:  migrations,
+		table:       DefaultTable,
+		lockTimeout: defaultLockTimeout,
+		dialect:     d,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if !namePattern.MatchString(m.table) {
+		return nil, fmt.Errorf("migrate: invalid table name %q", m.table)
+	}
+	return m, nil
+}
+
+// Migrations 返回按版本升序排列的全部迁移
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Load 读取 fsys 根目录下的迁移文件，按版本升序返回
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileNamePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrate: invalid migration file name %q", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrate: invalid version in %q", entry.Name())
+		}
+		name, direction := match[2], match[3]
+
+		key := strconv.FormatInt(version, 10) + "." + direction
+		if seen[key] {
+			return nil, fmt.Errorf("migrate: duplicate %s migration for version %d", direction, version)
+		}
+		seen[key] = true
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: read %s: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("migrate: version %d has mismatched names %q and %q", version, mig.Name, name)
+		}
+		if direction == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, mig := range byVersion {
+		if !seen[strconv.FormatInt(version, 10)+".up"] || !seen[strconv.FormatInt(version, 10)+".down"] {
+			return nil, fmt.Errorf("migrate: version %d must have both up and down files", version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up 执行全部未执行的迁移，返回本次执行的版本
+//
+// 版本号低于已执行最大版本的迁移（如并行分支合并后）同样会按版本顺序补执行。
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		done := make(map[int64]bool, len(records))
+		for _, r := range records {
+			done[r.version] = true
+		}
+		for _, mig := range m.migrations {
+			if done[mig.Version] {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig, mig.Up, true); err != nil {
+				return err
+			}
+			applied = append(applied, mig)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的版本
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("migrate: steps must be positive")
+	}
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		records, err := m.records(ctx, conn)
+		if err != nil {
+			return err
+		}
+		byVersion := make(map[int64]Migration, len(m.migrations))
+		for _, mig := range m.migrations {
+			byVersion[mig.Version] = mig
+		}
+		for i := len(records) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig, ok := byVersion[records[i].version]
+			if !ok {
+				return fmt.Errorf("migrate: no migration file for applied version %d", records[i].version)
+			}
+			if err := m.apply(ctx, conn, mig, mig.Down, false); err != nil {
+				return err
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status 返回全部迁移的执行状态，按版本升序
+// 包括数据库中有记录但迁移文件已不存在的版本
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	records, err := m.loadRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Status, len(m.migrations))
+	for _, mig := range m.migrations {
+		byVersion[mig.Version] = &Status{Version: mig.Version, Name: mig.Name}
+	}
+	for _, r := range records {
+		st, ok := byVersion[r.version]
+		if !ok {
+			st = &Status{Version: r.version, Name: r.name, Missing: true}
+			byVersion[r.version] = st
+		}
+		st.Applied = true
+		st.Dirty = r.dirty
+		st.AppliedAt = r.appliedAt
+	}
+
+	statuses := make([]Status, 0, len(byVersion))
+	for _, st := range byVersion {
+		statuses = append(statuses, *st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// withLock 在独占连接上持有咨询锁执行 fn
+// 咨询锁绑定数据库会话，加锁、迁移、解锁必须使用同一连接
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if err := m.dialect.lock(ctx, conn, m.table, m.lockTimeout); err != nil {
+		return err
+	}
+	// 使用独立 context 解锁，调用方取消后仍能释放；连接关闭时数据库也会自动释放
+	defer func() { _ = m.dialect.unlock(context.Background(), conn, m.table) }()
+
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// records 读取已执行的版本，存在 dirty 记录时返回 ErrDirty
+func (m *Migrator) records(ctx context.Context, conn *sql.Conn) ([]record, error) {
+	records, err := m.loadRecords(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		if r.dirty {
+			return nil, fmt.Errorf("%w: version %d did not complete, fix the schema manually then delete or clear the row in %s",
+				ErrDirty, r.version, m.table)
+		}
+	}
+	return records, nil
+}
+
+// apply 执行单个迁移的 up 或 down 脚本并维护迁移记录
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration, script string, up bool) error {
+	if up {
+		if _, err := conn.ExecContext(ctx,
+			m.dialect.rebind("INSERT INTO "+m.table+" (version, name, dirty, applied_at) VALUES (?, ?, ?, ?)"),
+			mig.Version, mig.Name, true, time.Now(),
+		); err != nil {
+			return fmt.Errorf("migrate: record %s: %w", mig, err)
+		}
+	} else {
+		if _, err := conn.ExecContext(ctx,
+			m.dialect.rebind("UPDATE "+m.table+" SET dirty = ? WHERE version = ?"), true, mig.Version,
+		); err != nil {
+			return fmt.Errorf("migrate: record %s: %w", mig, err)
+		}
+	}
+
+	for _, stmt := range SplitStatements(script) {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("migrate: %s: %w", mig, err)
+		}
+	}
+
+	var err error
+	if up {
+		_, err = conn.ExecContext(ctx, m.dialect.rebind("UPDATE "+m.table+" SET dirty = ? WHERE version = ?"), false, mig.Version)
+	} else {
+		_, err = conn.ExecContext(ctx, m.dialect.rebind("DELETE FROM "+m.table+" WHERE version = ?"), mig.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate: record %s: %w", mig, err)
+	}
+	return nil
+}
+
+// ensureTable 创建迁移记录表
+func (m *Migrator) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, m.dialect.createTableSQL(m.table)); err != nil {
+		return fmt.Errorf("migrate: create %s: %w", m.table, err)
+	}
+	return nil
+}
+
+// loadRecords 按版本升序读取迁移记录
+func (m *Migrator) loadRecords(ctx context.Context, conn *sql.Conn) ([]record, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, dirty, applied_at FROM "+m.table+" ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: read %s: %w", m.table, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var records []record
+	for rows.Next() {
+		var r record
+		if err := rows.Scan(&r.version, &r.name, &r.dirty, &r.appliedAt); err != nil {
+			return nil, fmt.Errorf("migrate: read %s: %w", m.table, err)
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// SplitStatements 按行尾分号把脚本拆分为语句，忽略空行与整行注释
+func SplitStatements(script string) []string {
+	var (
+		stmts []string
+		buf   strings.Builder
+	)
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(strings.TrimRight(line, " \t\r"))
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(buf.String()), ";"))
+			buf.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(buf.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
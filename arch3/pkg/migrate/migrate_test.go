@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoad(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	migrations, err := Load(fstest.MapFS{
+		"000002_add_index.up.sql":      file("CREATE INDEX i ON t (a);"),
+		"000002_add_index.down.sql":    file("DROP INDEX i ON t;"),
+		"000001_create_table.up.sql":   file("CREATE TABLE t (a INT);"),
+		"000001_create_table.down.sql": file("DROP TABLE t;"),
+		"README.md":                    file("ignored"),
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].String() != "000001_create_table" || migrations[1].String() != "000002_add_index" {
+		t.Fatalf("Expected 2 migrations in version order, got %v", migrations)
+	}
+	if migrations[0].Down != "DROP TABLE t;" {
+		t.Errorf("Expected down script loaded, got %q", migrations[0].Down)
+	}
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"缺少 down", fstest.MapFS{"000001_a.up.sql": file("")}},
+		{"名称不一致", fstest.MapFS{"000001_a.up.sql": file(""), "000001_b.down.sql": file("")}},
+		{"文件名非法", fstest.MapFS{"1-a.up.sql": file("")}},
+		{"版本为 0", fstest.MapFS{"0_a.up.sql": file(""), "0_a.down.sql": file("")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(tt.fsys); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- 注释
+CREATE TABLE t (
+    a INT,
+    b INT
+);
+
+INSERT INTO t VALUES (1, 2); 
+DROP TABLE x`
+
+	want := []string{
+		"CREATE TABLE t (\n    a INT,\n    b INT\n)",
+		"INSERT INTO t VALUES (1, 2)",
+		"DROP TABLE x",
+	}
+	if got := SplitStatements(script); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	dirs := []string{filepath.Join(root, "mysql"), filepath.Join(root, "sqlite")}
+	for _, dir := range dirs {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"mysql/000001_a.up.sql", "sqlite/000007_b.down.sql"} {
+		if err := os.WriteFile(filepath.Join(root, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	created, err := Create("add_column", dirs...)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dirs[0], "000008_add_column.up.sql"),
+		filepath.Join(dirs[0], "000008_add_column.down.sql"),
+		filepath.Join(dirs[1], "000008_add_column.up.sql"),
+		filepath.Join(dirs[1], "000008_add_column.down.sql"),
+	}
+	if !reflect.DeepEqual(created, want) {
+		t.Errorf("Expected %v, got %v", want, created)
+	}
+
+	if _, err := Create("Bad-Name", dirs...); err == nil {
+		t.Error("Expected error for invalid name, got nil")
+	}
+}
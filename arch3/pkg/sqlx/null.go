@@ -4,7 +4,10 @@
 // 无法用单一泛型函数处理，因此保留类型特化函数。
 package sqlx
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 // NullStringToPtr 将 sql.NullString 转换为 *string
 func NullStringToPtr(ns sql.NullString) *string {
@@ -85,3 +88,19 @@ func PtrToNullBool(b *bool) sql.NullBool {
 	}
 	return sql.NullBool{}
 }
+
+// NullTimeToPtr 将 sql.NullTime 转换为 *time.Time
+func NullTimeToPtr(n sql.NullTime) *time.Time {
+	if n.Valid {
+		return &n.Time
+	}
+	return nil
+}
+
+// PtrToNullTime 将 *time.Time 转换为 sql.NullTime
+func PtrToNullTime(t *time.Time) sql.NullTime {
+	if t != nil {
+		return sql.NullTime{Time: *t, Valid: true}
+	}
+	return sql.NullTime{}
+}
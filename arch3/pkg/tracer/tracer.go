@@ -129,6 +129,7 @@ const (
 	AttrPhoneMasked = "phone.masked"
 	AttrSMSType     = "sms.type"
 	AttrSMSProvider = "sms.provider"
+	AttrOTPChannel  = "otp.channel"
 
 	// 错误相关
 	AttrErrorType    = "error.type"
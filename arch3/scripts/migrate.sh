@@ -0,0 +1,14 @@
+#!/usr/bin/env bash
+# 数据库迁移，参数透传给 server migrate 子命令
+#
+# 用法:
+#   scripts/migrate.sh up
+#   scripts/migrate.sh down [n]
+#   scripts/migrate.sh status
+#   scripts/migrate.sh create <name>
+#
+# 配置文件通过 CONFIG 环境变量指定，默认 config/config.dev.yaml
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+exec go run ./cmd/server -config "${CONFIG:-config/config.dev.yaml}" migrate "$@"